@@ -33,6 +33,7 @@ type App struct {
 	cancel   context.CancelFunc
 	mu       sync.Mutex
 	instance *registry.ServiceInstance
+	ready    *readiness
 }
 
 // New create an application lifecycle manager.
@@ -56,6 +57,7 @@ func New(opts ...Option) *App {
 		ctx:    ctx,
 		cancel: cancel,
 		opts:   o,
+		ready:  newReadiness(o),
 	}
 }
 
@@ -98,8 +100,8 @@ func (a *App) Run() error {
 		}
 	}
 	octx := NewContext(a.opts.ctx, a)
-	for _, srv := range a.opts.servers {
-		server := srv
+	for i, srv := range a.opts.servers {
+		i, server := i, srv
 		eg.Go(func() error {
 			<-ctx.Done() // wait for stop signal
 			stopCtx := octx
@@ -112,8 +114,15 @@ func (a *App) Run() error {
 		})
 		wg.Add(1)
 		eg.Go(func() error {
+			a.ready.server(i, nil)
 			wg.Done() // here is to ensure server start has begun running before register, so defer is not needed
-			return server.Start(octx)
+			err := server.Start(octx)
+			if err == nil {
+				a.ready.server(i, errStopped)
+			} else {
+				a.ready.server(i, err)
+			}
+			return err
 		})
 	}
 	wg.Wait()
@@ -121,8 +130,10 @@ func (a *App) Run() error {
 		rctx, rcancel := context.WithTimeout(ctx, a.opts.registrarTimeout)
 		defer rcancel()
 		if err = a.opts.registrar.Register(rctx, instance); err != nil {
+			a.ready.registrar(err)
 			return err
 		}
+		a.ready.registrar(nil)
 	}
 	for _, fn := range a.opts.afterStart {
 		if err = fn(sctx); err != nil {
@@ -161,6 +172,7 @@ func (a *App) Stop() (err error) {
 	instance := a.instance
 	a.mu.Unlock()
 	if a.opts.registrar != nil && instance != nil {
+		a.ready.registrar(errNotRegistered)
 		ctx, cancel := context.WithTimeout(NewContext(a.ctx, a), a.opts.registrarTimeout)
 		defer cancel()
 		if err = a.opts.registrar.Deregister(ctx, instance); err != nil {
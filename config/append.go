@@ -0,0 +1,91 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Append is the key of an object appending its array to the array of its
+// key in the merged config rather than replacing it, e.g. the YAML
+//
+//	allowed_origins:
+//	  $append: [https://example.com]
+//
+// appends to the origins of the config file below, creating the array if
+// it is missing. The elements the array already holds are skipped, so that
+// merging a source again, e.g. on a change of another source, does not
+// repeat them.
+const Append = "$append"
+
+// appendValue is the elements appended to the array at path.
+type appendValue struct {
+	path  []string
+	elems []any
+}
+
+// takeAppends removes the keys of values set to an Append object and
+// returns them.
+func takeAppends(values map[string]any) []appendValue {
+	var appends []appendValue
+	var walk func(m map[string]any, prefix []string)
+	walk = func(m map[string]any, prefix []string) {
+		for k, v := range m {
+			sub, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			if elems, ok := sub[Append].([]any); ok && len(sub) == 1 {
+				delete(m, k)
+				appends = append(appends, appendValue{path: append(append([]string(nil), prefix...), k), elems: elems})
+				continue
+			}
+			walk(sub, append(prefix, k))
+		}
+	}
+	walk(values, nil)
+	return appends
+}
+
+// appendPath appends the elements of a to the array at its path in values,
+// the ones not already in it.
+func appendPath(values map[string]any, a appendValue) {
+	next := values
+	for _, k := range a.path[:len(a.path)-1] {
+		v, ok := next[k]
+		if !ok {
+			sub := make(map[string]any)
+			next[k] = sub
+			next = sub
+			continue
+		}
+		if next, ok = v.(map[string]any); !ok {
+			log.Warnf("config: ignored the elements appended to %s, its parent is not an object", strings.Join(a.path, "."))
+			return
+		}
+	}
+	key := a.path[len(a.path)-1]
+	var array []any
+	if v, ok := next[key]; ok && v != nil {
+		if array, ok = v.([]any); !ok {
+			log.Warnf("config: ignored the elements appended to %s, the value is not an array", strings.Join(a.path, "."))
+			return
+		}
+	}
+	for _, e := range a.elems {
+		if !containsValue(array, e) {
+			array = append(array, e)
+		}
+	}
+	next[key] = array
+}
+
+func containsValue(array []any, v any) bool {
+	for _, e := range array {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
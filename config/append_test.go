@@ -0,0 +1,76 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	testAppendBase = `{
+		"server": {"origins": ["a", "b"], "name": "app"}
+	}`
+	testAppendOverride = `{
+		"server": {"origins": {"$append": ["b", "c"]}, "name": {"$append": ["ignored"]}},
+		"ports": {"$append": [8080]}
+	}`
+)
+
+func TestAppend(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(testAppendBase), newTestJSONSource(testAppendOverride)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var conf struct {
+		Server struct {
+			Origins []string `json:"origins"`
+			Name    string   `json:"name"`
+		} `json:"server"`
+		Ports []int `json:"ports"`
+	}
+	if err := c.Scan(&conf); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(conf.Server.Origins, want) {
+		t.Errorf("expected %v, got %v", want, conf.Server.Origins)
+	}
+	// an append to a value which is not an array is ignored
+	if conf.Server.Name != "app" {
+		t.Errorf("expected server.name kept, got %q", conf.Server.Name)
+	}
+	// a missing array is created
+	if want := []int{8080}; !reflect.DeepEqual(conf.Ports, want) {
+		t.Errorf("expected %v, got %v", want, conf.Ports)
+	}
+}
+
+func TestAppendReapplied(t *testing.T) {
+	r := newReader(options{decoder: defaultDecoder, resolver: defaultResolver, merge: New().(*config).opts.merge}).(*reader)
+	base := &KeyValue{Key: "base", Format: "json", Value: []byte(testAppendBase)}
+	override := &KeyValue{Key: "override", Format: "json", Value: []byte(testAppendOverride)}
+	origins := func() []any {
+		v, ok := r.Value("server.origins")
+		if !ok {
+			t.Fatal("expected server.origins")
+		}
+		return v.Load().([]any)
+	}
+	if err := r.Merge(base, override); err != nil {
+		t.Fatal(err)
+	}
+	// merged again on top of its own result, e.g. on a change of another source
+	if _, err := r.apply(override); err != nil {
+		t.Fatal(err)
+	}
+	if want := []any{"a", "b", "c"}; !reflect.DeepEqual(origins(), want) {
+		t.Errorf("expected %v unchanged, got %v", want, origins())
+	}
+	// a change of the base is merged again under the override
+	base = &KeyValue{Key: "base", Format: "json", Value: []byte(`{"server": {"origins": ["x"]}}`)}
+	if _, err := r.apply(base, override); err != nil {
+		t.Fatal(err)
+	}
+	if want := []any{"x", "b", "c"}; !reflect.DeepEqual(origins(), want) {
+		t.Errorf("expected %v, got %v", want, origins())
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"sync"
 	"time"
@@ -24,6 +25,9 @@ var ErrNotFound = errors.New("key not found") // ErrNotFound is key not found.
 // Observer is config observer.
 type Observer func(string, Value)
 
+// BatchObserver is config observer notified once per source change with the changed keys.
+type BatchObserver func(keys []string)
+
 // Config is a config interface.
 type Config interface {
 	Load() error
@@ -39,6 +43,9 @@ type config struct {
 	cached    sync.Map
 	observers sync.Map
 	watchers  []Watcher
+
+	loadedMu sync.Mutex
+	loaded   [][]*KeyValue
 }
 
 // New a config with options.
@@ -53,13 +60,16 @@ func New(opts ...Option) Config {
 	for _, opt := range opts {
 		opt(&o)
 	}
+	if o.strict {
+		o.resolver = strictResolver(o.resolver)
+	}
 	return &config{
 		opts:   o,
 		reader: newReader(o),
 	}
 }
 
-func (c *config) watch(w Watcher) {
+func (c *config) watch(index int, w Watcher) {
 	for {
 		kvs, err := w.Next()
 		if err != nil {
@@ -71,30 +81,61 @@ func (c *config) watch(w Watcher) {
 			log.Errorf("failed to watch next config: %v", err)
 			continue
 		}
-		if err := c.reader.Merge(kvs...); err != nil {
-			log.Errorf("failed to merge next config: %v", err)
-			continue
-		}
-		if err := c.reader.Resolve(); err != nil {
-			log.Errorf("failed to resolve next config: %v", err)
+		// merge the sources of higher precedence back on top, so that a
+		// change of a lower one never overrides their values.
+		changes, err := c.apply(append(kvs, c.above(index)...)...)
+		if err != nil {
+			log.Errorf("failed to apply next config: %v", err)
 			continue
 		}
+		c.record(index, kvs)
+		// update every cached value before notifying, so that observers
+		// never see a mix of old and new values from the same change.
+		var notify []string
 		c.cached.Range(func(key, value any) bool {
 			k := key.(string)
 			v := value.(Value)
 			if n, ok := c.reader.Value(k); ok && reflect.TypeOf(n.Load()) == reflect.TypeOf(v.Load()) && !reflect.DeepEqual(n.Load(), v.Load()) {
 				v.Store(n.Load())
-				if o, ok := c.observers.Load(k); ok {
-					o.(Observer)(k, v)
-				}
+				notify = append(notify, k)
 			}
 			return true
 		})
+		for _, k := range notify {
+			if o, ok := c.observers.Load(k); ok {
+				v, _ := c.cached.Load(k)
+				o.(Observer)(k, v.(Value))
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if c.opts.diff != nil {
+			c.opts.diff(c.opts.mask(changes))
+		}
+		if c.opts.batch != nil {
+			keys := make([]string, 0, len(changes))
+			for _, ch := range changes {
+				keys = append(keys, ch.Key)
+			}
+			c.opts.batch(keys)
+		}
+	}
+}
+
+// apply merges and resolves kvs as a single atomic change.
+func (c *config) apply(kvs ...*KeyValue) ([]Change, error) {
+	if r, ok := c.reader.(*reader); ok {
+		return r.apply(kvs...)
+	}
+	if err := c.reader.Merge(kvs...); err != nil {
+		return nil, err
 	}
+	return nil, c.reader.Resolve()
 }
 
 func (c *config) Load() error {
-	for _, src := range c.opts.sources {
+	for i, src := range c.opts.sources {
 		kvs, err := src.Load()
 		if err != nil {
 			return err
@@ -106,13 +147,21 @@ func (c *config) Load() error {
 			log.Errorf("failed to merge config source: %v", err)
 			return err
 		}
+		c.record(i, kvs)
 		w, err := src.Watch()
-		if err != nil {
+		if err != nil && !errors.Is(err, ErrWatchNotSupported) {
 			log.Errorf("failed to watch config source: %v", err)
 			return err
 		}
+		if err != nil || w == nil {
+			if c.opts.poll <= 0 {
+				log.Warnf("config source does not support watch, changes will not be reloaded")
+				continue
+			}
+			w = newPollWatcher(src, c.opts.poll, kvs)
+		}
 		c.watchers = append(c.watchers, w)
-		go c.watch(w)
+		go c.watch(i, w)
 	}
 	if err := c.reader.Resolve(); err != nil {
 		log.Errorf("failed to resolve config source: %v", err)
@@ -121,6 +170,9 @@ func (c *config) Load() error {
 	return nil
 }
 
+// Value returns the value of a dotted key such as "a.b", of a JSON Pointer
+// such as "/a/b/0/c" or of a JSONPath such as "$.a.b[0].c", the latter two
+// index into arrays. A missing value returns an error wrapping ErrNotFound.
 func (c *config) Value(key string) Value {
 	if v, ok := c.cached.Load(key); ok {
 		return v.(Value)
@@ -129,15 +181,86 @@ func (c *config) Value(key string) Value {
 		c.cached.Store(key, v)
 		return v
 	}
+	if r, ok := c.reader.(*reader); ok && isPath(key) {
+		if _, err := r.path(key); err != nil {
+			return &errValue{err: err}
+		}
+	}
 	return &errValue{err: ErrNotFound}
 }
 
+// getError annotates err with key, ErrNotFound stays matchable with errors.Is.
+func getError(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("config: get %q: %w", key, err)
+}
+
+// GetString returns the value of key in c converted to a string.
+func GetString(c Config, key string) (string, error) {
+	v, err := c.Value(key).String()
+	return v, getError(key, err)
+}
+
+// GetInt returns the value of key in c converted to an int64.
+func GetInt(c Config, key string) (int64, error) {
+	v, err := c.Value(key).Int()
+	return v, getError(key, err)
+}
+
+// GetBool returns the value of key in c converted to a bool.
+func GetBool(c Config, key string) (bool, error) {
+	v, err := c.Value(key).Bool()
+	return v, getError(key, err)
+}
+
+// GetFloat returns the value of key in c converted to a float64.
+func GetFloat(c Config, key string) (float64, error) {
+	v, err := c.Value(key).Float()
+	return v, getError(key, err)
+}
+
+// GetDuration returns the value of key in c converted to a time.Duration.
+// Both an integer number of nanoseconds and a duration string such as
+// "1.5s" are accepted.
+func GetDuration(c Config, key string) (time.Duration, error) {
+	v := c.Value(key)
+	if s, ok := v.Load().(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+	}
+	d, err := v.Duration()
+	return d, getError(key, err)
+}
+
+// GetStringSlice returns the value of key in c converted to a slice of strings.
+func GetStringSlice(c Config, key string) ([]string, error) {
+	vs, err := c.Value(key).Slice()
+	if err != nil {
+		return nil, getError(key, err)
+	}
+	ss := make([]string, 0, len(vs))
+	for i, v := range vs {
+		s, err := v.String()
+		if err != nil {
+			return nil, getError(fmt.Sprintf("%s[%d]", key, i), err)
+		}
+		ss = append(ss, s)
+	}
+	return ss, nil
+}
+
 func (c *config) Scan(v any) error {
 	data, err := c.reader.Source()
 	if err != nil {
 		return err
 	}
-	return unmarshalJSON(data, v)
+	if err = unmarshalJSON(data, v); err != nil {
+		return err
+	}
+	return c.validate(v)
 }
 
 func (c *config) Watch(key string, o Observer) error {
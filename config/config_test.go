@@ -1,8 +1,14 @@
 package config
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"dario.cat/mergo"
 )
@@ -186,3 +192,179 @@ func TestConfig(t *testing.T) {
 		t.Error("len(testConf.Endpoints) is not equal to 2")
 	}
 }
+
+type testBatchSource struct {
+	kvs chan []*KeyValue
+}
+
+func (s *testBatchSource) Load() ([]*KeyValue, error) {
+	return []*KeyValue{{Key: "batch", Value: []byte(`{"a":"0","b":"0","c":"${a}"}`), Format: "json"}}, nil
+}
+
+func (s *testBatchSource) Watch() (Watcher, error) {
+	return &testBatchWatcher{kvs: s.kvs, exit: make(chan struct{})}, nil
+}
+
+type testBatchWatcher struct {
+	kvs  chan []*KeyValue
+	exit chan struct{}
+}
+
+func (w *testBatchWatcher) Next() ([]*KeyValue, error) {
+	select {
+	case kvs := <-w.kvs:
+		return kvs, nil
+	case <-w.exit:
+		return nil, context.Canceled
+	}
+}
+
+func (w *testBatchWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
+
+func TestConfigBatchObserver(t *testing.T) {
+	const events = 50
+	var (
+		mu      sync.Mutex
+		batches [][]string
+		done    = make(chan struct{})
+	)
+	src := &testBatchSource{kvs: make(chan []*KeyValue)}
+	c := New(
+		WithSource(src),
+		WithBatchObserver(func(keys []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			batches = append(batches, keys)
+			if len(batches) == events {
+				close(done)
+			}
+		}),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	stop := make(chan struct{})
+	readErr := make(chan error, 1)
+	go func() {
+		var v struct {
+			A string `json:"a"`
+			B string `json:"b"`
+			C string `json:"c"`
+		}
+		for {
+			select {
+			case <-stop:
+				readErr <- nil
+				return
+			default:
+			}
+			if err := c.Scan(&v); err != nil {
+				readErr <- err
+				return
+			}
+			if v.A != v.B || v.A != v.C {
+				readErr <- fmt.Errorf("torn read: %+v", v)
+				return
+			}
+		}
+	}()
+
+	for i := 1; i <= events; i++ {
+		data := fmt.Sprintf(`{"a":"%d","b":"%d","c":"${a}"}`, i, i)
+		src.kvs <- []*KeyValue{{Key: "batch", Value: []byte(data), Format: "json"}}
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting batch notifications")
+	}
+	close(stop)
+	if err := <-readErr; err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != events {
+		t.Fatalf("expected %d batches, got %d", events, len(batches))
+	}
+	for _, keys := range batches {
+		if !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+			t.Errorf("unexpected changed keys: %v", keys)
+		}
+	}
+}
+
+func TestConfigGet(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(`{
+		"name": "kratos",
+		"port": 8000,
+		"enabled": true,
+		"ratio": 0.5,
+		"timeout": "1.5s",
+		"interval": 1000000000,
+		"hosts": ["a", "b"],
+		"nested": {"key": "value"},
+		"objects": [{"key": "value"}]
+	}`)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	check := func(name string, got, want any, err error) {
+		t.Helper()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v got %v", name, want, got)
+		}
+	}
+	s, err := GetString(c, "name")
+	check("GetString", s, "kratos", err)
+	i, err := GetInt(c, "port")
+	check("GetInt", i, int64(8000), err)
+	b, err := GetBool(c, "enabled")
+	check("GetBool", b, true, err)
+	f, err := GetFloat(c, "ratio")
+	check("GetFloat", f, 0.5, err)
+	d, err := GetDuration(c, "timeout")
+	check("GetDuration string", d, 1500*time.Millisecond, err)
+	d, err = GetDuration(c, "interval")
+	check("GetDuration int", d, time.Second, err)
+	ss, err := GetStringSlice(c, "hosts")
+	check("GetStringSlice", ss, []string{"a", "b"}, err)
+
+	getters := map[string]func(string) error{
+		"GetString":      func(k string) error { _, err := GetString(c, k); return err },
+		"GetInt":         func(k string) error { _, err := GetInt(c, k); return err },
+		"GetBool":        func(k string) error { _, err := GetBool(c, k); return err },
+		"GetFloat":       func(k string) error { _, err := GetFloat(c, k); return err },
+		"GetDuration":    func(k string) error { _, err := GetDuration(c, k); return err },
+		"GetStringSlice": func(k string) error { _, err := GetStringSlice(c, k); return err },
+	}
+	for name, get := range getters {
+		if err := get("missing"); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), `"missing"`) {
+			t.Errorf("%s missing: expected ErrNotFound with key, got %v", name, err)
+		}
+	}
+	wrongType := map[string]string{
+		"GetString":      "nested",
+		"GetInt":         "name",
+		"GetBool":        "name",
+		"GetFloat":       "hosts",
+		"GetDuration":    "name",
+		"GetStringSlice": "objects",
+	}
+	for name, key := range wrongType {
+		err := getters[name](key)
+		if err == nil || errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), key) {
+			t.Errorf("%s wrong type: expected conversion error for %s, got %v", name, key, err)
+		}
+	}
+}
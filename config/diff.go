@@ -0,0 +1,105 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/internal/redact"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Change is a changed leaf key of a reload, Old is nil for an added key
+// and New is nil for a removed one.
+type Change struct {
+	Key string
+	Old any
+	New any
+}
+
+// DiffObserver is config observer notified once per source change with
+// the changed values, sorted by key.
+type DiffObserver func(changes []Change)
+
+// WithDiffObserver with config diff observer, e.g. LogDiff for an audit
+// trail of the reloads. The values of the secret keys are masked.
+func WithDiffObserver(do DiffObserver) Option {
+	return func(o *options) {
+		o.diff = do
+	}
+}
+
+// DefaultSecretKeys are the last path segments whose values are always
+// masked in the changes passed to the DiffObserver, e.g. db.password.
+var DefaultSecretKeys = []string{"password", "passwd", "secret", "token", "key", "credential", "credentials"}
+
+// WithSecretKeys with the keys whose values are masked in the changes
+// passed to the DiffObserver, in addition to DefaultSecretKeys. A key is
+// either a full path such as "data.database.dsn" or a last path segment
+// such as "dsn", which matches that segment at any depth. Matching is
+// case-insensitive.
+func WithSecretKeys(keys ...string) Option {
+	return func(o *options) {
+		o.secrets = append(o.secrets, keys...)
+	}
+}
+
+// LogDiff returns a DiffObserver logging every change to logger.
+func LogDiff(logger log.Logger) DiffObserver {
+	helper := log.NewHelper(logger)
+	return func(changes []Change) {
+		for _, c := range changes {
+			helper.Infow("msg", "config changed", "key", c.Key, "old", c.Old, "new", c.New)
+		}
+	}
+}
+
+// diffValues returns the sorted changes of the leaf paths between prev and next.
+func diffValues(prev, next map[string]any) []Change {
+	p, n := flattenMap("", prev, nil), flattenMap("", next, nil)
+	var changes []Change
+	for k, nv := range n {
+		if pv, ok := p[k]; !ok || !reflect.DeepEqual(pv, nv) {
+			changes = append(changes, Change{Key: k, Old: pv, New: nv})
+		}
+	}
+	for k, pv := range p {
+		if _, ok := n[k]; !ok {
+			changes = append(changes, Change{Key: k, Old: pv})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// mask returns changes with the values of the secret keys masked.
+func (o *options) mask(changes []Change) []Change {
+	masked := make([]Change, len(changes))
+	for i, c := range changes {
+		if o.secret(c.Key) {
+			if c.Old != nil {
+				c.Old = redact.Mask
+			}
+			if c.New != nil {
+				c.New = redact.Mask
+			}
+		}
+		masked[i] = c
+	}
+	return masked
+}
+
+func (o *options) secret(key string) bool {
+	last := key[strings.LastIndex(key, ".")+1:]
+	for _, s := range DefaultSecretKeys {
+		if strings.EqualFold(s, last) {
+			return true
+		}
+	}
+	for _, s := range o.secrets {
+		if strings.EqualFold(s, key) || strings.EqualFold(s, last) {
+			return true
+		}
+	}
+	return false
+}
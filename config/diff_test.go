@@ -0,0 +1,122 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestDiffObserver(t *testing.T) {
+	src := &testPollSource{
+		data:  `{"name":"v1","port":8000,"data":{"database":{"password":"old","dsn":"d1"}},"token":"t1"}`,
+		watch: func() (Watcher, error) { return nil, ErrWatchNotSupported },
+	}
+	diffs := make(chan []Change, 1)
+	c := New(
+		WithSource(src),
+		WithPollInterval(10*time.Millisecond),
+		WithSecretKeys("data.database.DSN"),
+		WithDiffObserver(func(changes []Change) { diffs <- changes }),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	src.set(`{"name":"v2","port":8000,"data":{"database":{"password":"new","dsn":"d2"}},"token":"t2","added":true}`)
+	select {
+	case changes := <-diffs:
+		want := []Change{
+			{Key: "added", New: true},
+			{Key: "data.database.dsn", Old: "****", New: "****"},
+			{Key: "data.database.password", Old: "****", New: "****"},
+			{Key: "name", Old: "v1", New: "v2"},
+			{Key: "token", Old: "****", New: "****"},
+		}
+		if !reflect.DeepEqual(changes, want) {
+			t.Errorf("expected %v, got %v", want, changes)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the diff")
+	}
+}
+
+func TestLogDiffDefaultSecrets(t *testing.T) {
+	src := &testPollSource{
+		data:  `{"db":{"password":"old","addr":":3306"}}`,
+		watch: func() (Watcher, error) { return nil, ErrWatchNotSupported },
+	}
+	var (
+		mu sync.Mutex
+		b  strings.Builder
+	)
+	logged := make(chan struct{}, 1)
+	logDiff := LogDiff(log.NewStdLogger(&b))
+	c := New(
+		WithSource(src),
+		WithPollInterval(10*time.Millisecond),
+		WithDiffObserver(func(changes []Change) {
+			mu.Lock()
+			logDiff(changes)
+			mu.Unlock()
+			logged <- struct{}{}
+		}),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	src.set(`{"db":{"password":"new","addr":":3307"}}`)
+	select {
+	case <-logged:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the diff")
+	}
+	mu.Lock()
+	out := b.String()
+	mu.Unlock()
+	if strings.Contains(out, "old=old") || strings.Contains(out, "new=new") || !strings.Contains(out, "key=db.password old=**** new=****") {
+		t.Errorf("expected db.password masked by default, got %q", out)
+	}
+	if !strings.Contains(out, "key=db.addr old=:3306 new=:3307") {
+		t.Errorf("expected db.addr logged, got %q", out)
+	}
+}
+
+func TestLogDiff(t *testing.T) {
+	var b strings.Builder
+	LogDiff(log.NewStdLogger(&b))([]Change{{Key: "name", Old: "v1", New: "v2"}})
+	if out := b.String(); !strings.Contains(out, "key=name old=v1 new=v2") {
+		t.Errorf("unexpected log: %q", out)
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	prev := map[string]any{
+		"a": "1",
+		"b": map[string]any{"c": "2", "d": "3"},
+		"e": "4",
+	}
+	next := map[string]any{
+		"a": "1",
+		"b": map[string]any{"c": "5"},
+		"f": []any{"6"},
+	}
+	want := []Change{
+		{Key: "b.c", Old: "2", New: "5"},
+		{Key: "b.d", Old: "3"},
+		{Key: "e", Old: "4"},
+		{Key: "f", New: []any{"6"}},
+	}
+	if got := diffValues(prev, next); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := diffValues(prev, prev); len(got) != 0 {
+		t.Errorf("expected no change, got %v", got)
+	}
+}
@@ -1,7 +1,10 @@
 package env
 
 import (
+	"encoding/json"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/go-kratos/kratos/v2/config"
@@ -11,6 +14,18 @@ type env struct {
 	prefixes []string
 }
 
+// NewSource returns a source reading the environment variables, the ones
+// starting with one of the prefixes if any, with the prefix trimmed.
+//
+// The variables ending with an index also set an array, e.g.
+// APP_ALLOWED_ORIGINS_0 and APP_ALLOWED_ORIGINS_1 the allowed_origins. The
+// name is lowercased and a double underscore nests, e.g.
+// APP_HTTP__CORS__ORIGINS_0 is an element of http.cors.origins. The
+// elements are in ascending order of their indexes whatever the gaps
+// between them, and the array replaces the one of a source of lower
+// precedence, e.g. a config file. The variables with APPEND before the
+// index append to that array instead, e.g. APP_ALLOWED_ORIGINS_APPEND_0,
+// see config.Append.
 func NewSource(prefixes ...string) config.Source {
 	return &env{prefixes: prefixes}
 }
@@ -20,7 +35,10 @@ func (e *env) Load() (kv []*config.KeyValue, err error) {
 }
 
 func (e *env) load(envs []string) []*config.KeyValue {
-	var kv []*config.KeyValue
+	var (
+		kv      []*config.KeyValue
+		indexed []indexedValue
+	)
 	for _, env := range envs {
 		var k, v string
 		subs := strings.SplitN(env, "=", 2) //nolint:mnd
@@ -44,8 +62,15 @@ func (e *env) load(envs []string) []*config.KeyValue {
 				Key:   k,
 				Value: []byte(v),
 			})
+			if iv, ok := indexedKey(k); ok {
+				iv.value = v
+				indexed = append(indexed, iv)
+			}
 		}
 	}
+	if arrays, ok := indexedArrays(indexed); ok {
+		kv = append(kv, arrays)
+	}
 	return kv
 }
 
@@ -57,6 +82,101 @@ func (e *env) Watch() (config.Watcher, error) {
 	return w, nil
 }
 
+// indexedValue is the element at index of the array at path.
+type indexedValue struct {
+	path   []string
+	index  int
+	append bool
+	value  string
+}
+
+// indexedKey returns the array element of an environment key ending with
+// an index, e.g. the element 0 of allowed_origins for ALLOWED_ORIGINS_0.
+func indexedKey(k string) (indexedValue, bool) {
+	i := strings.LastIndexByte(k, '_')
+	if i <= 0 || i == len(k)-1 || strings.Trim(k[i+1:], "0123456789") != "" {
+		return indexedValue{}, false
+	}
+	index, err := strconv.Atoi(k[i+1:])
+	if err != nil {
+		return indexedValue{}, false
+	}
+	name := strings.ToLower(k[:i])
+	iv := indexedValue{index: index}
+	if n, ok := strings.CutSuffix(name, "_append"); ok {
+		name, iv.append = n, true
+	}
+	iv.path = strings.Split(name, "__")
+	for _, p := range iv.path {
+		if p == "" {
+			return indexedValue{}, false
+		}
+	}
+	return iv, true
+}
+
+// indexedArrays returns the arrays of the indexed values as a single JSON
+// key value, the ones appending wrapped in a config.Append object. An
+// array appended to an array set by the variables too is simply added to
+// its end.
+func indexedArrays(elems []indexedValue) (*config.KeyValue, bool) {
+	if len(elems) == 0 {
+		return nil, false
+	}
+	sort.SliceStable(elems, func(i, j int) bool {
+		if elems[i].append != elems[j].append {
+			return !elems[i].append
+		}
+		return elems[i].index < elems[j].index
+	})
+	values := make(map[string]any)
+	for _, e := range elems {
+		parent, ok := indexedParent(values, e.path)
+		if !ok {
+			continue
+		}
+		key := e.path[len(e.path)-1]
+		switch v := parent[key].(type) {
+		case []any:
+			parent[key] = append(v, e.value)
+		case map[string]any:
+			if elems, ok := v[config.Append].([]any); ok {
+				v[config.Append] = append(elems, e.value)
+			}
+		case nil:
+			if e.append {
+				parent[key] = map[string]any{config.Append: []any{e.value}}
+			} else {
+				parent[key] = []any{e.value}
+			}
+		}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, false
+	}
+	return &config.KeyValue{Key: "indexed", Value: data, Format: "json"}, true
+}
+
+// indexedParent returns the object holding the last key of path, creating
+// the missing objects, or false if a key on the way is an array.
+func indexedParent(values map[string]any, path []string) (map[string]any, bool) {
+	next := values
+	for _, k := range path[:len(path)-1] {
+		v, ok := next[k]
+		if !ok {
+			sub := make(map[string]any)
+			next[k] = sub
+			next = sub
+			continue
+		}
+		if next, ok = v.(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return next, true
+}
+
 func matchPrefix(prefixes []string, s string) (string, bool) {
 	for _, p := range prefixes {
 		if strings.HasPrefix(s, p) {
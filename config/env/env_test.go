@@ -1,6 +1,7 @@
 package env
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -427,3 +428,124 @@ func Test_env_watch(t *testing.T) {
 	}
 	_ = w.Stop()
 }
+
+func Test_indexedKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		path   []string
+		index  int
+		append bool
+		ok     bool
+	}{
+		{"ALLOWED_ORIGINS_0", []string{"allowed_origins"}, 0, false, true},
+		{"ALLOWED_ORIGINS_12", []string{"allowed_origins"}, 12, false, true},
+		{"ALLOWED_ORIGINS_APPEND_1", []string{"allowed_origins"}, 1, true, true},
+		{"HTTP__CORS__ORIGINS_1", []string{"http", "cors", "origins"}, 1, false, true},
+		{"ALLOWED_ORIGINS", nil, 0, false, false},
+		{"ALLOWED_ORIGINS_", nil, 0, false, false},
+		{"_0", nil, 0, false, false},
+		{"VERSION_1A", nil, 0, false, false},
+		{"HTTP____CORS_0", nil, 0, false, false},
+	}
+	for _, tt := range tests {
+		got, ok := indexedKey(tt.key)
+		if ok != tt.ok || !reflect.DeepEqual(got.path, tt.path) || got.index != tt.index || got.append != tt.append {
+			t.Errorf("%s: expect %v %d %v %v, got %v %d %v %v", tt.key, tt.path, tt.index, tt.append, tt.ok, got.path, got.index, got.append, ok)
+		}
+	}
+}
+
+func TestEnvArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := []byte(`{
+		"allowed_origins": ["a", "b"],
+		"http": {"cors": {"origins": ["x"]}}
+	}`)
+	if err := os.WriteFile(path, data, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_ALLOWED_ORIGINS_0", "A")
+	t.Setenv("APP_ALLOWED_ORIGINS_5", "e")
+	t.Setenv("APP_ALLOWED_ORIGINS_3", "d")
+	t.Setenv("APP_HTTP__CORS__ORIGINS_APPEND_1", "y")
+	t.Setenv("APP_HTTP__CORS__ORIGINS_APPEND_0", "x")
+	t.Setenv("APP_PORTS_1", "8081")
+	t.Setenv("APP_PORTS_0", "8080")
+
+	c := config.New(config.WithSource(file.NewSource(path), NewSource("APP_")))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		// the indexes replace the array in order, whatever the gaps
+		{"allowed_origins", []string{"A", "d", "e"}},
+		// the elements already there are not appended again
+		{"http.cors.origins", []string{"x", "y"}},
+		// a missing array is created
+		{"ports", []string{"8080", "8081"}},
+	}
+	for _, tt := range tests {
+		got, err := config.GetStringSlice(c, tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expect %v, got %v", tt.key, tt.want, got)
+		}
+	}
+	var v struct {
+		AllowedOrigins []string `json:"allowed_origins"`
+	}
+	if err := c.Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v.AllowedOrigins, []string{"A", "d", "e"}) {
+		t.Errorf("expect the scanned origins, got %v", v.AllowedOrigins)
+	}
+	// the variable is still available as is, e.g. for placeholders
+	if got, _ := config.GetString(c, "ALLOWED_ORIGINS_0"); got != "A" {
+		t.Errorf("expect ALLOWED_ORIGINS_0 kept, got %q", got)
+	}
+}
+
+func Test_indexedArrays(t *testing.T) {
+	var elems []indexedValue
+	for k, v := range map[string]string{
+		"ORIGINS_2":        "c",
+		"ORIGINS_0":        "a",
+		"ORIGINS_APPEND_0": "d",
+		"PORTS_APPEND_0":   "8080",
+		"HTTP__NAMES_1":    "b",
+		"HTTP__NAMES_0":    "a",
+	} {
+		e, ok := indexedKey(k)
+		if !ok {
+			t.Fatalf("%s: expect an indexed key", k)
+		}
+		e.value = v
+		elems = append(elems, e)
+	}
+	kv, ok := indexedArrays(elems)
+	if !ok {
+		t.Fatal("expect the arrays")
+	}
+	var got map[string]any
+	if err := json.Unmarshal(kv.Value, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"origins": []any{"a", "c", "d"},
+		"ports":   map[string]any{config.Append: []any{"8080"}},
+		"http":    map[string]any{"names": []any{"a", "b"}},
+	}
+	if kv.Format != "json" || !reflect.DeepEqual(got, want) {
+		t.Errorf("expect %v, got %s %v", want, kv.Format, got)
+	}
+	if _, ok := indexedArrays(nil); ok {
+		t.Error("expect no key value without indexed values")
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import "fmt"
+
+// SourceValue is the value of a key provided by one config source.
+type SourceValue struct {
+	// Source is the index of the source in the WithSource order.
+	Source int
+	// Name is the type of the source, e.g. "*file.file".
+	Name string
+	// Key is the key of the KeyValue providing the value, e.g. a file name.
+	Key string
+	// Format is the format of the KeyValue providing the value.
+	Format string
+	// Value is the raw value, before placeholders are resolved.
+	Value any
+	// Winner reports whether the value overrides all the others in the merge.
+	Winner bool
+}
+
+// record stores the latest kvs loaded from the source at index, replacing
+// the previous ones of the same key.
+func (c *config) record(index int, kvs []*KeyValue) {
+	c.loadedMu.Lock()
+	defer c.loadedMu.Unlock()
+	for len(c.loaded) <= index {
+		c.loaded = append(c.loaded, nil)
+	}
+	prev := c.loaded[index]
+next:
+	for _, kv := range kvs {
+		for i, p := range prev {
+			if p.Key == kv.Key {
+				prev[i] = kv
+				continue next
+			}
+		}
+		prev = append(prev, kv)
+	}
+	c.loaded[index] = prev
+}
+
+// above returns the latest kvs of the sources after index in merge order.
+func (c *config) above(index int) []*KeyValue {
+	c.loadedMu.Lock()
+	defer c.loadedMu.Unlock()
+	var kvs []*KeyValue
+	for i := index + 1; i < len(c.loaded); i++ {
+		kvs = append(kvs, c.loaded[i]...)
+	}
+	return kvs
+}
+
+// Explainer is implemented by the configs reporting the source values of
+// their keys, such as the one of New.
+type Explainer interface {
+	// Explain returns, in merge order, every source value of key, the last
+	// one being the winner.
+	Explain(key string) []SourceValue
+}
+
+var _ Explainer = (*config)(nil)
+
+// Explain returns, in merge order, every source value of key in c, the
+// last one being the winner, or nil if c is not an Explainer. It is meant
+// to debug which source overrides a value.
+func Explain(c Config, key string) []SourceValue {
+	if e, ok := c.(Explainer); ok {
+		return e.Explain(key)
+	}
+	return nil
+}
+
+// Explain implements Explainer.
+func (c *config) Explain(key string) []SourceValue {
+	c.loadedMu.Lock()
+	loaded := make([][]*KeyValue, len(c.loaded))
+	copy(loaded, c.loaded)
+	c.loadedMu.Unlock()
+
+	var values []SourceValue
+	for i, kvs := range loaded {
+		for _, kv := range kvs {
+			next := make(map[string]any)
+			if err := c.opts.decoder(kv, next); err != nil {
+				continue
+			}
+			m, ok := convertMap(next).(map[string]any)
+			if !ok {
+				continue
+			}
+			v, ok := readValue(m, key)
+			if !ok || v.Load() == nil {
+				continue
+			}
+			values = append(values, SourceValue{
+				Source: i,
+				Name:   fmt.Sprintf("%T", c.opts.sources[i]),
+				Key:    kv.Key,
+				Format: kv.Format,
+				Value:  v.Load(),
+			})
+		}
+	}
+	if len(values) > 0 {
+		values[len(values)-1].Winner = true
+	}
+	return values
+}
@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestExplain(t *testing.T) {
+	c := New(WithSource(
+		newTestJSONSource(`{"server":{"addr":":8000","timeout":"1s"}}`),
+		newTestJSONSource(`{"server":{"addr":":9000"}}`),
+	))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	values := Explain(c, "server.addr")
+	if len(values) != 2 {
+		t.Fatalf("expect 2 source values, got %+v", values)
+	}
+	if v := values[0]; v.Source != 0 || v.Value != ":8000" || v.Winner {
+		t.Errorf("unexpected overridden value %+v", v)
+	}
+	if v := values[1]; v.Source != 1 || v.Value != ":9000" || !v.Winner || v.Key != "json" || v.Format != "json" || v.Name != "*config.testJSONSource" {
+		t.Errorf("unexpected winner %+v", v)
+	}
+	if addr, _ := GetString(c, "server.addr"); addr != values[1].Value {
+		t.Errorf("expect the winner %v to be the value, got %v", values[1].Value, addr)
+	}
+
+	if values = Explain(c, "server.timeout"); len(values) != 1 || values[0].Source != 0 || !values[0].Winner {
+		t.Errorf("unexpected source values %+v", values)
+	}
+	if values = Explain(c, "server.missing"); len(values) != 0 {
+		t.Errorf("expect no source value, got %+v", values)
+	}
+	// a config not implementing Explainer has nothing to explain
+	if values = Explain(struct{ Config }{c}, "server.addr"); values != nil {
+		t.Errorf("expect no source value, got %+v", values)
+	}
+}
@@ -34,6 +34,11 @@ func (f *file) loadFile(path string) (*config.KeyValue, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ext := format(info.Name()); ext == "yaml" || ext == "yml" {
+		if data, err = resolveIncludes(path, data); err != nil {
+			return nil, err
+		}
+	}
 	return &config.KeyValue{
 		Key:    info.Name(),
 		Format: format(info.Name()),
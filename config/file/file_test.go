@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -339,3 +340,139 @@ func TestMergeDataRace(t *testing.T) {
 	close(startCh)
 	wg.Wait()
 }
+
+// mountConfigMap lays out dir the way the kubelet projects a ConfigMap:
+// the data lives in a timestamped directory reached through "..data", and
+// every key is a symlink into "..data".
+func mountConfigMap(t *testing.T, dir, version string, data map[string]string) {
+	t.Helper()
+	ts := filepath.Join(dir, "..ts_"+version)
+	if err := os.Mkdir(ts, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range data {
+		if err := os.WriteFile(filepath.Join(ts, k), []byte(v), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(ts), tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, dataDir)); err != nil {
+		t.Fatal(err)
+	}
+	for k := range data {
+		link := filepath.Join(dir, k)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Join(dataDir, k), link); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWatchConfigMap(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		path func(dir string) string
+	}{
+		{name: "dir", path: func(dir string) string { return dir }},
+		{name: "file", path: func(dir string) string { return filepath.Join(dir, "test.json") }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			mountConfigMap(t, dir, "1", map[string]string{"test.json": _testJSON})
+
+			w, err := NewSource(tt.path(dir)).Watch()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Stop()
+
+			mountConfigMap(t, dir, "2", map[string]string{"test.json": _testJSONUpdate})
+			if err = os.RemoveAll(filepath.Join(dir, "..ts_1")); err != nil {
+				t.Fatal(err)
+			}
+
+			kvs, err := w.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(kvs) != 1 || kvs[0].Key != "test.json" || string(kvs[0].Value) != _testJSONUpdate {
+				t.Fatalf("unexpected reload: %+v", kvs)
+			}
+
+			next := make(chan []*config.KeyValue, 1)
+			go func() {
+				if kvs, err := w.Next(); err == nil {
+					next <- kvs
+				}
+			}()
+			select {
+			case kvs := <-next:
+				t.Fatalf("unexpected second reload: %+v", kvs)
+			case <-time.After(200 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.yaml":               "name: app\ndatabase: !include conf/database.yaml\nserver: !include server.json\n",
+		"server.json":            `{"addr":"127.0.0.1","port":8000}`,
+		"conf/database.yaml":     "driver: mysql\npool: !include pool/default.yaml\n",
+		"conf/pool/default.yaml": "max: 10\n",
+	})
+
+	c := config.New(config.WithSource(NewSource(filepath.Join(dir, "app.yaml"))))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for key, want := range map[string]string{
+		"name":              "app",
+		"database.driver":   "mysql",
+		"database.pool.max": "10",
+		"server.addr":       "127.0.0.1",
+		"server.port":       "8000",
+	} {
+		got, err := config.GetString(c, key)
+		if err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+		if got != want {
+			t.Fatalf("%s: want %q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.yaml": "b: !include b.yaml\n",
+		"b.yaml": "a: !include a.yaml\n",
+	})
+
+	_, err := NewSource(filepath.Join(dir, "a.yaml")).Load()
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("want include cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+// includeTag is the YAML tag replacing a node with the content of another
+// file, e.g. `database: !include common.yaml`.
+const includeTag = "!include"
+
+// resolveIncludes expands the include directives of the YAML file at path.
+// Relative includes are resolved against the directory of the including
+// file, included files are decoded by their extension and may include other
+// files in turn. Changes of included files are only reloaded when they are
+// watched too, e.g. by living in a watched directory.
+func resolveIncludes(path string, data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(includeTag)) {
+		return data, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err = yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if err = expandIncludes(&root, filepath.Dir(abs), []string{abs}); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&root)
+}
+
+func expandIncludes(n *yaml.Node, dir string, stack []string) error {
+	if n.Kind == yaml.ScalarNode && n.Tag == includeTag {
+		target := n.Value
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+		target = filepath.Clean(target)
+		for _, p := range stack {
+			if p == target {
+				return fmt.Errorf("config/file: include cycle: %s -> %s", strings.Join(stack, " -> "), target)
+			}
+		}
+		included, err := loadInclude(target, append(stack, target))
+		if err != nil {
+			return err
+		}
+		*n = *included
+		return nil
+	}
+	for _, c := range n.Content {
+		if err := expandIncludes(c, dir, stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadInclude(path string, stack []string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config/file: include %s: %w", path, err)
+	}
+	switch ext := format(path); ext {
+	case "yaml", "yml":
+		var doc yaml.Node
+		if err = yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("config/file: include %s: %w", path, err)
+		}
+		if err = expandIncludes(&doc, filepath.Dir(path), stack); err != nil {
+			return nil, err
+		}
+		if doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 {
+			return doc.Content[0], nil
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}, nil
+	default:
+		codec := encoding.GetCodec(ext)
+		if codec == nil {
+			return nil, fmt.Errorf("config/file: include %s: unsupported format %q", path, ext)
+		}
+		var v any
+		if err = codec.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("config/file: include %s: %w", path, err)
+		}
+		var n yaml.Node
+		if err = n.Encode(v); err != nil {
+			return nil, err
+		}
+		return &n, nil
+	}
+}
@@ -4,17 +4,25 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/fsnotify/fsnotify"
 
 	"github.com/go-kratos/kratos/v2/config"
 )
 
+// dataDir is the symlink Kubernetes swaps atomically when a mounted
+// ConfigMap or Secret is updated.
+const dataDir = "..data"
+
 var _ config.Watcher = (*watcher)(nil)
 
 type watcher struct {
 	f  *file
 	fw *fsnotify.Watcher
+	// symlink reports whether f.path is a file reached through a symlink,
+	// in which case its parent directory is watched for "..data" swaps.
+	symlink bool
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -28,38 +36,92 @@ func newWatcher(f *file) (config.Watcher, error) {
 	if err := fw.Add(f.path); err != nil {
 		return nil, err
 	}
+	var symlink bool
+	if fi, err := os.Lstat(f.path); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		symlink = true
+		if err := fw.Add(filepath.Dir(f.path)); err != nil {
+			_ = fw.Close()
+			return nil, err
+		}
+	}
 	ctx, cancel := context.WithCancel(context.Background())
-	return &watcher{f: f, fw: fw, ctx: ctx, cancel: cancel}, nil
+	return &watcher{f: f, fw: fw, symlink: symlink, ctx: ctx, cancel: cancel}, nil
 }
 
 func (w *watcher) Next() ([]*config.KeyValue, error) {
-	select {
-	case <-w.ctx.Done():
-		return nil, w.ctx.Err()
-	case event := <-w.fw.Events:
-		if event.Op == fsnotify.Rename {
-			if _, err := os.Stat(event.Name); err == nil || os.IsExist(err) {
-				if err := w.fw.Add(event.Name); err != nil {
-					return nil, err
-				}
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case event := <-w.fw.Events:
+			kvs, ok, err := w.handle(event)
+			if err != nil {
+				return nil, err
 			}
-		}
-		fi, err := os.Stat(w.f.path)
-		if err != nil {
+			if ok {
+				return kvs, nil
+			}
+		case err := <-w.fw.Errors:
 			return nil, err
 		}
-		path := w.f.path
-		if fi.IsDir() {
-			path = filepath.Join(w.f.path, filepath.Base(event.Name))
+	}
+}
+
+// handle turns an fsnotify event into the changed key values. It reports
+// false for events that do not change the observed configuration.
+func (w *watcher) handle(event fsnotify.Event) ([]*config.KeyValue, bool, error) {
+	name := filepath.Base(event.Name)
+	if name == dataDir {
+		// The "..data" symlink now points at a fully written directory,
+		// so every key is reloaded in a single batch.
+		if event.Op&fsnotify.Create == 0 {
+			return nil, false, nil
+		}
+		if w.symlink {
+			// Track the new target so in-place writes keep being observed.
+			_ = w.fw.Remove(w.f.path)
+			if err := w.fw.Add(w.f.path); err != nil {
+				return nil, false, err
+			}
 		}
-		kv, err := w.f.loadFile(path)
+		kvs, err := w.f.Load()
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
-		return []*config.KeyValue{kv}, nil
-	case err := <-w.fw.Errors:
-		return nil, err
+		return kvs, true, nil
+	}
+	if event.Name != w.f.path && strings.HasPrefix(name, ".") {
+		// Hidden entries, including the timestamped directories written by
+		// Kubernetes, are never loaded.
+		return nil, false, nil
+	}
+	if w.symlink && event.Name == w.f.path && event.Op&(fsnotify.Remove|fsnotify.Chmod) != 0 {
+		// The previous symlink target was removed after a "..data" swap.
+		return nil, false, nil
+	}
+	if event.Op == fsnotify.Rename {
+		if _, err := os.Stat(event.Name); err == nil || os.IsExist(err) {
+			if err := w.fw.Add(event.Name); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	fi, err := os.Stat(w.f.path)
+	if err != nil {
+		return nil, false, err
+	}
+	path := w.f.path
+	if fi.IsDir() {
+		path = filepath.Join(w.f.path, name)
+	} else if filepath.Clean(event.Name) != filepath.Clean(w.f.path) {
+		// Unrelated entry in the parent directory of a symlinked file.
+		return nil, false, nil
+	}
+	kv, err := w.f.loadFile(path)
+	if err != nil {
+		return nil, false, err
 	}
+	return []*config.KeyValue{kv}, true, nil
 }
 
 func (w *watcher) Stop() error {
@@ -0,0 +1,36 @@
+package flag
+
+import (
+	stdflag "flag"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+type flagSet struct {
+	fs *stdflag.FlagSet
+}
+
+// NewSource returns a source reading the flags of fs that were set
+// explicitly on the command line, so flag defaults never clobber values
+// from other sources. A flag named "database.url" maps to the config key
+// "database.url". fs must be parsed before the config is loaded, and the
+// source should be the last one passed to config.WithSource to take the
+// highest precedence in the merge.
+func NewSource(fs *stdflag.FlagSet) config.Source {
+	return &flagSet{fs: fs}
+}
+
+func (f *flagSet) Load() ([]*config.KeyValue, error) {
+	var kv []*config.KeyValue
+	f.fs.Visit(func(fl *stdflag.Flag) {
+		kv = append(kv, &config.KeyValue{
+			Key:   fl.Name,
+			Value: []byte(fl.Value.String()),
+		})
+	})
+	return kv, nil
+}
+
+func (f *flagSet) Watch() (config.Watcher, error) {
+	return NewWatcher()
+}
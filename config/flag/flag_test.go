@@ -0,0 +1,90 @@
+package flag
+
+import (
+	stdflag "flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+)
+
+const _testJSON = `
+{
+    "database":{
+        "url":"mysql://file",
+        "pool":10
+    },
+    "debug":false
+}`
+
+func newFlagSet(t *testing.T, args ...string) *stdflag.FlagSet {
+	t.Helper()
+	fs := stdflag.NewFlagSet("test", stdflag.ContinueOnError)
+	fs.String("database.url", "mysql://default", "database url")
+	fs.Int("database.pool", 100, "database pool size")
+	fs.Bool("debug", false, "debug mode")
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func TestLoad(t *testing.T) {
+	kvs, err := NewSource(newFlagSet(t, "--database.url=mysql://flag")).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 {
+		t.Fatalf("expected only the set flag, got %d kvs", len(kvs))
+	}
+	if kvs[0].Key != "database.url" || string(kvs[0].Value) != "mysql://flag" {
+		t.Errorf("unexpected kv %s=%s", kvs[0].Key, kvs[0].Value)
+	}
+
+	kvs, err = NewSource(newFlagSet(t)).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 0 {
+		t.Errorf("expected no kvs for default flags, got %d", len(kvs))
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.json")
+	if err := os.WriteFile(filename, []byte(_testJSON), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	c := config.New(config.WithSource(
+		file.NewSource(filename),
+		NewSource(newFlagSet(t, "--database.url", "mysql://flag", "--debug")),
+	))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if url, err := c.Value("database.url").String(); err != nil || url != "mysql://flag" {
+		t.Errorf("expected database.url from flag, got %q %v", url, err)
+	}
+	if debug, err := c.Value("debug").Bool(); err != nil || !debug {
+		t.Errorf("expected debug from flag, got %v %v", debug, err)
+	}
+	// the default of an unset flag must not clobber the file value
+	if pool, err := c.Value("database.pool").Int(); err != nil || pool != 10 {
+		t.Errorf("expected database.pool from file, got %d %v", pool, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	w, err := NewSource(newFlagSet(t)).Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Stop()
+	if _, err = w.Next(); err == nil {
+		t.Error("expect error after stop, actual nil")
+	}
+}
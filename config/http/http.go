@@ -0,0 +1,153 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/internal/httputil"
+)
+
+var _ config.Source = (*source)(nil)
+
+// Option is http config source option.
+type Option func(*options)
+
+type options struct {
+	client   *http.Client
+	header   http.Header
+	timeout  time.Duration
+	interval time.Duration
+	format   string
+}
+
+// WithClient sets the http client, default is http.DefaultClient.
+func WithClient(c *http.Client) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// WithHeader adds a header to every request, e.g. the Authorization header.
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.header.Add(key, value)
+	}
+}
+
+// WithTimeout sets the timeout of a request, default is 5s.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithInterval sets the interval between the polls of the watcher, default is 30s.
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.interval = d
+	}
+}
+
+// WithFormat sets the format of the config, default is the extension of
+// the url path, or else the subtype of the response content type.
+func WithFormat(format string) Option {
+	return func(o *options) {
+		o.format = format
+	}
+}
+
+type source struct {
+	url  string
+	opts options
+
+	mu   sync.Mutex
+	etag string
+	last *config.KeyValue
+}
+
+// NewSource returns a source fetching the config from url. The ETag of the
+// response is sent back in If-None-Match, so a server answering
+// 304 Not Modified is never downloaded again nor reported as a change.
+func NewSource(url string, opts ...Option) config.Source {
+	o := options{
+		client:   http.DefaultClient,
+		header:   make(http.Header),
+		timeout:  5 * time.Second,
+		interval: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &source{url: url, opts: o}
+}
+
+func (s *source) Load() ([]*config.KeyValue, error) {
+	kv, _, err := s.fetch(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return []*config.KeyValue{kv}, nil
+}
+
+func (s *source) Watch() (config.Watcher, error) {
+	return newWatcher(s), nil
+}
+
+// fetch returns the config and whether it changed since the last fetch.
+func (s *source) fetch(ctx context.Context) (*config.KeyValue, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for k, v := range s.opts.header {
+		req.Header[k] = v
+	}
+	s.mu.Lock()
+	etag, last := s.etag, s.last
+	s.mu.Unlock()
+	if etag != "" && last != nil {
+		req.Header.Set("If-None-Match", etag)
+	}
+	res, err := s.opts.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusNotModified && last != nil:
+		return last, false, nil
+	case res.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("config/http: unexpected status %d of %s", res.StatusCode, s.url)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	kv := &config.KeyValue{Key: s.url, Value: data, Format: s.format(res)}
+	// servers without ETag are compared by body
+	changed := last == nil || !bytes.Equal(last.Value, kv.Value) || last.Format != kv.Format
+	s.mu.Lock()
+	s.etag, s.last = res.Header.Get("ETag"), kv
+	s.mu.Unlock()
+	return kv, changed, nil
+}
+
+func (s *source) format(res *http.Response) string {
+	if s.opts.format != "" {
+		return s.opts.format
+	}
+	if ext := strings.TrimPrefix(path.Ext(res.Request.URL.Path), "."); ext != "" {
+		return ext
+	}
+	return httputil.ContentSubtype(res.Header.Get("Content-Type"))
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testServer struct {
+	mu       sync.Mutex
+	body     string
+	etag     string
+	requests atomic.Int64
+	modified atomic.Int64
+}
+
+func (s *testServer) set(body, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag = body, etag
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	s.mu.Lock()
+	body, etag := s.body, s.etag
+	s.mu.Unlock()
+	if etag != "" && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	s.modified.Add(1)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(body))
+}
+
+func TestLoad(t *testing.T) {
+	ts := &testServer{body: `{"name":"v1"}`, etag: `"1"`}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	s := NewSource(srv.URL+"/config", WithHeader("Authorization", "Bearer token"))
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Value) != `{"name":"v1"}` || kvs[0].Format != "json" || kvs[0].Key != srv.URL+"/config" {
+		t.Fatalf("unexpected key values: %+v", kvs[0])
+	}
+	// an unchanged config is not downloaded again
+	if kvs, err = s.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kvs[0].Value) != `{"name":"v1"}` {
+		t.Errorf("unexpected value: %s", kvs[0].Value)
+	}
+	if n := ts.modified.Load(); n != 1 {
+		t.Errorf("expected 1 download, got %d", n)
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	ts := &testServer{body: `{"name":"v1"}`}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	if _, err := NewSource(srv.URL).Load(); err == nil {
+		t.Error("expected an error of an unauthorized request")
+	}
+	slow := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer slow.Close()
+	if _, err := NewSource(slow.URL, WithTimeout(10*time.Millisecond)).Load(); err == nil {
+		t.Error("expected an error of a timed out request")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ts := &testServer{body: `{"name":"v1"}`, etag: `"1"`}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	s := NewSource(srv.URL+"/config.yaml",
+		WithHeader("Authorization", "Bearer token"),
+		WithInterval(10*time.Millisecond),
+	)
+	if _, err := s.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Stop()
+
+	next := make(chan string, 1)
+	go func() {
+		kvs, err := w.Next()
+		if err != nil {
+			next <- err.Error()
+			return
+		}
+		next <- string(kvs[0].Value) + " " + kvs[0].Format
+	}()
+	// 304 responses are no change
+	for ts.requests.Load() < 4 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case v := <-next:
+		t.Fatalf("unexpected change: %s", v)
+	default:
+	}
+	if n := ts.modified.Load(); n != 1 {
+		t.Errorf("expected 1 download, got %d", n)
+	}
+
+	ts.set("name: v2", `"2"`)
+	select {
+	case v := <-next:
+		if v != "name: v2 yaml" {
+			t.Errorf("unexpected change: %s", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the change")
+	}
+}
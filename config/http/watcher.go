@@ -0,0 +1,46 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+var _ config.Watcher = (*watcher)(nil)
+
+type watcher struct {
+	source *source
+	ticker *time.Ticker
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher(s *source) *watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &watcher{source: s, ticker: time.NewTicker(s.opts.interval), ctx: ctx, cancel: cancel}
+}
+
+// Next blocks until a poll returns a changed config.
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-w.ticker.C:
+		}
+		kv, changed, err := w.source.fetch(w.ctx)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			return []*config.KeyValue{kv}, nil
+		}
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.ticker.Stop()
+	w.cancel()
+	return nil
+}
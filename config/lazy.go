@@ -0,0 +1,47 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotConfigured is returned by Lazy.Get when the section is absent.
+var ErrNotConfigured = errors.New("config: section not configured")
+
+// Lazy is a config section decoded on first use, so that the section of an
+// optional feature may be absent until the feature is actually used.
+type Lazy[T any] struct {
+	c   Config
+	key string
+
+	mu      sync.Mutex
+	decoded bool
+	value   T
+}
+
+// NewLazy returns the section of c at key decoded into a T on first use.
+func NewLazy[T any](c Config, key string) *Lazy[T] {
+	return &Lazy[T]{c: c, key: key}
+}
+
+// Get decodes the section on the first call and returns the same value on
+// the later ones. An absent section returns an error wrapping
+// ErrNotConfigured and is looked up again by the next call.
+func (l *Lazy[T]) Get() (T, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.decoded {
+		return l.value, nil
+	}
+	var v T
+	value := l.c.Value(l.key)
+	if value.Load() == nil {
+		return v, fmt.Errorf("%w: %q", ErrNotConfigured, l.key)
+	}
+	if err := value.Scan(&v); err != nil {
+		return v, fmt.Errorf("config: decode section %q: %w", l.key, err)
+	}
+	l.value, l.decoded = v, true
+	return v, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+type testLazyFeature struct {
+	Endpoint string `json:"endpoint"`
+	Retries  int    `json:"retries"`
+}
+
+func TestLazy(t *testing.T) {
+	// the absent section of a feature does not fail the load
+	c := New(WithSource(newTestJSONSource(`{"name": "app", "search": {"endpoint": "es:9200", "retries": 3}}`)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	search := NewLazy[testLazyFeature](c, "search")
+	billing := NewLazy[testLazyFeature](c, "features.billing")
+
+	v, err := search.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Endpoint != "es:9200" || v.Retries != 3 {
+		t.Errorf("expected the search section, got %+v", v)
+	}
+
+	if _, err = billing.Get(); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("expected %v, got %v", ErrNotConfigured, err)
+	}
+}
+
+func TestLazyDecodeError(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(`{"search": {"retries": "many"}}`)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewLazy[testLazyFeature](c, "search").Get()
+	if err == nil || errors.Is(err, ErrNotConfigured) {
+		t.Errorf("expected the decode error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+var _ config.Source = (*Source)(nil)
+
+// Source is an in-memory config source, e.g. for the built-in defaults of a
+// library. Pass it first to config.WithSource to give it the lowest
+// precedence, so the values of the other sources override it.
+type Source struct {
+	mu       sync.Mutex
+	values   map[string]any
+	watchers map[*watcher]struct{}
+}
+
+// NewSource returns a source of values. A key may be a dotted path such as
+// "server.http.addr", which is expanded into nested values.
+func NewSource(values map[string]any) *Source {
+	s := &Source{
+		values:   make(map[string]any),
+		watchers: make(map[*watcher]struct{}),
+	}
+	for k, v := range values {
+		s.set(k, v)
+	}
+	return s
+}
+
+// Set sets the value of key, a dotted path, and notifies the watchers.
+// The value must be encodable as JSON.
+func (s *Source) Set(key string, value any) error {
+	if _, err := json.Marshal(value); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.set(key, value)
+	for w := range s.watchers {
+		w.notify()
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Source) set(key string, value any) {
+	keys := strings.Split(key, ".")
+	m := s.values
+	for _, k := range keys[:len(keys)-1] {
+		sub, ok := m[k].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			m[k] = sub
+		}
+		m = sub
+	}
+	m[keys[len(keys)-1]] = copyValue(value)
+}
+
+// copyValue copies the nested maps of v, so later Set calls never modify
+// the maps of the caller.
+func copyValue(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	c := make(map[string]any, len(m))
+	for k, v := range m {
+		c[k] = copyValue(v)
+	}
+	return c
+}
+
+// Load returns the values as a single JSON key value.
+func (s *Source) Load() ([]*config.KeyValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.Marshal(s.values)
+	if err != nil {
+		return nil, err
+	}
+	return []*config.KeyValue{{Key: "memory", Value: data, Format: "json"}}, nil
+}
+
+// Watch returns a watcher notified by Set.
+func (s *Source) Watch() (config.Watcher, error) {
+	w := newWatcher(s)
+	s.mu.Lock()
+	s.watchers[w] = struct{}{}
+	s.mu.Unlock()
+	return w, nil
+}
+
+func (s *Source) remove(w *watcher) {
+	s.mu.Lock()
+	delete(s.watchers, w)
+	s.mu.Unlock()
+}
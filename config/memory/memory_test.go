@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+)
+
+const _testJSON = `
+{
+    "server":{
+        "addr":"0.0.0.0:8000"
+    }
+}`
+
+func newConfig(t *testing.T, defaults *Source) config.Config {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "test.json")
+	if err := os.WriteFile(filename, []byte(_testJSON), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	c := config.New(config.WithSource(defaults, file.NewSource(filename)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestDefaults(t *testing.T) {
+	values := map[string]any{
+		"server.addr":    "127.0.0.1:9000",
+		"server.timeout": "1s",
+		"pool":           map[string]any{"size": 10},
+	}
+	c := newConfig(t, NewSource(values))
+	if addr, err := c.Value("server.addr").String(); err != nil || addr != "0.0.0.0:8000" {
+		t.Errorf("expected server.addr from file, got %q %v", addr, err)
+	}
+	if timeout, err := c.Value("server.timeout").String(); err != nil || timeout != "1s" {
+		t.Errorf("expected server.timeout default, got %q %v", timeout, err)
+	}
+	if size, err := c.Value("pool.size").Int(); err != nil || size != 10 {
+		t.Errorf("expected pool.size default, got %d %v", size, err)
+	}
+	if _, ok := values["server"]; ok {
+		t.Error("expected the values of the caller untouched")
+	}
+}
+
+func TestSet(t *testing.T) {
+	defaults := NewSource(map[string]any{"server.timeout": "1s"})
+	c := newConfig(t, defaults)
+	changed := make(chan string, 1)
+	if err := c.Watch("server.timeout", func(_ string, v config.Value) {
+		s, _ := v.String()
+		changed <- s
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := defaults.Set("server.timeout", "2s"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case s := <-changed:
+		if s != "2s" {
+			t.Errorf("expected 2s, got %s", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the Set notification")
+	}
+
+	// a default set later still does not override the file
+	if err := defaults.Set("server.addr", "127.0.0.1:9000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := defaults.Set("pool.size", 20); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if size, err := c.Value("pool.size").Int(); err == nil && size == 20 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting pool.size")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if addr, err := c.Value("server.addr").String(); err != nil || addr != "0.0.0.0:8000" {
+		t.Errorf("expected server.addr from file, got %q %v", addr, err)
+	}
+
+	if err := defaults.Set("invalid", func() {}); err == nil {
+		t.Error("expected an error for a value not encodable as JSON")
+	}
+}
+
+func TestWatcherStop(t *testing.T) {
+	s := NewSource(nil)
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Next(); err == nil {
+		t.Error("expect error after stop, actual nil")
+	}
+	if len(s.watchers) != 0 {
+		t.Errorf("expected the watcher removed, got %d", len(s.watchers))
+	}
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+var _ config.Watcher = (*watcher)(nil)
+
+type watcher struct {
+	source  *Source
+	changed chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func newWatcher(s *Source) *watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &watcher{source: s, changed: make(chan struct{}, 1), ctx: ctx, cancel: cancel}
+}
+
+// notify coalesces the changes made before the next call of Next.
+func (w *watcher) notify() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Next blocks until the source is changed by Set or the watcher is stopped.
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	select {
+	case <-w.changed:
+		return w.source.Load()
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.source.remove(w)
+	w.cancel()
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 )
@@ -26,6 +27,15 @@ type options struct {
 	decoder  Decoder
 	resolver Resolver
 	merge    Merge
+	batch    BatchObserver
+	diff     DiffObserver
+	secrets  []string
+	poll     time.Duration
+	validate []Validator
+	profile  string
+	strict   bool
+	// validation runs the Validate methods and the validate tags.
+	validation bool
 }
 
 // WithSource with config source.
@@ -68,6 +78,31 @@ func WithMergeFunc(m Merge) Option {
 	}
 }
 
+// WithBatchObserver with config batch observer,
+// it is called once per source change with all the changed keys.
+func WithBatchObserver(bo BatchObserver) Option {
+	return func(o *options) {
+		o.batch = bo
+	}
+}
+
+// WithPollInterval with config poll interval.
+// Sources that can not watch natively, whose Watch returns a nil Watcher or
+// ErrWatchNotSupported, are reloaded every interval and a change is notified
+// when the loaded key values differ. Sources with a native watcher are not polled.
+func WithPollInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.poll = d
+	}
+}
+
+// WithValidator with config validators run by Scan on the decoded value.
+func WithValidator(v ...Validator) Option {
+	return func(o *options) {
+		o.validate = append(o.validate, v...)
+	}
+}
+
 // defaultDecoder decode config from source KeyValue
 // to target map[string]interface{} using src.Format codec.
 func defaultDecoder(src *KeyValue, target map[string]any) error {
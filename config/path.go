@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// isPath reports whether key is a JSON Pointer such as "/a/b/0/c" or a
+// JSONPath such as "$.a.b[0].c" rather than a dotted key.
+func isPath(key string) bool {
+	return strings.HasPrefix(key, "/") || strings.HasPrefix(key, "$")
+}
+
+// splitPath splits a JSON Pointer (RFC 6901) or a simple JSONPath of
+// child names and array indexes, e.g. "$.a['b.c'][0]", into its tokens.
+func splitPath(path string) ([]string, error) {
+	if strings.HasPrefix(path, "/") {
+		tokens := strings.Split(path[1:], "/")
+		for i, t := range tokens {
+			tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+		}
+		return tokens, nil
+	}
+	var tokens []string
+	for s := path[1:]; s != ""; {
+		switch {
+		case s[0] == '.':
+			end := strings.IndexAny(s[1:], ".[") + 1
+			if end == 0 {
+				end = len(s)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("invalid path %q: %w", path, ErrNotFound)
+			}
+			tokens = append(tokens, s[1:end])
+			s = s[end:]
+		case strings.HasPrefix(s, "['"):
+			end := strings.Index(s, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: %w", path, ErrNotFound)
+			}
+			tokens = append(tokens, s[2:end])
+			s = s[end+2:]
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: %w", path, ErrNotFound)
+			}
+			tokens = append(tokens, s[1:end])
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: %w", path, ErrNotFound)
+		}
+	}
+	return tokens, nil
+}
+
+// lookupPath navigates values along path, the error names the first token
+// that was not found.
+func lookupPath(values map[string]any, path string) (any, error) {
+	tokens, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		next any = values
+		at       = path[:1]
+	)
+	for _, t := range tokens {
+		switch v := next.(type) {
+		case map[string]any:
+			value, ok := v[t]
+			if !ok {
+				return nil, fmt.Errorf("no %q at %q: %w", t, at, ErrNotFound)
+			}
+			next = value
+		case []any:
+			i, err := strconv.Atoi(t)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("no index %q at %q of %d elements: %w", t, at, len(v), ErrNotFound)
+			}
+			next = v[i]
+		default:
+			return nil, fmt.Errorf("no %q at %q, it is not an object or array: %w", t, at, ErrNotFound)
+		}
+		at = joinPath(at, t)
+	}
+	return next, nil
+}
+
+func joinPath(at, token string) string {
+	switch {
+	case strings.HasPrefix(at, "/"):
+		if at == "/" {
+			at = ""
+		}
+		return at + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+	case strings.ContainsAny(token, ".[]'"):
+		return at + "['" + token + "']"
+	default:
+		return at + "." + token
+	}
+}
+
+// path returns the value at a JSON Pointer or JSONPath.
+func (r *reader) path(path string) (Value, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	value, err := lookupPath(r.values, path)
+	if err != nil {
+		return nil, err
+	}
+	av := &atomicValue{}
+	av.Store(value)
+	return av, nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testPathConfig = `{
+	"server": {"http": {"addr": ":8000"}},
+	"a/b": {"c.d": "escaped"},
+	"endpoints": [
+		{"name": "primary", "hosts": ["10.0.0.1", "10.0.0.2"]},
+		{"name": "backup", "weight": 3}
+	]
+}`
+
+func TestValuePath(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(testPathConfig)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/server/http/addr", ":8000"},
+		{"$.server.http.addr", ":8000"},
+		{"/endpoints/0/name", "primary"},
+		{"/endpoints/0/hosts/1", "10.0.0.2"},
+		{"$.endpoints[1].name", "backup"},
+		{"$.endpoints[0].hosts[0]", "10.0.0.1"},
+		{"$['server']['http'].addr", ":8000"},
+		{"/a~1b/c.d", "escaped"},
+		{"$['a/b']['c.d']", "escaped"},
+	}
+	for _, tt := range tests {
+		got, err := c.Value(tt.path).String()
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.path, tt.want, got)
+		}
+	}
+	if weight, err := GetInt(c, "$.endpoints[1].weight"); err != nil || weight != 3 {
+		t.Errorf("expected weight 3, got %d %v", weight, err)
+	}
+	if hosts, err := GetStringSlice(c, "/endpoints/0/hosts"); err != nil || len(hosts) != 2 {
+		t.Errorf("expected 2 hosts, got %v %v", hosts, err)
+	}
+	var ep struct {
+		Name  string   `json:"name"`
+		Hosts []string `json:"hosts"`
+	}
+	if err := c.Value("/endpoints/0").Scan(&ep); err != nil || ep.Name != "primary" || len(ep.Hosts) != 2 {
+		t.Errorf("expected the primary endpoint, got %+v %v", ep, err)
+	}
+}
+
+func TestValuePathNotFound(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(testPathConfig)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		msg  string
+	}{
+		{"/server/grpc/addr", `no "grpc" at "/server"`},
+		{"$.server.grpc.addr", `no "grpc" at "$.server"`},
+		{"/endpoints/2/name", `no index "2" at "/endpoints" of 2 elements`},
+		{"$.endpoints[x]", `no index "x" at "$.endpoints"`},
+		{"/server/http/addr/port", `no "port" at "/server/http/addr", it is not an object or array`},
+		{"$server", `invalid path "$server"`},
+		{"$.endpoints[0", `invalid path "$.endpoints[0"`},
+	}
+	for _, tt := range tests {
+		_, err := c.Value(tt.path).String()
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", tt.path, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%s: expected error %q, got %q", tt.path, tt.msg, err)
+		}
+	}
+	if _, err := GetString(c, "/server/grpc"); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), `"/server/grpc"`) {
+		t.Errorf("expected the path in the error, got %v", err)
+	}
+	if err := c.Watch("/server/grpc", func(string, Value) {}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrWatchNotSupported is returned by Source.Watch when the source can not watch changes natively.
+var ErrWatchNotSupported = errors.New("config: source watch not supported")
+
+var _ Watcher = (*pollWatcher)(nil)
+
+// pollWatcher synthesizes changes for a source by reloading it periodically.
+type pollWatcher struct {
+	src    Source
+	ticker *time.Ticker
+	last   []*KeyValue
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newPollWatcher(src Source, interval time.Duration, last []*KeyValue) *pollWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &pollWatcher{
+		src:    src,
+		ticker: time.NewTicker(interval),
+		last:   last,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Next blocks until a reload returns key values different from the previous one.
+func (w *pollWatcher) Next() ([]*KeyValue, error) {
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-w.ticker.C:
+		}
+		kvs, err := w.src.Load()
+		if err != nil {
+			return nil, err
+		}
+		if equalKeyValues(w.last, kvs) {
+			continue
+		}
+		w.last = kvs
+		return kvs, nil
+	}
+}
+
+func (w *pollWatcher) Stop() error {
+	w.ticker.Stop()
+	w.cancel()
+	return nil
+}
+
+func equalKeyValues(a, b []*KeyValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || a[i].Format != b[i].Format || !bytes.Equal(a[i].Value, b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
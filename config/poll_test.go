@@ -0,0 +1,101 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testPollSource struct {
+	mu    sync.Mutex
+	data  string
+	loads int64
+	watch func() (Watcher, error)
+}
+
+func (s *testPollSource) set(data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = data
+}
+
+func (s *testPollSource) Load() ([]*KeyValue, error) {
+	atomic.AddInt64(&s.loads, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return []*KeyValue{{Key: "poll", Value: []byte(s.data), Format: "json"}}, nil
+}
+
+func (s *testPollSource) Watch() (Watcher, error) {
+	return s.watch()
+}
+
+func TestPollInterval(t *testing.T) {
+	tests := []struct {
+		name  string
+		watch func() (Watcher, error)
+	}{
+		{"nil watcher", func() (Watcher, error) { return nil, nil }},
+		{"not supported", func() (Watcher, error) { return nil, ErrWatchNotSupported }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			src := &testPollSource{data: `{"name":"v1"}`, watch: test.watch}
+			c := New(WithSource(src), WithPollInterval(10*time.Millisecond))
+			if err := c.Load(); err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			changed := make(chan string, 1)
+			if err := c.Watch("name", func(_ string, v Value) {
+				s, _ := v.String()
+				changed <- s
+			}); err != nil {
+				t.Fatal(err)
+			}
+			src.set(`{"name":"v2"}`)
+			select {
+			case got := <-changed:
+				if got != "v2" {
+					t.Errorf("expected v2, got %s", got)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting poll notification")
+			}
+			select {
+			case got := <-changed:
+				t.Errorf("unexpected notification %s without change", got)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestPollIntervalNativeWatcher(t *testing.T) {
+	src := &testPollSource{data: `{"name":"v1"}`}
+	src.watch = func() (Watcher, error) {
+		return newTestWatcher(make(chan struct{}), make(chan struct{})), nil
+	}
+	c := New(WithSource(src), WithPollInterval(5*time.Millisecond))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	time.Sleep(50 * time.Millisecond)
+	if loads := atomic.LoadInt64(&src.loads); loads != 1 {
+		t.Errorf("expected source with native watcher not polled, loaded %d times", loads)
+	}
+}
+
+func TestPollWithoutInterval(t *testing.T) {
+	src := &testPollSource{data: `{"name":"v1"}`, watch: func() (Watcher, error) { return nil, ErrWatchNotSupported }}
+	c := New(WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := c.Value("name").String(); err != nil || name != "v1" {
+		t.Errorf("expected v1, got %s %v", name, err)
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Sections of a config with profiles.
+const (
+	profileDefaultKey = "default"
+	profilesKey       = "profiles"
+)
+
+// WithProfile selects the profile of the config sources laid out as
+//
+//	default:
+//	  server: {addr: ":8000"}
+//	profiles:
+//	  prod:
+//	    server: {addr: ":80"}
+//
+// The section of the profile is deep-merged over the default section, and
+// both over the other top-level keys, in every source which has a profiles
+// section; the sources without are merged as usual. A source whose
+// profiles section lacks the profile fails to load.
+func WithProfile(name string) Option {
+	return func(o *options) {
+		o.profile = name
+	}
+}
+
+// WithProfileEnv selects the profile named by the environment variable
+// key, see WithProfile. No profile is selected when it is unset or empty.
+func WithProfileEnv(key string) Option {
+	return WithProfile(os.Getenv(key))
+}
+
+// selectProfile returns the values of a source with the profile selected.
+func (o *options) selectProfile(values map[string]any) (map[string]any, error) {
+	if o.profile == "" {
+		return values, nil
+	}
+	profiles, ok := values[profilesKey].(map[string]any)
+	if !ok {
+		return values, nil
+	}
+	section, ok := profiles[o.profile].(map[string]any)
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("config: unknown profile %q, the profiles are %v", o.profile, names)
+	}
+	base := make(map[string]any, len(values))
+	for k, v := range values {
+		if k != profileDefaultKey && k != profilesKey {
+			base[k] = v
+		}
+	}
+	if def, ok := values[profileDefaultKey].(map[string]any); ok {
+		if err := o.merge(&base, def); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.merge(&base, section); err != nil {
+		return nil, err
+	}
+	return base, nil
+}
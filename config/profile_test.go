@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const testProfileConfig = `{
+	"name": "app",
+	"default": {
+		"server": {"addr": ":8000", "timeout": "1s"},
+		"log": {"level": "debug"}
+	},
+	"profiles": {
+		"prod": {"server": {"addr": ":80"}, "log": {"level": "info"}},
+		"staging": {"server": {"addr": ":8080"}}
+	}
+}`
+
+func TestProfile(t *testing.T) {
+	tests := []struct {
+		profile string
+		addr    string
+		level   string
+	}{
+		{"prod", ":80", "info"},
+		{"staging", ":8080", "debug"},
+	}
+	for _, tt := range tests {
+		c := New(WithSource(newTestJSONSource(testProfileConfig)), WithProfile(tt.profile))
+		if err := c.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if addr, _ := GetString(c, "server.addr"); addr != tt.addr {
+			t.Errorf("%s: expected addr %s, got %s", tt.profile, tt.addr, addr)
+		}
+		if level, _ := GetString(c, "log.level"); level != tt.level {
+			t.Errorf("%s: expected level %s, got %s", tt.profile, tt.level, level)
+		}
+		// the default is kept for the keys the profile does not set
+		if timeout, _ := GetString(c, "server.timeout"); timeout != "1s" {
+			t.Errorf("%s: expected the default timeout, got %s", tt.profile, timeout)
+		}
+		if name, _ := GetString(c, "name"); name != "app" {
+			t.Errorf("%s: expected the top-level name, got %s", tt.profile, name)
+		}
+		if _, err := GetString(c, "profiles.prod.server.addr"); err == nil {
+			t.Errorf("%s: expected the profiles section removed", tt.profile)
+		}
+	}
+}
+
+func TestProfilePrecedence(t *testing.T) {
+	// a later source without profiles still overrides the selected profile
+	c := New(WithSource(
+		newTestJSONSource(testProfileConfig),
+		newTestJSONSource(`{"server": {"addr": ":9000"}}`),
+	), WithProfile("prod"))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if addr, _ := GetString(c, "server.addr"); addr != ":9000" {
+		t.Errorf("expected the addr of the later source, got %s", addr)
+	}
+	if level, _ := GetString(c, "log.level"); level != "info" {
+		t.Errorf("expected the level of the profile, got %s", level)
+	}
+
+	// no profile leaves the sections as they are
+	c = New(WithSource(newTestJSONSource(testProfileConfig)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if addr, _ := GetString(c, "default.server.addr"); addr != ":8000" {
+		t.Errorf("expected the default section kept, got %s", addr)
+	}
+}
+
+func TestProfileEnv(t *testing.T) {
+	t.Setenv("APP_PROFILE", "prod")
+	c := New(WithSource(newTestJSONSource(testProfileConfig)), WithProfileEnv("APP_PROFILE"))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if addr, _ := GetString(c, "server.addr"); addr != ":80" {
+		t.Errorf("expected the addr of prod, got %s", addr)
+	}
+}
+
+func TestProfileUnknown(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(testProfileConfig)), WithProfile("qa"))
+	err := c.Load()
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "qa"`) || !strings.Contains(err.Error(), "[prod staging]") {
+		t.Errorf("expected the unknown profile error, got %v", err)
+	}
+}
@@ -37,25 +37,64 @@ func newReader(opts options) Reader {
 }
 
 func (r *reader) Merge(kvs ...*KeyValue) error {
-	merged, err := r.cloneMap()
+	merged, err := r.merge(kvs...)
 	if err != nil {
 		return err
 	}
+	r.lock.Lock()
+	r.values = merged
+	r.lock.Unlock()
+	return nil
+}
+
+// apply merges and resolves kvs on a copy of the current values and then
+// swaps it in, so that readers observe either the previous or the next
+// values but never a partially applied change. It returns the changes.
+func (r *reader) apply(kvs ...*KeyValue) ([]Change, error) {
+	merged, err := r.merge(kvs...)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.opts.resolver(merged); err != nil {
+		return nil, err
+	}
+	r.lock.Lock()
+	prev := r.values
+	r.values = merged
+	r.lock.Unlock()
+	return diffValues(prev, merged), nil
+}
+
+func (r *reader) merge(kvs ...*KeyValue) (map[string]any, error) {
+	merged, err := r.cloneMap()
+	if err != nil {
+		return nil, err
+	}
 	for _, kv := range kvs {
 		next := make(map[string]any)
 		if err := r.opts.decoder(kv, next); err != nil {
 			log.Errorf("Failed to config decode error: %v key: %s value: %s", err, kv.Key, string(kv.Value))
-			return err
+			return nil, err
 		}
-		if err := r.opts.merge(&merged, convertMap(next)); err != nil {
+		values, err := r.opts.selectProfile(convertMap(next).(map[string]any))
+		if err != nil {
+			log.Errorf("Failed to config select profile error: %v key: %s", err, kv.Key)
+			return nil, err
+		}
+		tombstones := takeTombstones(values)
+		appends := takeAppends(values)
+		if err := r.opts.merge(&merged, values); err != nil {
 			log.Errorf("Failed to config merge error: %v key: %s value: %s", err, kv.Key, string(kv.Value))
-			return err
+			return nil, err
+		}
+		for _, path := range tombstones {
+			deletePath(merged, path)
+		}
+		for _, a := range appends {
+			appendPath(merged, a)
 		}
 	}
-	r.lock.Lock()
-	r.values = merged
-	r.lock.Unlock()
-	return nil
+	return merged, nil
 }
 
 func (r *reader) Value(path string) (Value, bool) {
@@ -101,6 +140,21 @@ func cloneMap(src map[string]any) (map[string]any, error) {
 	return clone, nil
 }
 
+// flattenMap flattens nested maps into dst keyed by dot-separated paths.
+func flattenMap(prefix string, src map[string]any, dst map[string]any) map[string]any {
+	if dst == nil {
+		dst = make(map[string]any)
+	}
+	for k, v := range src {
+		if m, ok := v.(map[string]any); ok && len(m) > 0 {
+			flattenMap(prefix+k+".", m, dst)
+			continue
+		}
+		dst[prefix+k] = v
+	}
+	return dst
+}
+
 func convertMap(src any) any {
 	switch m := src.(type) {
 	case map[string]any:
@@ -132,6 +186,15 @@ func convertMap(src any) any {
 // readValue read Value in given map[string]interface{}
 // by the given path, will return false if not found.
 func readValue(values map[string]any, path string) (Value, bool) {
+	if isPath(path) {
+		value, err := lookupPath(values, path)
+		if err != nil {
+			return nil, false
+		}
+		av := &atomicValue{}
+		av.Store(value)
+		return av, true
+	}
 	var (
 		next = values
 		keys = strings.Split(path, ".")
@@ -168,5 +231,5 @@ func unmarshalJSON(data []byte, v any) error {
 	if m, ok := v.(proto.Message); ok {
 		return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
 	}
-	return json.Unmarshal(data, v)
+	return unmarshalUnits(data, v)
 }
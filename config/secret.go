@@ -0,0 +1,9 @@
+package config
+
+import "context"
+
+// SecretProvider resolves secrets by name, e.g. from a cloud secret manager.
+// See the secret source resolving the secret:// references of a source.
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
@@ -0,0 +1,48 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+var _ config.SecretProvider = (*Memory)(nil)
+
+// ErrNotFound is returned by Memory for an unknown secret.
+var ErrNotFound = errors.New("secret: not found")
+
+// Memory is an in-memory secret provider, e.g. for tests.
+type Memory struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// NewMemory returns a provider of secrets by name.
+func NewMemory(secrets map[string]string) *Memory {
+	m := &Memory{secrets: make(map[string]string, len(secrets))}
+	for k, v := range secrets {
+		m.secrets[k] = v
+	}
+	return m
+}
+
+// Set sets the secret name, e.g. to rotate it.
+func (m *Memory) Set(name, value string) {
+	m.mu.Lock()
+	m.secrets[name] = value
+	m.mu.Unlock()
+}
+
+// Secret returns the secret name or ErrNotFound.
+func (m *Memory) Secret(_ context.Context, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return v, nil
+}
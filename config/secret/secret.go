@@ -0,0 +1,227 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+// Scheme prefixes the config values referencing a secret, e.g.
+// "secret://db-password".
+const Scheme = "secret://"
+
+var _ config.Source = (*source)(nil)
+
+// Option is secret config source option.
+type Option func(*options)
+
+type options struct {
+	refresh time.Duration
+	timeout time.Duration
+}
+
+// WithRefreshInterval sets the interval between the refreshes of the cached
+// secrets by the watcher, a changed secret is notified as a change of the
+// source. Default is 0, the secrets are fetched once.
+func WithRefreshInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.refresh = d
+	}
+}
+
+// WithTimeout sets the timeout of a provider call, default is 5s.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+type source struct {
+	src      config.Source
+	provider config.SecretProvider
+	opts     options
+
+	mu    sync.Mutex
+	cache map[string]string
+	last  []*config.KeyValue
+}
+
+// NewSource returns a source resolving the string values of src of the form
+// "secret://name" to the secret name of provider. The values of a key value
+// without a format are resolved as a whole. The secrets are cached, see
+// WithRefreshInterval to refresh them.
+func NewSource(src config.Source, provider config.SecretProvider, opts ...Option) config.Source {
+	o := options{timeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &source{
+		src:      src,
+		provider: provider,
+		opts:     o,
+		cache:    make(map[string]string),
+	}
+}
+
+func (s *source) Load() ([]*config.KeyValue, error) {
+	kvs, err := s.src.Load()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.last = kvs
+	s.mu.Unlock()
+	return s.resolve(kvs)
+}
+
+func (s *source) Watch() (config.Watcher, error) {
+	inner, err := s.src.Watch()
+	if err != nil && !errors.Is(err, config.ErrWatchNotSupported) {
+		return nil, err
+	}
+	if inner == nil && s.opts.refresh <= 0 {
+		return nil, config.ErrWatchNotSupported
+	}
+	return newWatcher(s, inner), nil
+}
+
+// resolve returns kvs with the secret references replaced.
+func (s *source) resolve(kvs []*config.KeyValue) ([]*config.KeyValue, error) {
+	resolved := make([]*config.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		r, err := s.resolveKeyValue(kv)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, nil
+}
+
+func (s *source) resolveKeyValue(kv *config.KeyValue) (*config.KeyValue, error) {
+	if kv.Format == "" {
+		name, ok := strings.CutPrefix(string(kv.Value), Scheme)
+		if !ok {
+			return kv, nil
+		}
+		v, err := s.secret(name)
+		if err != nil {
+			return nil, fmt.Errorf("secret: resolve %q of %s: %w", name, kv.Key, err)
+		}
+		return &config.KeyValue{Key: kv.Key, Value: []byte(v), Format: kv.Format}, nil
+	}
+	codec := encoding.GetCodec(kv.Format)
+	if codec == nil {
+		return kv, nil
+	}
+	var values map[string]any
+	if err := codec.Unmarshal(kv.Value, &values); err != nil {
+		return nil, err
+	}
+	replaced, err := s.replace(kv.Key, values)
+	if err != nil || !replaced {
+		return kv, err
+	}
+	data, err := codec.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return &config.KeyValue{Key: kv.Key, Value: data, Format: kv.Format}, nil
+}
+
+// replace replaces the secret references in the values decoded from key.
+func (s *source) replace(key string, values any) (replaced bool, err error) {
+	var walk func(v any) any
+	walk = func(v any) any {
+		switch vt := v.(type) {
+		case string:
+			name, ok := strings.CutPrefix(vt, Scheme)
+			if !ok || err != nil {
+				return vt
+			}
+			secret, e := s.secret(name)
+			if e != nil {
+				err = fmt.Errorf("secret: resolve %q of %s: %w", name, key, e)
+				return vt
+			}
+			replaced = true
+			return secret
+		case map[string]any:
+			for k, sub := range vt {
+				vt[k] = walk(sub)
+			}
+		case map[any]any:
+			for k, sub := range vt {
+				vt[k] = walk(sub)
+			}
+		case []any:
+			for i, sub := range vt {
+				vt[i] = walk(sub)
+			}
+		}
+		return v
+	}
+	walk(values)
+	return replaced, err
+}
+
+// secret returns the cached secret name, fetching it on a miss.
+func (s *source) secret(name string) (string, error) {
+	s.mu.Lock()
+	v, ok := s.cache[name]
+	s.mu.Unlock()
+	if ok {
+		return v, nil
+	}
+	v, err := s.fetch(name)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.cache[name] = v
+	s.mu.Unlock()
+	return v, nil
+}
+
+func (s *source) fetch(name string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.timeout)
+	defer cancel()
+	return s.provider.Secret(ctx, name)
+}
+
+// refresh fetches the cached secrets again and reports whether any changed.
+func (s *source) refresh() (changed bool, err error) {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.cache))
+	for name := range s.cache {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+	for _, name := range names {
+		v, err := s.fetch(name)
+		if err != nil {
+			return false, fmt.Errorf("secret: refresh %q: %w", name, err)
+		}
+		s.mu.Lock()
+		if s.cache[name] != v {
+			s.cache[name] = v
+			changed = true
+		}
+		s.mu.Unlock()
+	}
+	return changed, nil
+}
+
+// latest returns the last key values of src resolved.
+func (s *source) latest() ([]*config.KeyValue, error) {
+	s.mu.Lock()
+	kvs := s.last
+	s.mu.Unlock()
+	return s.resolve(kvs)
+}
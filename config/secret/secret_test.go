@@ -0,0 +1,112 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/memory"
+)
+
+// countProvider counts the calls of the wrapped provider.
+type countProvider struct {
+	config.SecretProvider
+	calls atomic.Int32
+}
+
+func (p *countProvider) Secret(ctx context.Context, name string) (string, error) {
+	p.calls.Add(1)
+	return p.SecretProvider.Secret(ctx, name)
+}
+
+func TestSource(t *testing.T) {
+	provider := &countProvider{SecretProvider: NewMemory(map[string]string{"db-password": "s3cr3t"})}
+	src := memory.NewSource(map[string]any{
+		"database": map[string]any{
+			"user":     "kratos",
+			"password": "secret://db-password",
+			"replicas": []any{map[string]any{"password": "secret://db-password"}},
+		},
+	})
+	c := config.New(config.WithSource(NewSource(src, provider, WithRefreshInterval(20*time.Millisecond))))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if v, _ := c.Value("database.password").String(); v != "s3cr3t" {
+		t.Errorf("expected the secret resolved, got %q", v)
+	}
+	if v, _ := c.Value("database.user").String(); v != "kratos" {
+		t.Errorf("expected the plain value kept, got %q", v)
+	}
+	var replicas []struct{ Password string }
+	if err := c.Value("database.replicas").Scan(&replicas); err != nil || len(replicas) != 1 || replicas[0].Password != "s3cr3t" {
+		t.Errorf("expected the secret of the array resolved, got %v %v", replicas, err)
+	}
+	// the secret is cached
+	if n := provider.calls.Load(); n != 1 {
+		t.Errorf("expected one provider call, got %d", n)
+	}
+
+	changed := make(chan string, 1)
+	if err := c.Watch("database.password", func(_ string, v config.Value) {
+		s, _ := v.String()
+		changed <- s
+	}); err != nil {
+		t.Fatal(err)
+	}
+	provider.SecretProvider.(*Memory).Set("db-password", "r0tated")
+	select {
+	case v := <-changed:
+		if v != "r0tated" {
+			t.Errorf("expected the refreshed secret, got %q", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the refreshed secret")
+	}
+
+	// a change of the wrapped source is resolved with the cached secrets
+	if err := src.Set("database.user", "admin"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if v, _ := c.Value("database.user").String(); v != "admin" {
+		t.Errorf("expected the changed user, got %q", v)
+	}
+	if v, _ := c.Value("database.password").String(); v != "r0tated" {
+		t.Errorf("expected the secret kept, got %q", v)
+	}
+}
+
+type kvSource []*config.KeyValue
+
+func (s kvSource) Load() ([]*config.KeyValue, error) { return s, nil }
+
+func (s kvSource) Watch() (config.Watcher, error) { return nil, config.ErrWatchNotSupported }
+
+func TestSourceRawValue(t *testing.T) {
+	src := NewSource(kvSource{
+		{Key: "DB_PASSWORD", Value: []byte("secret://db-password")},
+		{Key: "DB_USER", Value: []byte("kratos")},
+	}, NewMemory(map[string]string{"db-password": "s3cr3t"}))
+	kvs, err := src.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(kvs[0].Value) != "s3cr3t" || string(kvs[1].Value) != "kratos" {
+		t.Errorf("unexpected key values %s %s", kvs[0].Value, kvs[1].Value)
+	}
+	if _, err = src.Watch(); !errors.Is(err, config.ErrWatchNotSupported) {
+		t.Errorf("expected %v without refresh, got %v", config.ErrWatchNotSupported, err)
+	}
+}
+
+func TestSourceNotFound(t *testing.T) {
+	src := NewSource(kvSource{{Key: "DB_PASSWORD", Value: []byte("secret://missing")}}, NewMemory(nil))
+	if _, err := src.Load(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+}
@@ -0,0 +1,87 @@
+package secret
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+var _ config.Watcher = (*watcher)(nil)
+
+type next struct {
+	kvs []*config.KeyValue
+	err error
+}
+
+type watcher struct {
+	source *source
+	inner  config.Watcher
+	next   chan next
+	tick   <-chan time.Time
+	ticker *time.Ticker
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher(s *source, inner config.Watcher) *watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{source: s, inner: inner, next: make(chan next), ctx: ctx, cancel: cancel}
+	if s.opts.refresh > 0 {
+		w.ticker = time.NewTicker(s.opts.refresh)
+		w.tick = w.ticker.C
+	}
+	if inner != nil {
+		go w.watch()
+	}
+	return w
+}
+
+// watch forwards the changes of the wrapped source to Next.
+func (w *watcher) watch() {
+	for {
+		kvs, err := w.inner.Next()
+		select {
+		case w.next <- next{kvs: kvs, err: err}:
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// Next blocks until the wrapped source changes or a refresh changes a secret.
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case n := <-w.next:
+			if n.err != nil {
+				return nil, n.err
+			}
+			w.source.mu.Lock()
+			w.source.last = n.kvs
+			w.source.mu.Unlock()
+			return w.source.resolve(n.kvs)
+		case <-w.tick:
+			changed, err := w.source.refresh()
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				return w.source.latest()
+			}
+		}
+	}
+}
+
+func (w *watcher) Stop() error {
+	if w.ticker != nil {
+		w.ticker.Stop()
+	}
+	w.cancel()
+	if w.inner != nil {
+		return w.inner.Stop()
+	}
+	return nil
+}
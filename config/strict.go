@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnresolved is wrapped by the error of a strict resolve listing the
+// unresolved placeholders.
+var ErrUnresolved = errors.New("config: unresolved placeholders")
+
+var placeholderRegexp = regexp.MustCompile(`\${(.*?)}`)
+
+// WithStrictResolve fails the load, and rejects a change on watch, when a
+// placeholder without a default, e.g. ${DB_HOST}, names a missing key. All
+// the missing keys are reported at once, each with the first key referencing
+// it in key order. The placeholders with a default, e.g.
+// ${DB_HOST:localhost}, are never reported.
+func WithStrictResolve() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// strictResolver checks the placeholders of the input before resolving them.
+func strictResolver(next Resolver) Resolver {
+	return func(input map[string]any) error {
+		if err := unresolved(input); err != nil {
+			return err
+		}
+		return next(input)
+	}
+}
+
+// unresolved returns an error wrapping ErrUnresolved with the placeholders
+// of input naming a missing key without a default, or nil without any.
+func unresolved(input map[string]any) error {
+	missing := make(map[string]string)
+	var walk func(path string, v any)
+	walk = func(path string, v any) {
+		switch vt := v.(type) {
+		case string:
+			for _, m := range placeholderRegexp.FindAllStringSubmatch(vt, -1) {
+				name := strings.TrimSpace(m[1])
+				if strings.Contains(name, ":") {
+					continue
+				}
+				if _, ok := readValue(input, name); ok {
+					continue
+				}
+				if _, ok := missing[name]; !ok {
+					missing[name] = path
+				}
+			}
+		case map[string]any:
+			keys := make([]string, 0, len(vt))
+			for k := range vt {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(subKey(path, k), vt[k])
+			}
+		case []any:
+			for i, sub := range vt {
+				walk(subKey(path, strconv.Itoa(i)), sub)
+			}
+		}
+	}
+	walk("", input)
+	if len(missing) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	refs := make([]string, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, fmt.Sprintf("${%s} (at %s)", name, missing[name]))
+	}
+	return fmt.Errorf("%w: %s", ErrUnresolved, strings.Join(refs, ", "))
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictResolve(t *testing.T) {
+	c := New(WithSource(
+		newTestJSONSource(`{
+			"database": {"host": "${DB_HOST}", "port": "${DB_PORT:5432}"},
+			"redis": {"addrs": ["${REDIS_ADDR}", "${CACHE_ADDR}"]},
+			"backup": {"host": "${DB_HOST}"},
+			"name": "${APP_NAME}"
+		}`),
+		newTestJSONSource(`{"APP_NAME": "app"}`),
+	), WithStrictResolve())
+	err := c.Load()
+	if !errors.Is(err, ErrUnresolved) {
+		t.Fatalf("expected %v, got %v", ErrUnresolved, err)
+	}
+	// all the missing keys at once, the ones with a default or set are left out
+	want := "config: unresolved placeholders: ${CACHE_ADDR} (at redis.addrs.1), ${DB_HOST} (at backup.host), ${REDIS_ADDR} (at redis.addrs.0)"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestStrictResolveDefault(t *testing.T) {
+	c := New(WithSource(
+		newTestJSONSource(`{"database": {"host": "${DB_HOST:localhost}", "user": "${DB_USER}"}}`),
+		newTestJSONSource(`{"DB_USER": "kratos"}`),
+	), WithStrictResolve())
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if host, _ := c.Value("database.host").String(); host != "localhost" {
+		t.Errorf("expected the default host, got %s", host)
+	}
+	if user, _ := c.Value("database.user").String(); user != "kratos" {
+		t.Errorf("expected the resolved user, got %s", user)
+	}
+
+	// without the strict mode a missing key resolves to empty
+	c = New(WithSource(newTestJSONSource(`{"database": {"host": "${DB_HOST}"}}`)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if host, _ := c.Value("database.host").String(); host != "" {
+		t.Errorf("expected an empty host, got %s", host)
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var structMapTypes sync.Map // reflect.Type -> bool
+
+// hasStructMap reports whether t holds a map of struct values.
+func hasStructMap(t reflect.Type) bool {
+	return holds(&structMapTypes, t, func(t reflect.Type) bool {
+		if t.Kind() != reflect.Map {
+			return false
+		}
+		e := t.Elem()
+		for e.Kind() == reflect.Pointer {
+			e = e.Elem()
+		}
+		return e.Kind() == reflect.Struct
+	})
+}
+
+// mergeDefaults returns src, a decoded JSON value for v, merged on top of
+// the current value of v. encoding/json decodes every map entry into a
+// new zero value, so without it the defaults set on the entries of a map
+// of structs before Scan would be lost. src is not modified, it is
+// returned as is when v can not be marshaled.
+func mergeDefaults(v any, src any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return src, nil
+	}
+	dst, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return mergeJSON(dst, src, reflect.TypeOf(v)), nil
+}
+
+// mergeJSON merges the decoded JSON values src on top of dst, both values
+// of the type t. The keys of a struct match its fields case-insensitively
+// as in encoding/json, the src key replaces the dst one.
+func mergeJSON(dst, src any, t reflect.Type) any {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	dm, ok := dst.(map[string]any)
+	if !ok || t == nil || (t.Kind() != reflect.Struct && t.Kind() != reflect.Map) {
+		return src
+	}
+	sm, ok := src.(map[string]any)
+	if !ok {
+		return src
+	}
+	out := make(map[string]any, len(dm)+len(sm))
+	for k, v := range dm {
+		out[k] = v
+	}
+	for k, sv := range sm {
+		dv, found := out[k]
+		if !found && t.Kind() == reflect.Struct {
+			for ok, ov := range out {
+				if strings.EqualFold(ok, k) {
+					dv, found = ov, true
+					delete(out, ok)
+					break
+				}
+			}
+		}
+		ft, known := memberType(t, k)
+		if !found || !known {
+			out[k] = sv
+			continue
+		}
+		out[k] = mergeJSON(dv, sv, ft)
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDBConfig struct {
+	Host    string        `json:"host"`
+	Port    int           `json:"port"`
+	Timeout time.Duration `json:"timeout"`
+	Pool    struct {
+		Max  int `json:"max"`
+		Idle int `json:"idle"`
+	} `json:"pool"`
+}
+
+type testDatabasesConfig struct {
+	Databases map[string]testDBConfig  `json:"databases"`
+	Replicas  map[string]*testDBConfig `json:"replicas"`
+}
+
+func TestScanStructMap(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(`{
+		"databases": {
+			"primary": {"host": "10.0.0.1", "timeout": "5s", "pool": {"idle": 2}},
+			"replica": {"Host": "10.0.0.2", "port": 5433}
+		},
+		"replicas": {"eu": {"host": "10.0.1.1"}}
+	}`)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	primary := testDBConfig{Port: 5432, Timeout: time.Second}
+	primary.Pool.Max = 10
+	eu := &testDBConfig{Port: 5432}
+	cfg := testDatabasesConfig{
+		Databases: map[string]testDBConfig{"primary": primary, "replica": {Host: "localhost", Port: 5432}},
+		Replicas:  map[string]*testDBConfig{"eu": eu},
+	}
+	if err := c.Scan(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := testDBConfig{Host: "10.0.0.1", Port: 5432, Timeout: 5 * time.Second}
+	want.Pool.Max, want.Pool.Idle = 10, 2
+	if got := cfg.Databases["primary"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the defaults of primary kept, got %+v", got)
+	}
+	if got := cfg.Databases["replica"]; got.Host != "10.0.0.2" || got.Port != 5433 {
+		t.Errorf("expected replica overridden, got %+v", got)
+	}
+	if got := cfg.Replicas["eu"]; got.Host != "10.0.1.1" || got.Port != 5432 {
+		t.Errorf("expected the defaults of eu kept, got %+v", got)
+	}
+
+	// the value of a subkey scans into a map too
+	dbs := map[string]testDBConfig{"primary": primary}
+	if err := c.Value("databases").Scan(&dbs); err != nil {
+		t.Fatal(err)
+	}
+	if len(dbs) != 2 || !reflect.DeepEqual(dbs["primary"], want) {
+		t.Errorf("expected primary and replica, got %+v", dbs)
+	}
+}
+
+func TestScanStructMapEmpty(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(`{"databases": {}}`)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var cfg testDatabasesConfig
+	if err := c.Scan(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Databases == nil || len(cfg.Databases) != 0 {
+		t.Errorf("expected an empty map, got %#v", cfg.Databases)
+	}
+	if cfg.Replicas != nil {
+		t.Errorf("expected no replicas, got %#v", cfg.Replicas)
+	}
+}
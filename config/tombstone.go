@@ -0,0 +1,45 @@
+package config
+
+// Tombstone is the value deleting its key from the merged config, so that
+// a source removes a key of a source of lower precedence, e.g. the YAML
+//
+//	tracing: $delete
+//
+// removes the whole tracing section of the config file below. Unlike a
+// tombstone, a null value keeps the key, set to null.
+const Tombstone = "$delete"
+
+// takeTombstones removes the keys of values set to Tombstone and returns
+// their paths.
+func takeTombstones(values map[string]any) [][]string {
+	var paths [][]string
+	var walk func(m map[string]any, prefix []string)
+	walk = func(m map[string]any, prefix []string) {
+		for k, v := range m {
+			switch vt := v.(type) {
+			case string:
+				if vt == Tombstone {
+					delete(m, k)
+					paths = append(paths, append(append([]string(nil), prefix...), k))
+				}
+			case map[string]any:
+				walk(vt, append(prefix, k))
+			}
+		}
+	}
+	walk(values, nil)
+	return paths
+}
+
+// deletePath deletes the key of path from values, if it exists.
+func deletePath(values map[string]any, path []string) {
+	next := values
+	for _, k := range path[:len(path)-1] {
+		sub, ok := next[k].(map[string]any)
+		if !ok {
+			return
+		}
+		next = sub
+	}
+	delete(next, path[len(path)-1])
+}
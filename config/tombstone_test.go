@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	testTombstoneBase = `{
+		"name": "app",
+		"server": {"addr": ":80", "timeout": "1s", "tls": {"cert": "server.pem"}},
+		"tracing": {"endpoint": "localhost:4317"}
+	}`
+	testTombstoneOverride = `{
+		"server": {"tls": "$delete", "timeout": null},
+		"tracing": "$delete",
+		"missing": {"key": "$delete"}
+	}`
+)
+
+func TestTombstone(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(testTombstoneBase), newTestJSONSource(testTombstoneOverride)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	// a tombstone removes the key
+	for _, key := range []string{"tracing", "tracing.endpoint", "server.tls", "missing.key"} {
+		if err := c.Value(key).Scan(new(any)); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected deleted, got %v", key, err)
+		}
+	}
+	// a null sets the key to null
+	server, _ := c.Value("server").Load().(map[string]any)
+	if timeout, ok := server["timeout"]; !ok || timeout != nil {
+		t.Errorf("expected server.timeout set to null, got %v", server)
+	}
+	if addr, _ := GetString(c, "server.addr"); addr != ":80" {
+		t.Errorf("expected server.addr kept, got %q", addr)
+	}
+	var conf struct {
+		Name   string `json:"name"`
+		Server struct {
+			Addr string         `json:"addr"`
+			TLS  map[string]any `json:"tls"`
+		} `json:"server"`
+		Tracing map[string]any `json:"tracing"`
+	}
+	if err := c.Scan(&conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Name != "app" || conf.Server.Addr != ":80" || conf.Server.TLS != nil || conf.Tracing != nil {
+		t.Errorf("expected the deleted keys missing, got %+v", conf)
+	}
+}
+
+func TestTombstoneReapplied(t *testing.T) {
+	r := newReader(options{decoder: defaultDecoder, resolver: defaultResolver, merge: New().(*config).opts.merge}).(*reader)
+	base := &KeyValue{Key: "base", Format: "json", Value: []byte(testTombstoneBase)}
+	override := &KeyValue{Key: "override", Format: "json", Value: []byte(testTombstoneOverride)}
+	if err := r.Merge(base, override); err != nil {
+		t.Fatal(err)
+	}
+	// a change of the base is merged again under the override
+	base = &KeyValue{Key: "base", Format: "json", Value: []byte(`{"tracing": {"endpoint": "collector:4317"}, "debug": true}`)}
+	if _, err := r.apply(base, override); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Value("tracing"); ok {
+		t.Error("expected tracing still deleted")
+	}
+	if v, ok := r.Value("debug"); !ok || v.Load() != true {
+		t.Errorf("expected debug merged, got %v", v)
+	}
+	// the tombstone itself is never a value
+	if _, ok := r.Value("server.tls"); ok {
+		t.Error("expected server.tls still deleted")
+	}
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ByteSize is a size in bytes, Scan accepts both an integer number of bytes
+// and a human string such as "10MB" or "1.5GiB" for it.
+type ByteSize int64
+
+// Byte sizes, KB and friends are decimal while KiB and friends are binary.
+const (
+	B   ByteSize = 1
+	KB  ByteSize = 1000
+	MB           = 1000 * KB
+	GB           = 1000 * MB
+	TB           = 1000 * GB
+	KiB ByteSize = 1 << 10
+	MiB ByteSize = 1 << 20
+	GiB ByteSize = 1 << 30
+	TiB ByteSize = 1 << 40
+)
+
+var byteUnits = map[string]ByteSize{
+	"": B, "b": B,
+	"k": KB, "kb": KB, "m": MB, "mb": MB, "g": GB, "gb": GB, "t": TB, "tb": TB,
+	"ki": KiB, "kib": KiB, "mi": MiB, "mib": MiB, "gi": GiB, "gib": GiB, "ti": TiB, "tib": TiB,
+}
+
+// ParseByteSize parses a byte size such as "512", "10MB", "1.5 GiB".
+// Units are case-insensitive, KB is 1000 bytes and KiB is 1024 bytes.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return r != '.' && !unicode.IsDigit(r) })
+	if i < 0 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	mul, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q in byte size %q", s[i:], s)
+	}
+	size := n * float64(mul)
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("byte size %q overflows", s)
+	}
+	return ByteSize(size), nil
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+	unitTypes    sync.Map // reflect.Type -> bool
+)
+
+// hasUnits reports whether t holds a time.Duration or a ByteSize.
+func hasUnits(t reflect.Type) bool {
+	return holds(&unitTypes, t, func(t reflect.Type) bool {
+		return t == durationType || t == byteSizeType
+	})
+}
+
+// holds reports whether t or a type nested in it satisfies leaf, the
+// results are cached by type in cache.
+func holds(cache *sync.Map, t reflect.Type, leaf func(reflect.Type) bool) bool {
+	if v, ok := cache.Load(t); ok {
+		return v.(bool)
+	}
+	// only the final result is cached, a provisional one of a recursive
+	// type would be seen by the concurrent calls
+	has := reaches(t, leaf, make(map[reflect.Type]bool))
+	cache.Store(t, has)
+	return has
+}
+
+// reaches reports whether a type reachable from t satisfies leaf, visited
+// guards the recursive types.
+func reaches(t reflect.Type, leaf func(reflect.Type) bool, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	if leaf(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return reaches(t.Elem(), leaf, visited)
+	case reflect.Struct:
+		for _, f := range jsonFields(t) {
+			if reaches(f.typ, leaf, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type jsonField struct {
+	name string
+	typ  reflect.Type
+}
+
+// jsonFields returns the fields of the struct t as decoded by encoding/json.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(ft)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{name: name, typ: ft})
+	}
+	return fields
+}
+
+// memberType returns the type of the member key of the struct or map t.
+func memberType(t reflect.Type, key string) (reflect.Type, bool) {
+	if t.Kind() == reflect.Map {
+		return t.Elem(), true
+	}
+	fields := jsonFields(t)
+	for _, f := range fields {
+		if f.name == key {
+			return f.typ, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f.typ, true
+		}
+	}
+	return nil, false
+}
+
+func subKey(key, sub string) string {
+	if key == "" {
+		return sub
+	}
+	return key + "." + sub
+}
+
+// convertUnits returns src, a decoded JSON value at key, with the human
+// strings of the time.Duration and ByteSize fields of t converted to numbers.
+// src is not modified.
+func convertUnits(src any, t reflect.Type, key string) (any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == durationType:
+		if s, ok := src.(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, fmt.Errorf("config: invalid duration %q for key %q: %w", s, key, err)
+			}
+			return int64(d), nil
+		}
+		return src, nil
+	case t == byteSizeType:
+		if s, ok := src.(string); ok {
+			size, err := ParseByteSize(s)
+			if err != nil {
+				return nil, fmt.Errorf("config: invalid byte size for key %q: %w", key, err)
+			}
+			return int64(size), nil
+		}
+		return src, nil
+	case !hasUnits(t):
+		return src, nil
+	}
+	switch v := src.(type) {
+	case map[string]any:
+		if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+			return src, nil
+		}
+		m := make(map[string]any, len(v))
+		for k, sv := range v {
+			ft, ok := memberType(t, k)
+			if !ok {
+				m[k] = sv
+				continue
+			}
+			cv, err := convertUnits(sv, ft, subKey(key, k))
+			if err != nil {
+				return nil, err
+			}
+			m[k] = cv
+		}
+		return m, nil
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return src, nil
+		}
+		s := make([]any, len(v))
+		for i, sv := range v {
+			cv, err := convertUnits(sv, t.Elem(), fmt.Sprintf("%s[%d]", key, i))
+			if err != nil {
+				return nil, err
+			}
+			s[i] = cv
+		}
+		return s, nil
+	}
+	return src, nil
+}
+
+// unmarshalUnits decodes the JSON data into v, accepting human strings for
+// its time.Duration and ByteSize fields.
+func unmarshalUnits(data []byte, v any) error {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return json.Unmarshal(data, v)
+	}
+	units, maps := hasUnits(t), hasStructMap(t)
+	if !units && !maps {
+		return json.Unmarshal(data, v)
+	}
+	src, err := decodeJSON(data)
+	if err != nil {
+		return err
+	}
+	if units {
+		if src, err = convertUnits(src, t, ""); err != nil {
+			return err
+		}
+	}
+	if maps {
+		if src, err = mergeDefaults(v, src); err != nil {
+			return err
+		}
+	}
+	if data, err = json.Marshal(src); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// decodeJSON decodes data into generic values keeping the numbers exact.
+func decodeJSON(data []byte) (any, error) {
+	var src any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&src); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+	}{
+		{"512", 512},
+		{"512B", 512},
+		{"10KB", 10 * KB},
+		{"10MB", 10 * MB},
+		{"10mb", 10 * MB},
+		{"1GiB", GiB},
+		{"1.5 GiB", GiB + GiB/2},
+		{"2Ki", 2 * KiB},
+		{"1TB", TB},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.in, err)
+		} else if got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.in, tt.want, got)
+		}
+	}
+	for _, in := range []string{"", "MB", "10XB", "-1MB", "1.2.3KB"} {
+		if _, err := ParseByteSize(in); err == nil {
+			t.Errorf("%s: expected error", in)
+		}
+	}
+}
+
+type testUnitsConfig struct {
+	Server struct {
+		Timeout time.Duration `json:"timeout"`
+		Idle    *time.Duration
+	} `json:"server"`
+	MaxSize  ByteSize                 `json:"max_size"`
+	Retries  []time.Duration          `json:"retries"`
+	Limits   map[string]ByteSize      `json:"limits"`
+	Interval time.Duration            `json:"interval"`
+	Name     string                   `json:"name"`
+	Extra    map[string]time.Duration `json:"-"`
+}
+
+func TestScanUnits(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(`{
+		"server": {"timeout": "30s", "idle": "1h"},
+		"max_size": "10MB",
+		"retries": ["100ms", 200000000],
+		"limits": {"body": "1GiB", "header": 4096},
+		"interval": 1000000000,
+		"name": "30s"
+	}`)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var v testUnitsConfig
+	if err := c.Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Server.Timeout != 30*time.Second {
+		t.Errorf("expected timeout 30s, got %v", v.Server.Timeout)
+	}
+	if v.Server.Idle == nil || *v.Server.Idle != time.Hour {
+		t.Errorf("expected idle 1h, got %v", v.Server.Idle)
+	}
+	if v.MaxSize != 10*MB {
+		t.Errorf("expected max size 10MB, got %d", v.MaxSize)
+	}
+	if len(v.Retries) != 2 || v.Retries[0] != 100*time.Millisecond || v.Retries[1] != 200*time.Millisecond {
+		t.Errorf("unexpected retries %v", v.Retries)
+	}
+	if v.Limits["body"] != GiB || v.Limits["header"] != 4096 {
+		t.Errorf("unexpected limits %v", v.Limits)
+	}
+	if v.Interval != time.Second {
+		t.Errorf("expected interval 1s, got %v", v.Interval)
+	}
+	if v.Name != "30s" {
+		t.Errorf("expected name 30s, got %s", v.Name)
+	}
+
+	var server struct {
+		Timeout time.Duration `json:"timeout"`
+	}
+	if err := c.Value("server").Scan(&server); err != nil {
+		t.Fatal(err)
+	}
+	if server.Timeout != 30*time.Second {
+		t.Errorf("expected timeout 30s, got %v", server.Timeout)
+	}
+}
+
+func TestScanUnitsMalformed(t *testing.T) {
+	tests := []struct {
+		data string
+		key  string
+	}{
+		{`{"server": {"timeout": "30x"}}`, `"server.timeout"`},
+		{`{"max_size": "10XB"}`, `"max_size"`},
+		{`{"retries": ["1s", "fast"]}`, `"retries[1]"`},
+		{`{"limits": {"body": "big"}}`, `"limits.body"`},
+	}
+	for _, tt := range tests {
+		c := New(WithSource(newTestJSONSource(tt.data)))
+		if err := c.Load(); err != nil {
+			t.Fatal(err)
+		}
+		var v testUnitsConfig
+		err := c.Scan(&v)
+		if err == nil || !strings.Contains(err.Error(), tt.key) {
+			t.Errorf("%s: expected an error naming %s, got %v", tt.data, tt.key, err)
+		}
+		_ = c.Close()
+	}
+}
+
+// testConcurrentUnits is scanned by TestScanUnitsConcurrent only, its holds
+// result is not cached before.
+type testConcurrentUnits struct {
+	Timeout time.Duration        `json:"timeout"`
+	MaxSize ByteSize             `json:"max_size"`
+	Next    *testConcurrentUnits `json:"next"`
+}
+
+func TestScanUnitsConcurrent(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(`{"timeout": "1h", "max_size": "10MB", "next": {"timeout": "1s"}}`)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var v testConcurrentUnits
+			if err := c.Scan(&v); err != nil {
+				t.Error(err)
+				return
+			}
+			if v.Timeout != time.Hour || v.MaxSize != 10*MB || v.Next == nil || v.Next.Timeout != time.Second {
+				t.Errorf("unexpected scan %+v", v)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator validates the value decoded by Scan, e.g. port ranges or
+// required fields.
+type Validator func(v any) error
+
+// validator is implemented by generated messages, e.g. protoc-gen-validate.
+type validator interface {
+	Validate() error
+}
+
+// allValidator reports all the violations instead of the first one.
+type allValidator interface {
+	ValidateAll() error
+}
+
+// WithValidation validates the values decoded by Scan with their Validate
+// or ValidateAll method, e.g. of protoc-gen-validate, and with the rules of
+// the validate tags of their fields, comma separated:
+//
+//	Port    int           `json:"port" validate:"required,min=1,max=65535"`
+//	Timeout time.Duration `json:"timeout" validate:"min=1s"`
+//	Level   string        `json:"level" validate:"oneof=debug info warn error"`
+//
+// required rejects the zero value, min and max bound the numbers, the
+// durations, the byte sizes and the length of the strings, slices and maps,
+// oneof lists the allowed values separated by spaces. The other rules are
+// left to their validator, so that the tags of go-playground/validator keep
+// working: omitempty skips the rules of an unset value, the rules after dive
+// apply to the elements and are not checked.
+func WithValidation() Option {
+	return func(o *options) {
+		o.validation = true
+	}
+}
+
+// validate runs the Validate method and the tag rules of v with
+// WithValidation, then the registered validators, and joins all their
+// errors so that every problem is reported.
+func (c *config) validate(v any) error {
+	var errs []error
+	if c.opts.validation {
+		switch vv := v.(type) {
+		case allValidator:
+			if err := vv.ValidateAll(); err != nil {
+				errs = append(errs, err)
+			}
+		case validator:
+			if err := vv.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		errs = validateTags(errs, "", reflect.ValueOf(v))
+	}
+	for _, fn := range c.opts.validate {
+		if err := fn(v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("config: invalid config: %w", errors.Join(errs...))
+}
+
+// validateTags appends the violations of the validate tags of the fields
+// of v, and of the values nested in it, to errs.
+func validateTags(errs []error, path string, v reflect.Value) []error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			errs = validateTags(errs, path, v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			errs = validateTags(errs, fmt.Sprintf("%s[%d]", path, i), v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			errs = validateTags(errs, subKey(path, fmt.Sprint(iter.Key().Interface())), iter.Value())
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				name = f.Name
+			}
+			fpath := subKey(path, name)
+			if f.Anonymous && f.Tag.Get("json") == "" {
+				fpath = path
+			}
+			fv := v.Field(i)
+			if tag := f.Tag.Get("validate"); tag != "" {
+				errs = validateRules(errs, fpath, tag, fv)
+			}
+			errs = validateTags(errs, fpath, fv)
+		}
+	}
+	return errs
+}
+
+// validateRules appends the violations of the rules of the validate tag of
+// the field v to errs.
+func validateRules(errs []error, path, tag string, v reflect.Value) []error {
+	for _, rule := range strings.Split(tag, ",") {
+		switch rule = strings.TrimSpace(rule); {
+		case rule == "dive":
+			return errs
+		case rule == "omitempty":
+			if v.IsZero() {
+				return errs
+			}
+		case strings.Contains(rule, "|"):
+			// an alternative of rules, left to its validator
+		default:
+			if err := checkRule(rule, v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			}
+		}
+	}
+	return errs
+}
+
+// checkRule checks the value v against the validate tag rule, the unknown
+// rules pass.
+func checkRule(rule string, v reflect.Value) error {
+	name, arg, _ := strings.Cut(rule, "=")
+	if name != "required" && v.Kind() == reflect.Pointer && v.IsNil() {
+		// an unset optional value, see required
+		return nil
+	}
+	switch name {
+	case "":
+		return nil
+	case "required":
+		if v.IsZero() {
+			return errors.New("is required")
+		}
+		return nil
+	case "oneof":
+		got := fmt.Sprint(reflect.Indirect(v).Interface())
+		for _, want := range strings.Fields(arg) {
+			if got == want {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s, got %s", arg, got)
+	case "min", "max":
+		got, bound, err := ruleMeasure(v, arg)
+		if err != nil {
+			return fmt.Errorf("invalid rule %q: %w", rule, err)
+		}
+		if name == "min" && got < bound {
+			return fmt.Errorf("must be at least %s", arg)
+		}
+		if name == "max" && got > bound {
+			return fmt.Errorf("must be at most %s", arg)
+		}
+		return nil
+	}
+	return nil
+}
+
+// ruleMeasure returns the value to bound of v, its length for the strings,
+// slices and maps, and the bound arg parsed alike.
+func ruleMeasure(v reflect.Value, arg string) (got, bound float64, err error) {
+	v = reflect.Indirect(v)
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(arg)
+		return float64(v.Int()), float64(d), err
+	case v.Type() == byteSizeType:
+		s, err := ParseByteSize(arg)
+		return float64(v.Int()), float64(s), err
+	}
+	bound, err = strconv.ParseFloat(arg, 64)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), bound, err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), bound, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), bound, err
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), bound, err
+	}
+	return 0, 0, fmt.Errorf("unsupported type %s", v.Type())
+}
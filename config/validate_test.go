@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testValidateConfig struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+	URL  string `json:"url"`
+}
+
+func (c *testValidateConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+var (
+	errInvalidPort = errors.New("port out of range")
+	errEmptyURL    = errors.New("url is required")
+)
+
+func validatePort(v any) error {
+	if c, ok := v.(*testValidateConfig); ok && (c.Port <= 0 || c.Port > 65535) {
+		return errInvalidPort
+	}
+	return nil
+}
+
+func validateURL(v any) error {
+	if c, ok := v.(*testValidateConfig); ok && c.URL == "" {
+		return errEmptyURL
+	}
+	return nil
+}
+
+func TestScanValidate(t *testing.T) {
+	c := New(
+		WithSource(newTestJSONSource(`{"name":"kratos","port":8000,"url":"http://127.0.0.1"}`)),
+		WithValidator(validatePort, validateURL),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var v testValidateConfig
+	if err := c.Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Port != 8000 {
+		t.Errorf("expected port 8000, got %d", v.Port)
+	}
+}
+
+func TestScanValidateErrors(t *testing.T) {
+	c := New(
+		WithSource(newTestJSONSource(`{"port":70000}`)),
+		WithValidation(),
+		WithValidator(validatePort),
+		WithValidator(validateURL),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var v testValidateConfig
+	err := c.Scan(&v)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if !errors.Is(err, errInvalidPort) || !errors.Is(err, errEmptyURL) {
+		t.Errorf("expected all validator errors, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "name is required") {
+		t.Errorf("expected the Validate method error, got %v", err)
+	}
+	// validators only apply to the value they know
+	var m map[string]any
+	if err := c.Scan(&m); err != nil {
+		t.Errorf("expected no error scanning into a map, got %v", err)
+	}
+}
+
+func TestScanValidationOptIn(t *testing.T) {
+	// the Validate method runs only with WithValidation
+	c := New(WithSource(newTestJSONSource(`{"port":8000}`)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var v testValidateConfig
+	if err := c.Scan(&v); err != nil {
+		t.Errorf("expected no validation, got %v", err)
+	}
+}
+
+type testTagConfig struct {
+	Server struct {
+		Port    int           `json:"port" validate:"required,min=1,max=65535"`
+		Timeout time.Duration `json:"timeout" validate:"min=1s,max=1m"`
+	} `json:"server"`
+	Level    string            `json:"level" validate:"oneof=debug info warn error"`
+	MaxSize  ByteSize          `json:"max_size" validate:"max=1MB"`
+	Backends []testTagBackend  `json:"backends" validate:"min=1"`
+	Labels   map[string]string `json:"labels" validate:"max=2"`
+	Proxy    *testTagBackend   `json:"proxy"`
+}
+
+type testTagBackend struct {
+	URL string `json:"url" validate:"required"`
+}
+
+func TestScanValidationTags(t *testing.T) {
+	valid := `{
+		"server": {"port": 8000, "timeout": "10s"},
+		"level": "info",
+		"max_size": "512KB",
+		"backends": [{"url": "http://127.0.0.1"}],
+		"labels": {"zone": "a"}
+	}`
+	c := New(WithSource(newTestJSONSource(valid)), WithValidation())
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var v testTagConfig
+	if err := c.Scan(&v); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+
+	invalid := `{
+		"server": {"port": 70000, "timeout": "10ms"},
+		"level": "trace",
+		"max_size": "2MB",
+		"backends": [{"url": "http://127.0.0.1"}, {}],
+		"labels": {"a": "1", "b": "2", "c": "3"},
+		"proxy": {}
+	}`
+	c = New(WithSource(newTestJSONSource(invalid)), WithValidation())
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Scan(&testTagConfig{})
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		"server.port: must be at most 65535",
+		"server.timeout: must be at least 1s",
+		"level: must be one of debug info warn error, got trace",
+		"max_size: must be at most 1MB",
+		"backends[1].url: is required",
+		"labels: must be at most 2",
+		"proxy.url: is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+
+	c = New(WithSource(newTestJSONSource(`{}`)), WithValidation())
+	if err = c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	err = c.Scan(&testTagConfig{})
+	if err == nil || !strings.Contains(err.Error(), "server.port: is required") || !strings.Contains(err.Error(), "backends: must be at least 1") {
+		t.Errorf("expected the missing values reported, got %v", err)
+	}
+}
+
+type testForeignTagConfig struct {
+	Endpoint string   `json:"endpoint" validate:"required,url"`
+	Email    string   `json:"email" validate:"omitempty,email,max=8"`
+	Workers  int      `json:"workers" validate:"gte=1,max=16"`
+	Hosts    []string `json:"hosts" validate:"min=1,dive,hostname,min=64"`
+	Mode     string   `json:"mode" validate:"eq=a|eq=b"`
+}
+
+func TestScanValidationForeignTags(t *testing.T) {
+	// the rules of other validators, e.g. go-playground/validator, are ignored
+	c := New(WithSource(newTestJSONSource(`{"endpoint":"http://127.0.0.1","workers":4,"hosts":["a"],"mode":"a"}`)), WithValidation())
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Scan(&testForeignTagConfig{}); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+
+	c = New(WithSource(newTestJSONSource(`{"email":"kratos@example.com","workers":32}`)), WithValidation())
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Scan(&testForeignTagConfig{})
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		"endpoint: is required",
+		"email: must be at most 8",
+		"workers: must be at most 16",
+		"hosts: must be at least 1",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
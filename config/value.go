@@ -172,7 +172,7 @@ func (v *atomicValue) Scan(obj any) error {
 	if pb, ok := obj.(proto.Message); ok {
 		return kratosjson.UnmarshalOptions.Unmarshal(data, pb)
 	}
-	return json.Unmarshal(data, obj)
+	return unmarshalUnits(data, obj)
 }
 
 type errValue struct {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-kratos/kratos/v2/config"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 )
 
@@ -17,7 +18,8 @@ type options struct {
 	dataID string
 }
 
-// WithGroup With nacos config group.
+// WithGroup With nacos config group, default is DEFAULT_GROUP as for the
+// nacos registry. The namespace is the one of the client.
 func WithGroup(group string) Option {
 	return func(o *options) {
 		o.group = group
@@ -37,7 +39,7 @@ type Config struct {
 }
 
 func NewConfigSource(client config_client.IConfigClient, opts ...Option) config.Source {
-	_options := options{}
+	_options := options{group: constant.DEFAULT_GROUP}
 	for _, o := range opts {
 		o(&_options)
 	}
@@ -205,3 +205,54 @@ func TestConfig_Watch(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_LoadFake(t *testing.T) {
+	client := newFakeConfigClient()
+	_, _ = client.PublishConfig(vo.ConfigParam{DataId: "app.yaml", Group: "test", Content: "name: v1"})
+	kvs, err := NewConfigSource(client, WithGroup("test"), WithDataID("app.yaml")).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*config.KeyValue{{Key: "app.yaml", Value: []byte("name: v1"), Format: "yaml"}}
+	if !reflect.DeepEqual(kvs, want) {
+		t.Errorf("expected %+v, got %+v", want[0], kvs[0])
+	}
+}
+
+func TestConfig_WatchFake(t *testing.T) {
+	client := newFakeConfigClient()
+	_, _ = client.PublishConfig(vo.ConfigParam{DataId: "app.json", Group: constant.DEFAULT_GROUP, Content: `{"name":"v1"}`})
+	// the group defaults to the one of the registry
+	c := config.New(config.WithSource(NewConfigSource(client, WithDataID("app.json"))))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if v, err := c.Value("name").String(); err != nil || v != "v1" {
+		t.Fatalf("expected v1, got %q %v", v, err)
+	}
+	changed := make(chan string, 1)
+	if err := c.Watch("name", func(_ string, v config.Value) {
+		s, _ := v.String()
+		changed <- s
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = client.PublishConfig(vo.ConfigParam{DataId: "app.json", Group: constant.DEFAULT_GROUP, Content: `{"name":"v2"}`})
+	select {
+	case v := <-changed:
+		if v != "v2" {
+			t.Errorf("expected v2, got %q", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the change")
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if client.listening(constant.DEFAULT_GROUP, "app.json") {
+		t.Error("expected the listener canceled on close")
+	}
+}
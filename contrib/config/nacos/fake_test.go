@@ -0,0 +1,68 @@
+package config
+
+import (
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// fakeConfigClient is an in-memory config_client.IConfigClient, only the
+// methods used by the source are implemented.
+type fakeConfigClient struct {
+	config_client.IConfigClient
+
+	mu        sync.Mutex
+	configs   map[string]string
+	listeners map[string]func(namespace, group, dataId, data string)
+}
+
+func newFakeConfigClient() *fakeConfigClient {
+	return &fakeConfigClient{
+		configs:   make(map[string]string),
+		listeners: make(map[string]func(namespace, group, dataId, data string)),
+	}
+}
+
+func configKey(group, dataID string) string {
+	return group + "@@" + dataID
+}
+
+func (c *fakeConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.configs[configKey(param.Group, param.DataId)], nil
+}
+
+func (c *fakeConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	key := configKey(param.Group, param.DataId)
+	c.mu.Lock()
+	c.configs[key] = param.Content
+	onChange := c.listeners[key]
+	c.mu.Unlock()
+	if onChange != nil {
+		onChange("", param.Group, param.DataId, param.Content)
+	}
+	return true, nil
+}
+
+func (c *fakeConfigClient) ListenConfig(param vo.ConfigParam) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners[configKey(param.Group, param.DataId)] = param.OnChange
+	return nil
+}
+
+func (c *fakeConfigClient) CancelListenConfig(param vo.ConfigParam) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.listeners, configKey(param.Group, param.DataId))
+	return nil
+}
+
+func (c *fakeConfigClient) listening(group, dataID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.listeners[configKey(group, dataID)]
+	return ok
+}
@@ -0,0 +1,137 @@
+package nacos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+)
+
+// ErrorClass is the class of an error returned by the nacos sdk.
+type ErrorClass int
+
+const (
+	// ClassOther is an error neither of auth nor transient, e.g. an invalid
+	// request rejected by the server.
+	ClassOther ErrorClass = iota
+	// ClassAuth is an authentication or authorization failure, e.g. an
+	// expired access token. Retrying does not help until the credentials are
+	// refreshed.
+	ClassAuth
+	// ClassTransient is a network failure or timeout worth retrying.
+	ClassTransient
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ClassAuth:
+		return "auth"
+	case ClassTransient:
+		return "transient"
+	default:
+		return "other"
+	}
+}
+
+// authMessages are the messages of the 403 responses of the nacos server
+// to a failed authentication or authorization, lowercased and without
+// their trailing "!". The sdk returns them as plain errors over gRPC, so
+// they are matched as the whole message of an error.
+var authMessages = []string{
+	"token expired",
+	"token invalid",
+	"user not found",
+	"unknown user",
+	"authorization failed",
+}
+
+// transientMessages are the messages of the sdk for the server unreachable.
+var transientMessages = []string{
+	"client not connected",
+	"connection refused",
+	"connection reset",
+	"timeout",
+	"not ready to work",
+}
+
+// Classify returns the class of err returned by the nacos sdk or by
+// Register, Deregister, DeregisterAll, GetService and GetOne.
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ClassOther
+	}
+	if errors.Is(err, ErrAuth) {
+		return ClassAuth
+	}
+	// the errors of the registry itself, e.g. "heartbeat timeout" is no timeout
+	for _, e := range []error{ErrServiceInstanceNameEmpty, ErrInvalidHeartbeat, ErrNoInstances, ErrInvalidWeight} {
+		if errors.Is(err, e) {
+			return ClassOther
+		}
+	}
+	var ne *nacos_error.NacosError
+	if errors.As(err, &ne) {
+		switch ne.ErrorCode() {
+		case "401", "403":
+			return ClassAuth
+		}
+	}
+	if isAuthMessage(err) {
+		return ClassAuth
+	}
+	msg := strings.ToLower(err.Error())
+	var netErr net.Error
+	if errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) {
+		return ClassTransient
+	}
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return ClassTransient
+		}
+	}
+	return ClassOther
+}
+
+// isAuthMessage reports whether err, or an error it wraps, has one of the
+// authMessages as its whole message.
+func isAuthMessage(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(err.Error())), "!")
+	for _, m := range authMessages {
+		if msg == m {
+			return true
+		}
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return isAuthMessage(u.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if isAuthMessage(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wrapAuth wraps err with ErrAuth when it is an auth failure, the other
+// errors are returned as they are.
+func wrapAuth(err error) error {
+	if err == nil || errors.Is(err, ErrAuth) || Classify(err) != ClassAuth {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrAuth, err)
+}
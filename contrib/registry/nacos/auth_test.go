@@ -0,0 +1,68 @@
+package nacos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"nil", nil, ClassOther},
+		{"token expired", errors.New("token expired!"), ClassAuth},
+		{"user not found", errors.New("user not found!"), ClassAuth},
+		{"http forbidden", nacos_error.NewNacosError("403", "unknown user!", nil), ClassAuth},
+		{"wrapped", fmt.Errorf("%w: token invalid", ErrAuth), ClassAuth},
+		{"wrapped message", fmt.Errorf("register: %w", errors.New("Authorization failed!")), ClassAuth},
+		{"message mentioning forbidden", errors.New("forbidden characters in service name"), ClassOther},
+		{"message mentioning user not found", errors.New("metadata user not found in instance"), ClassOther},
+		{"dial", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, ClassTransient},
+		{"deadline", context.DeadlineExceeded, ClassTransient},
+		{"not connected", errors.New("client not connected, current status:UNHEALTHY"), ClassTransient},
+		{"http server error", nacos_error.NewNacosError("500", "internal error", nil), ClassOther},
+		{"invalid heartbeat", fmt.Errorf("%w: heartbeat timeout 1s", ErrInvalidHeartbeat), ClassOther},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestAuthError(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli)
+	si := &registry.ServiceInstance{ID: "1", Name: "auth", Endpoints: []string{"grpc://127.0.0.1:9000"}}
+
+	cli.setErr(errors.New("token expired!"))
+	if err := r.Register(context.Background(), si); !errors.Is(err, ErrAuth) {
+		t.Errorf("expected Register to fail with %v, got %v", ErrAuth, err)
+	}
+	if _, err := r.GetService(context.Background(), "auth"); !errors.Is(err, ErrAuth) {
+		t.Errorf("expected GetService to fail with %v, got %v", ErrAuth, err)
+	}
+	if err := r.Deregister(context.Background(), si); !errors.Is(err, ErrAuth) {
+		t.Errorf("expected Deregister to fail with %v, got %v", ErrAuth, err)
+	}
+
+	// a network error is not an auth failure
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	cli.setErr(netErr)
+	err := r.Register(context.Background(), si)
+	if errors.Is(err, ErrAuth) || !errors.Is(err, netErr) {
+		t.Errorf("expected Register to fail with the network error, got %v", err)
+	}
+	if c := Classify(err); c != ClassTransient {
+		t.Errorf("expected %s, got %s", ClassTransient, c)
+	}
+}
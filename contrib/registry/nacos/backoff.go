@@ -0,0 +1,51 @@
+package nacos
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Defaults of the backoff between re-subscriptions of a watcher.
+const (
+	defaultResubscribeBase = time.Second
+	defaultResubscribeMax  = 30 * time.Second
+)
+
+// backoff is a capped exponential backoff with jitter. The n-th interval is
+// drawn from [d/2, d] where d is base doubled n times up to max, so the
+// intervals grow until the cap while a fleet of clients spreads its retries.
+type backoff struct {
+	base    time.Duration
+	max     time.Duration
+	attempt int
+	rand    func(n int64) int64
+}
+
+func newBackoff(base, max time.Duration) *backoff {
+	if base <= 0 {
+		base = defaultResubscribeBase
+	}
+	if max < base {
+		max = base
+	}
+	return &backoff{base: base, max: max, rand: rand.Int63n}
+}
+
+// next returns the interval to wait before the next attempt.
+func (b *backoff) next() time.Duration {
+	d := b.base
+	for i := 0; i < b.attempt && d < b.max; i++ {
+		d *= 2
+	}
+	if d > b.max {
+		d = b.max
+	}
+	b.attempt++
+	half := d / 2
+	return half + time.Duration(b.rand(int64(d-half)+1))
+}
+
+// reset restarts the intervals from base after a successful attempt.
+func (b *backoff) reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,44 @@
+package nacos
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	b := newBackoff(100*time.Millisecond, time.Second)
+	// the upper bound of every interval
+	b.rand = func(n int64) int64 { return n - 1 }
+	want := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, w := range want {
+		if got := b.next(); got != w {
+			t.Errorf("interval %d: expected %v, got %v", i, w, got)
+		}
+	}
+	b.reset()
+	if got := b.next(); got != 100*time.Millisecond {
+		t.Errorf("expected %v after reset, got %v", 100*time.Millisecond, got)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	b := newBackoff(100*time.Millisecond, time.Second)
+	var prev time.Duration
+	for i := 0; i < 10; i++ {
+		d := b.next()
+		if d < prev && d < 500*time.Millisecond {
+			t.Errorf("interval %d: expected intervals to grow, got %v after %v", i, d, prev)
+		}
+		if d > time.Second {
+			t.Errorf("interval %d: expected at most the cap, got %v", i, d)
+		}
+		prev = d
+	}
+}
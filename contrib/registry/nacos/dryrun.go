@@ -0,0 +1,34 @@
+package nacos
+
+import (
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// WithDryRun validates the registrations and logs the nacos calls that
+// Register, Deregister and DeregisterAll would make instead of making them,
+// e.g. to exercise the registration wiring in CI without a nacos server.
+// The register jitter is skipped, the registrations are still tracked.
+func WithDryRun(dryRun bool) Option {
+	return func(o *options) { o.dryRun = dryRun }
+}
+
+func (r *Registry) registerInstance(param vo.RegisterInstanceParam) error {
+	if r.opts.dryRun {
+		log.Infof("[nacos] dry run: register instance %s:%d of service %s in group %s cluster %s with weight %g and metadata %v",
+			param.Ip, param.Port, param.ServiceName, param.GroupName, param.ClusterName, param.Weight, param.Metadata)
+		return nil
+	}
+	_, err := r.cli.RegisterInstance(param)
+	return err
+}
+
+func (r *Registry) deregisterInstance(param vo.DeregisterInstanceParam) error {
+	if r.opts.dryRun {
+		log.Infof("[nacos] dry run: deregister instance %s:%d of service %s in group %s cluster %s",
+			param.Ip, param.Port, param.ServiceName, param.GroupName, param.Cluster)
+		return nil
+	}
+	_, err := r.cli.DeregisterInstance(param)
+	return err
+}
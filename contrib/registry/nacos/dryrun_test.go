@@ -0,0 +1,71 @@
+package nacos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestRegistry_DryRun(t *testing.T) {
+	cli := newFakeNamingClient()
+	// any call of the sdk would fail
+	cli.setErr(errFakeClient)
+	r := New(cli, WithDryRun(true), WithRegisterJitter(time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	si := &registry.ServiceInstance{ID: "1", Name: "helloworld", Endpoints: []string{"grpc://127.0.0.1:9000", "http://127.0.0.1:8000"}}
+	if err := r.Register(ctx, si); err != nil {
+		t.Fatalf("expected the dry run to succeed, got %v", err)
+	}
+	if len(cli.instances) != 0 {
+		t.Errorf("expected no instance registered, got %v", cli.instances)
+	}
+	if got := r.RegisteredInstances(); len(got) != 1 || got[0].ID != "1" {
+		t.Errorf("expected the registration tracked, got %v", got)
+	}
+	if err := r.Deregister(ctx, si); err != nil {
+		t.Fatalf("expected the dry run to succeed, got %v", err)
+	}
+	if got := r.RegisteredInstances(); len(got) != 0 {
+		t.Errorf("expected the registration removed, got %v", got)
+	}
+	if err := r.Register(ctx, si); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DeregisterAll(ctx); err != nil {
+		t.Fatalf("expected the dry run to succeed, got %v", err)
+	}
+}
+
+func TestRegistry_DryRunValidation(t *testing.T) {
+	cli := newFakeNamingClient()
+	tests := []struct {
+		name string
+		opts []Option
+		si   *registry.ServiceInstance
+		err  error
+	}{
+		{"empty name", nil, &registry.ServiceInstance{ID: "1", Endpoints: []string{"grpc://127.0.0.1:9000"}}, ErrServiceInstanceNameEmpty},
+		{"invalid weight", []Option{WithWeight(-1)}, &registry.ServiceInstance{ID: "1", Name: "helloworld", Endpoints: []string{"grpc://127.0.0.1:9000"}}, ErrInvalidWeight},
+		{"invalid heartbeat", []Option{WithHeartbeatInterval(time.Minute)}, &registry.ServiceInstance{ID: "1", Name: "helloworld", Endpoints: []string{"grpc://127.0.0.1:9000"}}, ErrInvalidHeartbeat},
+		{"invalid endpoint", nil, &registry.ServiceInstance{ID: "1", Name: "helloworld", Endpoints: []string{"grpc://127.0.0.1"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(cli, append([]Option{WithDryRun(true)}, tt.opts...)...)
+			err := r.Register(context.Background(), tt.si)
+			if err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+			if got := r.RegisteredInstances(); len(got) != 0 {
+				t.Errorf("expected nothing tracked, got %v", got)
+			}
+		})
+	}
+	if len(cli.instances) != 0 {
+		t.Errorf("expected no instance registered, got %v", cli.instances)
+	}
+}
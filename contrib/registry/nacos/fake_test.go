@@ -0,0 +1,178 @@
+package nacos
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+var errFakeClient = errors.New("fake naming client error")
+
+// fakeNamingClient is an in-memory naming_client.INamingClient used by tests
+// that must not depend on a running nacos server.
+type fakeNamingClient struct {
+	naming_client.INamingClient
+
+	mu        sync.Mutex
+	err       error
+	instances map[string][]model.Instance
+	callbacks map[string]func([]model.Instance, error)
+}
+
+func newFakeNamingClient() *fakeNamingClient {
+	return &fakeNamingClient{
+		instances: make(map[string][]model.Instance),
+		callbacks: make(map[string]func([]model.Instance, error)),
+	}
+}
+
+func (c *fakeNamingClient) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *fakeNamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return false, c.err
+	}
+	c.instances[param.ServiceName] = append(c.instances[param.ServiceName], model.Instance{
+		Ip:          param.Ip,
+		Port:        param.Port,
+		Weight:      param.Weight,
+		Enable:      param.Enable,
+		Healthy:     param.Healthy,
+		Ephemeral:   param.Ephemeral,
+		Metadata:    param.Metadata,
+		ClusterName: param.ClusterName,
+		ServiceName: param.ServiceName,
+	})
+	return true, nil
+}
+
+func (c *fakeNamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return false, c.err
+	}
+	ins := c.instances[param.ServiceName][:0]
+	for _, in := range c.instances[param.ServiceName] {
+		if in.Ip != param.Ip || in.Port != param.Port {
+			ins = append(ins, in)
+		}
+	}
+	c.instances[param.ServiceName] = ins
+	return true, nil
+}
+
+func (c *fakeNamingClient) GetService(param vo.GetServiceParam) (model.Service, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return model.Service{}, c.err
+	}
+	var hosts []model.Instance
+	for _, in := range c.instances[param.ServiceName] {
+		if len(param.Clusters) == 0 || in.ClusterName == param.Clusters[0] {
+			hosts = append(hosts, in)
+		}
+	}
+	return model.Service{Name: param.ServiceName, Hosts: hosts}, nil
+}
+
+// GetAllServicesInfo pages through the names of the services with instances.
+func (c *fakeNamingClient) GetAllServicesInfo(param vo.GetAllServiceInfoParam) (model.ServiceList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return model.ServiceList{}, c.err
+	}
+	var names []string
+	for name, ins := range c.instances {
+		if len(ins) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	start := min(int(param.PageNo-1)*int(param.PageSize), len(names))
+	end := min(start+int(param.PageSize), len(names))
+	return model.ServiceList{Count: int64(len(names)), Doms: names[start:end]}, nil
+}
+
+func (c *fakeNamingClient) SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	var res []model.Instance
+	for _, in := range c.instances[param.ServiceName] {
+		if !param.HealthyOnly || in.Healthy {
+			res = append(res, in)
+		}
+	}
+	return res, nil
+}
+
+func (c *fakeNamingClient) Subscribe(param *vo.SubscribeParam) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.callbacks[param.ServiceName] = param.SubscribeCallback
+	return nil
+}
+
+// fail invokes the subscribe callback of serviceName with err.
+func (c *fakeNamingClient) fail(serviceName string, err error) {
+	c.mu.Lock()
+	cb := c.callbacks[serviceName]
+	c.mu.Unlock()
+	if cb != nil {
+		cb(nil, err)
+	}
+}
+
+// push invokes the subscribe callback of serviceName with its instances.
+func (c *fakeNamingClient) push(serviceName string) {
+	c.mu.Lock()
+	cb := c.callbacks[serviceName]
+	ins := c.instances[serviceName]
+	c.mu.Unlock()
+	if cb != nil {
+		cb(ins, nil)
+	}
+}
+
+func (c *fakeNamingClient) Unsubscribe(param *vo.SubscribeParam) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.callbacks, param.ServiceName)
+	return nil
+}
+
+func (c *fakeNamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanceParam) (*model.Instance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	for _, in := range c.instances[param.ServiceName] {
+		if len(param.Clusters) > 0 && in.ClusterName != param.Clusters[0] {
+			continue
+		}
+		if in.Healthy && in.Enable && in.Weight > 0 {
+			return &in, nil
+		}
+	}
+	// the error of the sdk for an empty instance list
+	return nil, errors.New("healthy instance list is empty!")
+}
@@ -5,6 +5,8 @@ go 1.21
 require (
 	github.com/go-kratos/kratos/v2 v2.8.4
 	github.com/nacos-group/nacos-sdk-go/v2 v2.3.5
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/metric v1.28.0
 )
 
 require (
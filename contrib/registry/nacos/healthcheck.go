@@ -0,0 +1,140 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// Defaults of the active health check.
+const (
+	defaultHealthCheckTimeout     = 500 * time.Millisecond
+	defaultHealthCheckConcurrency = 8
+)
+
+// Prober checks that an instance serves requests, e.g. by connecting to
+// it, it returns an error when the instance must not be used.
+type Prober func(ctx context.Context, si *registry.ServiceInstance) error
+
+// WithActiveHealthCheck probes the instances of GetService with prober,
+// the instances failing the probe are excluded. Nacos only tracks the
+// heartbeats of the instances, which an instance may send while failing
+// the connections. Default is disabled.
+func WithActiveHealthCheck(prober Prober) Option {
+	return func(o *options) { o.prober = prober }
+}
+
+// WithHealthCheckTimeout sets the timeout of a probe, default is 500ms.
+func WithHealthCheckTimeout(d time.Duration) Option {
+	return func(o *options) { o.probeTimeout = d }
+}
+
+// WithHealthCheckConcurrency bounds the probes running at once, default
+// is 8.
+func WithHealthCheckConcurrency(n int) Option {
+	return func(o *options) { o.probeConcurrency = n }
+}
+
+// TCPProber is a Prober connecting to the first endpoint of the instance.
+func TCPProber(ctx context.Context, si *registry.ServiceInstance) error {
+	u, err := firstEndpoint(si)
+	if err != nil {
+		return err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// HTTPProber returns a Prober requesting path, e.g. "/healthz", from the
+// first HTTP endpoint of the instance with client, http.DefaultClient when
+// nil. The probe fails unless the response status is 2xx.
+func HTTPProber(path string, client *http.Client) Prober {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context, si *registry.ServiceInstance) error {
+		var target *url.URL
+		for _, e := range si.Endpoints {
+			if u, err := url.Parse(e); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+				target = u
+				break
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("no http endpoint in %v", si.Endpoints)
+		}
+		target = target.JoinPath(path)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+		if err != nil {
+			return err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return fmt.Errorf("health check %s: status %d", target, res.StatusCode)
+		}
+		return nil
+	}
+}
+
+func firstEndpoint(si *registry.ServiceInstance) (*url.URL, error) {
+	if len(si.Endpoints) == 0 {
+		return nil, fmt.Errorf("instance %s has no endpoint", si.ID)
+	}
+	return url.Parse(si.Endpoints[0])
+}
+
+// probe returns the items passing the active health check, in order.
+func (r *Registry) probe(ctx context.Context, items []*registry.ServiceInstance) []*registry.ServiceInstance {
+	timeout := r.opts.probeTimeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	concurrency := r.opts.probeConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultHealthCheckConcurrency
+	}
+	var (
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, concurrency)
+		failed = make([]bool, len(items))
+	)
+	for i, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, item *registry.ServiceInstance) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			if err := r.opts.prober(pctx, item); err != nil {
+				log.Warnf("[nacos] instance %s of service %s failed the health check: %v", item.ID, item.Name, err)
+				failed[i] = true
+			}
+		}(i, item)
+	}
+	wg.Wait()
+	healthy := items[:0]
+	for i, item := range items {
+		if !failed[i] {
+			healthy = append(healthy, item)
+		}
+	}
+	return healthy
+}
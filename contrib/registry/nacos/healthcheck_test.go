@@ -0,0 +1,112 @@
+package nacos
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func registerInstances(t *testing.T, r *Registry, endpoints ...string) {
+	t.Helper()
+	for _, e := range endpoints {
+		if err := r.Register(context.Background(), &registry.ServiceInstance{ID: e, Name: "probed", Endpoints: []string{e}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRegistry_ActiveHealthCheck(t *testing.T) {
+	var inflight, peak atomic.Int64
+	prober := func(ctx context.Context, si *registry.ServiceInstance) error {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(10 * time.Millisecond)
+		if si.Endpoints[0] == "grpc://127.0.0.1:9002" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	r := New(newFakeNamingClient(), WithActiveHealthCheck(prober), WithHealthCheckConcurrency(2))
+	registerInstances(t, r, "grpc://127.0.0.1:9001", "grpc://127.0.0.1:9002", "grpc://127.0.0.1:9003", "grpc://127.0.0.1:9004")
+	items, err := r.GetService(context.Background(), "probed.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Endpoints[0])
+	}
+	if len(got) != 3 || got[0] != "grpc://127.0.0.1:9001" || got[1] != "grpc://127.0.0.1:9003" {
+		t.Errorf("expected the failing instance excluded, got %v", got)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("expected at most 2 probes at once, got %d", p)
+	}
+}
+
+func TestRegistry_ActiveHealthCheckTimeout(t *testing.T) {
+	prober := func(ctx context.Context, _ *registry.ServiceInstance) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	r := New(newFakeNamingClient(), WithActiveHealthCheck(prober), WithHealthCheckTimeout(10*time.Millisecond))
+	registerInstances(t, r, "grpc://127.0.0.1:9001")
+	items, err := r.GetService(context.Background(), "probed.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Errorf("expected the slow instance excluded, got %v", items)
+	}
+}
+
+func TestTCPProber(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+	ctx := context.Background()
+	if err = TCPProber(ctx, &registry.ServiceInstance{Endpoints: []string{"grpc://" + lis.Addr().String()}}); err != nil {
+		t.Errorf("expected the listening instance healthy, got %v", err)
+	}
+	if err = TCPProber(ctx, &registry.ServiceInstance{Endpoints: []string{"grpc://" + closed.Addr().String()}}); err == nil {
+		t.Error("expected the closed instance unhealthy")
+	}
+}
+
+func TestHTTPProber(t *testing.T) {
+	healthy := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/healthz" || !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+	probe := HTTPProber("/healthz", nil)
+	si := &registry.ServiceInstance{Endpoints: []string{"grpc://127.0.0.1:9000", ts.URL}}
+	if err := probe(context.Background(), si); err != nil {
+		t.Errorf("expected the instance healthy, got %v", err)
+	}
+	healthy = false
+	if err := probe(context.Background(), si); err == nil {
+		t.Error("expected the instance unhealthy")
+	}
+	if err := probe(context.Background(), &registry.ServiceInstance{Endpoints: []string{"grpc://127.0.0.1:9000"}}); err == nil {
+		t.Error("expected an instance without http endpoint unhealthy")
+	}
+}
@@ -0,0 +1,30 @@
+package nacos
+
+import (
+	"context"
+	"time"
+)
+
+// WithRegisterJitter delays every registration by a random duration up to
+// max, so a fleet deployed at once spreads its registrations instead of
+// spiking nacos. The delay ends early when the context of Register is done,
+// the instance is then not registered. Deregistration is never delayed.
+func WithRegisterJitter(max time.Duration) Option {
+	return func(o *options) { o.registerJitter = max }
+}
+
+// jitter waits the registration delay, it returns the error of ctx when
+// ctx is done first.
+func (r *Registry) jitter(ctx context.Context) error {
+	if r.opts.registerJitter <= 0 {
+		return nil
+	}
+	t := time.NewTimer(time.Duration(r.rand(int64(r.opts.registerJitter))))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
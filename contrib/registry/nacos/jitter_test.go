@@ -0,0 +1,59 @@
+package nacos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestRegistry_RegisterJitter(t *testing.T) {
+	const max = 50 * time.Millisecond
+	cli := newFakeNamingClient()
+	r := New(cli, WithRegisterJitter(max))
+	var bound int64
+	r.rand = func(n int64) int64 {
+		bound = n
+		return n - 1
+	}
+	si := &registry.ServiceInstance{ID: "1", Name: "jitter", Endpoints: []string{"grpc://127.0.0.1:9000"}}
+
+	start := time.Now()
+	if err := r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < max-time.Millisecond || elapsed > max+time.Second {
+		t.Errorf("expected a delay of about %s, got %s", max, elapsed)
+	}
+	if bound != int64(max) {
+		t.Errorf("expected the jitter drawn up to %d, got %d", max, bound)
+	}
+	if len(cli.instances["jitter.grpc"]) != 1 {
+		t.Errorf("expected the instance registered, got %v", cli.instances)
+	}
+
+	// deregistration is not delayed
+	start = time.Now()
+	if err := r.Deregister(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= max {
+		t.Errorf("expected no delay of deregistration, got %s", elapsed)
+	}
+}
+
+func TestRegistry_RegisterJitterCanceled(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli, WithRegisterJitter(time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	si := &registry.ServiceInstance{ID: "1", Name: "jitter", Endpoints: []string{"grpc://127.0.0.1:9000"}}
+	if err := r.Register(ctx, si); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(cli.instances["jitter.grpc"]) != 0 {
+		t.Errorf("expected the instance not registered, got %v", cli.instances)
+	}
+}
@@ -0,0 +1,100 @@
+package nacos
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxMetadataSize is the default limit of the JSON encoded metadata
+// of a registered instance.
+const DefaultMaxMetadataSize = 8 * 1024
+
+// MetadataTransformer rewrites the metadata of a registered instance, e.g.
+// to inject the region or strip the internal keys.
+type MetadataTransformer func(map[string]string) map[string]string
+
+// WithMetadataTransformer applies t by Register to the metadata of every
+// instance, after the metadata of the service instance is merged with the
+// keys of the framework; its output is the metadata sent to nacos. The keys
+// of the framework, the kind, the version, the heartbeat, TLS and multi-port
+// keys, are protected: t can read them but their values are restored.
+func WithMetadataTransformer(t MetadataTransformer) Option {
+	return func(o *options) { o.metadataTransformer = t }
+}
+
+// transformMetadata returns meta transformed WithMetadataTransformer, with
+// the protected keys of the framework restored.
+func (o *options) transformMetadata(meta map[string]string, framework ...map[string]string) map[string]string {
+	if o.metadataTransformer == nil {
+		return meta
+	}
+	in := make(map[string]string, len(meta))
+	for k, v := range meta {
+		in[k] = v
+	}
+	out := o.metadataTransformer(in)
+	if out == nil {
+		out = make(map[string]string)
+	}
+	for _, m := range framework {
+		for k := range m {
+			if v, ok := meta[k]; ok {
+				out[k] = v
+			}
+		}
+	}
+	return out
+}
+
+// WithMaxMetadataSize sets the limit in bytes of the JSON encoded metadata
+// of a registered instance. Register fails fast with ErrMetadataTooLarge
+// over it, instead of a late and unclear failure of nacos. Default is
+// DefaultMaxMetadataSize, a negative size disables the check.
+func WithMaxMetadataSize(size int) Option {
+	return func(o *options) { o.maxMetadataSize = size }
+}
+
+// checkMetadataSize returns an error wrapping ErrMetadataTooLarge when the
+// metadata of serviceName exceeds the limit, naming the largest keys to
+// remove to fit.
+func (o *options) checkMetadataSize(serviceName string, meta map[string]string) error {
+	if o.maxMetadataSize < 0 {
+		return nil
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	size := len(data)
+	if size <= o.maxMetadataSize {
+		return nil
+	}
+	type entry struct {
+		key  string
+		size int
+	}
+	entries := make([]entry, 0, len(meta))
+	for k, v := range meta {
+		kv, _ := json.Marshal(map[string]string{k: v})
+		// without the braces, with the separating comma
+		entries = append(entries, entry{key: k, size: len(kv) - 1})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].size != entries[j].size {
+			return entries[i].size > entries[j].size
+		}
+		return entries[i].key < entries[j].key
+	})
+	var keys []string
+	for _, e := range entries {
+		if size <= o.maxMetadataSize {
+			break
+		}
+		keys = append(keys, fmt.Sprintf("%s (%d bytes)", e.key, e.size))
+		size -= e.size
+	}
+	return fmt.Errorf("%w: metadata of %s is %d bytes, over the limit of %d, the largest keys are %s",
+		ErrMetadataTooLarge, serviceName, len(data), o.maxMetadataSize, strings.Join(keys, ", "))
+}
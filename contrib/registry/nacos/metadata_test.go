@@ -0,0 +1,63 @@
+package nacos
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+)
+
+func TestRegistry_MetadataTransformer(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli,
+		WithHeartbeatInterval(2*time.Second),
+		WithTLS("api.example.com"),
+		WithMetadataTransformer(func(md map[string]string) map[string]string {
+			for k := range md {
+				if strings.HasPrefix(k, "internal.") {
+					delete(md, k)
+				}
+			}
+			md["region"] = "eu-west-1"
+			// the keys of the framework are protected
+			md["kind"] = "http"
+			delete(md, "version")
+			delete(md, constant.HEART_BEAT_INTERVAL)
+			md[MetadataSNI] = "evil.example.com"
+			return md
+		}),
+	)
+	si := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "helloworld",
+		Version:   "v1.0.0",
+		Metadata:  map[string]string{"app": "helloworld", "internal.owner": "team-a"},
+		Endpoints: []string{"grpc://127.0.0.1:9000"},
+	}
+	if err := r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	ins := cli.instances["helloworld.grpc"]
+	if len(ins) != 1 {
+		t.Fatalf("expected one instance, got %v", cli.instances)
+	}
+	want := map[string]string{
+		"app":                        "helloworld",
+		"region":                     "eu-west-1",
+		"kind":                       "grpc",
+		"version":                    "v1.0.0",
+		constant.HEART_BEAT_INTERVAL: "2000",
+		MetadataTLS:                  "true",
+		MetadataSNI:                  "api.example.com",
+	}
+	if !reflect.DeepEqual(ins[0].Metadata, want) {
+		t.Errorf("expected metadata %v, got %v", want, ins[0].Metadata)
+	}
+	if si.Metadata["internal.owner"] != "team-a" {
+		t.Error("expected the metadata of the service instance untouched")
+	}
+}
@@ -0,0 +1,53 @@
+package nacos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestRegistry_MetadataSize(t *testing.T) {
+	si := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "helloworld",
+		Version:   "v1.0.0",
+		Metadata:  map[string]string{"app": "helloworld"},
+		Endpoints: []string{"grpc://127.0.0.1:9000"},
+	}
+
+	cli := newFakeNamingClient()
+	if err := New(cli).Register(context.Background(), si); err != nil {
+		t.Fatalf("expected the small metadata registered, got %v", err)
+	}
+	if len(cli.instances["helloworld.grpc"]) != 1 {
+		t.Fatalf("expected one instance, got %v", cli.instances)
+	}
+
+	si.Metadata = map[string]string{
+		"app":   "helloworld",
+		"cert":  strings.Repeat("x", 600),
+		"notes": strings.Repeat("y", 400),
+	}
+	cli = newFakeNamingClient()
+	err := New(cli, WithMaxMetadataSize(256)).Register(context.Background(), si)
+	if !errors.Is(err, ErrMetadataTooLarge) {
+		t.Fatalf("expected %v, got %v", ErrMetadataTooLarge, err)
+	}
+	// the keys to remove to fit are named, the largest first
+	if !strings.Contains(err.Error(), "cert (") || !strings.Contains(err.Error(), "notes (") ||
+		strings.Contains(err.Error(), "app (") || strings.Index(err.Error(), "cert") > strings.Index(err.Error(), "notes") {
+		t.Errorf("expected the oversized keys named, got %v", err)
+	}
+	if len(cli.instances) != 0 {
+		t.Errorf("expected no call to nacos, got %v", cli.instances)
+	}
+
+	// the check can be disabled
+	cli = newFakeNamingClient()
+	if err = New(cli, WithMaxMetadataSize(-1)).Register(context.Background(), si); err != nil {
+		t.Errorf("expected the check disabled, got %v", err)
+	}
+}
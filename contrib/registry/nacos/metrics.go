@@ -0,0 +1,88 @@
+package nacos
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	metricLabelOperation = "operation"
+	metricLabelService   = "service"
+	metricLabelResult    = "result"
+)
+
+const (
+	opRegister   = "register"
+	opDeregister = "deregister"
+	opGetService = "get_service"
+	opGetOne     = "get_one"
+	opWatch      = "watch"
+)
+
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+const (
+	DefaultRequestsCounterName  = "registry_nacos_requests_total"
+	DefaultSecondsHistogramName = "registry_nacos_requests_seconds_bucket"
+)
+
+// DefaultRequestsCounter return metric.Int64Counter for WithMetrics.
+func DefaultRequestsCounter(meter metric.Meter, name string) (metric.Int64Counter, error) {
+	return meter.Int64Counter(name, metric.WithUnit("{call}"))
+}
+
+// DefaultSecondsHistogram return metric.Float64Histogram for WithMetrics.
+func DefaultSecondsHistogram(meter metric.Meter, name string) (metric.Float64Histogram, error) {
+	return meter.Float64Histogram(
+		name,
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.250, 0.5, 1),
+	)
+}
+
+// WithMetrics with requests counter and seconds histogram.
+// counter: registry_nacos_requests_total{operation, service, result}
+// histogram: registry_nacos_requests_seconds_bucket{operation, service}
+// Either of them can be nil.
+func WithMetrics(requests metric.Int64Counter, seconds metric.Float64Histogram) Option {
+	return func(o *options) {
+		o.requests = requests
+		o.seconds = seconds
+	}
+}
+
+// observe records one operation against the nacos server.
+func (r *Registry) observe(ctx context.Context, operation, service string, start time.Time, err error) {
+	if r.opts.requests == nil && r.opts.seconds == nil {
+		return
+	}
+	if r.opts.requests != nil {
+		result := resultSuccess
+		if err != nil {
+			result = resultError
+		}
+		r.opts.requests.Add(
+			ctx, 1,
+			metric.WithAttributes(
+				attribute.String(metricLabelOperation, operation),
+				attribute.String(metricLabelService, service),
+				attribute.String(metricLabelResult, result),
+			),
+		)
+	}
+	if r.opts.seconds != nil {
+		r.opts.seconds.Record(
+			ctx, time.Since(start).Seconds(),
+			metric.WithAttributes(
+				attribute.String(metricLabelOperation, operation),
+				attribute.String(metricLabelService, service),
+			),
+		)
+	}
+}
@@ -0,0 +1,132 @@
+package nacos
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/embedded"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+type fakeCounter struct {
+	embedded.Int64Counter
+
+	mu     sync.Mutex
+	counts map[attribute.Distinct]int64
+}
+
+func (c *fakeCounter) Add(_ context.Context, incr int64, opts ...metric.AddOption) {
+	set := metric.NewAddConfig(opts).Attributes()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[attribute.Distinct]int64)
+	}
+	c.counts[set.Equivalent()] += incr
+}
+
+func (c *fakeCounter) count(operation, service, result string) int64 {
+	set := attribute.NewSet(
+		attribute.String(metricLabelOperation, operation),
+		attribute.String(metricLabelService, service),
+		attribute.String(metricLabelResult, result),
+	)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[set.Equivalent()]
+}
+
+type fakeHistogram struct {
+	embedded.Float64Histogram
+
+	mu      sync.Mutex
+	records int
+}
+
+func (h *fakeHistogram) Record(context.Context, float64, ...metric.RecordOption) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records++
+}
+
+func TestRegistry_Metrics(t *testing.T) {
+	cli := newFakeNamingClient()
+	counter := &fakeCounter{}
+	histogram := &fakeHistogram{}
+	r := New(cli, WithMetrics(counter, histogram))
+	ctx := context.Background()
+	ins := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "metrics",
+		Version:   "v1.0.0",
+		Endpoints: []string{"grpc://127.0.0.1:9000"},
+	}
+
+	if err := r.Register(ctx, ins); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetService(ctx, "metrics.grpc"); err != nil {
+		t.Fatal(err)
+	}
+	w, err := r.Watch(ctx, "metrics.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Stop()
+	if err = r.Deregister(ctx, ins); err != nil {
+		t.Fatal(err)
+	}
+
+	cli.setErr(errFakeClient)
+	if err = r.Register(ctx, ins); err == nil {
+		t.Fatal("expected register error")
+	}
+	if err = r.Deregister(ctx, ins); err == nil {
+		t.Fatal("expected deregister error")
+	}
+	if _, err = r.GetService(ctx, "metrics.grpc"); err == nil {
+		t.Fatal("expected get service error")
+	}
+	if _, err = r.Watch(ctx, "metrics.grpc"); err == nil {
+		t.Fatal("expected watch error")
+	}
+
+	tests := []struct {
+		operation string
+		service   string
+	}{
+		{opRegister, "metrics"},
+		{opDeregister, "metrics"},
+		{opGetService, "metrics.grpc"},
+		{opWatch, "metrics.grpc"},
+	}
+	for _, test := range tests {
+		if got := counter.count(test.operation, test.service, resultSuccess); got != 1 {
+			t.Errorf("%s success count = %d, want 1", test.operation, got)
+		}
+		if got := counter.count(test.operation, test.service, resultError); got != 1 {
+			t.Errorf("%s error count = %d, want 1", test.operation, got)
+		}
+	}
+	if histogram.records != 8 {
+		t.Errorf("histogram records = %d, want 8", histogram.records)
+	}
+}
+
+func TestRegistry_MetricsDisabled(t *testing.T) {
+	r := New(newFakeNamingClient())
+	if r.opts.requests != nil || r.opts.seconds != nil {
+		t.Fatal("metrics should be disabled by default")
+	}
+	err := r.Register(context.Background(), &registry.ServiceInstance{
+		Name:      "metrics",
+		Endpoints: []string{"grpc://127.0.0.1:9000"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
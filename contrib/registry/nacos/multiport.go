@@ -0,0 +1,70 @@
+package nacos
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+// metadataPortsPrefix prefixes the metadata keys recording the port of each
+// endpoint kind of a multi-port instance, e.g. ports.http and ports.grpc.
+const metadataPortsPrefix = "ports."
+
+// WithMultiPort registers all the endpoints of a service instance as a single
+// nacos instance named after the service, without the kind suffix. The first
+// endpoint is the address of the nacos instance, the port of every endpoint
+// is recorded in the metadata under ports.<kind>, from which the discovered
+// instances get all their endpoints back. The endpoints must share one host
+// and have distinct kinds.
+func WithMultiPort() Option {
+	return func(o *options) { o.multiPort = true }
+}
+
+// multiPortMetadata returns the ports metadata of addrs WithMultiPort, or nil.
+func (o *options) multiPortMetadata(addrs []endpointAddr) (map[string]string, error) {
+	if !o.multiPort || len(addrs) == 0 {
+		return nil, nil
+	}
+	ports := make(map[string]string, len(addrs))
+	for _, addr := range addrs {
+		if addr.host != addrs[0].host {
+			return nil, fmt.Errorf("kratos/nacos: multi-port endpoints must share one host, got %s and %s", addrs[0].host, addr.host)
+		}
+		key := metadataPortsPrefix + addr.scheme
+		if _, ok := ports[key]; ok {
+			return nil, fmt.Errorf("kratos/nacos: multi-port endpoints must have distinct kinds, got %s twice", addr.scheme)
+		}
+		ports[key] = strconv.FormatUint(addr.port, 10)
+	}
+	return ports, nil
+}
+
+// instanceEndpoints returns the endpoints of in, the one of its address
+// first, then those of the other ports of a multi-port instance by kind.
+func instanceEndpoints(kind string, in model.Instance) []string {
+	endpoints := []string{instanceEndpoint(kind, in)}
+	if k, ok := in.Metadata["kind"]; ok {
+		kind = k
+	}
+	var kinds []string
+	for key := range in.Metadata {
+		if k, ok := strings.CutPrefix(key, metadataPortsPrefix); ok && k != kind {
+			kinds = append(kinds, k)
+		}
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		port, err := strconv.ParseUint(in.Metadata[metadataPortsPrefix+k], 10, 64)
+		if err != nil {
+			continue
+		}
+		other := in
+		other.Port = port
+		other.Metadata = map[string]string{"kind": k, MetadataTLS: in.Metadata[MetadataTLS]}
+		endpoints = append(endpoints, instanceEndpoint(k, other))
+	}
+	return endpoints
+}
@@ -4,17 +4,46 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"go.opentelemetry.io/otel/metric"
 )
 
-var ErrServiceInstanceNameEmpty = errors.New("kratos/nacos: ServiceInstance.Name can not be empty")
+var (
+	ErrServiceInstanceNameEmpty = errors.New("kratos/nacos: ServiceInstance.Name can not be empty")
+	ErrInvalidHeartbeat         = errors.New("kratos/nacos: invalid heartbeat configuration")
+	ErrNoInstances              = errors.New("kratos/nacos: no healthy instance available")
+	ErrInvalidWeight            = errors.New("kratos/nacos: invalid weight")
+	ErrMetadataTooLarge         = errors.New("kratos/nacos: instance metadata too large")
+	// ErrAuth is wrapped around the auth failures of nacos, e.g. an expired
+	// access token, so that the callers can refresh the credentials instead
+	// of retrying. See Classify.
+	ErrAuth = errors.New("kratos/nacos: authentication failed")
+)
+
+// Defaults applied by nacos to ephemeral instances without preserved metadata.
+const (
+	defaultHeartbeatInterval = 5 * time.Second
+	defaultHeartbeatTimeout  = 15 * time.Second
+	defaultIPDeleteTimeout   = 30 * time.Second
+)
+
+// MetadataClusters is the metadata key listing the comma separated
+// clusters an instance is registered in. It is namespaced so as not to
+// replace a clusters key of the instance's own metadata.
+const MetadataClusters = "nacos.clusters"
 
 var (
 	_ registry.Registrar = (*Registry)(nil)
@@ -27,6 +56,38 @@ type options struct {
 	cluster string
 	group   string
 	kind    string
+
+	minWeight   float64
+	maxWeight   float64
+	weightScale float64
+
+	tls bool
+	sni string
+
+	heartbeatInterval time.Duration
+	heartbeatTimeout  time.Duration
+	ipDeleteTimeout   time.Duration
+
+	resubscribeBase time.Duration
+	resubscribeMax  time.Duration
+
+	multiPort bool
+
+	dryRun bool
+
+	metadataTransformer MetadataTransformer
+	maxMetadataSize     int
+
+	registerJitter time.Duration
+
+	servicesInterval time.Duration
+
+	prober           Prober
+	probeTimeout     time.Duration
+	probeConcurrency int
+
+	requests metric.Int64Counter
+	seconds  metric.Float64Histogram
 }
 
 type Option func(o *options)
@@ -51,9 +112,42 @@ func WithDefaultKind(kind string) Option {
 	return func(o *options) { o.kind = kind }
 }
 
+// WithHeartbeatInterval sets the interval at which the nacos client sends
+// heartbeats for the registered ephemeral instances.
+func WithHeartbeatInterval(d time.Duration) Option {
+	return func(o *options) { o.heartbeatInterval = d }
+}
+
+// WithHeartbeatTimeout sets how long nacos waits for a heartbeat before
+// marking an instance unhealthy.
+func WithHeartbeatTimeout(d time.Duration) Option {
+	return func(o *options) { o.heartbeatTimeout = d }
+}
+
+// WithIPDeleteTimeout sets how long nacos waits for a heartbeat before
+// removing an instance.
+func WithIPDeleteTimeout(d time.Duration) Option {
+	return func(o *options) { o.ipDeleteTimeout = d }
+}
+
+// WithResubscribeBackoff sets the backoff between the attempts of a watcher
+// to subscribe again after its subscription failed, starting at base and
+// doubling up to max with jitter. Default is 1s up to 30s.
+func WithResubscribeBackoff(base, max time.Duration) Option {
+	return func(o *options) {
+		o.resubscribeBase = base
+		o.resubscribeMax = max
+	}
+}
+
 type Registry struct {
 	opts options
 	cli  naming_client.INamingClient
+	rand func(n int64) int64
+
+	mu         sync.Mutex
+	registered map[string][]vo.DeregisterInstanceParam
+	instances  map[string]*registry.ServiceInstance
 }
 
 func New(cli naming_client.INamingClient, opts ...Option) *Registry {
@@ -63,42 +157,132 @@ func New(cli naming_client.INamingClient, opts ...Option) *Registry {
 		group:   constant.DEFAULT_GROUP,
 		weight:  100,
 		kind:    "grpc",
+
+		minWeight: defaultMinWeight,
+		maxWeight: defaultMaxWeight,
+
+		resubscribeBase: defaultResubscribeBase,
+		resubscribeMax:  defaultResubscribeMax,
+
+		maxMetadataSize: DefaultMaxMetadataSize,
 	}
 	for _, option := range opts {
 		option(&op)
 	}
 	return &Registry{
-		opts: op,
-		cli:  cli,
+		opts:       op,
+		cli:        cli,
+		rand:       rand.Int63n,
+		registered: make(map[string][]vo.DeregisterInstanceParam),
+		instances:  make(map[string]*registry.ServiceInstance),
 	}
 }
 
-func (r *Registry) Register(_ context.Context, si *registry.ServiceInstance) error {
+func (r *Registry) Register(ctx context.Context, si *registry.ServiceInstance) (err error) {
+	start := time.Now()
+	defer func() { r.observe(ctx, opRegister, si.Name, start, err) }()
 	if si.Name == "" {
 		return ErrServiceInstanceNameEmpty
 	}
-	for _, endpoint := range si.Endpoints {
-		u, err := url.Parse(endpoint)
-		if err != nil {
+	heartbeat, err := r.opts.heartbeatMetadata()
+	if err != nil {
+		return err
+	}
+	params, err := r.registerParams(si, heartbeat)
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		if err = r.opts.checkMetadataSize(param.ServiceName, param.Metadata); err != nil {
 			return err
 		}
-		host, port, err := net.SplitHostPort(u.Host)
-		if err != nil {
+	}
+	if !r.opts.dryRun {
+		if err = r.jitter(ctx); err != nil {
 			return err
 		}
-		p, err := strconv.Atoi(port)
+	}
+	// the latency excludes the jitter
+	start = time.Now()
+	for _, param := range params {
+		if err = r.registerInstance(param); err != nil {
+			return fmt.Errorf("RegisterInstance err: %w, %v", wrapAuth(err), net.JoinHostPort(param.Ip, strconv.FormatUint(param.Port, 10)))
+		}
+		r.track(instanceKey(si), deregisterParam(param))
+		r.remember(si)
+	}
+	return nil
+}
+
+// endpointAddr is a parsed endpoint of a service instance.
+type endpointAddr struct {
+	scheme string
+	host   string
+	port   uint64
+}
+
+func (o *options) parseEndpoint(endpoint string) (endpointAddr, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpointAddr{}, err
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return endpointAddr{}, err
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return endpointAddr{}, err
+	}
+	scheme := u.Scheme
+	if o.tls {
+		scheme = plainScheme(scheme)
+	}
+	return endpointAddr{scheme: scheme, host: host, port: uint64(p)}, nil
+}
+
+// registerParams returns the nacos instances registered for si, one per
+// endpoint, or a single one WithMultiPort.
+func (r *Registry) registerParams(si *registry.ServiceInstance, heartbeat map[string]string) ([]vo.RegisterInstanceParam, error) {
+	weight, err := r.opts.instanceWeight(si.Name, si.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]endpointAddr, 0, len(si.Endpoints))
+	for _, endpoint := range si.Endpoints {
+		addr, err := r.opts.parseEndpoint(endpoint)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		meta := map[string]string{"kind": u.Scheme, "version": si.Version}
-		for k, v := range si.Metadata {
-			meta[k] = v
+		addrs = append(addrs, addr)
+	}
+	ports, err := r.opts.multiPortMetadata(addrs)
+	if err != nil {
+		return nil, err
+	}
+	if ports != nil {
+		addrs = addrs[:1]
+	}
+	params := make([]vo.RegisterInstanceParam, 0, len(addrs))
+	for _, addr := range addrs {
+		serviceName := si.Name + "." + addr.scheme
+		if ports != nil {
+			serviceName = si.Name
 		}
-		_, err = r.cli.RegisterInstance(vo.RegisterInstanceParam{
-			Ip:          host,
-			Port:        uint64(p),
-			ServiceName: si.Name + "." + u.Scheme,
-			Weight:      r.opts.weight,
+		base := map[string]string{"kind": addr.scheme, "version": si.Version}
+		meta := make(map[string]string, len(base)+len(si.Metadata))
+		framework := []map[string]string{heartbeat, r.opts.tlsMetadata(), ports}
+		for _, m := range append([]map[string]string{base, si.Metadata}, framework...) {
+			for k, v := range m {
+				meta[k] = v
+			}
+		}
+		meta = r.opts.transformMetadata(meta, append(framework, base)...)
+		params = append(params, vo.RegisterInstanceParam{
+			Ip:          addr.host,
+			Port:        addr.port,
+			ServiceName: serviceName,
+			Weight:      weight,
 			Enable:      true,
 			Healthy:     true,
 			Ephemeral:   true,
@@ -106,68 +290,321 @@ func (r *Registry) Register(_ context.Context, si *registry.ServiceInstance) err
 			ClusterName: r.opts.cluster,
 			GroupName:   r.opts.group,
 		})
-		if err != nil {
-			return fmt.Errorf("RegisterInstance err: %v, %v", err, endpoint)
+	}
+	return params, nil
+}
+
+func deregisterParam(p vo.RegisterInstanceParam) vo.DeregisterInstanceParam {
+	return vo.DeregisterInstanceParam{
+		Ip:          p.Ip,
+		Port:        p.Port,
+		ServiceName: p.ServiceName,
+		GroupName:   p.GroupName,
+		Cluster:     p.ClusterName,
+		Ephemeral:   p.Ephemeral,
+	}
+}
+
+// heartbeatMetadata validates the heartbeat options and returns the nacos
+// preserved metadata for the ones that are set.
+func (o *options) heartbeatMetadata() (map[string]string, error) {
+	interval, timeout, deleteTimeout := defaultHeartbeatInterval, defaultHeartbeatTimeout, defaultIPDeleteTimeout
+	meta := make(map[string]string, 3)
+	for _, p := range []struct {
+		key   string
+		value time.Duration
+		dst   *time.Duration
+	}{
+		{constant.HEART_BEAT_INTERVAL, o.heartbeatInterval, &interval},
+		{constant.HEART_BEAT_TIMEOUT, o.heartbeatTimeout, &timeout},
+		{constant.IP_DELETE_TIMEOUT, o.ipDeleteTimeout, &deleteTimeout},
+	} {
+		if p.value == 0 {
+			continue
+		}
+		if p.value < time.Millisecond {
+			return nil, fmt.Errorf("%w: %s must be at least 1ms, got %s", ErrInvalidHeartbeat, p.key, p.value)
 		}
+		*p.dst = p.value
+		meta[p.key] = strconv.FormatInt(p.value.Milliseconds(), 10)
 	}
-	return nil
+	if interval >= timeout {
+		return nil, fmt.Errorf("%w: interval %s must be less than heartbeat timeout %s", ErrInvalidHeartbeat, interval, timeout)
+	}
+	if timeout > deleteTimeout {
+		return nil, fmt.Errorf("%w: heartbeat timeout %s must not exceed ip delete timeout %s", ErrInvalidHeartbeat, timeout, deleteTimeout)
+	}
+	return meta, nil
 }
 
-func (r *Registry) Deregister(_ context.Context, service *registry.ServiceInstance) error {
-	for _, endpoint := range service.Endpoints {
-		u, err := url.Parse(endpoint)
-		if err != nil {
+// Deregister removes the instances registered for service. The instances
+// actually registered under the same ID and name are removed, even if the
+// endpoints of service changed since Register.
+func (r *Registry) Deregister(ctx context.Context, service *registry.ServiceInstance) (err error) {
+	defer func(start time.Time) { r.observe(ctx, opDeregister, service.Name, start, err) }(time.Now())
+	key := instanceKey(service)
+	r.mu.Lock()
+	params, ok := r.registered[key]
+	delete(r.registered, key)
+	r.mu.Unlock()
+	if !ok {
+		if params, err = r.deregisterParams(service); err != nil {
 			return err
 		}
-		host, port, err := net.SplitHostPort(u.Host)
+	}
+	err = r.deregister(key, params)
+	r.forget(key)
+	return err
+}
+
+// DeregisterAll removes every instance registered through r, e.g. on
+// process exit.
+func (r *Registry) DeregisterAll(ctx context.Context) error {
+	r.mu.Lock()
+	registered := r.registered
+	r.registered = make(map[string][]vo.DeregisterInstanceParam)
+	r.mu.Unlock()
+	var errs []error
+	for key, params := range registered {
+		start := time.Now()
+		err := r.deregister(key, params)
+		r.forget(key)
+		r.observe(ctx, opDeregister, strings.SplitN(key, "/", 2)[0], start, err)
 		if err != nil {
-			return err
+			errs = append(errs, err)
 		}
-		p, err := strconv.Atoi(port)
+	}
+	return errors.Join(errs...)
+}
+
+// deregisterParams returns the params of the instances of service which
+// are not tracked, from its endpoints only: its weight and metadata need
+// not be valid to deregister it.
+func (r *Registry) deregisterParams(service *registry.ServiceInstance) ([]vo.DeregisterInstanceParam, error) {
+	params := make([]vo.DeregisterInstanceParam, 0, len(service.Endpoints))
+	for _, endpoint := range service.Endpoints {
+		addr, err := r.opts.parseEndpoint(endpoint)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		serviceName := service.Name + "." + addr.scheme
+		if r.opts.multiPort {
+			// the instance is registered at its first endpoint, see registerParams
+			serviceName = service.Name
 		}
-		_, err = r.cli.DeregisterInstance(vo.DeregisterInstanceParam{
-			Ip:          host,
-			Port:        uint64(p),
-			ServiceName: service.Name + "." + u.Scheme,
+		params = append(params, vo.DeregisterInstanceParam{
+			Ip:          addr.host,
+			Port:        addr.port,
+			ServiceName: serviceName,
 			GroupName:   r.opts.group,
 			Cluster:     r.opts.cluster,
 			Ephemeral:   true,
 		})
-		if err != nil {
-			return err
+		if r.opts.multiPort {
+			break
 		}
 	}
-	return nil
+	return params, nil
+}
+
+// deregister removes params from nacos, the ones failing are tracked again
+// under key so a later call can retry them.
+func (r *Registry) deregister(key string, params []vo.DeregisterInstanceParam) error {
+	var (
+		failed []vo.DeregisterInstanceParam
+		err    error
+	)
+	for _, param := range params {
+		if e := r.deregisterInstance(param); e != nil {
+			failed = append(failed, param)
+			if err == nil {
+				err = wrapAuth(e)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		r.track(key, failed...)
+	}
+	return err
+}
+
+func (r *Registry) track(key string, params ...vo.DeregisterInstanceParam) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, param := range params {
+		exists := false
+		for _, p := range r.registered[key] {
+			if p.ServiceName == param.ServiceName && p.Ip == param.Ip && p.Port == param.Port {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			r.registered[key] = append(r.registered[key], param)
+		}
+	}
+}
+
+// remember records si as registered, for RegisteredInstances.
+func (r *Registry) remember(si *registry.ServiceInstance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instances[instanceKey(si)] = cloneInstance(si)
+}
+
+// forget removes the instance of key unless some of its registrations
+// failed to be removed.
+func (r *Registry) forget(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.registered[key]; !ok {
+		delete(r.instances, key)
+	}
+}
+
+// RegisteredInstances returns a copy of the instances currently registered
+// through r, sorted by name and ID, e.g. for an admin endpoint. An instance
+// is listed until all of its registrations are removed.
+func (r *Registry) RegisteredInstances() []*registry.ServiceInstance {
+	r.mu.Lock()
+	items := make([]*registry.ServiceInstance, 0, len(r.instances))
+	for _, si := range r.instances {
+		items = append(items, cloneInstance(si))
+	}
+	r.mu.Unlock()
+	sort.Slice(items, func(i, j int) bool {
+		return instanceKey(items[i]) < instanceKey(items[j])
+	})
+	return items
+}
+
+func cloneInstance(si *registry.ServiceInstance) *registry.ServiceInstance {
+	c := *si
+	c.Endpoints = append([]string(nil), si.Endpoints...)
+	if si.Metadata != nil {
+		c.Metadata = make(map[string]string, len(si.Metadata))
+		for k, v := range si.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	return &c
 }
 
-func (r *Registry) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
-	return newWatcher(ctx, r.cli, serviceName, r.opts.group, r.opts.kind, []string{r.opts.cluster})
+// instanceKey identifies the registrations of an instance.
+func instanceKey(si *registry.ServiceInstance) string {
+	return si.Name + "/" + si.ID
 }
 
-func (r *Registry) GetService(_ context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+func (r *Registry) Watch(ctx context.Context, serviceName string) (w registry.Watcher, err error) {
+	defer func(start time.Time) { r.observe(ctx, opWatch, serviceName, start, err) }(time.Now())
+	return newWatcher(ctx, r.cli, serviceName, r.opts.group, r.opts.kind, []string{r.opts.cluster},
+		newBackoff(r.opts.resubscribeBase, r.opts.resubscribeMax))
+}
+
+func (r *Registry) GetService(ctx context.Context, serviceName string) (_ []*registry.ServiceInstance, err error) {
+	defer func(start time.Time) { r.observe(ctx, opGetService, serviceName, start, err) }(time.Now())
 	res, err := r.cli.SelectInstances(vo.SelectInstancesParam{
 		ServiceName: serviceName,
 		GroupName:   r.opts.group,
 		HealthyOnly: true,
 	})
 	if err != nil {
-		return nil, err
+		return nil, wrapAuth(err)
 	}
-	var items []*registry.ServiceInstance
-	for _, in := range res {
-		kind := r.opts.kind
-		if k, ok := in.Metadata["kind"]; ok {
-			kind = k
-		}
-		items = append(items, &registry.ServiceInstance{
-			ID:        in.InstanceId,
-			Name:      in.ServiceName,
-			Version:   in.Metadata["version"],
-			Metadata:  in.Metadata,
-			Endpoints: []string{fmt.Sprintf("%s://%s:%d", kind, in.Ip, in.Port)},
-		})
+	items := mergeInstances(r.opts.kind, res)
+	if r.opts.prober != nil {
+		items = r.probe(ctx, items)
 	}
 	return items, nil
 }
+
+// GetOne returns one healthy instance of the service picked by nacos with
+// its weighted round robin, in the configured group and cluster. It spares
+// fetching the full instance list when a single instance is needed.
+func (r *Registry) GetOne(ctx context.Context, serviceName string) (_ *registry.ServiceInstance, err error) {
+	defer func(start time.Time) { r.observe(ctx, opGetOne, serviceName, start, err) }(time.Now())
+	in, err := r.cli.SelectOneHealthyInstance(vo.SelectOneHealthInstanceParam{
+		ServiceName: serviceName,
+		GroupName:   r.opts.group,
+		Clusters:    []string{r.opts.cluster},
+	})
+	if err != nil {
+		// the sdk reports an empty instance list as a plain error, tell it
+		// from the others by the instances of the service
+		if r.noHealthyInstance(serviceName) {
+			return nil, ErrNoInstances
+		}
+		return nil, wrapAuth(err)
+	}
+	if in == nil {
+		return nil, ErrNoInstances
+	}
+	return newServiceInstance(r.opts.kind, *in), nil
+}
+
+// noHealthyInstance reports whether the service has no instance which
+// SelectOneHealthyInstance may pick, in the configured group and cluster.
+func (r *Registry) noHealthyInstance(serviceName string) bool {
+	service, err := r.cli.GetService(vo.GetServiceParam{
+		ServiceName: serviceName,
+		GroupName:   r.opts.group,
+		Clusters:    []string{r.opts.cluster},
+	})
+	if err != nil {
+		return false
+	}
+	for _, in := range service.Hosts {
+		if in.Healthy && in.Enable && in.Weight > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeInstances returns the service instances of res. The same instance
+// is returned by nacos once per cluster it registered in, with an instance
+// ID naming the cluster, so the entries of the same address are merged into
+// a single item recording all the clusters.
+func mergeInstances(kind string, res []model.Instance) []*registry.ServiceInstance {
+	var (
+		items    []*registry.ServiceInstance
+		clusters [][]string
+		seen     = make(map[string]int, len(res))
+	)
+	for _, in := range res {
+		key := net.JoinHostPort(in.Ip, strconv.FormatUint(in.Port, 10))
+		i, ok := seen[key]
+		if !ok {
+			i = len(items)
+			seen[key] = i
+			items = append(items, newServiceInstance(kind, in))
+			clusters = append(clusters, nil)
+		}
+		if in.ClusterName != "" {
+			clusters[i] = append(clusters[i], in.ClusterName)
+		}
+	}
+	for i, item := range items {
+		if len(clusters[i]) > 0 {
+			item.Metadata[MetadataClusters] = strings.Join(clusters[i], ",")
+		}
+	}
+	return items
+}
+
+func newServiceInstance(kind string, in model.Instance) *registry.ServiceInstance {
+	meta := make(map[string]string, len(in.Metadata)+1)
+	for k, v := range in.Metadata {
+		meta[k] = v
+	}
+	if in.ClusterName != "" {
+		meta[MetadataClusters] = in.ClusterName
+	}
+	return &registry.ServiceInstance{
+		ID:        in.InstanceId,
+		Name:      in.ServiceName,
+		Version:   in.Metadata["version"],
+		Metadata:  meta,
+		Endpoints: instanceEndpoints(kind, in),
+	}
+}
@@ -2,6 +2,7 @@ package nacos
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 )
 
@@ -562,3 +564,486 @@ func TestRegistry_Watch(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistry_GetServiceDedup(t *testing.T) {
+	cli := newFakeNamingClient()
+	cli.instances["dedup.grpc"] = []model.Instance{
+		{InstanceId: "127.0.0.1#9000#a#DEFAULT_GROUP@@dedup.grpc", Ip: "127.0.0.1", Port: 9000, Healthy: true, ClusterName: "a", ServiceName: "dedup.grpc", Metadata: map[string]string{"kind": "grpc"}},
+		{InstanceId: "127.0.0.1#9000#b#DEFAULT_GROUP@@dedup.grpc", Ip: "127.0.0.1", Port: 9000, Healthy: true, ClusterName: "b", ServiceName: "dedup.grpc", Metadata: map[string]string{"kind": "grpc"}},
+		{InstanceId: "127.0.0.2#9000#a#DEFAULT_GROUP@@dedup.grpc", Ip: "127.0.0.2", Port: 9000, Healthy: true, ClusterName: "a", ServiceName: "dedup.grpc"},
+		{InstanceId: "127.0.0.2#9000#c#DEFAULT_GROUP@@dedup.grpc", Ip: "127.0.0.2", Port: 9000, Healthy: true, ClusterName: "c", ServiceName: "dedup.grpc"},
+		{InstanceId: "127.0.0.3#9000##DEFAULT_GROUP@@dedup.grpc", Ip: "127.0.0.3", Port: 9000, Healthy: true, ServiceName: "dedup.grpc", Metadata: map[string]string{"clusters": "own"}},
+		{InstanceId: "127.0.0.3#9000#b#DEFAULT_GROUP@@dedup.grpc", Ip: "127.0.0.3", Port: 9000, Healthy: true, ClusterName: "b", ServiceName: "dedup.grpc", Metadata: map[string]string{"clusters": "own"}},
+	}
+	items, err := New(cli).GetService(context.Background(), "dedup.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 instances, got %d", len(items))
+	}
+	if got := items[0].Metadata[MetadataClusters]; got != "a,b" {
+		t.Errorf("expected clusters a,b, got %s", got)
+	}
+	if got := items[1].Metadata[MetadataClusters]; got != "a,c" {
+		t.Errorf("expected clusters a,c, got %s", got)
+	}
+	// an empty cluster is skipped, the instance's own clusters key is kept
+	if got := items[2].Metadata[MetadataClusters]; got != "b" {
+		t.Errorf("expected clusters b, got %s", got)
+	}
+	if got := items[2].Metadata["clusters"]; got != "own" {
+		t.Errorf("expected the metadata clusters kept, got %s", got)
+	}
+	if items[0].Endpoints[0] != "grpc://127.0.0.1:9000" || items[1].Endpoints[0] != "grpc://127.0.0.2:9000" {
+		t.Errorf("unexpected endpoints %v %v", items[0].Endpoints, items[1].Endpoints)
+	}
+	if _, ok := cli.instances["dedup.grpc"][0].Metadata[MetadataClusters]; ok {
+		t.Error("nacos instance metadata must not be modified")
+	}
+	// a watcher of every cluster returns the same merged instances
+	w, err := newWatcher(context.Background(), cli, "dedup.grpc", "", "grpc", nil, newBackoff(time.Second, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	watched, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(watched, items) {
+		t.Errorf("expected the watched instances %v, got %v", items, watched)
+	}
+}
+
+func TestRegistry_RegisterHeartbeat(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+		err  bool
+	}{
+		{
+			name: "unset",
+			want: map[string]string{},
+		},
+		{
+			name: "all",
+			opts: []Option{WithHeartbeatInterval(time.Second), WithHeartbeatTimeout(3 * time.Second), WithIPDeleteTimeout(6 * time.Second)},
+			want: map[string]string{
+				constant.HEART_BEAT_INTERVAL: "1000",
+				constant.HEART_BEAT_TIMEOUT:  "3000",
+				constant.IP_DELETE_TIMEOUT:   "6000",
+			},
+		},
+		{
+			name: "interval only",
+			opts: []Option{WithHeartbeatInterval(500 * time.Millisecond)},
+			want: map[string]string{constant.HEART_BEAT_INTERVAL: "500"},
+		},
+		{
+			name: "interval exceeds default timeout",
+			opts: []Option{WithHeartbeatInterval(20 * time.Second)},
+			err:  true,
+		},
+		{
+			name: "timeout exceeds delete timeout",
+			opts: []Option{WithHeartbeatTimeout(10 * time.Second), WithIPDeleteTimeout(5 * time.Second)},
+			err:  true,
+		},
+		{
+			name: "negative",
+			opts: []Option{WithHeartbeatInterval(-time.Second)},
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := newFakeNamingClient()
+			err := New(cli, tt.opts...).Register(context.Background(), &registry.ServiceInstance{
+				Name:      "heartbeat",
+				Endpoints: []string{"grpc://127.0.0.1:9000"},
+			})
+			if tt.err {
+				if !errors.Is(err, ErrInvalidHeartbeat) {
+					t.Fatalf("expected %v, got %v", ErrInvalidHeartbeat, err)
+				}
+				if len(cli.instances) != 0 {
+					t.Fatal("invalid options must not register the instance")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			meta := cli.instances["heartbeat.grpc"][0].Metadata
+			for _, key := range []string{constant.HEART_BEAT_INTERVAL, constant.HEART_BEAT_TIMEOUT, constant.IP_DELETE_TIMEOUT} {
+				if got, want := meta[key], tt.want[key]; got != want {
+					t.Errorf("metadata %s: expected %q, got %q", key, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry_DeregisterTracked(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli)
+	si := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "tracked",
+		Endpoints: []string{"grpc://127.0.0.1:9000", "http://127.0.0.1:8000"},
+	}
+	if err := r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	// the endpoints changed at runtime
+	changed := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "tracked",
+		Endpoints: []string{"grpc://127.0.0.1:9100"},
+	}
+	if err := r.Deregister(context.Background(), changed); err != nil {
+		t.Fatal(err)
+	}
+	for name, ins := range cli.instances {
+		if len(ins) != 0 {
+			t.Errorf("expected %s to be cleaned up, got %v", name, ins)
+		}
+	}
+}
+
+func TestRegistry_DeregisterUntracked(t *testing.T) {
+	cli := newFakeNamingClient()
+	si := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "untracked",
+		Endpoints: []string{"grpc://127.0.0.1:9000", "http://127.0.0.1:8000"},
+	}
+	if err := New(cli).Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	// another registry, e.g. after a restart, with a weight the instance fails
+	r := New(cli, WithWeightRange(1, 10))
+	untracked := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "untracked",
+		Metadata:  map[string]string{"weight": "invalid"},
+		Endpoints: si.Endpoints,
+	}
+	if err := r.Deregister(context.Background(), untracked); err != nil {
+		t.Fatal(err)
+	}
+	for name, ins := range cli.instances {
+		if len(ins) != 0 {
+			t.Errorf("expected %s to be cleaned up, got %v", name, ins)
+		}
+	}
+}
+
+func TestRegistry_DeregisterAll(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli)
+	for _, si := range []*registry.ServiceInstance{
+		{ID: "1", Name: "all", Endpoints: []string{"grpc://127.0.0.1:9000"}},
+		{ID: "2", Name: "all", Endpoints: []string{"grpc://127.0.0.2:9000"}},
+		{ID: "1", Name: "other", Endpoints: []string{"http://127.0.0.1:8000"}},
+	} {
+		if err := r.Register(context.Background(), si); err != nil {
+			t.Fatal(err)
+		}
+		// mutate the instance after registration
+		si.Endpoints = []string{"grpc://127.0.0.9:9999"}
+	}
+
+	cli.setErr(errFakeClient)
+	if err := r.DeregisterAll(context.Background()); !errors.Is(err, errFakeClient) {
+		t.Fatalf("expected %v, got %v", errFakeClient, err)
+	}
+	cli.setErr(nil)
+	// failed instances are retried by the next call
+	if err := r.DeregisterAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for name, ins := range cli.instances {
+		if len(ins) != 0 {
+			t.Errorf("expected %s to be cleaned up, got %v", name, ins)
+		}
+	}
+	if err := r.DeregisterAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistry_RegisteredInstances(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli)
+	ctx := context.Background()
+	ids := func() []string {
+		var ids []string
+		for _, si := range r.RegisteredInstances() {
+			ids = append(ids, si.Name+"/"+si.ID)
+		}
+		return ids
+	}
+	a := &registry.ServiceInstance{ID: "1", Name: "b", Metadata: map[string]string{"zone": "a"}, Endpoints: []string{"grpc://127.0.0.1:9000"}}
+	b := &registry.ServiceInstance{ID: "1", Name: "a", Endpoints: []string{"grpc://127.0.0.2:9000", "http://127.0.0.2:8000"}}
+	for _, si := range []*registry.ServiceInstance{a, b} {
+		if err := r.Register(ctx, si); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := ids(); !reflect.DeepEqual(got, []string{"a/1", "b/1"}) {
+		t.Errorf("expected both instances, got %v", got)
+	}
+	// the snapshot is a copy
+	snapshot := r.RegisteredInstances()
+	snapshot[1].Metadata["zone"] = "b"
+	snapshot[0].Endpoints[0] = "grpc://127.0.0.9:9999"
+	if got := r.RegisteredInstances(); got[1].Metadata["zone"] != "a" || got[0].Endpoints[0] != "grpc://127.0.0.2:9000" {
+		t.Errorf("expected the snapshot unaffected, got %v", got)
+	}
+
+	if err := r.Deregister(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(); !reflect.DeepEqual(got, []string{"a/1"}) {
+		t.Errorf("expected a/1 left, got %v", got)
+	}
+	// an instance failing to deregister is still listed
+	cli.setErr(errFakeClient)
+	if err := r.Deregister(ctx, b); err == nil {
+		t.Fatal("expected the deregister to fail")
+	}
+	if got := ids(); !reflect.DeepEqual(got, []string{"a/1"}) {
+		t.Errorf("expected a/1 left, got %v", got)
+	}
+	cli.setErr(nil)
+	if err := r.DeregisterAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(); len(got) != 0 {
+		t.Errorf("expected no instances, got %v", got)
+	}
+}
+
+func TestRegistry_TLS(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli, WithTLS("api.example.com"))
+	si := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "secure",
+		Version:   "v1.0.0",
+		Endpoints: []string{"grpcs://127.0.0.1:9000", "http://127.0.0.1:8000"},
+	}
+	if err := r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"secure.grpc", "secure.http"} {
+		ins := cli.instances[name]
+		if len(ins) != 1 {
+			t.Fatalf("expected %s to be registered, got %v", name, cli.instances)
+		}
+		if ins[0].Metadata[MetadataTLS] != "true" || ins[0].Metadata[MetadataSNI] != "api.example.com" {
+			t.Errorf("unexpected tls metadata %v", ins[0].Metadata)
+		}
+	}
+
+	items, err := r.GetService(context.Background(), "secure.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Endpoints[0] != "grpcs://127.0.0.1:9000" {
+		t.Fatalf("unexpected instances %v", items)
+	}
+	if items[0].Metadata[MetadataSNI] != "api.example.com" {
+		t.Errorf("unexpected metadata %v", items[0].Metadata)
+	}
+	items, err = r.GetService(context.Background(), "secure.http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Endpoints[0] != "https://127.0.0.1:8000" {
+		t.Fatalf("unexpected instances %v", items)
+	}
+
+	if err = r.Deregister(context.Background(), &registry.ServiceInstance{Name: "secure", Endpoints: si.Endpoints}); err != nil {
+		t.Fatal(err)
+	}
+	for name, ins := range cli.instances {
+		if len(ins) != 0 {
+			t.Errorf("expected %s to be cleaned up, got %v", name, ins)
+		}
+	}
+}
+
+func TestRegistry_GetOne(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli)
+	if _, err := r.GetOne(context.Background(), "one.grpc"); !errors.Is(err, ErrNoInstances) {
+		t.Fatalf("expected %v, got %v", ErrNoInstances, err)
+	}
+
+	si := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "one",
+		Version:   "v1.0.0",
+		Endpoints: []string{"grpc://127.0.0.1:9000"},
+	}
+	if err := r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.GetOne(context.Background(), "one.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "v1.0.0" || len(got.Endpoints) != 1 || got.Endpoints[0] != "grpc://127.0.0.1:9000" {
+		t.Errorf("unexpected instance %+v", got)
+	}
+
+	// an unhealthy instance only
+	cli.mu.Lock()
+	cli.instances["one.grpc"][0].Healthy = false
+	cli.mu.Unlock()
+	if _, err = r.GetOne(context.Background(), "one.grpc"); !errors.Is(err, ErrNoInstances) {
+		t.Errorf("expected %v, got %v", ErrNoInstances, err)
+	}
+
+	cli.setErr(errFakeClient)
+	if _, err = r.GetOne(context.Background(), "one.grpc"); !errors.Is(err, errFakeClient) {
+		t.Errorf("expected %v, got %v", errFakeClient, err)
+	}
+}
+
+func TestWatcher_Resubscribe(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli, WithResubscribeBackoff(10*time.Millisecond, 40*time.Millisecond))
+	si := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "resub",
+		Endpoints: []string{"grpc://127.0.0.1:9000"},
+	}
+	if err := r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watch, err := r.Watch(ctx, "resub.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watch.Stop()
+	w := watch.(*watcher)
+	if _, err = w.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	cli.setErr(errFakeClient)
+	cli.fail("resub.grpc", errFakeClient)
+	var intervals []time.Duration
+	for i := 0; i < 4; i++ {
+		start := time.Now()
+		if _, err = w.Next(); !errors.Is(err, errFakeClient) {
+			t.Fatalf("expected %v, got %v", errFakeClient, err)
+		}
+		intervals = append(intervals, time.Since(start))
+	}
+	// [5ms, 10ms], [10ms, 20ms], [20ms, 40ms], [20ms, 40ms]
+	for i, min := range []time.Duration{5, 10, 20, 20} {
+		if intervals[i] < min*time.Millisecond {
+			t.Errorf("interval %d: expected at least %v, got %v", i, min*time.Millisecond, intervals[i])
+		}
+	}
+
+	cli.setErr(nil)
+	items, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Errorf("expected 1 instance, got %d", len(items))
+	}
+	if w.backoff.attempt != 0 {
+		t.Errorf("expected the backoff reset after subscribing, got attempt %d", w.backoff.attempt)
+	}
+}
+
+func TestRegistry_MultiPort(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli, WithMultiPort())
+	si := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "multi",
+		Version:   "v1.0.0",
+		Endpoints: []string{"grpc://127.0.0.1:9000", "http://127.0.0.1:8000"},
+	}
+	if err := r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	ins := cli.instances["multi"]
+	if len(ins) != 1 || len(cli.instances) != 1 {
+		t.Fatalf("expected a single instance, got %v", cli.instances)
+	}
+	if ins[0].Port != 9000 || ins[0].Metadata["ports.grpc"] != "9000" || ins[0].Metadata["ports.http"] != "8000" {
+		t.Errorf("unexpected instance %+v", ins[0])
+	}
+
+	items, err := r.GetService(context.Background(), "multi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"grpc://127.0.0.1:9000", "http://127.0.0.1:8000"}
+	if len(items) != 1 || !reflect.DeepEqual(items[0].Endpoints, want) {
+		t.Fatalf("expected endpoints %v, got %v", want, items)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	w, err := r.Watch(ctx, "multi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if items, err = w.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || !reflect.DeepEqual(items[0].Endpoints, want) {
+		t.Fatalf("expected watched endpoints %v, got %v", want, items)
+	}
+
+	if err = r.Deregister(context.Background(), &registry.ServiceInstance{Name: "multi", Endpoints: si.Endpoints}); err != nil {
+		t.Fatal(err)
+	}
+	if len(cli.instances["multi"]) != 0 {
+		t.Errorf("expected the instance to be cleaned up, got %v", cli.instances)
+	}
+
+	for _, endpoints := range [][]string{
+		{"grpc://127.0.0.1:9000", "http://127.0.0.2:8000"},
+		{"grpc://127.0.0.1:9000", "grpc://127.0.0.1:9001"},
+	} {
+		if err = r.Register(context.Background(), &registry.ServiceInstance{ID: "2", Name: "multi", Endpoints: endpoints}); err == nil {
+			t.Errorf("expected an error registering %v", endpoints)
+		}
+	}
+}
+
+func TestRegistry_MultiPortTLS(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli, WithMultiPort(), WithTLS("api.example.com"))
+	si := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "multi",
+		Endpoints: []string{"https://127.0.0.1:8000", "grpcs://127.0.0.1:9000"},
+	}
+	if err := r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	items, err := r.GetService(context.Background(), "multi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || !reflect.DeepEqual(items[0].Endpoints, si.Endpoints) {
+		t.Fatalf("expected endpoints %v, got %v", si.Endpoints, items)
+	}
+}
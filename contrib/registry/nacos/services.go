@@ -0,0 +1,116 @@
+package nacos
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// Defaults of the polling of the service list.
+const (
+	defaultServicesInterval = 10 * time.Second
+	servicesPageSize        = 100
+)
+
+// ServicesWatcher watches the names of the services in the group.
+type ServicesWatcher interface {
+	// Next returns the current names, sorted, on the first call and then
+	// blocks until a service is added or removed.
+	Next() ([]string, error)
+	// Stop stops the watcher.
+	Stop() error
+}
+
+// WithServicesInterval sets the interval of WatchServices polling the
+// service list, which nacos does not push. Default is 10s.
+func WithServicesInterval(d time.Duration) Option {
+	return func(o *options) { o.servicesInterval = d }
+}
+
+// WatchServices watches the names of the services in the group, as
+// registered in nacos, e.g. "helloworld.grpc". The service list is
+// polled every WithServicesInterval, reading all of its pages.
+func (r *Registry) WatchServices(ctx context.Context) (ServicesWatcher, error) {
+	names, err := r.services()
+	if err != nil {
+		return nil, err
+	}
+	interval := r.opts.servicesInterval
+	if interval <= 0 {
+		interval = defaultServicesInterval
+	}
+	w := &servicesWatcher{
+		r:        r,
+		interval: interval,
+		names:    names,
+		first:    true,
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	return w, nil
+}
+
+// services returns the sorted names of every service of the group.
+func (r *Registry) services() ([]string, error) {
+	var names []string
+	for page := uint32(1); ; page++ {
+		res, err := r.cli.GetAllServicesInfo(vo.GetAllServiceInfoParam{
+			GroupName: r.opts.group,
+			PageNo:    page,
+			PageSize:  servicesPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, res.Doms...)
+		if len(res.Doms) < servicesPageSize || int64(len(names)) >= res.Count {
+			break
+		}
+	}
+	sort.Strings(names)
+	// a service may move to an earlier page while the pages are read
+	return slices.Compact(names), nil
+}
+
+type servicesWatcher struct {
+	r        *Registry
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	// names is the result of the previous Next.
+	names []string
+	first bool
+}
+
+func (w *servicesWatcher) Next() ([]string, error) {
+	if w.first {
+		w.first = false
+		return w.names, nil
+	}
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-t.C:
+		}
+		names, err := w.r.services()
+		if err != nil {
+			log.Warnf("[nacos] failed to list the services of group %s: %v", w.r.opts.group, err)
+			continue
+		}
+		if !slices.Equal(names, w.names) {
+			w.names = names
+			return names, nil
+		}
+	}
+}
+
+func (w *servicesWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
@@ -0,0 +1,79 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestWatchServices(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli, WithServicesInterval(10*time.Millisecond))
+	ctx := context.Background()
+	// more services than fit in a page
+	var want []string
+	for i := 0; i < servicesPageSize+5; i++ {
+		name := fmt.Sprintf("service-%03d", i)
+		if err := r.Register(ctx, &registry.ServiceInstance{ID: name, Name: name, Endpoints: []string{"grpc://127.0.0.1:9000"}}); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, name+".grpc")
+	}
+	w, err := r.WatchServices(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	next := func() []string {
+		names, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return names
+	}
+	if got := next(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %d services, got %d: %v", len(want), len(got), got)
+	}
+
+	added := &registry.ServiceInstance{ID: "added", Name: "added", Endpoints: []string{"http://127.0.0.1:8000"}}
+	if err = r.Register(ctx, added); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(); len(got) != len(want)+1 || got[0] != "added.http" {
+		t.Errorf("expected added.http added, got %v", got[:3])
+	}
+
+	if err = r.Deregister(ctx, added); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected added.http removed, got %v", got[:3])
+	}
+}
+
+func TestWatchServicesStop(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli, WithServicesInterval(time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := r.WatchServices(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names, err := w.Next(); err != nil || len(names) != 0 {
+		t.Fatalf("expected no services, got %v %v", names, err)
+	}
+	// a failing poll is retried until the watcher stops
+	cli.setErr(errFakeClient)
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err = w.Next(); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	if _, err = r.WatchServices(context.Background()); err != errFakeClient {
+		t.Errorf("expected %v, got %v", errFakeClient, err)
+	}
+}
@@ -0,0 +1,59 @@
+package nacos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+// Metadata keys describing the TLS configuration of an instance.
+const (
+	// MetadataTLS is "true" when the instance serves TLS.
+	MetadataTLS = "tls"
+	// MetadataSNI is the server name clients should verify the certificate of the instance against.
+	MetadataSNI = "sni"
+)
+
+// WithTLS marks the registered instances as serving TLS, with the server
+// name clients should use for SNI and certificate verification. Instances
+// are registered under their plain scheme, e.g. "grpc", and discovered with
+// the secure one, e.g. "grpcs".
+func WithTLS(serverName string) Option {
+	return func(o *options) {
+		o.tls = true
+		o.sni = serverName
+	}
+}
+
+// tlsMetadata returns the TLS metadata of the registered instances.
+func (o *options) tlsMetadata() map[string]string {
+	if !o.tls {
+		return nil
+	}
+	meta := map[string]string{MetadataTLS: "true"}
+	if o.sni != "" {
+		meta[MetadataSNI] = o.sni
+	}
+	return meta
+}
+
+// plainScheme strips the secure suffix of grpcs and https.
+func plainScheme(scheme string) string {
+	switch scheme {
+	case "grpcs", "https":
+		return strings.TrimSuffix(scheme, "s")
+	}
+	return scheme
+}
+
+// instanceEndpoint returns the endpoint of in, using the secure scheme for TLS instances.
+func instanceEndpoint(kind string, in model.Instance) string {
+	if k, ok := in.Metadata["kind"]; ok {
+		kind = k
+	}
+	if in.Metadata[MetadataTLS] == "true" && (kind == "grpc" || kind == "http") {
+		kind += "s"
+	}
+	return fmt.Sprintf("%s://%s:%d", kind, in.Ip, in.Port)
+}
@@ -2,8 +2,10 @@ package nacos
 
 import (
 	"context"
-	"fmt"
+	"sync/atomic"
+	"time"
 
+	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
@@ -12,6 +14,10 @@ import (
 
 var _ registry.Watcher = (*watcher)(nil)
 
+// watcher watches the instances of a service. The first Next returns the
+// instances fetched once subscribed, before any push of nacos, so that the
+// callers start from a deterministic state; the later calls of Next wait
+// for the pushes following it.
 type watcher struct {
 	serviceName    string
 	clusters       []string
@@ -22,9 +28,18 @@ type watcher struct {
 	cli            naming_client.INamingClient
 	kind           string
 	subscribeParam *vo.SubscribeParam
+	// instances is the result of the previous Next, to log the deltas.
+	instances []*registry.ServiceInstance
+	// snapshot is true until the first Next returns the instances fetched
+	// by newWatcher.
+	snapshot bool
+	// subscribed is false once the subscription failed, Next then
+	// subscribes again waiting backoff between the attempts.
+	subscribed atomic.Bool
+	backoff    *backoff
 }
 
-func newWatcher(ctx context.Context, cli naming_client.INamingClient, serviceName, groupName, kind string, clusters []string) (*watcher, error) {
+func newWatcher(ctx context.Context, cli naming_client.INamingClient, serviceName, groupName, kind string, clusters []string, b *backoff) (*watcher, error) {
 	w := &watcher{
 		serviceName: serviceName,
 		clusters:    clusters,
@@ -32,6 +47,7 @@ func newWatcher(ctx context.Context, cli naming_client.INamingClient, serviceNam
 		cli:         cli,
 		kind:        kind,
 		watchChan:   make(chan struct{}, 1),
+		backoff:     b,
 	}
 	w.ctx, w.cancel = context.WithCancel(ctx)
 	w.subscribeParam = &vo.SubscribeParam{
@@ -39,27 +55,83 @@ func newWatcher(ctx context.Context, cli naming_client.INamingClient, serviceNam
 		Clusters:    clusters,
 		GroupName:   groupName,
 		SubscribeCallback: func(services []model.Instance, err error) {
-			select {
-			case w.watchChan <- struct{}{}:
-			default:
+			if err != nil {
+				log.Warnf("[nacos] service %s subscription failed: %v", serviceName, err)
+				w.subscribed.Store(false)
 			}
+			w.notify()
 		},
 	}
 
 	e := w.cli.Subscribe(w.subscribeParam)
+	w.subscribed.Store(e == nil)
+	if e != nil {
+		return w, e
+	}
+	// the pushes before the fetch stay notified, the next Next then fetches
+	// again and may return the same instances
+	if _, err := w.fetch(); err != nil {
+		// the first Next fetches again
+		log.Warnf("[nacos] failed to get the instances of service %s: %v", serviceName, err)
+		w.notify()
+	} else {
+		w.snapshot = true
+	}
+	return w, nil
+}
+
+func (w *watcher) notify() {
 	select {
 	case w.watchChan <- struct{}{}:
 	default:
 	}
-	return w, e
 }
 
+// resubscribe subscribes again after waiting the backoff interval, which
+// grows on every failed attempt and resets once an attempt succeeds.
+func (w *watcher) resubscribe() error {
+	t := time.NewTimer(w.backoff.next())
+	defer t.Stop()
+	select {
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	case <-t.C:
+	}
+	if err := w.cli.Subscribe(w.subscribeParam); err != nil {
+		// retry on the next call of Next
+		w.notify()
+		return err
+	}
+	w.backoff.reset()
+	w.subscribed.Store(true)
+	return nil
+}
+
+// Next returns the instances fetched by newWatcher on the first call, and
+// blocks for the next push of nacos on the later ones.
 func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	if w.snapshot {
+		w.snapshot = false
+		if err := w.ctx.Err(); err != nil {
+			return nil, err
+		}
+		return w.instances, nil
+	}
 	select {
 	case <-w.ctx.Done():
 		return nil, w.ctx.Err()
 	case <-w.watchChan:
 	}
+	if !w.subscribed.Load() {
+		if err := w.resubscribe(); err != nil {
+			return nil, err
+		}
+	}
+	return w.fetch()
+}
+
+// fetch gets the instances of the service and logs the deltas.
+func (w *watcher) fetch() ([]*registry.ServiceInstance, error) {
 	res, err := w.cli.GetService(vo.GetServiceParam{
 		ServiceName: w.serviceName,
 		GroupName:   w.groupName,
@@ -68,20 +140,15 @@ func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
 	if err != nil {
 		return nil, err
 	}
-	items := make([]*registry.ServiceInstance, 0, len(res.Hosts))
-	for _, in := range res.Hosts {
-		kind := w.kind
-		if k, ok := in.Metadata["kind"]; ok {
-			kind = k
-		}
-		items = append(items, &registry.ServiceInstance{
-			ID:        in.InstanceId,
-			Name:      res.Name,
-			Version:   in.Metadata["version"],
-			Metadata:  in.Metadata,
-			Endpoints: []string{fmt.Sprintf("%s://%s:%d", kind, in.Ip, in.Port)},
-		})
+	items := mergeInstances(w.kind, res.Hosts)
+	for _, item := range items {
+		item.Name = res.Name
+	}
+	added, removed, changed := registry.Diff(w.instances, items)
+	if len(added)+len(removed)+len(changed) > 0 {
+		log.Infof("[nacos] service %s instances updated: %d added, %d removed, %d changed", w.serviceName, len(added), len(removed), len(changed))
 	}
+	w.instances = items
 	return items, nil
 }
 
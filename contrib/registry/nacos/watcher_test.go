@@ -0,0 +1,96 @@
+package nacos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// failingGetService fails the first GetService.
+type failingGetService struct {
+	*fakeNamingClient
+	failed bool
+}
+
+func (c *failingGetService) GetService(param vo.GetServiceParam) (model.Service, error) {
+	if !c.failed {
+		c.failed = true
+		return model.Service{}, errFakeClient
+	}
+	return c.fakeNamingClient.GetService(param)
+}
+
+func TestWatcher_InitialSnapshot(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli)
+	for _, endpoint := range []string{"grpc://127.0.0.1:9000", "grpc://127.0.0.2:9000"} {
+		si := &registry.ServiceInstance{ID: endpoint, Name: "snapshot", Endpoints: []string{endpoint}}
+		if err := r.Register(context.Background(), si); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	w, err := r.Watch(ctx, "snapshot.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// nacos has pushed nothing yet
+	items, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected the 2 seeded instances, got %v", items)
+	}
+
+	// the later calls wait for a push
+	next := make(chan []*registry.ServiceInstance, 1)
+	go func() {
+		items, _ := w.Next()
+		next <- items
+	}()
+	select {
+	case items = <-next:
+		t.Fatalf("expected Next to wait for a push, got %v", items)
+	case <-time.After(50 * time.Millisecond):
+	}
+	si := &registry.ServiceInstance{ID: "3", Name: "snapshot", Endpoints: []string{"grpc://127.0.0.3:9000"}}
+	if err = r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	cli.push("snapshot.grpc")
+	if items = <-next; len(items) != 3 {
+		t.Errorf("expected the 3 pushed instances, got %v", items)
+	}
+}
+
+func TestWatcher_InitialSnapshotError(t *testing.T) {
+	cli := newFakeNamingClient()
+	r := New(cli)
+	si := &registry.ServiceInstance{ID: "1", Name: "snapshot", Endpoints: []string{"grpc://127.0.0.1:9000"}}
+	if err := r.Register(context.Background(), si); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	w, err := newWatcher(ctx, &failingGetService{fakeNamingClient: cli}, "snapshot.grpc", "", "grpc", nil, newBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	// the failed snapshot is fetched again by the first Next
+	items, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Errorf("expected the seeded instance, got %v", items)
+	}
+}
@@ -0,0 +1,58 @@
+package nacos
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Bounds of the weights accepted by nacos, which clamps the others.
+const (
+	defaultMinWeight = 0
+	defaultMaxWeight = 10000
+)
+
+// metadataWeight is the metadata key of the weight of an instance, the one
+// read by the kratos selector.
+const metadataWeight = "weight"
+
+// WithWeightRange bounds the registered weights to [lo, hi], a weight out
+// of range fails Register. Default is the nacos range [0, 10000].
+func WithWeightRange(lo, hi float64) Option {
+	return func(o *options) {
+		o.minWeight = lo
+		o.maxWeight = hi
+	}
+}
+
+// WithWeightNormalization registers a weight as its fraction of the max of
+// the weight range times scale. For example with the range [1, 10] and a
+// scale of 100, a weight of 5 registers as 50, as does a weight of 500 with
+// the range [0, 1000], so that the services configured in different ranges
+// weigh alike. The weights are not normalized by the total weight of the
+// instances of a service, which the registry does not know.
+func WithWeightNormalization(scale float64) Option {
+	return func(o *options) { o.weightScale = scale }
+}
+
+// instanceWeight returns the nacos weight of si, its metadata weight if any
+// or the WithWeight one, validated and normalized.
+func (o *options) instanceWeight(name string, md map[string]string) (float64, error) {
+	if o.minWeight < 0 || o.maxWeight <= o.minWeight {
+		return 0, fmt.Errorf("%w: range [%g, %g] must be non negative and not empty", ErrInvalidWeight, o.minWeight, o.maxWeight)
+	}
+	weight := o.weight
+	if s, ok := md[metadataWeight]; ok {
+		w, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: metadata weight %q of %s is not a number", ErrInvalidWeight, s, name)
+		}
+		weight = w
+	}
+	if weight < o.minWeight || weight > o.maxWeight {
+		return 0, fmt.Errorf("%w: weight %g of %s is out of range [%g, %g]", ErrInvalidWeight, weight, name, o.minWeight, o.maxWeight)
+	}
+	if o.weightScale > 0 {
+		weight = weight / o.maxWeight * o.weightScale
+	}
+	return weight, nil
+}
@@ -0,0 +1,59 @@
+package nacos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestRegistry_Weight(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		md      map[string]string
+		want    float64
+		wantErr string
+	}{
+		{"default", nil, nil, 100, ""},
+		{"option", []Option{WithWeight(40)}, nil, 40, ""},
+		{"metadata", []Option{WithWeight(40)}, map[string]string{"weight": "7.5"}, 7.5, ""},
+		{"in range", []Option{WithWeightRange(1, 10), WithWeight(10)}, map[string]string{"weight": "1"}, 1, ""},
+		{"above max", nil, map[string]string{"weight": "20000"}, 0, "weight 20000 of helloworld is out of range [0, 10000]"},
+		{"below min", []Option{WithWeightRange(1, 10)}, map[string]string{"weight": "0.5"}, 0, "weight 0.5 of helloworld is out of range [1, 10]"},
+		{"option out of range", []Option{WithWeightRange(1, 10)}, nil, 0, "weight 100 of helloworld is out of range [1, 10]"},
+		{"not a number", nil, map[string]string{"weight": "heavy"}, 0, `metadata weight "heavy" of helloworld is not a number`},
+		{"empty range", []Option{WithWeightRange(10, 1)}, nil, 0, "range [10, 1] must be non negative and not empty"},
+		{"normalized", []Option{WithWeightRange(1, 10), WithWeightNormalization(100)}, map[string]string{"weight": "5"}, 50, ""},
+		{"normalized max", []Option{WithWeightRange(1, 10), WithWeightNormalization(100)}, map[string]string{"weight": "10"}, 100, ""},
+		{"normalized default range", []Option{WithWeightNormalization(100)}, map[string]string{"weight": "2500"}, 25, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := newFakeNamingClient()
+			r := New(cli, tt.opts...)
+			si := &registry.ServiceInstance{ID: "1", Name: "helloworld", Metadata: tt.md, Endpoints: []string{"grpc://127.0.0.1:9000", "http://127.0.0.1:8000"}}
+			err := r.Register(context.Background(), si)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidWeight) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q, got %v", tt.wantErr, err)
+				}
+				if len(cli.instances) != 0 || len(r.RegisteredInstances()) != 0 {
+					t.Errorf("expected nothing registered, got %v", cli.instances)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, service := range []string{"helloworld.grpc", "helloworld.http"} {
+				ins := cli.instances[service]
+				if len(ins) != 1 || ins[0].Weight != tt.want {
+					t.Errorf("%s: expected weight %v, got %v", service, tt.want, ins)
+				}
+			}
+		})
+	}
+}
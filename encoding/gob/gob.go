@@ -0,0 +1,50 @@
+// Package gob registers a gob codec, served over HTTP as application/x-gob.
+//
+// Gob is a Go specific format, it is only suitable between Go services such as
+// internal service-to-service calls, clients written in other languages can
+// not decode it. The codec is not registered by default, import the package:
+//
+//	import _ "github.com/go-kratos/kratos/v2/encoding/gob"
+//
+// The concrete types sent in interface fields must be registered on both
+// sides with Register.
+package gob
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+// Name is the name registered for the gob codec.
+const Name = "gob"
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// Register records the concrete type of value to be sent in interface fields,
+// see encoding/gob.Register.
+func Register(value any) {
+	gob.Register(value)
+}
+
+// codec is a Codec implementation with gob.
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (codec) Name() string {
+	return Name
+}
@@ -0,0 +1,73 @@
+package gob
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+type Shape interface {
+	Area() float64
+}
+
+type Rect struct {
+	W, H float64
+}
+
+func (r Rect) Area() float64 { return r.W * r.H }
+
+type Circle struct {
+	R float64
+}
+
+func (c *Circle) Area() float64 { return 3 * c.R * c.R }
+
+type Drawing struct {
+	Name   string
+	Shapes []Shape
+	Attrs  map[string]any
+}
+
+func init() {
+	Register(Rect{})
+	Register(&Circle{})
+}
+
+func TestCodec(t *testing.T) {
+	c := encoding.GetCodec(Name)
+	if c == nil {
+		t.Fatal("expected the gob codec registered")
+	}
+	in := &Drawing{
+		Name:   "kratos",
+		Shapes: []Shape{Rect{W: 2, H: 3}, &Circle{R: 1}},
+		Attrs:  map[string]any{"count": 2, "rect": Rect{W: 1, H: 1}},
+	}
+	data, err := c.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(Drawing)
+	if err = c.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("expected %+v, got %+v", in, out)
+	}
+	if out.Shapes[0].Area() != 6 || out.Shapes[1].Area() != 3 {
+		t.Errorf("unexpected concrete types %#v", out.Shapes)
+	}
+}
+
+type Square struct {
+	S float64
+}
+
+func (s Square) Area() float64 { return s.S * s.S }
+
+func TestCodec_Unregistered(t *testing.T) {
+	if _, err := (codec{}).Marshal(&Drawing{Shapes: []Shape{Square{S: 1}}}); err == nil {
+		t.Error("expected an error for an unregistered concrete type")
+	}
+}
@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// FieldViolation describes a single bad request field, it is sent in the
+// google.rpc.BadRequest detail of the gRPC status.
+type FieldViolation struct {
+	// Field is the path to the field, e.g. "user.emails[0]".
+	Field string
+	// Description is why the field is bad.
+	Description string
+}
+
+// WithFieldViolations with the fields of a bad request and why they are bad,
+// e.g. the failures of a request validation.
+func (e *Error) WithFieldViolations(violations ...FieldViolation) *Error {
+	err := Clone(e)
+	err.violations = append([]FieldViolation(nil), violations...)
+	return err
+}
+
+// FieldViolations returns the bad request fields of the error.
+func (e *Error) FieldViolations() []FieldViolation {
+	return e.violations
+}
+
+// badRequest returns the google.rpc.BadRequest detail of the field
+// violations, or nil without any.
+func (e *Error) badRequest() *errdetails.BadRequest {
+	if len(e.violations) == 0 {
+		return nil
+	}
+	br := &errdetails.BadRequest{FieldViolations: make([]*errdetails.BadRequest_FieldViolation, 0, len(e.violations))}
+	for _, v := range e.violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	return br
+}
+
+// fromDetails sets the reason, metadata and field violations of the detail
+// messages of a gRPC status into e.
+func (e *Error) fromDetails(details []any) {
+	for _, detail := range details {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			e.Reason = d.Reason
+			e.Metadata = make(map[string]string, len(d.Metadata))
+			for k, v := range d.Metadata {
+				e.Metadata[k] = v
+			}
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				e.violations = append(e.violations, FieldViolation{Field: v.GetField(), Description: v.GetDescription()})
+			}
+		}
+	}
+}
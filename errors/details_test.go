@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCStatusDetails(t *testing.T) {
+	violations := []FieldViolation{
+		{Field: "user.email", Description: "must be a valid email"},
+		{Field: "user.age", Description: "must be positive"},
+	}
+	err := BadRequest("INVALID_USER", "invalid user").
+		WithMetadata(map[string]string{"user": "42"}).
+		WithFieldViolations(violations...)
+
+	st := err.GRPCStatus()
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("expect %v, got %v", codes.InvalidArgument, st.Code())
+	}
+	var (
+		info *errdetails.ErrorInfo
+		br   *errdetails.BadRequest
+	)
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			br = d
+		}
+	}
+	if info == nil || info.Reason != "INVALID_USER" || info.Metadata["user"] != "42" {
+		t.Errorf("expect the ErrorInfo detail, got %v", info)
+	}
+	if br == nil || len(br.FieldViolations) != 2 || br.FieldViolations[0].Field != "user.email" || br.FieldViolations[1].Description != "must be positive" {
+		t.Errorf("expect the BadRequest detail, got %v", br)
+	}
+
+	// round-trip through the gRPC status of the client
+	got := FromError(st.Err())
+	if got.Code != 400 || got.Reason != "INVALID_USER" || got.Message != "invalid user" || got.Metadata["user"] != "42" {
+		t.Errorf("expect the error reconstructed, got %v", got)
+	}
+	if !reflect.DeepEqual(got.FieldViolations(), violations) {
+		t.Errorf("expect %v, got %v", violations, got.FieldViolations())
+	}
+}
+
+func TestGRPCStatusWithoutViolations(t *testing.T) {
+	st := NotFound("USER_NOT_FOUND", "user not found").GRPCStatus()
+	if len(st.Details()) != 1 {
+		t.Errorf("expect only the ErrorInfo detail, got %v", st.Details())
+	}
+	if got := FromError(st.Err()); got.Reason != "USER_NOT_FOUND" || got.FieldViolations() != nil {
+		t.Errorf("expect no field violations, got %v", got)
+	}
+}
+
+func TestFromErrorForeignDetails(t *testing.T) {
+	// a status of a non kratos server
+	st, err := status.New(codes.InvalidArgument, "bad request").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "required"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := FromError(st.Err())
+	if got.Code != 400 || got.Reason != UnknownReason {
+		t.Errorf("expect a bad request without reason, got %v", got)
+	}
+	if want := []FieldViolation{{Field: "name", Description: "required"}}; !reflect.DeepEqual(got.FieldViolations(), want) {
+		t.Errorf("expect %v, got %v", want, got.FieldViolations())
+	}
+}
+
+func TestFieldViolationsClone(t *testing.T) {
+	base := BadRequest("INVALID", "invalid")
+	err := base.WithFieldViolations(FieldViolation{Field: "a", Description: "bad"})
+	if base.FieldViolations() != nil {
+		t.Errorf("expect the base error unchanged, got %v", base.FieldViolations())
+	}
+	clone := Clone(err)
+	clone.violations[0].Field = "b"
+	if err.FieldViolations()[0].Field != "a" {
+		t.Errorf("expect a deep clone, got %v", err.FieldViolations())
+	}
+	if md := err.WithMetadata(map[string]string{"k": "v"}); len(md.FieldViolations()) != 1 {
+		t.Errorf("expect the violations kept with metadata, got %v", md.FieldViolations())
+	}
+}
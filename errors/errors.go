@@ -22,7 +22,8 @@ const (
 // Error is a status error.
 type Error struct {
 	Status
-	cause error
+	cause      error
+	violations []FieldViolation
 }
 
 func (e *Error) Error() string {
@@ -54,13 +55,20 @@ func (e *Error) WithMetadata(md map[string]string) *Error {
 	return err
 }
 
-// GRPCStatus returns the Status represented by se.
+// GRPCStatus returns the Status represented by se. Its details are a
+// google.rpc.ErrorInfo with the reason and metadata, and a
+// google.rpc.BadRequest with the field violations if any.
 func (e *Error) GRPCStatus() *status.Status {
-	s, _ := status.New(httpstatus.ToGRPCCode(int(e.Code)), e.Message).
-		WithDetails(&errdetails.ErrorInfo{
-			Reason:   e.Reason,
-			Metadata: e.Metadata,
-		})
+	st := status.New(httpstatus.ToGRPCCode(int(e.Code)), e.Message)
+	info := &errdetails.ErrorInfo{
+		Reason:   e.Reason,
+		Metadata: e.Metadata,
+	}
+	if br := e.badRequest(); br != nil {
+		s, _ := st.WithDetails(info, br)
+		return s
+	}
+	s, _ := st.WithDetails(info)
 	return s
 }
 
@@ -85,6 +93,15 @@ func Errorf(code int, reason, format string, a ...any) error {
 	return New(code, reason, fmt.Sprintf(format, a...))
 }
 
+// Wrap returns an error object for the code and reason that keeps cause in
+// its unwrap chain, so errors.Is and errors.As still match the cause.
+// The cause is only reported by Error and is never sent to the client.
+func Wrap(code int, reason string, cause error) *Error {
+	err := New(code, reason, "")
+	err.cause = cause
+	return err
+}
+
 // Code returns the http code for an error.
 // It supports wrapped errors.
 func Code(err error) int {
@@ -113,7 +130,8 @@ func Clone(err *Error) *Error {
 		metadata[k] = v
 	}
 	return &Error{
-		cause: err.cause,
+		cause:      err.cause,
+		violations: append([]FieldViolation(nil), err.violations...),
 		Status: Status{
 			Code:     err.Code,
 			Reason:   err.Reason,
@@ -141,12 +159,6 @@ func FromError(err error) *Error {
 		UnknownReason,
 		gs.Message(),
 	)
-	for _, detail := range gs.Details() {
-		switch d := detail.(type) {
-		case *errdetails.ErrorInfo:
-			ret.Reason = d.Reason
-			return ret.WithMetadata(d.Metadata)
-		}
-	}
+	ret.fromDetails(gs.Details())
 	return ret
 }
@@ -1,10 +1,12 @@
 package errors
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"google.golang.org/grpc/codes"
@@ -147,3 +149,39 @@ func TestOther(t *testing.T) {
 		t.Errorf("Clone(nil) = %v, want %v", Clone(err400), err400)
 	}
 }
+
+func TestWrap(t *testing.T) {
+	cause := &TestError{message: "sql: no rows in result set"}
+	err := Wrap(http.StatusNotFound, "USER_NOT_FOUND", fmt.Errorf("query user: %w", cause))
+	if !errors.Is(err, cause) {
+		t.Errorf("expect %v in the chain of %v", cause, err)
+	}
+	if te := new(TestError); !errors.As(err, &te) || te != cause {
+		t.Errorf("expect As to find %v, got %v", cause, te)
+	}
+	if !errors.Is(err, NotFound("USER_NOT_FOUND", "")) {
+		t.Errorf("expect %v to match its code and reason", err)
+	}
+	if Code(err) != http.StatusNotFound || Reason(err) != "USER_NOT_FOUND" {
+		t.Errorf("unexpected code %d reason %s", Code(err), Reason(err))
+	}
+
+	// the cause must not be leaked on the wire
+	if err.Message != "" {
+		t.Errorf("expect empty message, got %q", err.Message)
+	}
+	gs := err.GRPCStatus()
+	if strings.Contains(gs.Message(), cause.message) || strings.Contains(fmt.Sprint(gs.Details()), cause.message) {
+		t.Errorf("grpc status leaks the cause: %v", gs.Proto())
+	}
+	if se := FromError(gs.Err()); se.Code != http.StatusNotFound || se.Reason != "USER_NOT_FOUND" {
+		t.Errorf("unexpected error from status: %v", se)
+	}
+	body, jerr := json.Marshal(&err.Status)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	if strings.Contains(string(body), cause.message) {
+		t.Errorf("http body leaks the cause: %s", body)
+	}
+}
@@ -56,6 +56,18 @@ func IsConflict(err error) bool {
 	return Code(err) == 409
 }
 
+// PreconditionFailed new PreconditionFailed error that is mapped to a 412
+// response, and to the FailedPrecondition code of gRPC.
+func PreconditionFailed(reason, message string) *Error {
+	return New(412, reason, message)
+}
+
+// IsPreconditionFailed determines if err is an error which indicates a PreconditionFailed error.
+// It supports wrapped errors.
+func IsPreconditionFailed(err error) bool {
+	return Code(err) == 412
+}
+
 // InternalServer new InternalServer error that is mapped to a 500 response.
 func InternalServer(reason, message string) *Error {
 	return New(500, reason, message)
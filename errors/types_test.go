@@ -12,6 +12,7 @@ func TestTypes(t *testing.T) {
 			Forbidden("reason_403", "message_403"),
 			NotFound("reason_404", "message_404"),
 			Conflict("reason_409", "message_409"),
+			PreconditionFailed("reason_412", "message_412"),
 			InternalServer("reason_500", "message_500"),
 			ServiceUnavailable("reason_503", "message_503"),
 			GatewayTimeout("reason_504", "message_504"),
@@ -23,6 +24,7 @@ func TestTypes(t *testing.T) {
 			IsForbidden,
 			IsNotFound,
 			IsConflict,
+			IsPreconditionFailed,
 			IsInternalServer,
 			IsServiceUnavailable,
 			IsGatewayTimeout,
@@ -113,3 +113,26 @@ func (mc *mergeCtx) Value(key any) any {
 	}
 	return mc.parent2.Value(key)
 }
+
+type requestDeadlineKey struct{}
+
+type requestDeadline struct {
+	deadline time.Time
+	ok       bool
+}
+
+// WithRequestDeadline records the deadline of the request as received by
+// the server, before the server timeout is applied to ctx.
+func WithRequestDeadline(ctx context.Context) context.Context {
+	d, ok := ctx.Deadline()
+	return context.WithValue(ctx, requestDeadlineKey{}, requestDeadline{deadline: d, ok: ok})
+}
+
+// RequestDeadline returns the deadline recorded by WithRequestDeadline,
+// or the deadline of ctx if none was recorded.
+func RequestDeadline(ctx context.Context) (time.Time, bool) {
+	if rd, ok := ctx.Value(requestDeadlineKey{}).(requestDeadline); ok {
+		return rd.deadline, rd.ok
+	}
+	return ctx.Deadline()
+}
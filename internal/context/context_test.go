@@ -278,3 +278,18 @@ func Test_Err2(t *testing.T) {
 		t.Errorf("expect %v, got %v", context.Canceled, ctx.Err())
 	}
 }
+
+func TestRequestDeadline(t *testing.T) {
+	if _, ok := RequestDeadline(context.Background()); ok {
+		t.Error("expected no deadline")
+	}
+	ctx, cancel := context.WithTimeout(WithRequestDeadline(context.Background()), time.Second)
+	defer cancel()
+	if _, ok := RequestDeadline(ctx); ok {
+		t.Error("expected no deadline recorded")
+	}
+	want, _ := ctx.Deadline()
+	if got, ok := RequestDeadline(WithRequestDeadline(ctx)); !ok || !got.Equal(want) {
+		t.Errorf("expected deadline %v, got %v", want, got)
+	}
+}
@@ -62,3 +62,10 @@ func (g *Group) Clear() {
 	g.vals = make(map[string]any)
 	g.Unlock()
 }
+
+// Delete deletes the object of the given key.
+func (g *Group) Delete(key string) {
+	g.Lock()
+	delete(g.vals, key)
+	g.Unlock()
+}
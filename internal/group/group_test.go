@@ -77,3 +77,20 @@ func TestGroupClear(t *testing.T) {
 		t.Errorf("expect length 0, actual %v", length)
 	}
 }
+
+func TestGroupDelete(t *testing.T) {
+	count := 0
+	g := NewGroup(func() any {
+		count++
+		return count
+	})
+	g.Get("key_0")
+	g.Get("key_1")
+	g.Delete("key_0")
+	if _, ok := g.vals["key_0"]; ok || len(g.vals) != 1 {
+		t.Errorf("expect only key_1 left, actual %v", g.vals)
+	}
+	if v := g.Get("key_0"); !reflect.DeepEqual(v.(int), 3) {
+		t.Errorf("expect a new object 3, actual %v", v)
+	}
+}
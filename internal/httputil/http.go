@@ -8,8 +8,16 @@ const (
 	baseContentType = "application"
 )
 
+// subtypes maps the codec names whose content-subtype differs from the name.
+var subtypes = map[string]string{
+	"gob": "x-gob",
+}
+
 // ContentType returns the content-type with base prefix.
 func ContentType(subtype string) string {
+	if s, ok := subtypes[subtype]; ok {
+		subtype = s
+	}
 	return baseContentType + "/" + subtype
 }
 
@@ -18,6 +26,7 @@ func ContentType(subtype string) string {
 // but no content-subtype will be returned.
 // according rfc7231.
 // contentType is assumed to be lowercase already.
+// The content-subtype of a differently named codec, e.g. x-gob, returns its name.
 func ContentSubtype(contentType string) string {
 	left := strings.Index(contentType, "/")
 	if left == -1 {
@@ -30,5 +39,11 @@ func ContentSubtype(contentType string) string {
 	if right < left {
 		return ""
 	}
-	return contentType[left+1 : right]
+	subtype := contentType[left+1 : right]
+	for name, s := range subtypes {
+		if s == subtype {
+			return name
+		}
+	}
+	return subtype
 }
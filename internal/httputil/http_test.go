@@ -15,6 +15,7 @@ func TestContentSubtype(t *testing.T) {
 		{"application/json", "json"},
 		{"application/xml", "xml"},
 		{"text/xml", "xml"},
+		{"application/x-gob", "gob"},
 		{";text/xml", ""},
 		{"application", ""},
 	}
@@ -36,6 +37,7 @@ func TestContentType(t *testing.T) {
 	}{
 		{"kratos", "kratos", "application/kratos"},
 		{"json", "json", "application/json"},
+		{"gob", "gob", "application/x-gob"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,22 @@
+package matcher
+
+import "strings"
+
+// Operation reports whether operation matches one of the selectors, a
+// selector ending with "*" matches every operation with that prefix.
+// selector:
+//   - '/*'
+//   - '/helloworld.v1.Greeter/*'
+//   - '/helloworld.v1.Greeter/SayHello'
+func Operation(operation string, selectors ...string) bool {
+	for _, selector := range selectors {
+		if prefix, ok := strings.CutSuffix(selector, "*"); ok {
+			if strings.HasPrefix(operation, prefix) {
+				return true
+			}
+		} else if selector == operation {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package matcher
+
+import "testing"
+
+func TestOperation(t *testing.T) {
+	selectors := []string{"/helloworld.v1.Greeter/*", "/user.v1.User/GetUser"}
+	tests := []struct {
+		operation string
+		want      bool
+	}{
+		{"/helloworld.v1.Greeter/SayHello", true},
+		{"/user.v1.User/GetUser", true},
+		{"/user.v1.User/GetUsers", false},
+		{"/user.v1.User/DeleteUser", false},
+	}
+	for _, tt := range tests {
+		if got := Operation(tt.operation, selectors...); got != tt.want {
+			t.Errorf("%s: expected %v got %v", tt.operation, tt.want, got)
+		}
+	}
+	if Operation("/user.v1.User/GetUser") {
+		t.Error("expected no selector to match nothing")
+	}
+}
@@ -0,0 +1,53 @@
+package matcher
+
+import (
+	"sort"
+	"strings"
+)
+
+// Sizes maps operation selectors to size limits, e.g. of the responses.
+// The zero value is ready to use and limits nothing.
+type Sizes struct {
+	def     int64
+	prefix  []string
+	matches map[string]int64
+}
+
+// Add sets the limit size of the operations matching one of the selectors,
+// or of every operation without a more specific limit when there is none.
+// selector:
+//   - '/*'
+//   - '/helloworld.v1.Greeter/*'
+//   - '/helloworld.v1.Greeter/SayHello'
+func (s *Sizes) Add(size int64, selectors ...string) {
+	if len(selectors) == 0 {
+		s.def = size
+		return
+	}
+	if s.matches == nil {
+		s.matches = make(map[string]int64)
+	}
+	for _, selector := range selectors {
+		if strings.HasSuffix(selector, "*") {
+			selector = strings.TrimSuffix(selector, "*")
+			s.prefix = append(s.prefix, selector)
+			sort.Slice(s.prefix, func(i, j int) bool {
+				return s.prefix[i] > s.prefix[j]
+			})
+		}
+		s.matches[selector] = size
+	}
+}
+
+// Match returns the limit size of the operation, 0 when it is unlimited.
+func (s *Sizes) Match(operation string) int64 {
+	if size, ok := s.matches[operation]; ok {
+		return size
+	}
+	for _, prefix := range s.prefix {
+		if strings.HasPrefix(operation, prefix) {
+			return s.matches[prefix]
+		}
+	}
+	return s.def
+}
@@ -0,0 +1,29 @@
+package matcher
+
+import "testing"
+
+func TestSizes(t *testing.T) {
+	var s Sizes
+	if got := s.Match("/foo.Bar/Baz"); got != 0 {
+		t.Errorf("expected no limit, got %d", got)
+	}
+	s.Add(10)
+	s.Add(20, "/foo.*")
+	s.Add(30, "/foo.Bar/*", "/other.Service/Call")
+	s.Add(40, "/foo.Bar/Baz")
+	tests := []struct {
+		operation string
+		want      int64
+	}{
+		{"/foo.Bar/Baz", 40},
+		{"/foo.Bar/Qux", 30},
+		{"/foo.Other/Qux", 20},
+		{"/other.Service/Call", 30},
+		{"/other.Service/List", 10},
+	}
+	for _, tt := range tests {
+		if got := s.Match(tt.operation); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.operation, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// Mask replaces the value of every redacted field.
+	Mask = "****"
+	// DefaultMaxSize is the default size cap of the redacted output.
+	DefaultMaxSize = 4096
+)
+
+// Redactor masks field paths of proto messages and plain structs.
+type Redactor struct {
+	root    node
+	maxSize int
+}
+
+type node map[string]node
+
+// New returns a Redactor masking the given dot-separated field paths,
+// e.g. "password" or "user.ssn". Proto messages are matched by their proto
+// field names and plain structs by their json names. The output is truncated
+// to maxSize bytes, maxSize <= 0 means DefaultMaxSize.
+func New(paths []string, maxSize int) *Redactor {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	r := &Redactor{root: node{}, maxSize: maxSize}
+	for _, path := range paths {
+		n := r.root
+		for _, key := range strings.Split(path, ".") {
+			next, ok := n[key]
+			if !ok {
+				next = node{}
+				n[key] = next
+			}
+			n = next
+		}
+	}
+	return r
+}
+
+// Redact returns the json representation of v with configured fields masked.
+func (r *Redactor) Redact(v any) string {
+	var (
+		data []byte
+		err  error
+	)
+	if m, ok := v.(proto.Message); ok {
+		data, err = protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return r.truncate(fmt.Sprintf("%+v", v))
+	}
+	var value any
+	if err = json.Unmarshal(data, &value); err != nil {
+		return r.truncate(string(data))
+	}
+	if data, err = json.Marshal(mask(value, r.root)); err != nil {
+		return r.truncate(fmt.Sprintf("%+v", v))
+	}
+	return r.truncate(string(data))
+}
+
+func (r *Redactor) truncate(s string) string {
+	if len(s) <= r.maxSize {
+		return s
+	}
+	return s[:r.maxSize] + "..."
+}
+
+// mask replaces the leaves of value matched by n with Mask.
+// Lists are traversed transparently so "items.secret" masks every item.
+func mask(value any, n node) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, child := range v {
+			next, ok := n[k]
+			if !ok {
+				continue
+			}
+			if len(next) == 0 {
+				v[k] = Mask
+				continue
+			}
+			v[k] = mask(child, next)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = mask(item, n)
+		}
+	}
+	return value
+}
+
+// Message returns a copy of m with configured fields masked, matched by their
+// proto field names. Masked string and bytes fields are set to Mask, other
+// masked fields are cleared. m itself is left untouched.
+func (r *Redactor) Message(m proto.Message) proto.Message {
+	if len(r.root) == 0 {
+		return m
+	}
+	c := proto.Clone(m)
+	maskMessage(c.ProtoReflect(), r.root)
+	return c
+}
+
+func maskMessage(m protoreflect.Message, n node) {
+	fields := m.Descriptor().Fields()
+	for key, next := range n {
+		fd := fields.ByName(protoreflect.Name(key))
+		if fd == nil || !m.Has(fd) {
+			continue
+		}
+		switch {
+		case len(next) == 0:
+			maskField(m, fd)
+		case fd.IsList() && fd.Message() != nil:
+			list := m.Mutable(fd).List()
+			for i := 0; i < list.Len(); i++ {
+				maskMessage(list.Get(i).Message(), next)
+			}
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			maskMessage(m.Mutable(fd).Message(), next)
+		}
+	}
+}
+
+func maskField(m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	switch {
+	case fd.IsList() && (fd.Kind() == protoreflect.StringKind || fd.Kind() == protoreflect.BytesKind):
+		list := m.Mutable(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			list.Set(i, maskValue(fd.Kind()))
+		}
+	case !fd.IsList() && !fd.IsMap() && (fd.Kind() == protoreflect.StringKind || fd.Kind() == protoreflect.BytesKind):
+		m.Set(fd, maskValue(fd.Kind()))
+	default:
+		m.Clear(fd)
+	}
+}
+
+func maskValue(kind protoreflect.Kind) protoreflect.Value {
+	if kind == protoreflect.BytesKind {
+		return protoreflect.ValueOfBytes([]byte(Mask))
+	}
+	return protoreflect.ValueOfString(Mask)
+}
+
+// JSON returns the json document data with configured fields masked,
+// matched by their json names.
+func (r *Redactor) JSON(data []byte) ([]byte, error) {
+	if len(r.root) == 0 {
+		return data, nil
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mask(value, r.root))
+}
@@ -0,0 +1,98 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/go-kratos/kratos/v2/internal/testdata/complex"
+)
+
+type testUser struct {
+	Name string `json:"name"`
+	SSN  string `json:"ssn"`
+}
+
+type testRequest struct {
+	Username string     `json:"username"`
+	Password string     `json:"password"`
+	User     testUser   `json:"user"`
+	Friends  []testUser `json:"friends"`
+}
+
+func TestRedactStruct(t *testing.T) {
+	r := New([]string{"password", "user.ssn", "friends.ssn"}, 0)
+	got := r.Redact(&testRequest{
+		Username: "kratos",
+		Password: "secret",
+		User:     testUser{Name: "go", SSN: "123-45-6789"},
+		Friends:  []testUser{{Name: "friend", SSN: "987-65-4321"}},
+	})
+	want := `{"friends":[{"name":"friend","ssn":"****"}],"password":"****","user":{"name":"go","ssn":"****"},"username":"kratos"}`
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestRedactProto(t *testing.T) {
+	msg, err := structpb.NewStruct(map[string]any{
+		"password": "secret",
+		"name":     "kratos",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := New([]string{"password"}, 0).Redact(msg)
+	want := `{"name":"kratos","password":"****"}`
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestRedactMaxSize(t *testing.T) {
+	got := New(nil, 16).Redact(map[string]string{"name": strings.Repeat("x", 64)})
+	if len(got) != 16+len("...") || !strings.HasSuffix(got, "...") {
+		t.Errorf("expected truncated output, got %s", got)
+	}
+}
+
+func TestRedactUnsupported(t *testing.T) {
+	got := New([]string{"password"}, 0).Redact(func() {})
+	if got == "" {
+		t.Error("expected fallback output")
+	}
+}
+
+func TestRedactMessage(t *testing.T) {
+	msg := &complex.Complex{
+		Id:      1,
+		NoOne:   "secret",
+		Simple:  &complex.Simple{Component: "secret"},
+		Simples: []string{"a", "b"},
+		Age:     18,
+	}
+	got := New([]string{"no_one", "simple.component", "simples", "age"}, 0).Message(msg).(*complex.Complex)
+	if got.NoOne != Mask || got.Simple.Component != Mask || got.Simples[0] != Mask || got.Simples[1] != Mask {
+		t.Errorf("expected masked fields, got %v", got)
+	}
+	if got.Age != 0 || got.Id != 1 {
+		t.Errorf("expected age cleared and id kept, got %v", got)
+	}
+	if msg.NoOne != "secret" || msg.Simple.Component != "secret" {
+		t.Errorf("expected the original message untouched, got %v", msg)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	got, err := New([]string{"password"}, 0).JSON([]byte(`{"name":"kratos","password":"secret"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"kratos","password":"****"}`; string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if _, err = New([]string{"password"}, 0).JSON([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid json")
+	}
+}
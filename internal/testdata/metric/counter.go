@@ -0,0 +1,41 @@
+// Package metric provides the metric instruments recording in memory for
+// the tests.
+package metric
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/embedded"
+)
+
+var _ metric.Int64Counter = (*Counter)(nil)
+
+// Counter is a metric.Int64Counter counting the increments per attribute set.
+type Counter struct {
+	embedded.Int64Counter
+
+	mu     sync.Mutex
+	counts map[attribute.Distinct]int64
+}
+
+// Add records incr for the attributes of opts.
+func (c *Counter) Add(_ context.Context, incr int64, opts ...metric.AddOption) {
+	set := metric.NewAddConfig(opts).Attributes()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[attribute.Distinct]int64)
+	}
+	c.counts[set.Equivalent()] += incr
+}
+
+// Count returns the sum of the increments recorded for exactly attrs.
+func (c *Counter) Count(attrs ...attribute.KeyValue) int64 {
+	set := attribute.NewSet(attrs...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[set.Equivalent()]
+}
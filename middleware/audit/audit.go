@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/internal/redact"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+)
+
+const (
+	// ResultSuccess is the result of an operation returning no error.
+	ResultSuccess = "success"
+	// ResultFailure is the result of an operation returning an error.
+	ResultFailure = "failure"
+)
+
+// Event is an audit event of an operation.
+type Event struct {
+	// Time is when the operation started.
+	Time time.Time
+	// Actor is who called the operation, empty when unknown.
+	Actor string
+	// Action is the operation called.
+	Action string
+	// Request is the redacted json summary of the request.
+	Request string
+	// Result is either ResultSuccess or ResultFailure.
+	Result string
+	// Code and Reason are the ones of the returned error, Code is 200 on success.
+	Code   int32
+	Reason string
+	// Latency is how long the operation took.
+	Latency time.Duration
+}
+
+// Sink receives the audit events, e.g. to persist them.
+type Sink interface {
+	Emit(ctx context.Context, e *Event) error
+}
+
+type logSink struct {
+	logger log.Logger
+}
+
+// NewLogSink returns a Sink writing the events to logger.
+func NewLogSink(logger log.Logger) Sink {
+	return &logSink{logger: logger}
+}
+
+func (s *logSink) Emit(ctx context.Context, e *Event) error {
+	return log.WithContext(ctx, s.logger).Log(log.LevelInfo,
+		"msg", "audit",
+		"time", e.Time.Format(time.RFC3339Nano),
+		"actor", e.Actor,
+		"action", e.Action,
+		"request", e.Request,
+		"result", e.Result,
+		"code", e.Code,
+		"reason", e.Reason,
+		"latency", e.Latency.Seconds(),
+	)
+}
+
+// Actor returns who calls the operation of ctx.
+type Actor func(ctx context.Context) string
+
+// Option is audit option.
+type Option func(*options)
+
+type options struct {
+	sink         Sink
+	actor        Actor
+	operations   []string
+	redactFields []string
+	redactSize   int
+}
+
+// WithSink sets the sink of the events, default is a log sink of the
+// default logger.
+func WithSink(s Sink) Option {
+	return func(o *options) {
+		o.sink = s
+	}
+}
+
+// WithActor sets how the actor is extracted, default is the subject of
+// the jwt claims.
+func WithActor(a Actor) Option {
+	return func(o *options) {
+		o.actor = a
+	}
+}
+
+// WithOperations audits only the operations, default is every operation.
+// An operation ending with "*" matches every operation with that prefix,
+// e.g. "/helloworld.v1.Greeter/*".
+func WithOperations(operations ...string) Option {
+	return func(o *options) {
+		o.operations = operations
+	}
+}
+
+// WithRedactFields masks the given field paths of the request summary,
+// e.g. "password" or "user.ssn". Proto messages are matched by proto field
+// names and plain structs by json names.
+func WithRedactFields(paths ...string) Option {
+	return func(o *options) {
+		o.redactFields = paths
+	}
+}
+
+// WithRedactMaxSize caps the size of the request summary, default is 4096 bytes.
+func WithRedactMaxSize(size int) Option {
+	return func(o *options) {
+		o.redactSize = size
+	}
+}
+
+// jwtActor returns the subject of the jwt claims of ctx.
+func jwtActor(ctx context.Context) string {
+	claims, ok := jwt.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	sub, _ := claims.GetSubject()
+	return sub
+}
+
+// Server is a server middleware emitting an audit event for every call of
+// the audited operations, both successful and failed. It must run after
+// the authentication middleware so that the actor is known.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{
+		actor: jwtActor,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.sink == nil {
+		o.sink = NewLogSink(log.GetLogger())
+	}
+	r := redact.New(o.redactFields, o.redactSize)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || !o.match(tr.Operation()) {
+				return handler(ctx, req)
+			}
+			e := &Event{
+				Time:    time.Now(),
+				Actor:   o.actor(ctx),
+				Action:  tr.Operation(),
+				Request: r.Redact(req),
+				Result:  ResultSuccess,
+				Code:    int32(status.FromGRPCCode(codes.OK)),
+			}
+			reply, err := handler(ctx, req)
+			e.Latency = time.Since(e.Time)
+			if err != nil {
+				e.Result = ResultFailure
+				if se := errors.FromError(err); se != nil {
+					e.Code, e.Reason = se.Code, se.Reason
+				}
+			}
+			if serr := o.sink.Emit(ctx, e); serr != nil {
+				log.Errorf("audit: failed to emit event of %s: %v", e.Action, serr)
+			}
+			return reply, err
+		}
+	}
+}
+
+func (o *options) match(operation string) bool {
+	return len(o.operations) == 0 || matcher.Operation(operation, o.operations...)
+}
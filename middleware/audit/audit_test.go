@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct {
+	transport.Transporter
+	operation string
+}
+
+func (tr *Transport) Operation() string { return tr.operation }
+
+type testSink struct {
+	events []*Event
+}
+
+func (s *testSink) Emit(_ context.Context, e *Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+type request struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func newContext(operation, subject string) context.Context {
+	ctx := transport.NewServerContext(context.Background(), &Transport{operation: operation})
+	return jwt.NewContext(ctx, jwtv5.RegisteredClaims{Subject: subject})
+}
+
+func TestServerSuccess(t *testing.T) {
+	sink := &testSink{}
+	next := func(context.Context, any) (any, error) { return "reply", nil }
+	m := Server(WithSink(sink), WithRedactFields("password"))(next)
+	reply, err := m(newContext("/test.Service/Update", "alice"), &request{Name: "foo", Password: "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reply != "reply" {
+		t.Errorf("expected reply, got %v", reply)
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(sink.events))
+	}
+	e := sink.events[0]
+	if e.Actor != "alice" {
+		t.Errorf("expected actor alice, got %q", e.Actor)
+	}
+	if e.Action != "/test.Service/Update" {
+		t.Errorf("expected action /test.Service/Update, got %q", e.Action)
+	}
+	if e.Request != `{"name":"foo","password":"****"}` {
+		t.Errorf("unexpected request: %s", e.Request)
+	}
+	if e.Result != ResultSuccess || e.Code != 200 || e.Reason != "" {
+		t.Errorf("unexpected result: %s %d %s", e.Result, e.Code, e.Reason)
+	}
+	if e.Time.IsZero() {
+		t.Error("expected the event time to be set")
+	}
+}
+
+func TestServerFailure(t *testing.T) {
+	sink := &testSink{}
+	next := func(context.Context, any) (any, error) {
+		return nil, errors.Forbidden("FORBIDDEN", "not allowed")
+	}
+	m := Server(WithSink(sink))(next)
+	if _, err := m(newContext("/test.Service/Delete", "bob"), &request{Name: "foo"}); !errors.IsForbidden(err) {
+		t.Fatalf("expected forbidden, got %v", err)
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(sink.events))
+	}
+	e := sink.events[0]
+	if e.Actor != "bob" || e.Action != "/test.Service/Delete" {
+		t.Errorf("unexpected actor or action: %s %s", e.Actor, e.Action)
+	}
+	if e.Result != ResultFailure || e.Code != 403 || e.Reason != "FORBIDDEN" {
+		t.Errorf("unexpected result: %s %d %s", e.Result, e.Code, e.Reason)
+	}
+}
+
+func TestServerOperations(t *testing.T) {
+	sink := &testSink{}
+	next := func(context.Context, any) (any, error) { return "reply", nil }
+	m := Server(WithSink(sink), WithOperations("/test.Service/Update", "/admin.Service/*"))(next)
+	for _, op := range []string{"/test.Service/Update", "/test.Service/Get", "/admin.Service/Reset"} {
+		if _, err := m(newContext(op, "alice"), "req"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(sink.events) != 2 || sink.events[0].Action != "/test.Service/Update" || sink.events[1].Action != "/admin.Service/Reset" {
+		t.Errorf("unexpected events: %v", sink.events)
+	}
+}
+
+func TestServerActor(t *testing.T) {
+	sink := &testSink{}
+	next := func(context.Context, any) (any, error) { return "reply", nil }
+	actor := func(context.Context) string { return "service-account" }
+	m := Server(WithSink(sink), WithActor(actor))(next)
+	ctx := transport.NewServerContext(context.Background(), &Transport{operation: "/test.Service/Update"})
+	if _, err := m(ctx, "req"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sink.events) != 1 || sink.events[0].Actor != "service-account" {
+		t.Errorf("unexpected events: %v", sink.events)
+	}
+}
+
+func TestLogSink(t *testing.T) {
+	var b strings.Builder
+	sink := NewLogSink(log.NewStdLogger(&b))
+	next := func(context.Context, any) (any, error) { return nil, errors.BadRequest("INVALID", "") }
+	_, _ = Server(WithSink(sink))(next)(newContext("/test.Service/Update", "alice"), "req")
+	out := b.String()
+	for _, want := range []string{"msg=audit", "actor=alice", "action=/test.Service/Update", "result=failure", "code=400", "reason=INVALID"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in %q", want, out)
+		}
+	}
+}
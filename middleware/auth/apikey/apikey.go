@@ -0,0 +1,129 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// DefaultHeader is the header, or metadata key, carrying the API key.
+const DefaultHeader = "X-API-Key"
+
+// Reasons of the errors rejecting a request.
+const (
+	ReasonMissing = "API_KEY_MISSING"
+	ReasonInvalid = "API_KEY_INVALID"
+	reason        = "UNAUTHORIZED"
+)
+
+var (
+	ErrMissingKey   = errors.Unauthorized(ReasonMissing, "API key is missing")
+	ErrInvalidKey   = errors.Unauthorized(ReasonInvalid, "API key is invalid")
+	ErrMissingStore = errors.Unauthorized(reason, "API key store is missing")
+	ErrWrongContext = errors.Unauthorized(reason, "Wrong context for middleware")
+)
+
+// KeyStore validates the API keys.
+type KeyStore interface {
+	// Lookup returns the identity of the client key belongs to, or
+	// ErrInvalidKey for an unknown key.
+	Lookup(ctx context.Context, key string) (identity string, err error)
+}
+
+// StaticKeys is a KeyStore of the valid keys by identity. An identity may
+// have several valid keys, e.g. the old and the new one during a rotation.
+type StaticKeys map[string][]string
+
+// Lookup returns the identity having key, comparing the keys in constant time.
+func (s StaticKeys) Lookup(_ context.Context, key string) (string, error) {
+	var identity string
+	for id, keys := range s {
+		for _, k := range keys {
+			if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+				identity = id
+			}
+		}
+	}
+	if identity == "" {
+		return "", ErrInvalidKey
+	}
+	return identity, nil
+}
+
+// Option is API key option.
+type Option func(*options)
+
+type options struct {
+	header string
+}
+
+// WithHeader sets the header, or metadata key, carrying the API key.
+// Default is DefaultHeader.
+func WithHeader(name string) Option {
+	return func(o *options) {
+		o.header = name
+	}
+}
+
+// Server is a server auth middleware. It validates the API key of the
+// request with store and puts the identity of the client into the context.
+func Server(store KeyStore, opts ...Option) middleware.Middleware {
+	o := &options{header: DefaultHeader}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, ErrWrongContext
+			}
+			if store == nil {
+				return nil, ErrMissingStore
+			}
+			key := tr.RequestHeader().Get(o.header)
+			if key == "" {
+				return nil, ErrMissingKey
+			}
+			identity, err := store.Lookup(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			return handler(NewContext(ctx, identity), req)
+		}
+	}
+}
+
+// Client is a client API key middleware setting key on the requests.
+func Client(key string, opts ...Option) middleware.Middleware {
+	o := &options{header: DefaultHeader}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromClientContext(ctx)
+			if !ok {
+				return nil, ErrWrongContext
+			}
+			tr.RequestHeader().Set(o.header, key)
+			return handler(ctx, req)
+		}
+	}
+}
+
+type identityKey struct{}
+
+// NewContext put the identity of the client into context
+func NewContext(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext extract the identity of the client from context
+func FromContext(ctx context.Context) (identity string, ok bool) {
+	identity, ok = ctx.Value(identityKey{}).(string)
+	return
+}
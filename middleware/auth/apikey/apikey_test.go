@@ -0,0 +1,112 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type Transport struct {
+	transport.Transporter
+	header headerCarrier
+}
+
+func (tr *Transport) RequestHeader() transport.Header { return tr.header }
+
+func TestServer(t *testing.T) {
+	store := StaticKeys{
+		"billing": {"old-billing-key", "new-billing-key"},
+		"orders":  {"orders-key"},
+	}
+	errStore := errors.New("store unavailable")
+	tests := []struct {
+		name     string
+		header   string
+		key      string
+		store    KeyStore
+		identity string
+		err      error
+	}{
+		{"valid", DefaultHeader, "orders-key", store, "orders", nil},
+		{"rotated old", DefaultHeader, "old-billing-key", store, "billing", nil},
+		{"rotated new", DefaultHeader, "new-billing-key", store, "billing", nil},
+		{"invalid", DefaultHeader, "orders-key-2", store, "", ErrInvalidKey},
+		{"missing", DefaultHeader, "", store, "", ErrMissingKey},
+		{"other header", "X-Token", "orders-key", store, "", ErrMissingKey},
+		{"no store", DefaultHeader, "orders-key", nil, "", ErrMissingStore},
+		{"store error", DefaultHeader, "orders-key", storeFunc(func(string) (string, error) { return "", errStore }), "", errStore},
+	}
+	for _, tt := range tests {
+		header := headerCarrier{}
+		if tt.key != "" {
+			header.Set(tt.header, tt.key)
+		}
+		ctx := transport.NewServerContext(context.Background(), &Transport{header: header})
+		var identity string
+		next := func(ctx context.Context, _ any) (any, error) {
+			identity, _ = FromContext(ctx)
+			return "reply", nil
+		}
+		_, err := Server(tt.store)(next)(ctx, nil)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+		if identity != tt.identity {
+			t.Errorf("%s: expected identity %q, got %q", tt.name, tt.identity, identity)
+		}
+	}
+}
+
+type storeFunc func(key string) (string, error)
+
+func (f storeFunc) Lookup(_ context.Context, key string) (string, error) { return f(key) }
+
+func TestWithHeader(t *testing.T) {
+	header := headerCarrier{}
+	header.Set("X-Token", "orders-key")
+	ctx := transport.NewServerContext(context.Background(), &Transport{header: header})
+	next := func(ctx context.Context, _ any) (any, error) {
+		identity, _ := FromContext(ctx)
+		return identity, nil
+	}
+	reply, err := Server(StaticKeys{"orders": {"orders-key"}}, WithHeader("X-Token"))(next)(ctx, nil)
+	if err != nil || reply != "orders" {
+		t.Errorf("expected the identity orders, got %v %v", reply, err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	header := headerCarrier{}
+	ctx := transport.NewClientContext(context.Background(), &Transport{header: header})
+	next := func(context.Context, any) (any, error) { return nil, nil }
+	if _, err := Client("orders-key")(next)(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if key := header.Get(DefaultHeader); key != "orders-key" {
+		t.Errorf("expected the key set, got %q", key)
+	}
+	if _, err := Client("orders-key")(next)(context.Background(), nil); !errors.Is(err, ErrWrongContext) {
+		t.Errorf("expected %v, got %v", ErrWrongContext, err)
+	}
+}
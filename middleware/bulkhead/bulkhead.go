@@ -0,0 +1,161 @@
+package bulkhead
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// ErrFull is returned when the pool of an operation has no capacity left.
+var ErrFull = errors.New(429, "BULKHEAD_FULL", "operation concurrency limit exceeded")
+
+// Limits returns the concurrency limit of a pool, reporting false to use
+// the default one. It is evaluated per request, so it must be cheap.
+type Limits func(pool string) (int, bool)
+
+// Option is bulkhead option.
+type Option func(*options)
+
+type options struct {
+	limit  int
+	limits Limits
+	groups []group
+}
+
+type group struct {
+	name       string
+	operations []string
+}
+
+// WithLimit sets the default concurrency limit of each pool, default is 100.
+func WithLimit(n int) Option {
+	return func(o *options) {
+		o.limit = n
+	}
+}
+
+// WithGroup makes the operations share the pool named name instead of
+// getting a pool each. An operation ending with "*" matches every
+// operation with that prefix, e.g. "/helloworld.v1.Greeter/*".
+func WithGroup(name string, operations ...string) Option {
+	return func(o *options) {
+		o.groups = append(o.groups, group{name: name, operations: operations})
+	}
+}
+
+// WithLimits sets per pool limits, e.g. a hot-reloaded one returned by FromConfig.
+func WithLimits(limits Limits) Option {
+	return func(o *options) {
+		o.limits = limits
+	}
+}
+
+// FromConfig returns Limits backed by the config key, a map from pool name
+// to concurrency limit which is hot-reloaded on config changes.
+func FromConfig(c config.Config, key string) Limits {
+	var limits atomic.Value
+	limits.Store(map[string]int{})
+	load := func(v config.Value) {
+		m, err := v.Map()
+		if err != nil {
+			log.Errorf("bulkhead: invalid limits %s: %v", key, err)
+			return
+		}
+		next := make(map[string]int, len(m))
+		for pool, v := range m {
+			n, err := v.Int()
+			if err != nil {
+				log.Errorf("bulkhead: invalid limit %s.%s: %v", key, pool, err)
+				return
+			}
+			next[pool] = int(n)
+		}
+		limits.Store(next)
+	}
+	if v := c.Value(key); v.Load() != nil {
+		load(v)
+	}
+	if err := c.Watch(key, func(_ string, v config.Value) { load(v) }); err != nil {
+		log.Warnf("bulkhead: failed to watch limits %s: %v", key, err)
+	}
+	return func(pool string) (int, bool) {
+		n, ok := limits.Load().(map[string]int)[pool]
+		return n, ok
+	}
+}
+
+// Server is a server bulkhead middleware partitioning the concurrency per
+// operation, so a saturated operation cannot starve the others.
+func Server(opts ...Option) middleware.Middleware {
+	return newBulkhead(opts).middleware(func(ctx context.Context) (transport.Transporter, bool) {
+		return transport.FromServerContext(ctx)
+	})
+}
+
+// Client is a client bulkhead middleware partitioning the concurrency per operation.
+func Client(opts ...Option) middleware.Middleware {
+	return newBulkhead(opts).middleware(func(ctx context.Context) (transport.Transporter, bool) {
+		return transport.FromClientContext(ctx)
+	})
+}
+
+type bulkhead struct {
+	opts  options
+	pools sync.Map // pool name -> *atomic.Int64 in flight
+}
+
+func newBulkhead(opts []Option) *bulkhead {
+	o := options{limit: 100}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &bulkhead{opts: o}
+}
+
+func (b *bulkhead) middleware(from func(context.Context) (transport.Transporter, bool)) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			var operation string
+			if tr, ok := from(ctx); ok {
+				operation = tr.Operation()
+			}
+			name := b.pool(operation)
+			limit := b.opts.limit
+			if b.opts.limits != nil {
+				if n, ok := b.opts.limits(name); ok {
+					limit = n
+				}
+			}
+			v, ok := b.pools.Load(name)
+			if !ok {
+				v, _ = b.pools.LoadOrStore(name, new(atomic.Int64))
+			}
+			inflight := v.(*atomic.Int64)
+			if inflight.Add(1) > int64(limit) {
+				inflight.Add(-1)
+				return nil, ErrFull
+			}
+			defer inflight.Add(-1)
+			return handler(ctx, req)
+		}
+	}
+}
+
+// pool returns the pool name of operation.
+func (b *bulkhead) pool(operation string) string {
+	for _, g := range b.opts.groups {
+		for _, op := range g.operations {
+			if op == operation || strings.HasSuffix(op, "*") && strings.HasPrefix(operation, strings.TrimSuffix(op, "*")) {
+				return g.name
+			}
+		}
+	}
+	return operation
+}
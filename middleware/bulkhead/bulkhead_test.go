@@ -0,0 +1,120 @@
+package bulkhead
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/memory"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct {
+	transport.Transporter
+	operation string
+}
+
+func (tr *Transport) Operation() string { return tr.operation }
+
+func operationContext(operation string) context.Context {
+	return transport.NewServerContext(context.Background(), &Transport{operation: operation})
+}
+
+// saturate occupies a slot of the pool of operation until the handler is released.
+func saturate(t *testing.T, h func(context.Context, any) (any, error), operation string) {
+	t.Helper()
+	started := make(chan struct{})
+	go func() {
+		_, _ = h(operationContext(operation), started)
+	}()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatalf("%s was not admitted", operation)
+	}
+}
+
+func blockingHandler(release chan struct{}) func(context.Context, any) (any, error) {
+	return func(_ context.Context, req any) (any, error) {
+		if started, ok := req.(chan struct{}); ok {
+			close(started)
+			<-release
+		}
+		return "reply", nil
+	}
+}
+
+func TestServerIsolation(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	h := Server(WithLimit(1))(blockingHandler(release))
+
+	saturate(t, h, "/test.Service/Noisy")
+	if _, err := h(operationContext("/test.Service/Noisy"), nil); !errors.Is(err, ErrFull) {
+		t.Fatalf("expected %v, got %v", ErrFull, err)
+	} else if code := errors.FromError(err).GRPCStatus().Code(); code != codes.ResourceExhausted {
+		t.Errorf("expected %v, got %v", codes.ResourceExhausted, code)
+	}
+	// saturating one operation doesn't reject another
+	if reply, err := h(operationContext("/test.Service/Quiet"), nil); err != nil || reply != "reply" {
+		t.Fatalf("unexpected reply %v %v", reply, err)
+	}
+}
+
+func TestServerGroup(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	h := Server(WithLimit(1), WithGroup("greeter", "/test.Greeter/*"))(blockingHandler(release))
+
+	saturate(t, h, "/test.Greeter/SayHello")
+	if _, err := h(operationContext("/test.Greeter/SayBye"), nil); !errors.Is(err, ErrFull) {
+		t.Fatalf("expected operations of a group to share the pool, got %v", err)
+	}
+	if _, err := h(operationContext("/test.Service/Get"), nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	src := memory.NewSource(map[string]any{"bulkhead.greeter": 1})
+	c := config.New(config.WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	limits := FromConfig(c, "bulkhead")
+	if n, ok := limits("greeter"); !ok || n != 1 {
+		t.Fatalf("expected limit 1, got %d %v", n, ok)
+	}
+	if _, ok := limits("other"); ok {
+		t.Fatal("expected no limit for other")
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	h := Server(WithLimit(10), WithLimits(limits), WithGroup("greeter", "/test.Greeter/*"))(blockingHandler(release))
+	saturate(t, h, "/test.Greeter/SayHello")
+	if _, err := h(operationContext("/test.Greeter/SayHello"), nil); !errors.Is(err, ErrFull) {
+		t.Fatalf("expected %v, got %v", ErrFull, err)
+	}
+
+	if err := src.Set("bulkhead.greeter", 2); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if n, _ := limits("greeter"); n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected limit 2 after reload")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	saturate(t, h, "/test.Greeter/SayHello")
+}
@@ -2,6 +2,7 @@ package circuitbreaker
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kratos/aegis/circuitbreaker"
 	"github.com/go-kratos/aegis/circuitbreaker/sre"
@@ -35,21 +36,62 @@ func WithCircuitBreaker(genBreakerFunc func() circuitbreaker.CircuitBreaker) Opt
 	}
 }
 
+// WithNodeBreaker keys the circuit breakers by the selected node address
+// instead of the operation, so one bad node trips independently. Nodes whose
+// breaker rejects requests are removed from the candidates until the breaker
+// lets requests through again. See NewNodeBreaker to expose their state.
+func WithNodeBreaker() Option {
+	return func(o *options) {
+		o.node = true
+	}
+}
+
+// WithEjectCooldown ejects for d a node whose breaker rejects a request, with
+// WithNodeBreaker: it is left out of the candidates without asking its
+// breaker until the cooldown expires. Default is 0, a node is left out as long as its
+// breaker rejects requests.
+func WithEjectCooldown(d time.Duration) Option {
+	return func(o *options) {
+		o.cooldown = d
+	}
+}
+
+// WithNodeIdleTimeout drops the breaker and the state of a node which is
+// not among the candidates for d, with WithNodeBreaker, e.g. a node removed
+// from the discovery. Default is 10 minutes.
+func WithNodeIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.idleTimeout = d
+	}
+}
+
 type options struct {
-	group *group.Group
+	group       *group.Group
+	node        bool
+	cooldown    time.Duration
+	idleTimeout time.Duration
 }
 
-// Client circuitbreaker middleware will return errBreakerTriggered when the circuit
-// breaker is triggered and the request is rejected directly.
-func Client(opts ...Option) middleware.Middleware {
+func newOptions(opts ...Option) *options {
 	opt := &options{
 		group: group.NewGroup(func() any {
 			return sre.NewBreaker()
 		}),
+		idleTimeout: 10 * time.Minute,
 	}
 	for _, o := range opts {
 		o(opt)
 	}
+	return opt
+}
+
+// Client circuitbreaker middleware will return errBreakerTriggered when the circuit
+// breaker is triggered and the request is rejected directly.
+func Client(opts ...Option) middleware.Middleware {
+	opt := newOptions(opts...)
+	if opt.node {
+		return newNodeBreaker(opt).Middleware()
+	}
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req any) (any, error) {
 			info, _ := transport.FromClientContext(ctx)
@@ -63,12 +105,16 @@ func Client(opts ...Option) middleware.Middleware {
 			}
 			// allowed
 			reply, err := handler(ctx, req)
-			if err != nil && (errors.IsInternalServer(err) || errors.IsServiceUnavailable(err) || errors.IsGatewayTimeout(err)) {
-				breaker.MarkFailed()
-			} else {
-				breaker.MarkSuccess()
-			}
+			mark(breaker, err)
 			return reply, err
 		}
 	}
 }
+
+func mark(breaker circuitbreaker.CircuitBreaker, err error) {
+	if err != nil && (errors.IsInternalServer(err) || errors.IsServiceUnavailable(err) || errors.IsGatewayTimeout(err)) {
+		breaker.MarkFailed()
+	} else {
+		breaker.MarkSuccess()
+	}
+}
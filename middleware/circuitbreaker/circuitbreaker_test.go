@@ -5,8 +5,12 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/go-kratos/aegis/circuitbreaker"
+
 	kratoserrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/group"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/random"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -77,3 +81,81 @@ func TestServer(_ *testing.T) {
 
 	_, _ = Client(func(_ *options) {})(nextInvalid)(ctx, nil)
 }
+
+// countBreaker trips after failures consecutive failures.
+type countBreaker struct {
+	failures int
+	limit    int
+}
+
+func (c *countBreaker) Allow() error {
+	if c.failures >= c.limit {
+		return errors.New("breaker open")
+	}
+	return nil
+}
+func (c *countBreaker) MarkSuccess() { c.failures = 0 }
+func (c *countBreaker) MarkFailed()  { c.failures++ }
+
+func TestNodeBreaker(t *testing.T) {
+	breakers := make(map[*countBreaker]struct{})
+	m := Client(
+		WithCircuitBreaker(func() circuitbreaker.CircuitBreaker {
+			b := &countBreaker{limit: 3}
+			breakers[b] = struct{}{}
+			return b
+		}),
+		WithNodeBreaker(),
+	)
+	sel := random.New()
+	sel.Apply([]selector.Node{
+		selector.NewNode("http", "127.0.0.1:8000", nil),
+		selector.NewNode("http", "127.0.0.2:8000", nil),
+	})
+	// next mimics a transport: it selects a node and records it as the peer.
+	next := func(ctx context.Context, _ any) (any, error) {
+		n, done, err := sel.Select(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if p, ok := selector.FromPeerContext(ctx); ok {
+			p.Node = n
+		}
+		var reply error
+		if n.Address() == "127.0.0.1:8000" {
+			reply = kratoserrors.ServiceUnavailable("BAD_NODE", "bad node")
+		}
+		done(ctx, selector.DoneInfo{Err: reply})
+		return n.Address(), reply
+	}
+	h := m(next)
+	for i := 0; i < 100; i++ {
+		ctx := transport.NewClientContext(context.Background(), &transportMock{operation: "/package.service/method"})
+		ctx = selector.NewPeerContext(ctx, &selector.Peer{})
+		_, _ = h(ctx, nil)
+	}
+	if len(breakers) != 2 {
+		t.Fatalf("expected one breaker per node, got %d", len(breakers))
+	}
+
+	for i := 0; i < 10; i++ {
+		ctx := transport.NewClientContext(context.Background(), &transportMock{operation: "/package.service/method"})
+		ctx = selector.NewPeerContext(ctx, &selector.Peer{})
+		reply, err := h(ctx, nil)
+		if err != nil {
+			t.Fatalf("expected healthy node served, got %v", err)
+		}
+		if reply != "127.0.0.2:8000" {
+			t.Errorf("expected broken node skipped, got %v", reply)
+		}
+	}
+	open := 0
+	for b := range breakers {
+		if b.Allow() != nil {
+			open++
+		}
+	}
+	if open != 1 {
+		t.Errorf("expected only one broken node, got %d", open)
+	}
+}
@@ -0,0 +1,170 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// State is the circuit state of a node.
+type State string
+
+const (
+	// StateClosed is a node whose breaker lets requests through.
+	StateClosed State = "closed"
+	// StateOpen is a node left out of the candidates, its breaker rejects
+	// requests or it is ejected until its cooldown expires.
+	StateOpen State = "open"
+)
+
+// NodeState is the circuit state of one node of a NodeBreaker.
+type NodeState struct {
+	Address string    `json:"address"`
+	State   State     `json:"state"`
+	Since   time.Time `json:"since"`
+	// Cooldown is the time left before an open node is tried again, zero
+	// for a closed node or without WithEjectCooldown.
+	Cooldown time.Duration `json:"cooldown"`
+}
+
+type nodeState struct {
+	open  bool
+	since time.Time
+	until time.Time
+	// seen is the last time the node was a candidate.
+	seen time.Time
+}
+
+// NodeBreaker keys the circuit breakers by the selected node address, as
+// Client WithNodeBreaker, and exposes the state of the nodes, e.g. for a
+// debug endpoint.
+type NodeBreaker struct {
+	opt *options
+	now func() time.Time
+
+	mu    sync.Mutex
+	nodes map[string]*nodeState
+	swept time.Time
+}
+
+// NewNodeBreaker returns a node breaker, its Middleware is the client
+// middleware.
+func NewNodeBreaker(opts ...Option) *NodeBreaker {
+	return newNodeBreaker(newOptions(opts...))
+}
+
+func newNodeBreaker(opt *options) *NodeBreaker {
+	return &NodeBreaker{
+		opt:   opt,
+		now:   time.Now,
+		nodes: make(map[string]*nodeState),
+	}
+}
+
+// Middleware returns the client middleware removing from the candidates the
+// nodes whose breaker rejects requests.
+func (b *NodeBreaker) Middleware() middleware.Middleware {
+	filter := func(_ context.Context, nodes []selector.Node) []selector.Node {
+		allowed := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if b.allow(n.Address()) {
+				allowed = append(allowed, n)
+			}
+		}
+		b.sweep()
+		return allowed
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			reply, err := handler(selector.NewHealthFilterContext(ctx, filter), req)
+			// the peer node is set by the transport once a node is selected.
+			if p, ok := selector.FromPeerContext(ctx); ok && p.Node != nil {
+				mark(b.breaker(p.Node.Address()), err)
+			}
+			return reply, err
+		}
+	}
+}
+
+// States returns the state of the nodes seen so far, by address. It is safe
+// to call concurrently with the requests.
+func (b *NodeBreaker) States() []NodeState {
+	now := b.now()
+	b.mu.Lock()
+	states := make([]NodeState, 0, len(b.nodes))
+	for addr, s := range b.nodes {
+		state := NodeState{Address: addr, State: StateClosed, Since: s.since}
+		if s.open {
+			state.State = StateOpen
+			if left := s.until.Sub(now); left > 0 {
+				state.Cooldown = left
+			}
+		}
+		states = append(states, state)
+	}
+	b.mu.Unlock()
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Address < states[j].Address
+	})
+	return states
+}
+
+func (b *NodeBreaker) breaker(addr string) circuitbreaker.CircuitBreaker {
+	return b.opt.group.Get(addr).(circuitbreaker.CircuitBreaker)
+}
+
+// allow reports whether the node at addr is a candidate, recording the
+// transitions of its state.
+func (b *NodeBreaker) allow(addr string) bool {
+	now := b.now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.nodes[addr]
+	if !ok {
+		s = &nodeState{since: now}
+		b.nodes[addr] = s
+	}
+	s.seen = now
+	if s.open && now.Before(s.until) {
+		return false
+	}
+	allowed := b.breaker(addr).Allow() == nil
+	if s.open == allowed {
+		s.open = !allowed
+		s.since = now
+	}
+	if !allowed {
+		s.until = now.Add(b.opt.cooldown)
+	}
+	return allowed
+}
+
+// sweep drops the nodes which have not been candidates for the idle
+// timeout, at most once per idle timeout.
+func (b *NodeBreaker) sweep() {
+	if b.opt.idleTimeout <= 0 {
+		return
+	}
+	now := b.now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.swept.IsZero() {
+		b.swept = now
+	}
+	if now.Sub(b.swept) < b.opt.idleTimeout {
+		return
+	}
+	b.swept = now
+	for addr, s := range b.nodes {
+		if now.Sub(s.seen) >= b.opt.idleTimeout {
+			delete(b.nodes, addr)
+			b.opt.group.Delete(addr)
+		}
+	}
+}
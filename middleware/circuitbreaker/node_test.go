@@ -0,0 +1,142 @@
+package circuitbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/random"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestNodeBreakerStates(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := NewNodeBreaker(
+		WithCircuitBreaker(func() circuitbreaker.CircuitBreaker {
+			return &countBreaker{limit: 3}
+		}),
+		WithEjectCooldown(10*time.Second),
+	)
+	b.now = func() time.Time { return now }
+	sel := random.New()
+	sel.Apply([]selector.Node{
+		selector.NewNode("http", "127.0.0.1:8000", nil),
+		selector.NewNode("http", "127.0.0.2:8000", nil),
+	})
+	bad := map[string]bool{"127.0.0.1:8000": true}
+	// next mimics a transport: it selects a node and records it as the peer.
+	next := func(ctx context.Context, _ any) (any, error) {
+		n, done, err := sel.Select(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if p, ok := selector.FromPeerContext(ctx); ok {
+			p.Node = n
+		}
+		var reply error
+		if bad[n.Address()] {
+			reply = kratoserrors.ServiceUnavailable("BAD_NODE", "bad node")
+		}
+		done(ctx, selector.DoneInfo{Err: reply})
+		return n.Address(), reply
+	}
+	h := b.Middleware()(next)
+	call := func(n int) {
+		for i := 0; i < n; i++ {
+			ctx := transport.NewClientContext(context.Background(), &transportMock{operation: "/package.service/method"})
+			_, _ = h(selector.NewPeerContext(ctx, &selector.Peer{}), nil)
+		}
+	}
+	expect := func(want ...NodeState) {
+		t.Helper()
+		got := b.States()
+		if len(got) != len(want) {
+			t.Fatalf("expected %d nodes, got %+v", len(want), got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("expected %+v, got %+v", want[i], got[i])
+			}
+		}
+	}
+	start := now
+
+	call(1)
+	expect(
+		NodeState{Address: "127.0.0.1:8000", State: StateClosed, Since: start},
+		NodeState{Address: "127.0.0.2:8000", State: StateClosed, Since: start},
+	)
+
+	// the bad node trips and is ejected
+	call(100)
+	expect(
+		NodeState{Address: "127.0.0.1:8000", State: StateOpen, Since: start, Cooldown: 10 * time.Second},
+		NodeState{Address: "127.0.0.2:8000", State: StateClosed, Since: start},
+	)
+	now = now.Add(4 * time.Second)
+	expect(
+		NodeState{Address: "127.0.0.1:8000", State: StateOpen, Since: start, Cooldown: 6 * time.Second},
+		NodeState{Address: "127.0.0.2:8000", State: StateClosed, Since: start},
+	)
+
+	// the node recovers, but it is left out until the cooldown expires
+	delete(bad, "127.0.0.1:8000")
+	b.breaker("127.0.0.1:8000").MarkSuccess()
+	call(10)
+	if s := b.States()[0]; s.State != StateOpen {
+		t.Errorf("expected the node ejected during the cooldown, got %+v", s)
+	}
+	now = now.Add(6 * time.Second)
+	call(1)
+	expect(
+		NodeState{Address: "127.0.0.1:8000", State: StateClosed, Since: now},
+		NodeState{Address: "127.0.0.2:8000", State: StateClosed, Since: start},
+	)
+}
+
+func TestNodeBreakerIdle(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := NewNodeBreaker(WithNodeIdleTimeout(time.Minute))
+	b.now = func() time.Time { return now }
+	filter := func(addrs ...string) {
+		var nodes []selector.Node
+		for _, addr := range addrs {
+			nodes = append(nodes, selector.NewNode("http", addr, nil))
+		}
+		next := func(ctx context.Context, _ any) (any, error) {
+			for _, f := range selector.FromHealthFilterContext(ctx) {
+				nodes = f(ctx, nodes)
+			}
+			return nil, nil
+		}
+		_, _ = b.Middleware()(next)(context.Background(), nil)
+	}
+	addresses := func() []string {
+		var addrs []string
+		for _, s := range b.States() {
+			addrs = append(addrs, s.Address)
+		}
+		return addrs
+	}
+
+	filter("127.0.0.1:8000", "127.0.0.2:8000")
+	idle := b.breaker("127.0.0.1:8000")
+	// the first node is removed from the discovery
+	now = now.Add(30 * time.Second)
+	filter("127.0.0.2:8000")
+	if got := addresses(); len(got) != 2 {
+		t.Errorf("expected both nodes before the idle timeout, got %v", got)
+	}
+	now = now.Add(40 * time.Second)
+	filter("127.0.0.2:8000")
+	if got := addresses(); len(got) != 1 || got[0] != "127.0.0.2:8000" {
+		t.Errorf("expected the idle node dropped, got %v", got)
+	}
+	if b.breaker("127.0.0.1:8000") == idle {
+		t.Error("expected the breaker of the idle node dropped")
+	}
+}
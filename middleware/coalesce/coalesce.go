@@ -0,0 +1,200 @@
+package coalesce
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// KeyFunc returns the key of a request, the concurrent requests of the
+// same key share one execution. A request is not coalesced on an error.
+type KeyFunc func(ctx context.Context, operation string, req any) (string, error)
+
+// Option is request coalescing option.
+type Option func(*options)
+
+type options struct {
+	operations []string
+	key        KeyFunc
+}
+
+// WithOperations coalesces only the operations, which must be idempotent.
+// An operation ending with "*" matches every operation with that prefix,
+// e.g. "/helloworld.v1.Greeter/*". Default is only the HTTP GET and HEAD
+// requests.
+func WithOperations(operations ...string) Option {
+	return func(o *options) {
+		o.operations = operations
+	}
+}
+
+// WithKey sets the key of the requests, default is DefaultKey. A custom
+// key must tell the callers apart when the reply depends on the caller.
+func WithKey(fn KeyFunc) Option {
+	return func(o *options) {
+		o.key = fn
+	}
+}
+
+// Server is a server middleware coalescing the concurrent identical reads,
+// e.g. to protect the backend of a cache from a stampede on its misses.
+// The first request of a key executes the handler and the requests of the
+// same key arriving while it is in flight wait for it; all of them get the
+// same reply and error, so the reply must not be modified. The shared
+// execution keeps the values of the context of the first request but is
+// canceled only once every waiting request is canceled, a single request
+// giving up on its context returns its error alone.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{key: DefaultKey}
+	for _, opt := range opts {
+		opt(o)
+	}
+	g := &group{calls: make(map[string]*call)}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || !o.match(tr) {
+				return handler(ctx, req)
+			}
+			key, err := o.key(ctx, tr.Operation(), req)
+			if err != nil {
+				return handler(ctx, req)
+			}
+			return g.do(ctx, key, func(ctx context.Context) (any, error) {
+				return handler(ctx, req)
+			})
+		}
+	}
+}
+
+// identityHeaders are the request headers carrying the credentials of the
+// caller, e.g. of the jwt and apikey middlewares.
+var identityHeaders = []string{"Authorization", "Cookie", "X-API-Key"}
+
+// DefaultKey is the default KeyFunc, the operation and the hash of req,
+// marshaled deterministically when it is a proto message and as JSON
+// otherwise, and of the credential headers of the request. The credentials
+// keep the concurrent requests of distinct callers apart, which would
+// otherwise share the reply of a caller-dependent read such as "GET /me".
+func DefaultKey(ctx context.Context, operation string, req any) (string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if m, ok := req.(proto.Message); ok {
+		data, err = proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	} else {
+		data, err = json.Marshal(req)
+	}
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(data)
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		header := tr.RequestHeader()
+		for _, k := range identityHeaders {
+			for _, v := range header.Values(k) {
+				h.Write([]byte("\x00" + k + "\x00" + v))
+			}
+		}
+	}
+	return operation + "#" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (o *options) match(tr transport.Transporter) bool {
+	if len(o.operations) == 0 {
+		ht, ok := tr.(khttp.Transporter)
+		if !ok {
+			return false
+		}
+		method := ht.Request().Method
+		return method == http.MethodGet || method == http.MethodHead
+	}
+	return matcher.Operation(tr.Operation(), o.operations...)
+}
+
+// call is an execution in flight shared by its waiting requests.
+type call struct {
+	done    chan struct{}
+	reply   any
+	err     error
+	panic   any
+	waiters int
+	cancel  context.CancelFunc
+}
+
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+func (g *group) do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if !ok {
+		cctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		go func() {
+			defer func() {
+				// the panic is raised again in the waiting requests,
+				// e.g. for the recovery middleware
+				c.panic = recover()
+				cancel()
+				if g.forget(key, c) && c.panic != nil {
+					// nobody waits to raise it again
+					buf := make([]byte, 64<<10) //nolint:mnd
+					buf = buf[:runtime.Stack(buf, false)]
+					log.Errorf("coalesce: execution of %s panicked after every request gave up: %v\n%s", key, c.panic, buf)
+				}
+				close(c.done)
+			}()
+			c.reply, c.err = fn(cctx)
+		}()
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		if c.panic != nil {
+			panic(c.panic)
+		}
+		return c.reply, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		if c.waiters--; c.waiters == 0 {
+			// nobody waits for the execution anymore, the next request
+			// of the key starts a new one
+			c.cancel()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// forget removes the finished c and reports whether nobody waits for it.
+func (g *group) forget(key string, c *call) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	return c.waiters == 0
+}
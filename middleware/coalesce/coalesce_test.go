@@ -0,0 +1,303 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string        { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string   { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	transport.Transporter
+	operation string
+	header    headerCarrier
+}
+
+func (tr *Transport) Operation() string               { return tr.operation }
+func (tr *Transport) RequestHeader() transport.Header { return tr.header }
+
+type HTTPTransport struct {
+	Transport
+	request *http.Request
+}
+
+func (tr *HTTPTransport) Request() *http.Request { return tr.request }
+func (tr *HTTPTransport) PathTemplate() string   { return tr.operation }
+
+func newContext(operation string, header ...string) context.Context {
+	h := headerCarrier{}
+	for i := 0; i+1 < len(header); i += 2 {
+		h.Add(header[i], header[i+1])
+	}
+	return transport.NewServerContext(context.Background(), &Transport{operation: operation, header: h})
+}
+
+type request struct {
+	ID int `json:"id"`
+}
+
+// blocking returns a handler blocking until release is closed, it counts
+// its executions and signals each start on started.
+func blocking(release <-chan struct{}, started chan<- any, calls *atomic.Int64) func(context.Context, any) (any, error) {
+	return func(ctx context.Context, req any) (any, error) {
+		calls.Add(1)
+		started <- req
+		select {
+		case <-release:
+			return req, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func TestCoalesced(t *testing.T) {
+	var (
+		calls   atomic.Int64
+		wg      sync.WaitGroup
+		release = make(chan struct{})
+		started = make(chan any, 10)
+	)
+	h := Server(WithOperations("/test.Service/Get"))(blocking(release, started, &calls))
+	const requests = 10
+	replies := make(chan any, requests)
+	call := func() {
+		defer wg.Done()
+		reply, err := h(newContext("/test.Service/Get"), &request{ID: 1})
+		if err != nil {
+			t.Error(err)
+		}
+		replies <- reply
+	}
+	wg.Add(1)
+	go call()
+	<-started
+	for i := 1; i < requests; i++ {
+		wg.Add(1)
+		go call()
+	}
+	// let the followers join the execution in flight
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 execution, got %d", got)
+	}
+	first := <-replies
+	for i := 1; i < requests; i++ {
+		if reply := <-replies; reply != first {
+			t.Errorf("expected the shared reply %v, got %v", first, reply)
+		}
+	}
+
+	// a later request executes again
+	release = make(chan struct{})
+	close(release)
+	h = Server(WithOperations("/test.Service/Get"))(blocking(release, started, &calls))
+	if _, err := h(newContext("/test.Service/Get"), &request{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 executions, got %d", got)
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	var (
+		calls   atomic.Int64
+		wg      sync.WaitGroup
+		release = make(chan struct{})
+		started = make(chan any, 10)
+	)
+	h := Server(WithOperations("/test.Service/*"))(blocking(release, started, &calls))
+	for _, tt := range []struct {
+		operation string
+		id        int
+	}{
+		{"/test.Service/Get", 1},
+		{"/test.Service/Get", 2},
+		{"/test.Service/List", 1},
+	} {
+		tt := tt
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := h(newContext(tt.operation), &request{ID: tt.id}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	// every distinct request executes concurrently
+	for i := 0; i < 3; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatalf("expected 3 executions in flight, got %d", i)
+		}
+	}
+	close(release)
+	wg.Wait()
+}
+
+func TestLeaderCanceled(t *testing.T) {
+	var (
+		calls   atomic.Int64
+		release = make(chan struct{})
+		started = make(chan any, 10)
+	)
+	h := Server(WithOperations("/test.Service/Get"))(blocking(release, started, &calls))
+	ctx, cancel := context.WithCancel(newContext("/test.Service/Get"))
+	leader := make(chan error, 1)
+	go func() {
+		_, err := h(ctx, &request{ID: 1})
+		leader <- err
+	}()
+	<-started
+	follower := make(chan error, 1)
+	go func() {
+		_, err := h(newContext("/test.Service/Get"), &request{ID: 1})
+		follower <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the leader canceled, got %v", err)
+	}
+	// the follower still waits for the shared execution
+	close(release)
+	if err := <-follower; err != nil {
+		t.Errorf("expected the follower served, got %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 execution, got %d", got)
+	}
+}
+
+func TestAllCanceled(t *testing.T) {
+	var calls atomic.Int64
+	canceled := make(chan error, 1)
+	h := Server(WithOperations("/test.Service/Get"))(func(ctx context.Context, req any) (any, error) {
+		if calls.Add(1) > 1 {
+			return req, nil
+		}
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return nil, ctx.Err()
+	})
+	ctx, cancel := context.WithTimeout(newContext("/test.Service/Get"), 10*time.Millisecond)
+	defer cancel()
+	if _, err := h(ctx, &request{ID: 1}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	// the execution is canceled once nobody waits for it
+	if err := <-canceled; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the execution canceled, got %v", err)
+	}
+	if _, err := h(newContext("/test.Service/Get"), &request{ID: 1}); err != nil {
+		t.Errorf("expected a new execution, got %v", err)
+	}
+}
+
+func TestDefaultMethods(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	started := make(chan any, 10)
+	h := Server()(blocking(release, started, &calls))
+	close(release)
+	for _, tt := range []struct {
+		ctx  context.Context
+		want bool
+	}{
+		{newContext("/test.Service/Get"), false},
+		{transport.NewServerContext(context.Background(), &HTTPTransport{
+			Transport: Transport{operation: "/test.Service/Get"},
+			request:   &http.Request{Method: http.MethodGet},
+		}), true},
+		{transport.NewServerContext(context.Background(), &HTTPTransport{
+			Transport: Transport{operation: "/test.Service/Create"},
+			request:   &http.Request{Method: http.MethodPost},
+		}), false},
+	} {
+		tr, _ := transport.FromServerContext(tt.ctx)
+		if got := (&options{}).match(tr); got != tt.want {
+			t.Errorf("%s: expected coalesced %v, got %v", tr.Operation(), tt.want, got)
+		}
+		if _, err := h(tt.ctx, &request{ID: 1}); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPanic(t *testing.T) {
+	h := Server(WithOperations("/test.Service/Get"))(func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("expected the panic raised again, got %v", p)
+		}
+	}()
+	_, _ = h(newContext("/test.Service/Get"), &request{ID: 1})
+}
+
+func TestDefaultKeyIdentity(t *testing.T) {
+	key := func(header ...string) string {
+		k, err := DefaultKey(newContext("/test.Service/Me", header...), "/test.Service/Me", &request{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	anonymous := key()
+	alice := key("Authorization", "Bearer alice")
+	bob := key("Authorization", "Bearer bob")
+	if alice == bob || alice == anonymous {
+		t.Errorf("expected the callers keyed apart, got %s %s %s", anonymous, alice, bob)
+	}
+	if key("Authorization", "Bearer alice") != alice {
+		t.Error("expected the same caller keyed alike")
+	}
+	if key("Cookie", "session=alice") == key("Cookie", "session=bob") {
+		t.Error("expected the cookies keyed apart")
+	}
+	if key("X-Request-Id", "1") != anonymous {
+		t.Error("expected the other headers ignored")
+	}
+}
+
+func TestPanicAllCanceled(t *testing.T) {
+	release := make(chan struct{})
+	done := make(chan struct{})
+	h := Server(WithOperations("/test.Service/Get"))(func(context.Context, any) (any, error) {
+		defer close(done)
+		<-release
+		panic("boom")
+	})
+	ctx, cancel := context.WithCancel(newContext("/test.Service/Get"))
+	cancel()
+	if _, err := h(ctx, &request{ID: 1}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	// the panic of the abandoned execution is logged, not raised
+	close(release)
+	<-done
+}
@@ -0,0 +1,104 @@
+package deadline
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	ic "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// ErrDeadlineRequired is returned when a request carries no deadline and RequireDeadline is set.
+var ErrDeadlineRequired = errors.BadRequest("DEADLINE_REQUIRED", "request deadline is required")
+
+// Option is deadline option.
+type Option func(*options)
+
+// WithDefault set the deadline applied to requests, default is 5s.
+// A request that already carries an earlier deadline keeps it.
+func WithDefault(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// RequireDeadline rejects requests without a deadline instead of applying the default.
+func RequireDeadline() Option {
+	return func(o *options) {
+		o.require = true
+	}
+}
+
+type options struct {
+	timeout time.Duration
+	require bool
+	header  string
+}
+
+// Server is a server middleware that bounds every request with a deadline.
+// RequireDeadline checks the deadline of the request as received, e.g. of
+// grpc-timeout or of the header set by Propagate, before the Timeout of the
+// HTTP and gRPC servers is applied.
+func Server(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			if o.require && !o.requested(ctx) {
+				return nil, ErrDeadlineRequired
+			}
+			return o.bound(handler)(ctx, req)
+		}
+	}
+}
+
+// Client is a client middleware that bounds every outgoing call with a deadline,
+// which is then propagated to the remote server by the transport.
+func Client(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			if _, ok := ctx.Deadline(); !ok && o.require {
+				return nil, ErrDeadlineRequired
+			}
+			return o.bound(handler)(ctx, req)
+		}
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		timeout: 5 * time.Second,
+		header:  DefaultHeader,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// requested reports whether the request carried a deadline when received.
+func (o *options) requested(ctx context.Context) bool {
+	if _, ok := ic.RequestDeadline(ctx); ok {
+		return true
+	}
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		_, ok = decodeTimeout(tr.RequestHeader().Get(o.header))
+		return ok
+	}
+	return false
+}
+
+// bound applies the default timeout to the calls of handler.
+func (o *options) bound(handler middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req any) (any, error) {
+		if o.timeout > 0 {
+			// context.WithTimeout keeps the parent deadline when it is earlier.
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, o.timeout)
+			defer cancel()
+		}
+		return handler(ctx, req)
+	}
+}
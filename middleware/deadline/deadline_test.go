@@ -0,0 +1,158 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func TestServerDefaultDeadline(t *testing.T) {
+	var (
+		deadline time.Time
+		ok       bool
+	)
+	next := func(ctx context.Context, _ any) (any, error) {
+		deadline, ok = ctx.Deadline()
+		return "reply", nil
+	}
+	start := time.Now()
+	reply, err := Server(WithDefault(time.Second))(next)(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "reply" {
+		t.Errorf("expect reply, got %v", reply)
+	}
+	if !ok {
+		t.Fatal("expected default deadline to be applied")
+	}
+	if deadline.Before(start.Add(time.Second)) || deadline.After(time.Now().Add(time.Second)) {
+		t.Errorf("unexpected deadline %v", deadline)
+	}
+}
+
+func TestServerKeepsEarlierDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	next := func(ctx context.Context, _ any) (any, error) {
+		if got, _ := ctx.Deadline(); !got.Equal(want) {
+			t.Errorf("expect deadline %v, got %v", want, got)
+		}
+		return nil, nil
+	}
+	_, _ = Server(WithDefault(time.Minute))(next)(ctx, nil)
+}
+
+func TestServerShortensLaterDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	next := func(ctx context.Context, _ any) (any, error) {
+		if got, _ := ctx.Deadline(); time.Until(got) > time.Second {
+			t.Errorf("expect deadline within 1s, got %v", time.Until(got))
+		}
+		return nil, nil
+	}
+	_, _ = Server(WithDefault(time.Second))(next)(ctx, nil)
+}
+
+func TestServerRequireDeadline(t *testing.T) {
+	called := false
+	next := func(context.Context, any) (any, error) {
+		called = true
+		return nil, nil
+	}
+	_, err := Server(RequireDeadline())(next)(context.Background(), nil)
+	if !errors.Is(err, ErrDeadlineRequired) {
+		t.Errorf("expect %v, got %v", ErrDeadlineRequired, err)
+	}
+	if called {
+		t.Error("handler must not be called without deadline")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err = Server(RequireDeadline())(next)(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("handler must be called with deadline")
+	}
+}
+
+func TestClientDefaultDeadline(t *testing.T) {
+	next := func(ctx context.Context, _ any) (any, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected default deadline to be applied")
+		}
+		return nil, nil
+	}
+	_, _ = Client()(next)(context.Background(), nil)
+}
+
+type greeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (greeter) SayHello(_ context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	return &pb.HelloReply{Message: "hello " + in.Name}, nil
+}
+
+func TestServerRequireDeadlineGRPC(t *testing.T) {
+	// the server Timeout applies before the middleware
+	srv := grpc.NewServer(grpc.Timeout(time.Second), grpc.Middleware(Server(RequireDeadline())))
+	pb.RegisterGreeterServer(srv, greeter{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	// no client timeout, so that the calls carry their own deadline only
+	conn, err := grpc.DialInsecure(context.Background(), grpc.WithEndpoint(u.Host), grpc.WithTimeout(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := pb.NewGreeterClient(conn)
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); !errors.Is(errors.FromError(err), ErrDeadlineRequired) {
+		t.Errorf("expect %v, got %v", ErrDeadlineRequired, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err = client.SayHello(ctx, &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Errorf("expect the call with a deadline served, got %v", err)
+	}
+}
+
+func TestServerRequireDeadlineHTTP(t *testing.T) {
+	srv := http.NewServer(http.Timeout(time.Second), http.Middleware(Server(RequireDeadline())))
+	pb.RegisterGreeterHTTPServer(srv, greeter{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	conn, err := http.NewClient(context.Background(), http.WithEndpoint(u.Host), http.WithMiddleware(Propagate()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := pb.NewGreeterHTTPClient(conn)
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); !errors.Is(err, ErrDeadlineRequired) {
+		t.Errorf("expect %v, got %v", ErrDeadlineRequired, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err = client.SayHello(ctx, &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Errorf("expect the call with a propagated deadline served, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package deadline
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	ic "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// DefaultHeader is the header carrying the remaining time of a request.
+const DefaultHeader = "X-Request-Timeout"
+
+// maxTimeoutValue is the largest value of a timeout, 8 digits at most.
+const maxTimeoutValue int64 = 100000000 - 1
+
+// WithHeader sets the header of Propagate, Receive and Server, default is
+// DefaultHeader.
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// Propagate is a client middleware setting the remaining time of the call
+// deadline in the request header, in the format of grpc-timeout, e.g.
+// "150m" for 150 milliseconds, so that the server budgets its own calls.
+// It works for HTTP, which does not propagate deadlines by itself, as well
+// as gRPC. A call whose deadline is exceeded fails without being sent.
+func Propagate(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			d, ok := ctx.Deadline()
+			if !ok {
+				return handler(ctx, req)
+			}
+			remaining := time.Until(d)
+			if remaining <= 0 {
+				return nil, context.DeadlineExceeded
+			}
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				tr.RequestHeader().Set(o.header, encodeTimeout(remaining))
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Receive is a server middleware applying the remaining time set in the
+// request header by Propagate as the request deadline. It applies to the
+// requests received without a deadline only, e.g. of grpc-timeout, and
+// can only shorten the Timeout of the server. An invalid header is ignored.
+func Receive(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			if _, ok := ic.RequestDeadline(ctx); ok {
+				return handler(ctx, req)
+			}
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			timeout, ok := decodeTimeout(tr.RequestHeader().Get(o.header))
+			if !ok {
+				return handler(ctx, req)
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return handler(ctx, req)
+		}
+	}
+}
+
+var timeoutUnits = []struct {
+	unit byte
+	d    time.Duration
+}{
+	{'n', time.Nanosecond},
+	{'u', time.Microsecond},
+	{'m', time.Millisecond},
+	{'S', time.Second},
+	{'M', time.Minute},
+	{'H', time.Hour},
+}
+
+// encodeTimeout encodes t in the smallest unit fitting 8 digits, rounded up.
+func encodeTimeout(t time.Duration) string {
+	for _, u := range timeoutUnits {
+		v := int64(t / u.d)
+		if t%u.d > 0 {
+			v++
+		}
+		if v <= maxTimeoutValue {
+			return strconv.FormatInt(v, 10) + string(u.unit)
+		}
+	}
+	return strconv.FormatInt(maxTimeoutValue, 10) + "H"
+}
+
+func decodeTimeout(s string) (time.Duration, bool) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || v < 0 {
+		return 0, false
+	}
+	for _, u := range timeoutUnits {
+		if u.unit == s[len(s)-1] {
+			// a timeout overflowing time.Duration is no deadline at all
+			return time.Duration(v) * u.d, v <= int64(math.MaxInt64/u.d)
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,135 @@
+package deadline
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	ic "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string) { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string) { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type Transport struct {
+	transport.Transporter
+	header headerCarrier
+}
+
+func (tr *Transport) RequestHeader() transport.Header { return tr.header }
+
+func TestPropagate(t *testing.T) {
+	tr := &Transport{header: headerCarrier{}}
+	ctx, cancel := context.WithTimeout(transport.NewClientContext(context.Background(), tr), time.Second)
+	defer cancel()
+	next := func(context.Context, any) (any, error) { return "reply", nil }
+	if _, err := Propagate()(next)(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := decodeTimeout(tr.header.Get(DefaultHeader))
+	if !ok || got > time.Second || got < 900*time.Millisecond {
+		t.Errorf("expected the remaining budget of 1s, got %q", tr.header.Get(DefaultHeader))
+	}
+
+	// no deadline, no header
+	tr = &Transport{header: headerCarrier{}}
+	if _, err := Propagate(WithHeader("x-budget"))(next)(transport.NewClientContext(context.Background(), tr), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.header) != 0 {
+		t.Errorf("expected no header without deadline, got %v", tr.header)
+	}
+
+	// an exceeded deadline is not sent
+	ctx, cancel = context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	if _, err := Propagate()(next)(ctx, nil); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestReceive(t *testing.T) {
+	serverContext := func(parent context.Context, timeout string) context.Context {
+		tr := &Transport{header: headerCarrier{}}
+		if timeout != "" {
+			tr.header.Set(DefaultHeader, timeout)
+		}
+		return transport.NewServerContext(parent, tr)
+	}
+	remaining := func(ctx context.Context) time.Duration {
+		var got time.Duration = -1
+		next := func(ctx context.Context, _ any) (any, error) {
+			if d, ok := ctx.Deadline(); ok {
+				got = time.Until(d)
+			}
+			return nil, nil
+		}
+		_, _ = Receive()(next)(ctx, nil)
+		return got
+	}
+	if got := remaining(serverContext(context.Background(), "100m")); got > 100*time.Millisecond || got < 50*time.Millisecond {
+		t.Errorf("expected the budget of 100ms applied, got %v", got)
+	}
+	if got := remaining(serverContext(context.Background(), "")); got != -1 {
+		t.Errorf("expected no deadline without header, got %v", got)
+	}
+	if got := remaining(serverContext(context.Background(), "100x")); got != -1 {
+		t.Errorf("expected an invalid header ignored, got %v", got)
+	}
+	// the header shortens the server timeout applied after the request is received
+	ctx, cancel := context.WithTimeout(ic.WithRequestDeadline(context.Background()), time.Hour)
+	defer cancel()
+	if got := remaining(serverContext(ctx, "100m")); got > 100*time.Millisecond {
+		t.Errorf("expected the budget of 100ms applied, got %v", got)
+	}
+	// a deadline the request was received with, e.g. of grpc-timeout, is kept
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if got := remaining(serverContext(ctx, "2S")); got <= 2*time.Second {
+		t.Errorf("expected the later deadline kept, got %v", got)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if got := remaining(serverContext(ctx, "1H")); got > time.Second {
+		t.Errorf("expected the earlier deadline kept, got %v", got)
+	}
+}
+
+func TestEncodeTimeout(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{150 * time.Microsecond, "150000n"},
+		{150 * time.Millisecond, "150000u"},
+		{time.Second, "1000000u"},
+		{90 * time.Second, "90000000u"},
+		{time.Hour, "3600000m"},
+		{200 * time.Hour, "720000S"},
+	}
+	for _, tt := range tests {
+		got := encodeTimeout(tt.d)
+		if got != tt.want {
+			t.Errorf("%v: expected %s, got %s", tt.d, tt.want, got)
+		}
+		if d, ok := decodeTimeout(got); !ok || d != tt.d {
+			t.Errorf("%s: expected %v, got %v", got, tt.d, d)
+		}
+	}
+	if _, ok := decodeTimeout("99999999H"); ok {
+		t.Error("expected an overflowing timeout ignored")
+	}
+}
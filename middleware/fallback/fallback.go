@@ -0,0 +1,121 @@
+package fallback
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	metricLabelOperation = "operation"
+	metricLabelCode      = "code"
+)
+
+// Fallback returns the degraded reply of a request whose handler failed.
+type Fallback func(ctx context.Context, req any) (any, error)
+
+// Option is fallback option.
+type Option func(*options)
+
+type options struct {
+	operations []string
+	codes      []int
+	degraded   metric.Int64Counter
+}
+
+// WithOperations degrades only the operations, default is every operation.
+// An operation ending with "*" matches every operation with that prefix,
+// e.g. "/helloworld.v1.Greeter/*".
+func WithOperations(operations ...string) Option {
+	return func(o *options) {
+		o.operations = operations
+	}
+}
+
+// WithCodes sets the error codes degraded to the fallback, default are
+// service unavailable and gateway timeout.
+func WithCodes(codes ...int) Option {
+	return func(o *options) {
+		o.codes = codes
+	}
+}
+
+// WithDegraded sets the counter of the degraded requests, labeled by
+// operation and code of the error.
+func WithDegraded(c metric.Int64Counter) Option {
+	return func(o *options) {
+		o.degraded = c
+	}
+}
+
+// Server is a server middleware returning the reply of fallback instead
+// of the matching errors of the handler.
+func Server(fallback Fallback, opts ...Option) middleware.Middleware {
+	return newMiddleware(fallback, opts, func(ctx context.Context) (transport.Transporter, bool) {
+		return transport.FromServerContext(ctx)
+	})
+}
+
+// Client is a client middleware returning the reply of fallback instead
+// of the matching errors of the call, e.g. for non-critical enrichment
+// calls. The reply of fallback must be of the type of the call reply.
+func Client(fallback Fallback, opts ...Option) middleware.Middleware {
+	return newMiddleware(fallback, opts, func(ctx context.Context) (transport.Transporter, bool) {
+		return transport.FromClientContext(ctx)
+	})
+}
+
+func newMiddleware(fallback Fallback, opts []Option, from func(context.Context) (transport.Transporter, bool)) middleware.Middleware {
+	o := &options{
+		codes: []int{http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			reply, err := handler(ctx, req)
+			if err == nil {
+				return reply, nil
+			}
+			var operation string
+			if info, ok := from(ctx); ok {
+				operation = info.Operation()
+			}
+			code := errors.Code(err)
+			if !o.match(operation) || !o.degrade(code) {
+				return reply, err
+			}
+			log.Warnf("fallback: degraded %s: %v", operation, err)
+			if o.degraded != nil {
+				o.degraded.Add(ctx, 1, metric.WithAttributes(
+					attribute.String(metricLabelOperation, operation),
+					attribute.String(metricLabelCode, strconv.Itoa(code)),
+				))
+			}
+			return fallback(ctx, req)
+		}
+	}
+}
+
+func (o *options) degrade(code int) bool {
+	for _, c := range o.codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *options) match(operation string) bool {
+	return len(o.operations) == 0 || matcher.Operation(operation, o.operations...)
+}
@@ -0,0 +1,90 @@
+package fallback
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	metrictest "github.com/go-kratos/kratos/v2/internal/testdata/metric"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct {
+	transport.Transporter
+	operation string
+}
+
+func (tr *Transport) Operation() string { return tr.operation }
+
+func defaults(context.Context, any) (any, error) { return "default", nil }
+
+func failing(err error) func(context.Context, any) (any, error) {
+	return func(context.Context, any) (any, error) { return nil, err }
+}
+
+func TestClient(t *testing.T) {
+	const operation = "/profile.v1.Profile/GetAvatar"
+	errUnavailable := errors.ServiceUnavailable("UNAVAILABLE", "")
+	tests := []struct {
+		name    string
+		opts    []Option
+		handler func(context.Context, any) (any, error)
+		reply   any
+		err     error
+	}{
+		{"success", nil, func(context.Context, any) (any, error) { return "reply", nil }, "reply", nil},
+		{"unavailable", nil, failing(errUnavailable), "default", nil},
+		{"grpc unavailable", nil, failing(status.Error(codes.Unavailable, "down")), "default", nil},
+		{"gateway timeout", nil, failing(errors.GatewayTimeout("TIMEOUT", "")), "default", nil},
+		{"not matching code", nil, failing(errors.BadRequest("INVALID", "")), nil, errors.BadRequest("INVALID", "")},
+		{"codes", []Option{WithCodes(404)}, failing(errors.NotFound("NOT_FOUND", "")), "default", nil},
+		{"codes not matching", []Option{WithCodes(404)}, failing(errUnavailable), nil, errUnavailable},
+		{"operations", []Option{WithOperations("/profile.v1.Profile/*")}, failing(errUnavailable), "default", nil},
+		{"operations not matching", []Option{WithOperations("/order.v1.Order/*")}, failing(errUnavailable), nil, errUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := transport.NewClientContext(context.Background(), &Transport{operation: operation})
+			reply, err := Client(defaults, test.opts...)(test.handler)(ctx, "req")
+			if test.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+			if reply != test.reply {
+				t.Errorf("expected %v, got %v", test.reply, reply)
+			}
+		})
+	}
+}
+
+func TestServerDegraded(t *testing.T) {
+	counter := &metrictest.Counter{}
+	m := Server(defaults, WithDegraded(counter))
+	ctx := transport.NewServerContext(context.Background(), &Transport{operation: "/test.Service/Call"})
+	for i := 0; i < 2; i++ {
+		if _, err := m(failing(errors.ServiceUnavailable("UNAVAILABLE", "")))(ctx, "req"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := m(defaults)(ctx, "req"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := counter.Count(attribute.String(metricLabelOperation, "/test.Service/Call"), attribute.String(metricLabelCode, "503")); n != 2 {
+		t.Errorf("expected 2 degraded requests, got %d", n)
+	}
+}
+
+func TestFallbackError(t *testing.T) {
+	errFallback := errors.InternalServer("FALLBACK", "")
+	fallback := func(context.Context, any) (any, error) { return nil, errFallback }
+	_, err := Server(fallback)(failing(errors.ServiceUnavailable("UNAVAILABLE", "")))(context.Background(), "req")
+	if !errors.Is(err, errFallback) {
+		t.Errorf("expected %v, got %v", errFallback, err)
+	}
+}
@@ -0,0 +1,151 @@
+package fault
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// DefaultReason is the reason of the injected errors without AbortReason.
+const DefaultReason = "FAULT_INJECTED"
+
+// Spec is the faults to inject, nothing is injected unless Enabled.
+type Spec struct {
+	Enabled bool   `json:"enabled"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Rule is a fault injected into the requests of its operations. An
+// operation ending with "*" matches every operation with that prefix,
+// e.g. "/helloworld.v1.Greeter/*", no operations match every operation.
+// A request is delayed with the probability DelayRate, from 0 to 1, and
+// then aborted with the probability AbortRate, failing with the error of
+// AbortCode without calling the handler. The first matching rule applies.
+type Rule struct {
+	Operations  []string      `json:"operations"`
+	Delay       time.Duration `json:"delay"`
+	DelayRate   float64       `json:"delay_rate"`
+	AbortCode   int           `json:"abort_code"`
+	AbortReason string        `json:"abort_reason"`
+	AbortRate   float64       `json:"abort_rate"`
+}
+
+// Option is fault injection option.
+type Option func(*options)
+
+type options struct {
+	spec   atomic.Pointer[Spec]
+	random func() float64
+}
+
+// WithSpec sets the faults to inject.
+func WithSpec(spec Spec) Option {
+	return func(o *options) {
+		o.spec.Store(&spec)
+	}
+}
+
+// WithConfig reads the faults to inject from the config key, scanned into
+// a Spec and reloaded on its changes, so that they are toggled without a
+// redeploy. An invalid spec disables the injection.
+func WithConfig(c config.Config, key string) Option {
+	return func(o *options) {
+		o.load(key, c.Value(key))
+		if err := c.Watch(key, func(_ string, value config.Value) {
+			o.load(key, value)
+		}); err != nil {
+			log.Warnf("fault: failed to watch %s: %v", key, err)
+		}
+	}
+}
+
+func (o *options) load(key string, value config.Value) {
+	spec := new(Spec)
+	if err := value.Scan(spec); err != nil {
+		if !errors.Is(err, config.ErrNotFound) {
+			log.Errorf("fault: invalid spec %s: %v", key, err)
+		}
+		spec = new(Spec)
+	}
+	o.spec.Store(spec)
+}
+
+// Server is a server middleware injecting faults, e.g. to test the
+// resilience of the clients of a service in staging. It injects nothing
+// by default.
+func Server(opts ...Option) middleware.Middleware {
+	return newMiddleware(opts, func(ctx context.Context) (transport.Transporter, bool) {
+		return transport.FromServerContext(ctx)
+	})
+}
+
+// Client is a client middleware injecting faults, e.g. to test the
+// timeouts, retries and breakers of a client in staging. It injects
+// nothing by default.
+func Client(opts ...Option) middleware.Middleware {
+	return newMiddleware(opts, func(ctx context.Context) (transport.Transporter, bool) {
+		return transport.FromClientContext(ctx)
+	})
+}
+
+func newMiddleware(opts []Option, from func(context.Context) (transport.Transporter, bool)) middleware.Middleware {
+	o := &options{random: rand.Float64}
+	o.spec.Store(new(Spec))
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			spec := o.spec.Load()
+			if !spec.Enabled {
+				return handler(ctx, req)
+			}
+			tr, ok := from(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			rule, ok := spec.match(tr.Operation())
+			if !ok {
+				return handler(ctx, req)
+			}
+			if rule.Delay > 0 && o.random() < rule.DelayRate {
+				t := time.NewTimer(rule.Delay)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return nil, ctx.Err()
+				case <-t.C:
+				}
+			}
+			if rule.AbortCode > 0 && o.random() < rule.AbortRate {
+				reason := rule.AbortReason
+				if reason == "" {
+					reason = DefaultReason
+				}
+				return nil, errors.New(rule.AbortCode, reason, "fault injected")
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func (s *Spec) match(operation string) (*Rule, bool) {
+	for i := range s.Rules {
+		if s.Rules[i].match(operation) {
+			return &s.Rules[i], true
+		}
+	}
+	return nil, false
+}
+
+func (r *Rule) match(operation string) bool {
+	return len(r.Operations) == 0 || matcher.Operation(operation, r.Operations...)
+}
@@ -0,0 +1,152 @@
+package fault
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/memory"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct {
+	transport.Transporter
+	operation string
+}
+
+func (tr *Transport) Operation() string { return tr.operation }
+
+func newContext(operation string) context.Context {
+	return transport.NewServerContext(context.Background(), &Transport{operation: operation})
+}
+
+func reply(context.Context, any) (any, error) { return "reply", nil }
+
+// withSequence samples with the values of seq in turn instead of random ones.
+func withSequence(seq ...float64) Option {
+	var i atomic.Int64
+	return func(o *options) {
+		o.random = func() float64 { return seq[int(i.Add(1)-1)%len(seq)] }
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	rules := []Rule{{AbortCode: 503, AbortRate: 1, Delay: time.Second, DelayRate: 1}}
+	for _, h := range []func(context.Context, any) (any, error){
+		Server()(reply),
+		Server(WithSpec(Spec{Rules: rules}))(reply),
+	} {
+		start := time.Now()
+		r, err := h(newContext("/test.Service/Call"), "req")
+		if err != nil || r != "reply" {
+			t.Errorf("expected the passthrough, got %v %v", r, err)
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Errorf("expected no delay, took %v", elapsed)
+		}
+	}
+}
+
+func TestDelay(t *testing.T) {
+	h := Server(WithSpec(Spec{Enabled: true, Rules: []Rule{
+		{Operations: []string{"/test.Service/*"}, Delay: 50 * time.Millisecond, DelayRate: 1},
+	}}))(reply)
+	start := time.Now()
+	if r, err := h(newContext("/test.Service/Call"), "req"); err != nil || r != "reply" {
+		t.Fatalf("unexpected reply %v %v", r, err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected a delay of 50ms, took %v", elapsed)
+	}
+
+	// the delay gives up with the context
+	ctx, cancel := context.WithTimeout(newContext("/test.Service/Call"), 10*time.Millisecond)
+	defer cancel()
+	if _, err := h(ctx, "req"); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// other operations are not delayed
+	start = time.Now()
+	_, _ = h(newContext("/test.Other/Call"), "req")
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("expected no delay, took %v", elapsed)
+	}
+}
+
+func TestAbort(t *testing.T) {
+	h := Server(
+		WithSpec(Spec{Enabled: true, Rules: []Rule{
+			{Operations: []string{"/test.Service/Call"}, AbortCode: 503, AbortRate: 0.25},
+			{Operations: []string{"/test.Service/Other"}, AbortCode: 500, AbortReason: "CHAOS", AbortRate: 1},
+		}}),
+		withSequence(0, 0.3, 0.6, 0.9),
+	)(reply)
+	var aborted int
+	for i := 0; i < 100; i++ {
+		_, err := h(newContext("/test.Service/Call"), "req")
+		if err != nil {
+			if !errors.IsServiceUnavailable(err) || errors.Reason(err) != DefaultReason {
+				t.Fatalf("unexpected error %v", err)
+			}
+			aborted++
+		}
+	}
+	if aborted != 25 {
+		t.Errorf("expected 25 aborted requests, got %d", aborted)
+	}
+	if _, err := h(newContext("/test.Service/Other"), "req"); errors.Code(err) != 500 || errors.Reason(err) != "CHAOS" {
+		t.Errorf("expected the CHAOS error, got %v", err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	h := Client(WithSpec(Spec{Enabled: true, Rules: []Rule{{AbortCode: 504, AbortRate: 1}}}))(reply)
+	ctx := transport.NewClientContext(context.Background(), &Transport{operation: "/test.Service/Call"})
+	if _, err := h(ctx, "req"); !errors.IsGatewayTimeout(err) {
+		t.Errorf("expected gateway timeout, got %v", err)
+	}
+	// a server request is not affected by the client middleware
+	if _, err := h(newContext("/test.Service/Call"), "req"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestWithConfig(t *testing.T) {
+	src := memory.NewSource(map[string]any{
+		"fault": map[string]any{
+			"enabled": false,
+			"rules":   []any{map[string]any{"abort_code": 503, "abort_rate": 1, "delay": "1ms", "delay_rate": 1}},
+		},
+	})
+	c := config.New(config.WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	h := Server(WithConfig(c, "fault"))(reply)
+	if _, err := h(newContext("/test.Service/Call"), "req"); err != nil {
+		t.Fatalf("expected the injection disabled, got %v", err)
+	}
+	if err := src.Set("fault.enabled", true); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := h(newContext("/test.Service/Call"), "req"); errors.IsServiceUnavailable(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the injection enabled after reload")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// a missing key injects nothing
+	if _, err := Server(WithConfig(c, "missing"))(reply)(newContext("/test.Service/Call"), "req"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
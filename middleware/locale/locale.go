@@ -0,0 +1,199 @@
+package locale
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// DefaultLocale is the locale of the requests without an accepted locale.
+const DefaultLocale = "en"
+
+// Catalog is the localized error messages by locale and error reason.
+type Catalog map[string]map[string]string
+
+// Option is locale option.
+type Option func(*options)
+
+type options struct {
+	fallback  string
+	supported []string
+	catalog   Catalog
+}
+
+// WithDefault sets the locale of the requests without an accepted, or
+// supported, locale. Default is DefaultLocale.
+func WithDefault(locale string) Option {
+	return func(o *options) {
+		o.fallback = locale
+	}
+}
+
+// WithSupported keeps only the accepted locales among the supported ones.
+// An accepted locale with a region falls back to its supported language,
+// e.g. "fr-CH" to "fr". By default every accepted locale is kept.
+func WithSupported(locales ...string) Option {
+	return func(o *options) {
+		o.supported = append(o.supported, locales...)
+	}
+}
+
+// WithCatalog localizes the messages of the errors returned by the handler
+// whose reason is in the catalog of the first preferred locale having it.
+func WithCatalog(c Catalog) Option {
+	return func(o *options) {
+		o.catalog = c
+	}
+}
+
+type localeKey struct{}
+
+// NewContext returns a new context carrying the preferred locales.
+func NewContext(ctx context.Context, locales []string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locales)
+}
+
+// FromContext returns the preferred locales of the request, most preferred first.
+func FromContext(ctx context.Context) (locales []string, ok bool) {
+	locales, ok = ctx.Value(localeKey{}).([]string)
+	return
+}
+
+// Preferred returns the most preferred locale of the request, or def
+// without any.
+func Preferred(ctx context.Context, def string) string {
+	if locales, ok := FromContext(ctx); ok && len(locales) > 0 {
+		return locales[0]
+	}
+	return def
+}
+
+// Server is a server middleware parsing the Accept-Language header into
+// the preferred locales of the request, ordered by quality, available to
+// the handler with FromContext. The list is never empty, it falls back to
+// the default locale.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{fallback: DefaultLocale}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			var accepted []string
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				accepted = Parse(strings.Join(tr.RequestHeader().Values("Accept-Language"), ","))
+			}
+			locales := o.filter(accepted)
+			reply, err := handler(NewContext(ctx, locales), req)
+			if err != nil && o.catalog != nil {
+				err = o.localize(locales, err)
+			}
+			return reply, err
+		}
+	}
+}
+
+// Parse returns the locales of an Accept-Language header value by
+// descending quality, the ones of equal quality in their order. The
+// locales of quality zero and the wildcard are left out.
+func Parse(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var ws []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(part, ";")
+		locale = strings.TrimSpace(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if !ok || strings.TrimSpace(k) != "q" {
+				continue
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil || f < 0 || f > 1 {
+				f = 0
+			}
+			q = f
+		}
+		if q > 0 {
+			ws = append(ws, weighted{locale: locale, q: q})
+		}
+	}
+	sort.SliceStable(ws, func(i, j int) bool {
+		return ws[i].q > ws[j].q
+	})
+	locales := make([]string, 0, len(ws))
+	for _, w := range ws {
+		locales = append(locales, w.locale)
+	}
+	return locales
+}
+
+// filter returns the supported locales among the accepted ones, without
+// duplicates, or the default locale without any.
+func (o *options) filter(accepted []string) []string {
+	var locales []string
+	add := func(locale string) {
+		for _, l := range locales {
+			if strings.EqualFold(l, locale) {
+				return
+			}
+		}
+		locales = append(locales, locale)
+	}
+	for _, locale := range accepted {
+		if len(o.supported) == 0 {
+			add(locale)
+			continue
+		}
+		if s, ok := o.match(locale); ok {
+			add(s)
+		}
+	}
+	if len(locales) == 0 {
+		return []string{o.fallback}
+	}
+	return locales
+}
+
+// match returns the supported locale of locale, itself or its language.
+func (o *options) match(locale string) (string, bool) {
+	for _, s := range o.supported {
+		if strings.EqualFold(s, locale) {
+			return s, true
+		}
+	}
+	if lang, _, ok := strings.Cut(locale, "-"); ok {
+		for _, s := range o.supported {
+			if strings.EqualFold(s, lang) {
+				return s, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (o *options) localize(locales []string, err error) error {
+	e := errors.FromError(err)
+	if e.Reason == errors.UnknownReason {
+		return err
+	}
+	for _, locale := range locales {
+		if msg, ok := o.catalog[locale][e.Reason]; ok {
+			localized := errors.Clone(e)
+			localized.Message = msg
+			return localized
+		}
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package locale
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type Transport struct {
+	transport.Transporter
+	header headerCarrier
+}
+
+func (tr *Transport) RequestHeader() transport.Header { return tr.header }
+
+func newContext(values ...string) context.Context {
+	header := headerCarrier{}
+	for _, v := range values {
+		header.Add("Accept-Language", v)
+	}
+	return transport.NewServerContext(context.Background(), &Transport{header: header})
+}
+
+func locales(t *testing.T, ctx context.Context, opts ...Option) []string {
+	t.Helper()
+	var got []string
+	next := func(ctx context.Context, _ any) (any, error) {
+		got, _ = FromContext(ctx)
+		return "reply", nil
+	}
+	if _, err := Server(opts...)(next)(ctx, "req"); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", []string{"fr-CH", "fr", "en", "de"}},
+		{"en;q=0.5, de, ja;q=0.8", []string{"de", "ja", "en"}},
+		{"en, de", []string{"en", "de"}},
+		{"en;q=0, de;q=0.1", []string{"de"}},
+		{"en;q=abc, de", []string{"de"}},
+		{" , en-US ;q=0.3", []string{"en-US"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	if got := locales(t, newContext("de;q=0.5, ja", "en-US;q=0.8")); !reflect.DeepEqual(got, []string{"ja", "en-US", "de"}) {
+		t.Errorf("expected the quality order, got %v", got)
+	}
+	// missing header
+	if got := locales(t, newContext()); !reflect.DeepEqual(got, []string{DefaultLocale}) {
+		t.Errorf("expected the default locale, got %v", got)
+	}
+	if got := locales(t, context.Background(), WithDefault("zh")); !reflect.DeepEqual(got, []string{"zh"}) {
+		t.Errorf("expected the custom default locale, got %v", got)
+	}
+}
+
+func TestServerSupported(t *testing.T) {
+	opts := []Option{WithSupported("en", "fr", "pt-BR"), WithDefault("en")}
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"fr-CH, fr;q=0.9, de;q=0.8", []string{"fr"}},
+		{"PT-br, en;q=0.1", []string{"pt-BR", "en"}},
+		{"pt-PT", []string{"en"}},
+		{"de, ja", []string{"en"}},
+	}
+	for _, tt := range tests {
+		if got := locales(t, newContext(tt.header), opts...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestServerCatalog(t *testing.T) {
+	catalog := Catalog{
+		"fr": {"USER_NOT_FOUND": "utilisateur introuvable"},
+		"de": {"USER_NOT_FOUND": "Benutzer nicht gefunden"},
+	}
+	next := func(context.Context, any) (any, error) {
+		return nil, errors.NotFound("USER_NOT_FOUND", "user not found")
+	}
+	h := Server(WithSupported("en", "fr", "de"), WithCatalog(catalog))(next)
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"de;q=0.5, fr", "utilisateur introuvable"},
+		{"en, de;q=0.5", "Benutzer nicht gefunden"},
+		{"ja", "user not found"},
+	}
+	for _, tt := range tests {
+		_, err := h(newContext(tt.header), "req")
+		e := errors.FromError(err)
+		if e.Message != tt.want || e.Reason != "USER_NOT_FOUND" || e.Code != http.StatusNotFound {
+			t.Errorf("%q: expected %q, got %v", tt.header, tt.want, err)
+		}
+	}
+}
+
+func TestPreferred(t *testing.T) {
+	if got := Preferred(context.Background(), "en"); got != "en" {
+		t.Errorf("expected en, got %s", got)
+	}
+	if got := Preferred(NewContext(context.Background(), []string{"fr", "en"}), "en"); got != "fr" {
+		t.Errorf("expected fr, got %s", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"google.golang.org/grpc/codes"
 
 	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/redact"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -19,8 +20,50 @@ type Redacter interface {
 	Redact() string
 }
 
+// Option is logging option.
+type Option func(*options)
+
+type options struct {
+	redactFields []string
+	redactSize   int
+}
+
+// WithRedactFields masks the given field paths of the logged args,
+// e.g. "password" or "user.ssn". Proto messages are matched by proto field
+// names and plain structs by json names.
+func WithRedactFields(paths ...string) Option {
+	return func(o *options) {
+		o.redactFields = paths
+	}
+}
+
+// WithRedactMaxSize caps the size of the redacted args, default is 4096 bytes.
+func WithRedactMaxSize(size int) Option {
+	return func(o *options) {
+		o.redactSize = size
+	}
+}
+
+func newExtractor(opts []Option) func(any) string {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.redactFields) == 0 {
+		return extractArgs
+	}
+	r := redact.New(o.redactFields, o.redactSize)
+	return func(req any) string {
+		if redacter, ok := req.(Redacter); ok {
+			return redacter.Redact()
+		}
+		return r.Redact(req)
+	}
+}
+
 // Server is an server logging middleware.
-func Server(logger log.Logger) middleware.Middleware {
+func Server(logger log.Logger, opts ...Option) middleware.Middleware {
+	extract := newExtractor(opts)
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req any) (reply any, err error) {
 			var (
@@ -48,7 +91,7 @@ func Server(logger log.Logger) middleware.Middleware {
 				"kind", "server",
 				"component", kind,
 				"operation", operation,
-				"args", extractArgs(req),
+				"args", extract(req),
 				"code", code,
 				"reason", reason,
 				"stack", stack,
@@ -60,7 +103,8 @@ func Server(logger log.Logger) middleware.Middleware {
 }
 
 // Client is a client logging middleware.
-func Client(logger log.Logger) middleware.Middleware {
+func Client(logger log.Logger, opts ...Option) middleware.Middleware {
+	extract := newExtractor(opts)
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req any) (reply any, err error) {
 			var (
@@ -88,7 +132,7 @@ func Client(logger log.Logger) middleware.Middleware {
 				"kind", "client",
 				"component", kind,
 				"operation", operation,
-				"args", extractArgs(req),
+				"args", extract(req),
 				"code", code,
 				"reason", reason,
 				"stack", stack,
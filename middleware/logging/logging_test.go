@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -46,7 +47,7 @@ func TestHTTP(t *testing.T) {
 
 	tests := []struct {
 		name string
-		kind func(logger log.Logger) middleware.Middleware
+		kind func(logger log.Logger, opts ...Option) middleware.Middleware
 		err  error
 		ctx  context.Context
 	}{
@@ -233,3 +234,36 @@ func TestClient_CallerPath(t *testing.T) {
 		t.Fatalf("middleware should have the same caller as log.Helper. middleware: %s, helper: %s", a[0][1], a[1][1])
 	}
 }
+
+type redactUser struct {
+	Name string `json:"name"`
+	SSN  string `json:"ssn"`
+}
+
+type redactRequest struct {
+	Username string     `json:"username"`
+	Password string     `json:"password"`
+	User     redactUser `json:"user"`
+}
+
+func TestRedactFields(t *testing.T) {
+	req := &redactRequest{
+		Username: "kratos",
+		Password: "secret",
+		User:     redactUser{Name: "go", SSN: "123-45-6789"},
+	}
+	for _, kind := range []func(log.Logger, ...Option) middleware.Middleware{Server, Client} {
+		bf := bytes.NewBuffer(nil)
+		next := func(context.Context, any) (any, error) { return "reply", nil }
+		_, _ = kind(log.NewStdLogger(bf), WithRedactFields("password", "user.ssn"))(next)(context.Background(), req)
+		args := bf.String()
+		if strings.Contains(args, "secret") || strings.Contains(args, "123-45-6789") {
+			t.Errorf("expected masked fields, got %s", args)
+		}
+		for _, want := range []string{`"password":"****"`, `"ssn":"****"`, `"username":"kratos"`, `"name":"go"`} {
+			if !strings.Contains(args, want) {
+				t.Errorf("expected %s in %s", want, args)
+			}
+		}
+	}
+}
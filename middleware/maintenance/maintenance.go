@@ -0,0 +1,122 @@
+package maintenance
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// DefaultRetryAfter is the Retry-After hint of the rejected requests
+// without Spec.RetryAfter.
+const DefaultRetryAfter = time.Minute
+
+// ErrUnderMaintenance is returned for the requests rejected during maintenance.
+var ErrUnderMaintenance = errors.ServiceUnavailable("UNDER_MAINTENANCE", "service under maintenance")
+
+// Spec is the maintenance mode, requests pass through unless Enabled.
+// Exempt adds operations kept live to the WithExempt ones, an operation
+// ending with "*" matches every operation with that prefix. Message
+// replaces the message of ErrUnderMaintenance.
+type Spec struct {
+	Enabled    bool          `json:"enabled"`
+	RetryAfter time.Duration `json:"retry_after"`
+	Message    string        `json:"message"`
+	Exempt     []string      `json:"exempt"`
+}
+
+// Option is maintenance mode option.
+type Option func(*options)
+
+type options struct {
+	spec   atomic.Pointer[Spec]
+	exempt []string
+}
+
+// WithSpec sets the maintenance mode.
+func WithSpec(spec Spec) Option {
+	return func(o *options) {
+		o.spec.Store(&spec)
+	}
+}
+
+// WithConfig reads the maintenance mode from the config key, scanned into
+// a Spec and reloaded on its changes, so that it is toggled without a
+// redeploy. An invalid spec disables the maintenance mode.
+func WithConfig(c config.Config, key string) Option {
+	return func(o *options) {
+		o.load(key, c.Value(key))
+		if err := c.Watch(key, func(_ string, value config.Value) {
+			o.load(key, value)
+		}); err != nil {
+			log.Warnf("maintenance: failed to watch %s: %v", key, err)
+		}
+	}
+}
+
+// WithExempt keeps the operations matched by the selectors live during
+// maintenance, e.g. the health checks and the admin endpoints:
+//   - '/grpc.health.v1.Health/*'
+//   - '/admin.v1.Admin/Status'
+func WithExempt(selectors ...string) Option {
+	return func(o *options) {
+		o.exempt = append(o.exempt, selectors...)
+	}
+}
+
+func (o *options) load(key string, value config.Value) {
+	spec := new(Spec)
+	if err := value.Scan(spec); err != nil {
+		if !errors.Is(err, config.ErrNotFound) {
+			log.Errorf("maintenance: invalid spec %s: %v", key, err)
+		}
+		spec = new(Spec)
+	}
+	o.spec.Store(spec)
+}
+
+// Server is a server middleware rejecting the requests of the non exempt
+// operations with ErrUnderMaintenance while the maintenance mode is
+// enabled. The Retry-After hint in seconds is set on the reply header and
+// in the error metadata "retry_after".
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{}
+	o.spec.Store(new(Spec))
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			spec := o.spec.Load()
+			if !spec.Enabled {
+				return handler(ctx, req)
+			}
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			if matcher.Operation(tr.Operation(), o.exempt...) || matcher.Operation(tr.Operation(), spec.Exempt...) {
+				return handler(ctx, req)
+			}
+			retryAfter := spec.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = DefaultRetryAfter
+			}
+			seconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+			tr.ReplyHeader().Set("Retry-After", seconds)
+			err := ErrUnderMaintenance.WithMetadata(map[string]string{"retry_after": seconds})
+			if spec.Message != "" {
+				err.Message = spec.Message
+			}
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/memory"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type Transport struct {
+	transport.Transporter
+	operation string
+	reply     headerCarrier
+}
+
+func (tr *Transport) Operation() string { return tr.operation }
+
+func (tr *Transport) ReplyHeader() transport.Header { return tr.reply }
+
+func call(h func(context.Context, any) (any, error), operation string) (*Transport, error) {
+	tr := &Transport{operation: operation, reply: headerCarrier{}}
+	_, err := h(transport.NewServerContext(context.Background(), tr), "req")
+	return tr, err
+}
+
+func reply(context.Context, any) (any, error) { return "reply", nil }
+
+func TestServer(t *testing.T) {
+	h := Server(
+		WithSpec(Spec{Enabled: true, RetryAfter: 90 * time.Second, Exempt: []string{"/admin.v1.Admin/Status"}}),
+		WithExempt("/grpc.health.v1.Health/*"),
+	)(reply)
+
+	tr, err := call(h, "/helloworld.v1.Greeter/SayHello")
+	if !errors.Is(err, ErrUnderMaintenance) || !errors.IsServiceUnavailable(err) {
+		t.Fatalf("expected %v, got %v", ErrUnderMaintenance, err)
+	}
+	if got := errors.FromError(err).Metadata["retry_after"]; got != "90" {
+		t.Errorf("expected retry_after 90, got %q", got)
+	}
+	if got := tr.reply.Get("Retry-After"); got != "90" {
+		t.Errorf("expected Retry-After 90, got %q", got)
+	}
+	for _, op := range []string{"/grpc.health.v1.Health/Check", "/admin.v1.Admin/Status"} {
+		if tr, err := call(h, op); err != nil || tr.reply.Get("Retry-After") != "" {
+			t.Errorf("%s: expected exempt, got %v", op, err)
+		}
+	}
+}
+
+func TestServerMessage(t *testing.T) {
+	h := Server(WithSpec(Spec{Enabled: true, Message: "back at 02:00 UTC"}))(reply)
+	tr, err := call(h, "/helloworld.v1.Greeter/SayHello")
+	if e := errors.FromError(err); e.Message != "back at 02:00 UTC" || e.Reason != ErrUnderMaintenance.Reason {
+		t.Errorf("expected the custom message, got %v", err)
+	}
+	if got := tr.reply.Get("Retry-After"); got != "60" {
+		t.Errorf("expected the default Retry-After 60, got %q", got)
+	}
+	if ErrUnderMaintenance.Message != "service under maintenance" {
+		t.Errorf("expected ErrUnderMaintenance unchanged, got %q", ErrUnderMaintenance.Message)
+	}
+}
+
+func TestServerDisabled(t *testing.T) {
+	for _, h := range []func(context.Context, any) (any, error){
+		Server()(reply),
+		Server(WithSpec(Spec{Exempt: []string{"/admin.v1.Admin/*"}}))(reply),
+	} {
+		if _, err := call(h, "/helloworld.v1.Greeter/SayHello"); err != nil {
+			t.Errorf("expected passthrough, got %v", err)
+		}
+	}
+}
+
+func TestWithConfig(t *testing.T) {
+	src := memory.NewSource(map[string]any{
+		"maintenance": map[string]any{
+			"enabled":     false,
+			"retry_after": "30s",
+		},
+	})
+	c := config.New(config.WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	h := Server(WithConfig(c, "maintenance"), WithExempt("/grpc.health.v1.Health/*"))(reply)
+	if _, err := call(h, "/helloworld.v1.Greeter/SayHello"); err != nil {
+		t.Fatalf("expected passthrough, got %v", err)
+	}
+	if err := src.Set("maintenance.enabled", true); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		tr, err := call(h, "/helloworld.v1.Greeter/SayHello")
+		if errors.Is(err, ErrUnderMaintenance) {
+			if got := tr.reply.Get("Retry-After"); got != "30" {
+				t.Errorf("expected Retry-After 30, got %q", got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the maintenance mode enabled after reload")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := call(h, "/grpc.health.v1.Health/Check"); err != nil {
+		t.Errorf("expected the health check exempt, got %v", err)
+	}
+
+	// a missing key is no maintenance
+	if _, err := call(Server(WithConfig(c, "missing"))(reply), "/helloworld.v1.Greeter/SayHello"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -13,8 +13,10 @@ import (
 type Option func(*options)
 
 type options struct {
-	prefix []string
-	md     metadata.Metadata
+	prefix     []string
+	md         metadata.Metadata
+	operations []operation
+	override   bool
 }
 
 func (o *options) hasPrefix(key string) bool {
@@ -79,6 +81,7 @@ func Client(opts ...Option) middleware.Middleware {
 	for _, o := range opts {
 		o(options)
 	}
+	operations := options.operationMatcher()
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req any) (reply any, err error) {
 			tr, ok := transport.FromClientContext(ctx)
@@ -110,6 +113,12 @@ func Client(opts ...Option) middleware.Middleware {
 					}
 				}
 			}
+			// per operation constants
+			if operations != nil {
+				if ms := operations.Match(tr.Operation()); len(ms) > 0 {
+					return middleware.Chain(ms...)(handler)(ctx, req)
+				}
+			}
 			return handler(ctx, req)
 		}
 	}
@@ -0,0 +1,67 @@
+package metadata
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type operation struct {
+	selector string
+	md       metadata.Metadata
+}
+
+// WithOperation with constant metadata sent by the client with the requests
+// of the operations matched by selector, e.g. a priority class:
+//   - '/*'
+//   - '/helloworld.v1.Greeter/*'
+//   - '/helloworld.v1.Greeter/SayHello'
+//
+// A key already set on the request keeps its values unless WithOverride.
+func WithOperation(selector string, md metadata.Metadata) Option {
+	return func(o *options) {
+		o.operations = append(o.operations, operation{selector: selector, md: md})
+	}
+}
+
+// WithOverride with the operation metadata overriding the values already set on the request.
+func WithOverride() Option {
+	return func(o *options) {
+		o.override = true
+	}
+}
+
+// operationMatcher returns the matcher of the operation metadata, or nil without any.
+func (o *options) operationMatcher() matcher.Matcher {
+	if len(o.operations) == 0 {
+		return nil
+	}
+	m := matcher.New()
+	for _, op := range o.operations {
+		m.Add(op.selector, inject(op.md, o.override))
+	}
+	return m
+}
+
+func inject(md metadata.Metadata, override bool) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				header := tr.RequestHeader()
+				for k, vList := range md {
+					if len(vList) == 0 || (!override && header.Get(k) != "") {
+						continue
+					}
+					header.Set(k, vList[0])
+					for _, v := range vList[1:] {
+						header.Add(k, v)
+					}
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type operationTransport struct {
+	testTransport
+	operation string
+}
+
+func (tr *operationTransport) Operation() string { return tr.operation }
+
+func TestClientWithOperation(t *testing.T) {
+	const priorityKey = "x-md-local-priority"
+	opts := []Option{
+		WithOperation("/helloworld.v1.Greeter/*", metadata.Metadata{priorityKey: {"low"}}),
+		WithOperation("/helloworld.v1.Greeter/SayHello", metadata.Metadata{priorityKey: {"high"}}),
+	}
+	call := func(operation string, clientMD metadata.Metadata, opts ...Option) transport.Header {
+		t.Helper()
+		ctx := context.Background()
+		if clientMD != nil {
+			ctx = metadata.NewClientContext(ctx, clientMD)
+		}
+		tr := &operationTransport{testTransport: testTransport{headerCarrier{}}, operation: operation}
+		ctx = transport.NewClientContext(ctx, tr)
+		next := func(context.Context, any) (any, error) { return "reply", nil }
+		if _, err := Client(opts...)(next)(ctx, "req"); err != nil {
+			t.Fatal(err)
+		}
+		return tr.header
+	}
+
+	tests := []struct {
+		name      string
+		operation string
+		clientMD  metadata.Metadata
+		opts      []Option
+		want      []string
+	}{
+		{"exact", "/helloworld.v1.Greeter/SayHello", nil, opts, []string{"high"}},
+		{"prefix", "/helloworld.v1.Greeter/SayBye", nil, opts, []string{"low"}},
+		{"passthrough", "/other.v1.Service/Call", nil, opts, nil},
+		{"keep existing", "/helloworld.v1.Greeter/SayHello", metadata.Metadata{priorityKey: {"custom"}}, opts, []string{"custom"}},
+		{"override", "/helloworld.v1.Greeter/SayHello", metadata.Metadata{priorityKey: {"custom"}}, append([]Option{WithOverride()}, opts...), []string{"high"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := call(tt.operation, tt.clientMD, tt.opts...)
+			if got := header.Values(priorityKey); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import "fmt"
+
+// Ordered is a Middleware declaring its name and the names of the
+// middleware which must run before it.
+type Ordered struct {
+	Name       string
+	After      []string
+	Middleware Middleware
+}
+
+// Order declares m as name, which must run after the middleware named after,
+// e.g. Order("authz", authz, "auth").
+func Order(name string, m Middleware, after ...string) Ordered {
+	return Ordered{Name: name, After: after, Middleware: m}
+}
+
+// ValidateOrder returns an error if the chain ms violates a declared
+// ordering. A dependency missing from the chain is not a violation, only
+// the relative order of the chained middleware is checked.
+func ValidateOrder(ms ...Ordered) error {
+	index := make(map[string]int, len(ms))
+	for i, m := range ms {
+		if m.Name == "" {
+			continue
+		}
+		if _, ok := index[m.Name]; ok {
+			return fmt.Errorf("middleware: duplicate middleware %q", m.Name)
+		}
+		index[m.Name] = i
+	}
+	for i, m := range ms {
+		for _, after := range m.After {
+			if j, ok := index[after]; ok && j > i {
+				return fmt.Errorf("middleware: %q must run after %q", m.Name, after)
+			}
+		}
+	}
+	return nil
+}
+
+// ChainOrdered returns the Chain of ms, or an error if their declared
+// ordering is violated.
+func ChainOrdered(ms ...Ordered) (Middleware, error) {
+	if err := ValidateOrder(ms...); err != nil {
+		return nil, err
+	}
+	chain := make([]Middleware, 0, len(ms))
+	for _, m := range ms {
+		chain = append(chain, m.Middleware)
+	}
+	return Chain(chain...), nil
+}
+
+// MustChainOrdered is like ChainOrdered but panics if the declared ordering
+// is violated, to fail at startup.
+func MustChainOrdered(ms ...Ordered) Middleware {
+	m, err := ChainOrdered(ms...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
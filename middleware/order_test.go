@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func recordMiddleware(name string, calls *[]string) Middleware {
+	return func(handler Handler) Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			*calls = append(*calls, name)
+			return handler(ctx, req)
+		}
+	}
+}
+
+func TestChainOrdered(t *testing.T) {
+	var calls []string
+	m, err := ChainOrdered(
+		Order("tracing", recordMiddleware("tracing", &calls)),
+		Order("auth", recordMiddleware("auth", &calls)),
+		Order("logging", recordMiddleware("logging", &calls), "tracing"),
+		Order("authz", recordMiddleware("authz", &calls), "auth", "missing"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m(func(context.Context, any) (any, error) { return "reply", nil })(context.Background(), "req"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"tracing", "auth", "logging", "authz"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("expect %v, got %v", want, calls)
+	}
+}
+
+func TestChainOrderedViolated(t *testing.T) {
+	var calls []string
+	_, err := ChainOrdered(
+		Order("authz", recordMiddleware("authz", &calls), "auth"),
+		Order("auth", recordMiddleware("auth", &calls)),
+	)
+	if err == nil || err.Error() != `middleware: "authz" must run after "auth"` {
+		t.Errorf("expect ordering error, got %v", err)
+	}
+	if err = ValidateOrder(Order("auth", nil), Order("auth", nil)); err == nil {
+		t.Error("expect duplicate error")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expect panic")
+		}
+	}()
+	MustChainOrdered(
+		Order("logging", recordMiddleware("logging", &calls), "tracing"),
+		Order("tracing", recordMiddleware("tracing", &calls)),
+	)
+}
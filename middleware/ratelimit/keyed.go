@@ -0,0 +1,183 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// KeyFunc returns the client identity of a request, e.g. an API key,
+// a tenant or the client IP. Requests with an empty key are not limited.
+type KeyFunc func(ctx context.Context, req any) string
+
+// KeyFromHeader returns a KeyFunc reading the request header key, e.g. "x-api-key".
+func KeyFromHeader(key string) KeyFunc {
+	return func(ctx context.Context, _ any) string {
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			return tr.RequestHeader().Get(key)
+		}
+		return ""
+	}
+}
+
+// KeyFromIP returns a KeyFunc reading the remote IP of HTTP and gRPC clients.
+func KeyFromIP() KeyFunc {
+	return func(ctx context.Context, _ any) string {
+		var addr string
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			if ht, ok := tr.(interface{ Request() *http.Request }); ok {
+				addr = ht.Request().RemoteAddr
+			}
+		}
+		if addr == "" {
+			if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+				addr = p.Addr.String()
+			}
+		}
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			return host
+		}
+		return addr
+	}
+}
+
+// KeyOption is keyed ratelimit option.
+type KeyOption func(*keyOptions)
+
+type keyOptions struct {
+	key     KeyFunc
+	rate    float64
+	burst   int
+	maxKeys int
+	now     func() time.Time
+}
+
+// WithKeyFunc sets the client identity extractor, default is KeyFromIP.
+func WithKeyFunc(fn KeyFunc) KeyOption {
+	return func(o *keyOptions) {
+		o.key = fn
+	}
+}
+
+// WithRate sets the requests per second allowed for each key and the burst
+// size of its token bucket, default is 100 requests per second with a burst of 100.
+func WithRate(rate float64, burst int) KeyOption {
+	return func(o *keyOptions) {
+		o.rate = rate
+		o.burst = burst
+	}
+}
+
+// WithMaxKeys bounds the number of tracked keys, the least recently used
+// key is evicted beyond it. Default is 10000.
+func WithMaxKeys(n int) KeyOption {
+	return func(o *keyOptions) {
+		o.maxKeys = n
+	}
+}
+
+// KeyServer is a server ratelimiter middleware maintaining an independent
+// token bucket per client identity, so a single abusive client cannot starve
+// the others. Rejected requests fail with ErrLimitExceed carrying a
+// Retry-After hint, which is also set on the reply header.
+func KeyServer(opts ...KeyOption) middleware.Middleware {
+	o := &keyOptions{
+		key:     KeyFromIP(),
+		rate:    100,
+		burst:   100,
+		maxKeys: 10000,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	l := newKeyLimiter(o)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			key := o.key(ctx, req)
+			if key == "" {
+				return handler(ctx, req)
+			}
+			if wait, ok := l.allow(key); !ok {
+				retryAfter := strconv.Itoa(int(math.Ceil(wait.Seconds())))
+				if tr, ok := transport.FromServerContext(ctx); ok {
+					tr.ReplyHeader().Set("Retry-After", retryAfter)
+				}
+				return nil, ErrLimitExceed.WithMetadata(map[string]string{"retry_after": retryAfter})
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// bucket is a token bucket.
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// keyLimiter holds the token buckets of the most recently used keys.
+type keyLimiter struct {
+	opts *keyOptions
+
+	mu      sync.Mutex
+	lru     *list.List
+	buckets map[string]*list.Element
+}
+
+func newKeyLimiter(o *keyOptions) *keyLimiter {
+	return &keyLimiter{
+		opts:    o,
+		lru:     list.New(),
+		buckets: make(map[string]*list.Element),
+	}
+}
+
+// allow takes a token from the bucket of key, or returns how long to wait for one.
+func (l *keyLimiter) allow(key string) (time.Duration, bool) {
+	now := l.opts.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var b *bucket
+	if e, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(e)
+		b = e.Value.(*bucket)
+		b.tokens = math.Min(float64(l.opts.burst), b.tokens+now.Sub(b.last).Seconds()*l.opts.rate)
+		b.last = now
+	} else {
+		b = &bucket{key: key, tokens: float64(l.opts.burst), last: now}
+		l.buckets[key] = l.lru.PushFront(b)
+		for l.lru.Len() > l.opts.maxKeys {
+			oldest := l.lru.Back()
+			l.lru.Remove(oldest)
+			delete(l.buckets, oldest.Value.(*bucket).key)
+		}
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	if l.opts.rate <= 0 {
+		// the bucket never refills
+		return time.Second, false
+	}
+	return time.Duration((1 - b.tokens) / l.opts.rate * float64(time.Second)), false
+}
+
+// len returns the number of tracked keys.
+func (l *keyLimiter) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lru.Len()
+}
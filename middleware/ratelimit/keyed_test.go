@@ -0,0 +1,137 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string) { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string) { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type testTransport struct {
+	reqHeader   headerCarrier
+	replyHeader headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "/test" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.reqHeader }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.replyHeader }
+
+func newKeyContext(apiKey string) (context.Context, *testTransport) {
+	tr := &testTransport{reqHeader: headerCarrier{}, replyHeader: headerCarrier{}}
+	tr.reqHeader.Set("x-api-key", apiKey)
+	return transport.NewServerContext(context.Background(), tr), tr
+}
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func withClock(c *fakeClock) KeyOption {
+	return func(o *keyOptions) { o.now = c.now }
+}
+
+func next(context.Context, any) (any, error) { return "reply", nil }
+
+func TestKeyServerIsolation(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	h := KeyServer(WithKeyFunc(KeyFromHeader("x-api-key")), WithRate(1, 2), withClock(clock))(next)
+
+	abusive, _ := newKeyContext("abusive")
+	for i := 0; i < 2; i++ {
+		if _, err := h(abusive, nil); err != nil {
+			t.Fatalf("request %d: unexpected error %v", i, err)
+		}
+	}
+	if _, err := h(abusive, nil); !errors.Is(err, ErrLimitExceed) {
+		t.Fatalf("expected %v, got %v", ErrLimitExceed, err)
+	}
+	// other clients keep their own budget
+	other, _ := newKeyContext("other")
+	if _, err := h(other, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// requests without identity are not limited
+	anonymous, _ := newKeyContext("")
+	for i := 0; i < 5; i++ {
+		if _, err := h(anonymous, nil); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	// the bucket refills over time
+	clock.t = clock.t.Add(time.Second)
+	if _, err := h(abusive, nil); err != nil {
+		t.Fatalf("expected refill, got %v", err)
+	}
+}
+
+func TestKeyServerRetryAfter(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	h := KeyServer(WithKeyFunc(KeyFromHeader("x-api-key")), WithRate(0.25, 1), withClock(clock))(next)
+
+	ctx, tr := newKeyContext("client")
+	if _, err := h(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err := h(ctx, nil)
+	se := errors.FromError(err)
+	if se.Code != 429 || se.Metadata["retry_after"] != "4" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := tr.replyHeader.Get("Retry-After"); got != "4" {
+		t.Errorf("expected Retry-After 4, got %q", got)
+	}
+}
+
+func TestKeyLimiterEviction(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	l := newKeyLimiter(&keyOptions{rate: 1, burst: 1, maxKeys: 2, now: clock.now})
+
+	if _, ok := l.allow("a"); !ok {
+		t.Fatal("expected a allowed")
+	}
+	if _, ok := l.allow("b"); !ok {
+		t.Fatal("expected b allowed")
+	}
+	// a is exhausted and becomes the most recently used key
+	if _, ok := l.allow("a"); ok {
+		t.Fatal("expected a limited")
+	}
+	// b is the least recently used key
+	l.allow("c")
+	if _, ok := l.buckets["b"]; ok {
+		t.Fatal("expected b evicted")
+	}
+	if _, ok := l.buckets["a"]; !ok {
+		t.Fatal("expected a kept")
+	}
+	for i := 0; i < 10; i++ {
+		l.allow("key" + strconv.Itoa(i))
+		if n := l.len(); n > 2 {
+			t.Fatalf("expected at most 2 keys, got %d", n)
+		}
+	}
+	// the exhausted bucket of a was evicted, so a starts over with a full one
+	if _, ok := l.allow("a"); !ok {
+		t.Error("expected a allowed after eviction")
+	}
+}
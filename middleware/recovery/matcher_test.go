@@ -0,0 +1,64 @@
+package recovery
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+type parseError struct{ input string }
+
+func TestMatchers(t *testing.T) {
+	matchers := []Matcher{
+		func(rerr any) (*errors.Error, bool) {
+			if pe, ok := rerr.(parseError); ok {
+				return errors.BadRequest("INVALID_INPUT", "invalid input "+pe.input), true
+			}
+			return nil, false
+		},
+		func(rerr any) (*errors.Error, bool) {
+			if s, ok := rerr.(string); ok && strings.HasPrefix(s, "lib: ") {
+				return errors.BadRequest("LIB_REJECTED", s), true
+			}
+			return nil, false
+		},
+	}
+	tests := []struct {
+		name   string
+		panic  any
+		code   int
+		reason string
+	}{
+		{"typed", parseError{input: "x"}, 400, "INVALID_INPUT"},
+		{"second", "lib: unsupported charset", 400, "LIB_REJECTED"},
+		{"unmatched", "nil pointer", 500, "UNKNOWN"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			next := func(context.Context, any) (any, error) {
+				panic(tt.panic)
+			}
+			_, err := Recovery(WithMatchers(matchers...))(next)(context.Background(), "req")
+			e := errors.FromError(err)
+			if int(e.Code) != tt.code || e.Reason != tt.reason {
+				t.Errorf("expected %d %s, got %v", tt.code, tt.reason, err)
+			}
+		})
+	}
+}
+
+func TestMatchersFirstWins(t *testing.T) {
+	next := func(context.Context, any) (any, error) {
+		panic("boom")
+	}
+	_, err := Recovery(WithMatchers(
+		func(any) (*errors.Error, bool) { return errors.BadRequest("FIRST", ""), true },
+		func(any) (*errors.Error, bool) { return errors.Conflict("SECOND", ""), true },
+	))(next)(context.Background(), "req")
+	if e := errors.FromError(err); e.Reason != "FIRST" {
+		t.Errorf("expected the first matcher, got %v", err)
+	}
+}
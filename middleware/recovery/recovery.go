@@ -19,11 +19,17 @@ var ErrUnknownRequest = errors.InternalServer("UNKNOWN", "unknown request error"
 // HandlerFunc is recovery handler func.
 type HandlerFunc func(ctx context.Context, req, err any) error
 
+// Matcher translates a known panic, e.g. of a third-party library that
+// panics on certain inputs, into a precise error. It reports false for the
+// panics it does not know.
+type Matcher func(recovered any) (*errors.Error, bool)
+
 // Option is recovery option.
 type Option func(*options)
 
 type options struct {
-	handler HandlerFunc
+	handler  HandlerFunc
+	matchers []Matcher
 }
 
 // WithHandler with recovery handler.
@@ -33,6 +39,15 @@ func WithHandler(h HandlerFunc) Option {
 	}
 }
 
+// WithMatchers with the panic matchers, evaluated in order before the
+// handler. The first match is returned as the error, the unmatched panics
+// are passed to the handler.
+func WithMatchers(m ...Matcher) Option {
+	return func(o *options) {
+		o.matchers = append(o.matchers, m...)
+	}
+}
+
 // Recovery is a server middleware that recovers from any panics.
 func Recovery(opts ...Option) middleware.Middleware {
 	op := options{
@@ -53,10 +68,19 @@ func Recovery(opts ...Option) middleware.Middleware {
 					buf = buf[:n]
 					log.Context(ctx).Errorf("%v: %+v\n%s\n", rerr, req, buf)
 					ctx = context.WithValue(ctx, Latency{}, time.Since(startTime).Seconds())
-					err = op.handler(ctx, req, rerr)
+					err = op.recovered(ctx, req, rerr)
 				}
 			}()
 			return handler(ctx, req)
 		}
 	}
 }
+
+func (o *options) recovered(ctx context.Context, req, rerr any) error {
+	for _, m := range o.matchers {
+		if e, ok := m(rerr); ok && e != nil {
+			return e
+		}
+	}
+	return o.handler(ctx, req, rerr)
+}
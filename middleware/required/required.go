@@ -0,0 +1,131 @@
+package required
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// ReasonMissing is the reason of the error of a request missing required metadata.
+	ReasonMissing = "METADATA_MISSING"
+	// ReasonInvalid is the reason of the error of a request with an invalid metadata value.
+	ReasonInvalid = "METADATA_INVALID"
+)
+
+// Validator validates the value of a required metadata key.
+type Validator func(value string) error
+
+// OneOf accepts only the values.
+func OneOf(values ...string) Validator {
+	return func(value string) error {
+		for _, v := range values {
+			if v == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("want one of %s", strings.Join(values, ", "))
+	}
+}
+
+// Regexp accepts only the values matching re.
+func Regexp(re *regexp.Regexp) Validator {
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("want a match of %s", re)
+		}
+		return nil
+	}
+}
+
+// Option is required metadata option.
+type Option func(*options)
+
+type key struct {
+	name       string
+	validators []Validator
+}
+
+type options struct {
+	keys       []key
+	operations []string
+}
+
+// WithKey requires the metadata key, e.g. x-api-version, whose value must
+// pass the validators.
+func WithKey(name string, validators ...Validator) Option {
+	return func(o *options) {
+		o.keys = append(o.keys, key{name: name, validators: validators})
+	}
+}
+
+// WithOperations requires the metadata only of the operations matched by
+// the selectors, a trailing '*' matches a prefix:
+//   - '/helloworld.v1.Greeter/*'
+//   - '/helloworld.v1.Greeter/SayHello'
+//
+// Default is every operation.
+func WithOperations(selectors ...string) Option {
+	return func(o *options) {
+		o.operations = append(o.operations, selectors...)
+	}
+}
+
+// Server is a server middleware rejecting the requests missing a required
+// metadata key, or with a value failing its validators, before they reach
+// the handler. Missing keys are reported together in a bad request error
+// whose metadata "missing" lists them, an invalid value is reported with
+// the key in the metadata "key".
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || !o.match(tr.Operation()) {
+				return handler(ctx, req)
+			}
+			if err := o.check(tr.RequestHeader()); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func (o *options) check(header transport.Header) error {
+	var missing []string
+	for _, k := range o.keys {
+		if strings.TrimSpace(header.Get(k.name)) == "" {
+			missing = append(missing, k.name)
+		}
+	}
+	if len(missing) > 0 {
+		list := strings.Join(missing, ", ")
+		return errors.BadRequest(ReasonMissing, "missing metadata: "+list).
+			WithMetadata(map[string]string{"missing": list})
+	}
+	for _, k := range o.keys {
+		value := strings.TrimSpace(header.Get(k.name))
+		for _, validate := range k.validators {
+			if err := validate(value); err != nil {
+				return errors.BadRequest(ReasonInvalid, fmt.Sprintf("invalid metadata %s %q: %v", k.name, value, err)).
+					WithMetadata(map[string]string{"key": k.name}).
+					WithCause(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (o *options) match(operation string) bool {
+	return len(o.operations) == 0 || matcher.Operation(operation, o.operations...)
+}
@@ -0,0 +1,120 @@
+package required
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type Transport struct {
+	transport.Transporter
+	operation string
+	header    headerCarrier
+}
+
+func (tr *Transport) Operation() string { return tr.operation }
+
+func (tr *Transport) RequestHeader() transport.Header { return tr.header }
+
+func call(operation string, header map[string]string, opts ...Option) error {
+	hc := headerCarrier{}
+	for k, v := range header {
+		hc.Set(k, v)
+	}
+	ctx := transport.NewServerContext(context.Background(), &Transport{operation: operation, header: hc})
+	next := func(context.Context, any) (any, error) { return "reply", nil }
+	_, err := Server(opts...)(next)(ctx, "req")
+	return err
+}
+
+func TestServer(t *testing.T) {
+	opts := []Option{
+		WithKey("x-api-version", OneOf("v1", "v2")),
+		WithKey("x-trace-sampled", Regexp(regexp.MustCompile(`^[01]$`))),
+		WithKey("x-tenant"),
+	}
+	tests := []struct {
+		name     string
+		header   map[string]string
+		reason   string
+		metadata map[string]string
+	}{
+		{"all present", map[string]string{"x-api-version": "v2", "x-trace-sampled": "1", "x-tenant": "acme"}, "", nil},
+		{"one missing", map[string]string{"x-api-version": "v2", "x-tenant": "acme"}, ReasonMissing, map[string]string{"missing": "x-trace-sampled"}},
+		{"all missing", nil, ReasonMissing, map[string]string{"missing": "x-api-version, x-trace-sampled, x-tenant"}},
+		{"blank", map[string]string{"x-api-version": "v2", "x-trace-sampled": "1", "x-tenant": " "}, ReasonMissing, map[string]string{"missing": "x-tenant"}},
+		{"invalid one of", map[string]string{"x-api-version": "v3", "x-trace-sampled": "1", "x-tenant": "acme"}, ReasonInvalid, map[string]string{"key": "x-api-version"}},
+		{"invalid regexp", map[string]string{"x-api-version": "v1", "x-trace-sampled": "yes", "x-tenant": "acme"}, ReasonInvalid, map[string]string{"key": "x-trace-sampled"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := call("/helloworld.v1.Greeter/SayHello", tt.header, opts...)
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			e := errors.FromError(err)
+			if e.Code != http.StatusBadRequest || e.Reason != tt.reason {
+				t.Fatalf("expected bad request %s, got %v", tt.reason, err)
+			}
+			for k, v := range tt.metadata {
+				if e.Metadata[k] != v {
+					t.Errorf("expected metadata %s=%q, got %q", k, v, e.Metadata[k])
+				}
+			}
+		})
+	}
+}
+
+func TestServerWithOperations(t *testing.T) {
+	opts := []Option{
+		WithKey("x-api-version"),
+		WithOperations("/helloworld.v1.Greeter/*", "/admin.v1.Admin/Reset"),
+	}
+	tests := []struct {
+		operation string
+		required  bool
+	}{
+		{"/helloworld.v1.Greeter/SayHello", true},
+		{"/admin.v1.Admin/Reset", true},
+		{"/admin.v1.Admin/Status", false},
+		{"/other.v1.Service/Call", false},
+	}
+	for _, tt := range tests {
+		err := call(tt.operation, nil, opts...)
+		if got := errors.Reason(err) == ReasonMissing; got != tt.required {
+			t.Errorf("%s: expected required %v, got %v", tt.operation, tt.required, err)
+		}
+	}
+}
+
+func TestServerWithoutTransport(t *testing.T) {
+	next := func(context.Context, any) (any, error) { return "reply", nil }
+	if _, err := Server(WithKey("x-api-version"))(next)(context.Background(), "req"); err != nil {
+		t.Errorf("expected no error without transport, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is retry option.
+type Option func(*options)
+
+type options struct {
+	attempts  int
+	initial   time.Duration
+	max       time.Duration
+	retryable func(error) bool
+	throttle  *Throttle
+}
+
+// WithAttempts sets the maximum number of attempts of a request, including
+// the first one, default is 3.
+func WithAttempts(n int) Option {
+	return func(o *options) {
+		o.attempts = n
+	}
+}
+
+// WithBackoff sets the backoff between attempts, a random duration up to
+// initial doubled on each retry and capped by max. Default is 50ms up to 1s.
+func WithBackoff(initial, max time.Duration) Option {
+	return func(o *options) {
+		o.initial = initial
+		o.max = max
+	}
+}
+
+// WithRetryable sets the errors which are retried, default are the
+// service unavailable and gateway timeout errors.
+func WithRetryable(fn func(error) bool) Option {
+	return func(o *options) {
+		o.retryable = fn
+	}
+}
+
+// WithThrottle sets the retry budget, default is a budget of 10 tokens
+// refilled by 0.1 per success dedicated to the middleware.
+func WithThrottle(t *Throttle) Option {
+	return func(o *options) {
+		o.throttle = t
+	}
+}
+
+func retryable(err error) bool {
+	return errors.IsServiceUnavailable(err) || errors.IsGatewayTimeout(err)
+}
+
+// Client is a client middleware retrying the requests failing with a
+// retryable error. Retries are suppressed once the retry budget is exhausted,
+// the error of the last attempt is then returned. The request must be safe
+// to send again, it should run before the middleware adding request headers.
+// Every attempt carries its number in the context, see
+// transport.AttemptFromContext, for the middleware after it.
+func Client(opts ...Option) middleware.Middleware {
+	o := &options{
+		attempts:  3,
+		initial:   50 * time.Millisecond,
+		max:       time.Second,
+		retryable: retryable,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.throttle == nil {
+		o.throttle = NewThrottle(10, 0.1)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (reply any, err error) {
+			backoff := o.initial
+			for attempt := 1; ; attempt++ {
+				reply, err = handler(transport.NewAttemptContext(ctx, attempt-1), req)
+				if err == nil {
+					o.throttle.Success()
+					return reply, nil
+				}
+				if !o.retryable(err) {
+					return reply, err
+				}
+				o.throttle.Failure()
+				if attempt >= o.attempts || ctx.Err() != nil || !o.throttle.Allow() {
+					return reply, err
+				}
+				if backoff > 0 {
+					t := time.NewTimer(time.Duration(rand.Int63n(int64(backoff)) + 1))
+					select {
+					case <-ctx.Done():
+						t.Stop()
+						return reply, err
+					case <-t.C:
+					}
+				}
+				if backoff *= 2; backoff > o.max {
+					backoff = o.max
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package retry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestClient(t *testing.T) {
+	calls := 0
+	next := func(context.Context, any) (any, error) {
+		if calls++; calls == 1 {
+			return nil, errors.ServiceUnavailable("UNAVAILABLE", "")
+		}
+		return "ok", nil
+	}
+	reply, err := Client(WithBackoff(time.Millisecond, time.Millisecond))(next)(context.Background(), "req")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reply != "ok" {
+		t.Errorf("expected ok, got %v", reply)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestClientNotRetryable(t *testing.T) {
+	calls := 0
+	next := func(context.Context, any) (any, error) {
+		calls++
+		return nil, errors.BadRequest("BAD_REQUEST", "")
+	}
+	_, err := Client(WithBackoff(0, 0))(next)(context.Background(), "req")
+	if !errors.IsBadRequest(err) {
+		t.Errorf("expected bad request, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestClientAttempts(t *testing.T) {
+	calls := 0
+	next := func(context.Context, any) (any, error) {
+		calls++
+		return nil, errors.GatewayTimeout("TIMEOUT", "")
+	}
+	_, err := Client(WithAttempts(5), WithBackoff(0, 0), WithThrottle(NewThrottle(100, 1)))(next)(context.Background(), "req")
+	if !errors.IsGatewayTimeout(err) {
+		t.Errorf("expected gateway timeout, got %v", err)
+	}
+	if calls != 5 {
+		t.Errorf("expected 5 calls, got %d", calls)
+	}
+}
+
+func TestClientAttemptContext(t *testing.T) {
+	var attempts []int
+	next := func(ctx context.Context, _ any) (any, error) {
+		attempts = append(attempts, transport.AttemptFromContext(ctx))
+		return nil, errors.ServiceUnavailable("UNAVAILABLE", "")
+	}
+	_, _ = Client(WithAttempts(3), WithBackoff(0, 0))(next)(context.Background(), "req")
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(attempts, want) {
+		t.Errorf("expected attempts %v, got %v", want, attempts)
+	}
+}
+
+func TestClientThrottled(t *testing.T) {
+	calls := 0
+	next := func(context.Context, any) (any, error) {
+		calls++
+		return nil, errors.ServiceUnavailable("UNAVAILABLE", "")
+	}
+	h := Client(WithBackoff(0, 0), WithThrottle(NewThrottle(10, 0.1)))(next)
+	for i := 0; i < 10; i++ {
+		_, _ = h(context.Background(), "req")
+	}
+	// the budget is exhausted, a request is sent only once.
+	calls = 0
+	for i := 0; i < 10; i++ {
+		_, _ = h(context.Background(), "req")
+	}
+	if calls != 10 {
+		t.Errorf("expected 10 calls, got %d", calls)
+	}
+}
+
+func TestClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	next := func(context.Context, any) (any, error) {
+		calls++
+		cancel()
+		return nil, errors.ServiceUnavailable("UNAVAILABLE", "")
+	}
+	_, _ = Client(WithBackoff(time.Second, time.Second))(next)(ctx, "req")
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	th := NewThrottle(4, 0.5)
+	if !th.Allow() {
+		t.Fatal("expected a full budget to allow")
+	}
+	th.Failure()
+	th.Failure()
+	if th.Allow() {
+		t.Fatal("expected half of the budget to disallow")
+	}
+	th.Success()
+	if !th.Allow() {
+		t.Fatal("expected a success to refill the budget")
+	}
+	for i := 0; i < 10; i++ {
+		th.Failure()
+	}
+	for i := 0; i < 4; i++ {
+		th.Success()
+	}
+	if th.Allow() {
+		t.Fatal("expected the budget to be floored at zero")
+	}
+	th.Success()
+	if !th.Allow() {
+		t.Fatal("expected the budget to be refilled")
+	}
+}
@@ -0,0 +1,48 @@
+package retry
+
+import "sync"
+
+// Throttle is a retry budget following the gRPC retry throttling design
+// (gRFC A6). Every failed attempt takes a token and every successful one
+// gives back ratio of a token, retries are allowed only while more than
+// half of the tokens are left. Share one Throttle across the clients of a
+// backend so that retries stop amplifying a widespread failure.
+type Throttle struct {
+	mu     sync.Mutex
+	tokens float64
+	max    float64
+	ratio  float64
+}
+
+// NewThrottle returns a Throttle of maxTokens tokens, e.g. 10, refilled by
+// ratio for each success, e.g. 0.1.
+func NewThrottle(maxTokens, ratio float64) *Throttle {
+	return &Throttle{tokens: maxTokens, max: maxTokens, ratio: ratio}
+}
+
+// Allow reports whether the budget allows a retry.
+func (t *Throttle) Allow() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tokens > t.max/2
+}
+
+// Success records a successful attempt.
+func (t *Throttle) Success() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens += t.ratio
+	if t.tokens > t.max {
+		t.tokens = t.max
+	}
+}
+
+// Failure records a failed attempt.
+func (t *Throttle) Failure() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens--
+	if t.tokens < 0 {
+		t.tokens = 0
+	}
+}
@@ -0,0 +1,97 @@
+package safemethod
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// ReasonUnsafeMethod is the reason of the error of a mutating operation
+// mapped to a safe HTTP method.
+const ReasonUnsafeMethod = "UNSAFE_METHOD"
+
+// ErrUnsafeMethod is returned for a mutating operation called with a safe
+// HTTP method.
+var ErrUnsafeMethod = errors.New(http.StatusMethodNotAllowed, ReasonUnsafeMethod, "mutating operation mapped to a safe method")
+
+// Option is safe method option.
+type Option func(*options)
+
+type options struct {
+	operations []string
+	warn       bool
+}
+
+// WithMutating annotates the operations as mutating. An operation ending
+// with "*" matches every operation with that prefix, e.g.
+// "/helloworld.v1.Greeter/Delete*".
+func WithMutating(operations ...string) Option {
+	return func(o *options) {
+		o.operations = append(o.operations, operations...)
+	}
+}
+
+// WithWarn logs a warning once per operation and method instead of
+// rejecting the request, e.g. to find the faulty routes of a running
+// service before enforcing.
+func WithWarn() Option {
+	return func(o *options) {
+		o.warn = true
+	}
+}
+
+// Server is a server middleware rejecting with ErrUnsafeMethod the mutating
+// operations mapped to the safe HTTP methods GET, HEAD, OPTIONS and TRACE,
+// which the clients, proxies and browsers retry and prefetch freely. The
+// requests of the other transports pass.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var warned sync.Map
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			ht, ok := tr.(khttp.Transporter)
+			if !ok {
+				return handler(ctx, req)
+			}
+			method, operation := ht.Request().Method, tr.Operation()
+			if !Safe(method) || !o.mutating(operation) {
+				return handler(ctx, req)
+			}
+			if o.warn {
+				if _, loaded := warned.LoadOrStore(method+" "+operation, struct{}{}); !loaded {
+					log.Context(ctx).Warnf("[safemethod] mutating operation %s is mapped to %s", operation, method)
+				}
+				return handler(ctx, req)
+			}
+			return nil, errors.Newf(http.StatusMethodNotAllowed, ReasonUnsafeMethod,
+				"mutating operation %s is mapped to %s", operation, method)
+		}
+	}
+}
+
+// Safe reports whether method is a safe HTTP method.
+func Safe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func (o *options) mutating(operation string) bool {
+	return matcher.Operation(operation, o.operations...)
+}
@@ -0,0 +1,81 @@
+package safemethod
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct {
+	transport.Transporter
+	operation string
+	request   *http.Request
+}
+
+func (tr *Transport) Operation() string      { return tr.operation }
+func (tr *Transport) Request() *http.Request { return tr.request }
+func (tr *Transport) PathTemplate() string   { return tr.request.URL.Path }
+
+func newContext(method, operation string) context.Context {
+	req, _ := http.NewRequest(method, "http://127.0.0.1/v1/users/1", nil)
+	return transport.NewServerContext(context.Background(), &Transport{operation: operation, request: req})
+}
+
+func TestServer(t *testing.T) {
+	const (
+		get    = "/user.v1.User/GetUser"
+		del    = "/user.v1.User/DeleteUser"
+		update = "/user.v1.User/UpdateName"
+	)
+	m := Server(WithMutating(del, "/user.v1.User/Update*"))
+	tests := []struct {
+		name      string
+		method    string
+		operation string
+		rejected  bool
+	}{
+		{"read on GET", http.MethodGet, get, false},
+		{"delete on DELETE", http.MethodDelete, del, false},
+		{"update on POST", http.MethodPost, update, false},
+		{"delete on GET", http.MethodGet, del, true},
+		{"update on HEAD", http.MethodHead, update, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			_, err := m(func(context.Context, any) (any, error) {
+				called = true
+				return "ok", nil
+			})(newContext(tt.method, tt.operation), nil)
+			if tt.rejected {
+				if !errors.Is(err, ErrUnsafeMethod) || called {
+					t.Errorf("expected %v without calling the handler, got %v", ErrUnsafeMethod, err)
+				}
+				return
+			}
+			if err != nil || !called {
+				t.Errorf("expected the handler called, got %v", err)
+			}
+		})
+	}
+}
+
+func TestServerWarn(t *testing.T) {
+	called := 0
+	h := Server(WithMutating("/user.v1.User/DeleteUser"), WithWarn())(func(context.Context, any) (any, error) {
+		called++
+		return "ok", nil
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := h(newContext(http.MethodGet, "/user.v1.User/DeleteUser"), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if called != 2 {
+		t.Errorf("expected the requests passed, got %d", called)
+	}
+}
@@ -0,0 +1,145 @@
+package servertiming
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// DefaultTotal is the name of the segment timing the whole handler.
+const DefaultTotal = "total"
+
+// Header is the response header carrying the timings.
+const Header = "Server-Timing"
+
+// Segment is a named timing of a request.
+type Segment struct {
+	Name     string
+	Desc     string
+	Duration time.Duration
+}
+
+// Timings records the segments of a request, it is safe for concurrent use.
+type Timings struct {
+	mu       sync.Mutex
+	segments []Segment
+}
+
+// Record records a segment, the segments recorded with the same name are
+// all kept.
+func (t *Timings) Record(name string, d time.Duration, desc ...string) {
+	s := Segment{Name: name, Duration: d}
+	if len(desc) > 0 {
+		s.Desc = desc[0]
+	}
+	t.mu.Lock()
+	t.segments = append(t.segments, s)
+	t.mu.Unlock()
+}
+
+// Segments returns the recorded segments in their order.
+func (t *Timings) Segments() []Segment {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Segment(nil), t.segments...)
+}
+
+// String returns the value of the Server-Timing header, e.g.
+// `db;dur=12.5, cache;desc="hit";dur=0.3`, the durations in milliseconds.
+func (t *Timings) String() string {
+	var b strings.Builder
+	for i, s := range t.Segments() {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(s.Name)
+		if s.Desc != "" {
+			b.WriteString(`;desc="`)
+			b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s.Desc))
+			b.WriteByte('"')
+		}
+		b.WriteString(";dur=")
+		b.WriteString(strconv.FormatFloat(float64(s.Duration.Round(time.Microsecond))/float64(time.Millisecond), 'f', -1, 64))
+	}
+	return b.String()
+}
+
+type timingsKey struct{}
+
+// NewContext returns a new context carrying t.
+func NewContext(ctx context.Context, t *Timings) context.Context {
+	return context.WithValue(ctx, timingsKey{}, t)
+}
+
+// FromContext returns the timings of the request.
+func FromContext(ctx context.Context) (t *Timings, ok bool) {
+	t, ok = ctx.Value(timingsKey{}).(*Timings)
+	return
+}
+
+// Record records a segment in the timings of ctx, it does nothing for a
+// context without timings, e.g. with the middleware left out.
+func Record(ctx context.Context, name string, d time.Duration, desc ...string) {
+	if t, ok := FromContext(ctx); ok {
+		t.Record(name, d, desc...)
+	}
+}
+
+// Start starts a segment and returns the func recording it when called,
+// e.g. `defer servertiming.Start(ctx, "db")()`.
+func Start(ctx context.Context, name string, desc ...string) func() {
+	t, ok := FromContext(ctx)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { t.Record(name, time.Since(start), desc...) }
+}
+
+// Option is server timing option.
+type Option func(*options)
+
+type options struct {
+	total string
+}
+
+// WithTotal sets the name of the segment timing the whole handler, the
+// empty name leaves it out. Default is DefaultTotal.
+func WithTotal(name string) Option {
+	return func(o *options) {
+		o.total = name
+	}
+}
+
+// Server is a server middleware collecting the segments recorded by the
+// handler and the middleware after it with Record or Start, and emitting
+// them in the Server-Timing reply header.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{total: DefaultTotal}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			start := time.Now()
+			t := &Timings{}
+			reply, err := handler(NewContext(ctx, t), req)
+			if o.total != "" {
+				t.Record(o.total, time.Since(start))
+			}
+			if v := t.String(); v != "" {
+				tr.ReplyHeader().Set(Header, v)
+			}
+			return reply, err
+		}
+	}
+}
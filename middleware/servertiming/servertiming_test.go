@@ -0,0 +1,81 @@
+package servertiming
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type Transport struct {
+	transport.Transporter
+	reply headerCarrier
+}
+
+func (tr *Transport) ReplyHeader() transport.Header { return tr.reply }
+
+func TestServer(t *testing.T) {
+	tr := &Transport{reply: headerCarrier{}}
+	next := func(ctx context.Context, _ any) (any, error) {
+		Record(ctx, "db", 12500*time.Microsecond)
+		Record(ctx, "cache", 300*time.Microsecond, `hit "l1"`)
+		stop := Start(ctx, "render")
+		time.Sleep(5 * time.Millisecond)
+		stop()
+		return "reply", nil
+	}
+	reply, err := Server()(next)(transport.NewServerContext(context.Background(), tr), nil)
+	if err != nil || reply != "reply" {
+		t.Fatalf("unexpected reply %v %v", reply, err)
+	}
+	got := tr.reply.Get(Header)
+	want := regexp.MustCompile(`^db;dur=12\.5, cache;desc="hit \\"l1\\"";dur=0\.3, render;dur=([0-9.]+), total;dur=([0-9.]+)$`)
+	m := want.FindStringSubmatch(got)
+	if m == nil {
+		t.Fatalf("unexpected header %q", got)
+	}
+	for _, dur := range m[1:] {
+		if d, _ := time.ParseDuration(dur + "ms"); d < 5*time.Millisecond {
+			t.Errorf("expected at least 5ms, got %s in %q", d, got)
+		}
+	}
+}
+
+func TestServerWithoutTotal(t *testing.T) {
+	tr := &Transport{reply: headerCarrier{}}
+	next := func(context.Context, any) (any, error) { return nil, nil }
+	_, _ = Server(WithTotal(""))(next)(transport.NewServerContext(context.Background(), tr), nil)
+	if v := tr.reply.Get(Header); v != "" {
+		t.Errorf("expected no header without segments, got %q", v)
+	}
+}
+
+func TestRecordWithoutMiddleware(t *testing.T) {
+	// the helpers are no-ops without the middleware
+	Record(context.Background(), "db", time.Millisecond)
+	Start(context.Background(), "db")()
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("expected no timings")
+	}
+}
@@ -0,0 +1,145 @@
+package shadow
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	metricLabelOperation = "operation"
+	metricLabelResult    = "result"
+
+	resultSent    = "sent"
+	resultError   = "error"
+	resultDropped = "dropped"
+)
+
+// Target sends a mirrored request to the shadow backend, e.g. through a
+// client of the new service version. Its reply is discarded.
+type Target func(ctx context.Context, operation string, req any) error
+
+// Option is shadow traffic option.
+type Option func(*options)
+
+type options struct {
+	rate        float64
+	operations  []string
+	timeout     time.Duration
+	concurrency int
+	requests    metric.Int64Counter
+	random      func() float64
+}
+
+// WithRate sets the share of requests mirrored, from 0 to 1, default is 1.
+func WithRate(rate float64) Option {
+	return func(o *options) {
+		o.rate = rate
+	}
+}
+
+// WithOperations mirrors only the requests of the operations, default is
+// every operation. An operation ending with "*" matches every operation
+// with that prefix, e.g. "/helloworld.v1.Greeter/*".
+func WithOperations(operations ...string) Option {
+	return func(o *options) {
+		o.operations = operations
+	}
+}
+
+// WithTimeout sets the timeout of a mirrored request, default is 1s.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithConcurrency bounds the mirrored requests in flight, the requests
+// sampled beyond it are dropped. Default is 100.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithRequests sets the counter of the mirrored requests, labeled by
+// operation and result: sent, error or dropped.
+func WithRequests(c metric.Int64Counter) Option {
+	return func(o *options) {
+		o.requests = c
+	}
+}
+
+// Server is a server middleware mirroring a sample of the requests to a
+// shadow target, e.g. a new version of the service to compare. The mirrored
+// request runs in the background after the primary handler returns, it never
+// delays nor affects the primary reply; its errors are only logged and
+// counted. The request is cloned when it is a proto message, other requests
+// must not be modified by the handler.
+func Server(target Target, opts ...Option) middleware.Middleware {
+	o := &options{
+		rate:        1,
+		timeout:     time.Second,
+		concurrency: 100,
+		random:      rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	inflight := make(chan struct{}, o.concurrency)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || !o.match(tr.Operation()) || o.random() >= o.rate {
+				return handler(ctx, req)
+			}
+			operation := tr.Operation()
+			shadow := req
+			if m, ok := req.(proto.Message); ok {
+				shadow = proto.Clone(m)
+			}
+			reply, err := handler(ctx, req)
+			select {
+			case inflight <- struct{}{}:
+			default:
+				o.observe(ctx, operation, resultDropped)
+				return reply, err
+			}
+			go func() {
+				defer func() { <-inflight }()
+				sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), o.timeout)
+				defer cancel()
+				if err := target(sctx, operation, shadow); err != nil {
+					log.Warnf("shadow: failed to mirror %s: %v", operation, err)
+					o.observe(sctx, operation, resultError)
+					return
+				}
+				o.observe(sctx, operation, resultSent)
+			}()
+			return reply, err
+		}
+	}
+}
+
+func (o *options) match(operation string) bool {
+	return len(o.operations) == 0 || matcher.Operation(operation, o.operations...)
+}
+
+func (o *options) observe(ctx context.Context, operation, result string) {
+	if o.requests == nil {
+		return
+	}
+	o.requests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String(metricLabelOperation, operation),
+		attribute.String(metricLabelResult, result),
+	))
+}
@@ -0,0 +1,151 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	metrictest "github.com/go-kratos/kratos/v2/internal/testdata/metric"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct {
+	transport.Transporter
+	operation string
+}
+
+func (tr *Transport) Operation() string { return tr.operation }
+
+func newContext(operation string) context.Context {
+	return transport.NewServerContext(context.Background(), &Transport{operation: operation})
+}
+
+func reply(context.Context, any) (any, error) { return "reply", nil }
+
+// withSequence samples with the values of seq in turn instead of random ones.
+func withSequence(seq ...float64) Option {
+	var i atomic.Int64
+	return func(o *options) {
+		o.random = func() float64 { return seq[int(i.Add(1)-1)%len(seq)] }
+	}
+}
+
+func TestServerRate(t *testing.T) {
+	var (
+		wg    sync.WaitGroup
+		calls atomic.Int64
+	)
+	target := func(context.Context, string, any) error {
+		defer wg.Done()
+		calls.Add(1)
+		return nil
+	}
+	const requests = 100
+	wg.Add(requests / 4)
+	m := Server(target, WithRate(0.25), withSequence(0, 0.3, 0.6, 0.9))(reply)
+	for i := 0; i < requests; i++ {
+		if _, err := m(newContext("/test.Service/Call"), "req"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if got := calls.Load(); got != requests/4 {
+		t.Errorf("expected %d mirrored requests, got %d", requests/4, got)
+	}
+}
+
+func TestServerRandomRate(t *testing.T) {
+	var calls atomic.Int64
+	target := func(context.Context, string, any) error {
+		calls.Add(1)
+		return nil
+	}
+	const requests = 2000
+	m := Server(target, WithRate(0.5), WithConcurrency(requests))(reply)
+	for i := 0; i < requests; i++ {
+		_, _ = m(newContext("/test.Service/Call"), "req")
+	}
+	// wait for the mirrored requests to settle
+	for prev := int64(-1); prev != calls.Load(); {
+		prev = calls.Load()
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := calls.Load(); got < requests*4/10 || got > requests*6/10 {
+		t.Errorf("expected about %d mirrored requests, got %d", requests/2, got)
+	}
+}
+
+func TestServerOperations(t *testing.T) {
+	mirrored := make(chan string, 10)
+	target := func(_ context.Context, operation string, _ any) error {
+		mirrored <- operation
+		return nil
+	}
+	m := Server(target, WithOperations("/test.Service/Call", "/test.Other/*"))(reply)
+	for _, op := range []string{"/test.Service/Call", "/test.Service/Skip", "/test.Other/Any", "/test.Unknown/Call"} {
+		_, _ = m(newContext(op), "req")
+	}
+	got := map[string]bool{<-mirrored: true, <-mirrored: true}
+	if !got["/test.Service/Call"] || !got["/test.Other/Any"] {
+		t.Errorf("unexpected mirrored operations %v", got)
+	}
+	select {
+	case op := <-mirrored:
+		t.Errorf("unexpected mirrored operation %s", op)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestServerLatency(t *testing.T) {
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	target := func(ctx context.Context, _ string, _ any) error {
+		<-release
+		done <- ctx.Err()
+		return errors.New("shadow failed")
+	}
+	counter := &metrictest.Counter{}
+	m := Server(target, WithRequests(counter))(reply)
+	start := time.Now()
+	r, err := m(newContext("/test.Service/Call"), "req")
+	if err != nil || r != "reply" {
+		t.Fatalf("unexpected primary reply %v %v", r, err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected the primary unaffected by the shadow, took %v", elapsed)
+	}
+	close(release)
+	// the shadow outlives the primary request
+	if err = <-done; err != nil {
+		t.Errorf("expected the shadow context alive, got %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for counter.Count(attribute.String(metricLabelOperation, "/test.Service/Call"), attribute.String(metricLabelResult, resultError)) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the shadow error counted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestServerConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	target := func(context.Context, string, any) error {
+		<-release
+		return nil
+	}
+	counter := &metrictest.Counter{}
+	m := Server(target, WithConcurrency(2), WithRequests(counter))(reply)
+	for i := 0; i < 5; i++ {
+		_, _ = m(newContext("/test.Service/Call"), "req")
+	}
+	close(release)
+	if got := counter.Count(attribute.String(metricLabelOperation, "/test.Service/Call"), attribute.String(metricLabelResult, resultDropped)); got != 3 {
+		t.Errorf("expected 3 dropped requests, got %d", got)
+	}
+}
@@ -0,0 +1,151 @@
+package slowlog
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Threshold returns the latency above which a request is reported as slow.
+// It is evaluated per request, so it must be cheap. A non-positive
+// threshold disables the warning.
+type Threshold func() time.Duration
+
+// Option is slow request logging option.
+type Option func(*options)
+
+type options struct {
+	threshold  Threshold
+	operations map[string]time.Duration
+}
+
+// WithThreshold sets the default threshold, default is 1s.
+func WithThreshold(d time.Duration) Option {
+	return func(o *options) {
+		o.threshold = func() time.Duration { return d }
+	}
+}
+
+// WithThresholdFunc sets a dynamic default threshold, e.g. one returned by FromConfig.
+func WithThresholdFunc(t Threshold) Option {
+	return func(o *options) {
+		o.threshold = t
+	}
+}
+
+// WithOperationThreshold overrides the default threshold for an operation.
+func WithOperationThreshold(operation string, d time.Duration) Option {
+	return func(o *options) {
+		o.operations[operation] = d
+	}
+}
+
+// FromConfig returns a Threshold backed by the config key, which is
+// hot-reloaded on config changes. The value is either a duration string
+// such as "500ms" or an integer number of nanoseconds. def is used when
+// the key is missing or invalid.
+func FromConfig(c config.Config, key string, def time.Duration) Threshold {
+	var threshold atomic.Int64
+	threshold.Store(int64(def))
+	if d, err := parseDuration(c.Value(key)); err == nil {
+		threshold.Store(int64(d))
+	}
+	if err := c.Watch(key, func(_ string, value config.Value) {
+		d, err := parseDuration(value)
+		if err != nil {
+			log.Errorf("slowlog: invalid threshold %s: %v", key, err)
+			return
+		}
+		threshold.Store(int64(d))
+	}); err != nil {
+		log.Warnf("slowlog: failed to watch threshold %s: %v", key, err)
+	}
+	return func() time.Duration { return time.Duration(threshold.Load()) }
+}
+
+func parseDuration(v config.Value) (time.Duration, error) {
+	if s, err := v.String(); err == nil {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+	}
+	return v.Duration()
+}
+
+// Server is a server middleware that logs a warning for requests slower than the threshold.
+func Server(logger log.Logger, opts ...Option) middleware.Middleware {
+	return newMiddleware("server", logger, opts, func(ctx context.Context) (transport.Transporter, bool) {
+		return transport.FromServerContext(ctx)
+	})
+}
+
+// Client is a client middleware that logs a warning for requests slower than the threshold.
+func Client(logger log.Logger, opts ...Option) middleware.Middleware {
+	return newMiddleware("client", logger, opts, func(ctx context.Context) (transport.Transporter, bool) {
+		return transport.FromClientContext(ctx)
+	})
+}
+
+func newMiddleware(kind string, logger log.Logger, opts []Option, from func(context.Context) (transport.Transporter, bool)) middleware.Middleware {
+	o := options{
+		threshold:  func() time.Duration { return time.Second },
+		operations: make(map[string]time.Duration),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			start := time.Now()
+			reply, err := handler(ctx, req)
+			latency := time.Since(start)
+			var operation, component string
+			if info, ok := from(ctx); ok {
+				operation = info.Operation()
+				component = info.Kind().String()
+			}
+			threshold, ok := o.operations[operation]
+			if !ok {
+				threshold = o.threshold()
+			}
+			if threshold <= 0 || latency <= threshold {
+				return reply, err
+			}
+			log.NewHelper(log.WithContext(ctx, logger)).Log(log.LevelWarn,
+				"msg", "slow request",
+				"kind", kind,
+				"component", component,
+				"operation", operation,
+				"latency", latency.Seconds(),
+				"threshold", threshold.Seconds(),
+				"args_size", argsSize(req),
+			)
+			return reply, err
+		}
+	}
+}
+
+// argsSize returns the wire size of proto messages and the size of the
+// formatted value otherwise.
+func argsSize(req any) int {
+	switch v := req.(type) {
+	case nil:
+		return 0
+	case proto.Message:
+		return proto.Size(v)
+	case []byte:
+		return len(v)
+	case string:
+		return len(v)
+	default:
+		return len(fmt.Sprintf("%+v", v))
+	}
+}
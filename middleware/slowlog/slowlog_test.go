@@ -0,0 +1,139 @@
+package slowlog
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/memory"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct {
+	kind      transport.Kind
+	operation string
+}
+
+func (tr *Transport) Kind() transport.Kind            { return tr.kind }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return tr.operation }
+func (tr *Transport) RequestHeader() transport.Header { return nil }
+func (tr *Transport) ReplyHeader() transport.Header   { return nil }
+
+type record struct {
+	level log.Level
+	kvs   map[any]any
+}
+
+type testLogger struct {
+	mu      sync.Mutex
+	records []record
+}
+
+func (l *testLogger) Log(level log.Level, keyvals ...any) error {
+	kvs := make(map[any]any, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		kvs[keyvals[i]] = keyvals[i+1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, record{level: level, kvs: kvs})
+	return nil
+}
+
+func sleep(d time.Duration) func(context.Context, any) (any, error) {
+	return func(context.Context, any) (any, error) {
+		time.Sleep(d)
+		return "reply", nil
+	}
+}
+
+func serverContext(operation string) context.Context {
+	return transport.NewServerContext(context.Background(), &Transport{kind: transport.KindHTTP, operation: operation})
+}
+
+func TestServer(t *testing.T) {
+	logger := &testLogger{}
+	m := Server(logger,
+		WithThreshold(20*time.Millisecond),
+		WithOperationThreshold("/test.Service/Fast", 200*time.Millisecond),
+	)
+
+	// fast request
+	if reply, err := m(sleep(0))(serverContext("/test.Service/Get"), "hello"); err != nil || reply != "reply" {
+		t.Fatalf("unexpected reply %v %v", reply, err)
+	}
+	// slow for the default but within the operation threshold
+	if _, err := m(sleep(40*time.Millisecond))(serverContext("/test.Service/Fast"), "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.records) != 0 {
+		t.Fatalf("expected no log, got %v", logger.records)
+	}
+
+	// slow request
+	if _, err := m(sleep(40*time.Millisecond))(serverContext("/test.Service/Get"), "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.records) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(logger.records))
+	}
+	r := logger.records[0]
+	if r.level != log.LevelWarn {
+		t.Errorf("expected level %v, got %v", log.LevelWarn, r.level)
+	}
+	if r.kvs["kind"] != "server" || r.kvs["component"] != "http" || r.kvs["operation"] != "/test.Service/Get" {
+		t.Errorf("unexpected fields %v", r.kvs)
+	}
+	if latency, _ := r.kvs["latency"].(float64); latency < 0.04 {
+		t.Errorf("expected latency >= 0.04, got %v", r.kvs["latency"])
+	}
+	if r.kvs["threshold"] != 0.02 {
+		t.Errorf("expected threshold 0.02, got %v", r.kvs["threshold"])
+	}
+	if r.kvs["args_size"] != len("hello") {
+		t.Errorf("expected args_size %d, got %v", len("hello"), r.kvs["args_size"])
+	}
+}
+
+func TestClientDisabled(t *testing.T) {
+	logger := &testLogger{}
+	ctx := transport.NewClientContext(context.Background(), &Transport{kind: transport.KindGRPC, operation: "/test.Service/Get"})
+	if _, err := Client(logger, WithThreshold(0))(sleep(10*time.Millisecond))(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.records) != 0 {
+		t.Fatalf("expected no log, got %v", logger.records)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	src := memory.NewSource(map[string]any{"slow": "500ms"})
+	c := config.New(config.WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	threshold := FromConfig(c, "slow", time.Second)
+	if d := threshold(); d != 500*time.Millisecond {
+		t.Fatalf("expected 500ms, got %v", d)
+	}
+	if err := src.Set("slow", "100ms"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for threshold() != 100*time.Millisecond {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 100ms after reload, got %v", threshold())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if d := FromConfig(c, "missing", time.Second)(); d != time.Second {
+		t.Errorf("expected default for missing key, got %v", d)
+	}
+}
@@ -0,0 +1,51 @@
+package toggle
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// Flag reports whether the gated middleware is enabled.
+// It is evaluated per request, so it must be cheap.
+type Flag func() bool
+
+// New returns a middleware which runs ms when flag reports true
+// and passes through to the next handler otherwise.
+func New(flag Flag, ms ...middleware.Middleware) middleware.Middleware {
+	chain := middleware.Chain(ms...)
+	return func(handler middleware.Handler) middleware.Handler {
+		enabled := chain(handler)
+		return func(ctx context.Context, req any) (any, error) {
+			if flag() {
+				return enabled(ctx, req)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// FromConfig returns a Flag backed by the bool config key, which is
+// hot-reloaded on config changes. def is used when the key is missing
+// or is not a bool.
+func FromConfig(c config.Config, key string, def bool) Flag {
+	var enabled atomic.Bool
+	enabled.Store(def)
+	if v, err := c.Value(key).Bool(); err == nil {
+		enabled.Store(v)
+	}
+	if err := c.Watch(key, func(_ string, value config.Value) {
+		v, err := value.Bool()
+		if err != nil {
+			log.Errorf("toggle: invalid flag %s: %v", key, err)
+			return
+		}
+		enabled.Store(v)
+	}); err != nil {
+		log.Warnf("toggle: failed to watch flag %s: %v", key, err)
+	}
+	return enabled.Load
+}
@@ -0,0 +1,78 @@
+package toggle
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/memory"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+func counter(n *int) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			*n++
+			return handler(ctx, req)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	var (
+		enabled atomic.Bool
+		calls   int
+	)
+	next := func(context.Context, any) (any, error) { return "reply", nil }
+	h := New(enabled.Load, counter(&calls))(next)
+
+	tests := []struct {
+		enabled bool
+		calls   int
+	}{
+		{false, 0},
+		{true, 1},
+		{true, 2},
+		{false, 2},
+	}
+	for _, test := range tests {
+		enabled.Store(test.enabled)
+		reply, err := h(context.Background(), nil)
+		if err != nil || reply != "reply" {
+			t.Fatalf("unexpected reply %v %v", reply, err)
+		}
+		if calls != test.calls {
+			t.Errorf("enabled=%v: expected %d calls, got %d", test.enabled, test.calls, calls)
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	src := memory.NewSource(map[string]any{"verbose": false})
+	c := config.New(config.WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	flag := FromConfig(c, "verbose", true)
+	if flag() {
+		t.Fatal("expected flag disabled from config")
+	}
+	if err := src.Set("verbose", true); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !flag() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected flag enabled after reload")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if !FromConfig(c, "missing", true)() {
+		t.Error("expected default for missing key")
+	}
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Baggage returns the static key/values recorded on every span, such as the
+// service version or the deployment region.
+type Baggage func() map[string]string
+
+// WithBaggage records the static key/values kv as attributes of every span.
+// They are set when the span is created, so the attributes set per request
+// take precedence over them. They are not propagated to the peers.
+func WithBaggage(kv map[string]string) Option {
+	return WithBaggageFunc(func() map[string]string { return kv })
+}
+
+// WithBaggageFunc is like WithBaggage with the key/values returned by fn for
+// each span, e.g. BaggageFromConfig.
+func WithBaggageFunc(fn Baggage) Option {
+	return func(opts *options) {
+		opts.baggage = fn
+	}
+}
+
+// BaggageFromConfig returns Baggage backed by the config key, a map of
+// string values which is hot-reloaded on config changes.
+func BaggageFromConfig(c config.Config, key string) Baggage {
+	var baggage atomic.Value
+	baggage.Store(map[string]string{})
+	load := func(v config.Value) {
+		m, err := v.Map()
+		if err != nil {
+			log.Errorf("tracing: invalid baggage %s: %v", key, err)
+			return
+		}
+		next := make(map[string]string, len(m))
+		for k, v := range m {
+			s, err := v.String()
+			if err != nil {
+				log.Errorf("tracing: invalid baggage %s.%s: %v", key, k, err)
+				return
+			}
+			next[k] = s
+		}
+		baggage.Store(next)
+	}
+	if v := c.Value(key); v.Load() != nil {
+		load(v)
+	}
+	if err := c.Watch(key, func(_ string, v config.Value) { load(v) }); err != nil {
+		log.Warnf("tracing: failed to watch baggage %s: %v", key, err)
+	}
+	return func() map[string]string {
+		return baggage.Load().(map[string]string)
+	}
+}
+
+func (o *options) baggageAttributes() []attribute.KeyValue {
+	if o.baggage == nil {
+		return nil
+	}
+	kv := o.baggage()
+	if len(kv) == 0 {
+		return nil
+	}
+	attrs := make([]attribute.KeyValue, 0, len(kv))
+	for k, v := range kv {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return attrs
+}
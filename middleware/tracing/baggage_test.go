@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/memory"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func spanAttributes(t *testing.T, opts ...Option) map[attribute.Key]string {
+	t.Helper()
+	tr := &mockTransport{
+		kind:      transport.KindHTTP,
+		endpoint:  "server:2233",
+		operation: "/test.server/hello",
+		header:    headerCarrier{},
+	}
+	recorder := tracetest.NewSpanRecorder()
+	opts = append(opts, WithTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))))
+	next := func(context.Context, any) (any, error) { return "reply", nil }
+	if _, err := Server(opts...)(next)(transport.NewServerContext(context.Background(), tr), "req"); err != nil {
+		t.Fatal(err)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+func TestBaggage(t *testing.T) {
+	attrs := spanAttributes(t, WithBaggage(map[string]string{
+		"service.version": "v1.0.0",
+		"region":          "eu-west-1",
+		"rpc.system":      "static",
+	}))
+	if attrs["service.version"] != "v1.0.0" || attrs["region"] != "eu-west-1" {
+		t.Errorf("expected the static baggage, got %v", attrs)
+	}
+	// per-request attributes are not overridden
+	if attrs["rpc.system"] != transport.KindHTTP.String() {
+		t.Errorf("expected rpc.system %s, got %s", transport.KindHTTP, attrs["rpc.system"])
+	}
+	if attrs["rpc.method"] != "hello" {
+		t.Errorf("expected rpc.method hello, got %v", attrs)
+	}
+}
+
+func TestBaggageFromConfig(t *testing.T) {
+	src := memory.NewSource(map[string]any{"baggage.region": "eu-west-1"})
+	c := config.New(config.WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	baggage := BaggageFromConfig(c, "baggage")
+	if attrs := spanAttributes(t, WithBaggageFunc(baggage)); attrs["region"] != "eu-west-1" {
+		t.Fatalf("expected region eu-west-1, got %v", attrs)
+	}
+	if err := src.Set("baggage.region", "us-east-1"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for baggage()["region"] != "us-east-1" {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected region us-east-1 after reload, got %v", baggage())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if attrs := spanAttributes(t, WithBaggageFunc(baggage)); attrs["region"] != "us-east-1" {
+		t.Errorf("expected region us-east-1, got %v", attrs)
+	}
+
+	if kv := BaggageFromConfig(c, "missing")(); len(kv) != 0 {
+		t.Errorf("expected no baggage for missing key, got %v", kv)
+	}
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+)
+
+// Carrier is the correlation context of a request in a serializable form,
+// e.g. enqueued with a job: the propagated trace context and metadata. It
+// can be encoded as JSON.
+type Carrier struct {
+	Trace    map[string]string   `json:"trace,omitempty"`
+	Metadata map[string][]string `json:"metadata,omitempty"`
+}
+
+// WithMetadataKeys selects the metadata keys carried by ExtractCarrier,
+// by default all of the server metadata of the request.
+func WithMetadataKeys(keys ...string) Option {
+	return func(opts *options) {
+		opts.metadataKeys = keys
+	}
+}
+
+func defaultPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(Metadata{}, propagation.Baggage{}, propagation.TraceContext{})
+}
+
+func carrierOptions(opts []Option) *options {
+	op := &options{propagator: defaultPropagator()}
+	for _, o := range opts {
+		o(op)
+	}
+	return op
+}
+
+// ExtractCarrier returns the correlation context of ctx: the trace context
+// injected by the propagator, see WithPropagator, and the server metadata,
+// see WithMetadataKeys.
+func ExtractCarrier(ctx context.Context, opts ...Option) Carrier {
+	op := carrierOptions(opts)
+	c := Carrier{Trace: make(map[string]string)}
+	op.propagator.Inject(ctx, propagation.MapCarrier(c.Trace))
+	if md, ok := metadata.FromServerContext(ctx); ok {
+		c.Metadata = make(map[string][]string)
+		md.Range(func(k string, v []string) bool {
+			if selectedKey(op.metadataKeys, k) {
+				c.Metadata[k] = append([]string(nil), v...)
+			}
+			return true
+		})
+	}
+	return c
+}
+
+// NewDetachedContext returns a new background context carrying the
+// correlation context of c, for the async work of a request outliving it:
+// the spans started with it are children of the span of the request and the
+// metadata is propagated by the metadata client middleware.
+func NewDetachedContext(c Carrier, opts ...Option) context.Context {
+	op := carrierOptions(opts)
+	ctx := op.propagator.Extract(context.Background(), propagation.MapCarrier(c.Trace))
+	if len(c.Metadata) == 0 {
+		return ctx
+	}
+	// the metadata propagator may have set the service name already
+	if md, ok := metadata.FromServerContext(ctx); ok {
+		return metadata.NewServerContext(ctx, metadata.New(md, c.Metadata))
+	}
+	return metadata.NewServerContext(ctx, metadata.New(c.Metadata))
+}
+
+// Detach returns a new background context carrying the correlation context
+// of ctx, e.g. for a goroutine spawned by a handler, which is not canceled
+// with the request.
+func Detach(ctx context.Context, opts ...Option) context.Context {
+	return NewDetachedContext(ExtractCarrier(ctx, opts...), opts...)
+}
+
+func selectedKey(keys []string, key string) bool {
+	if len(keys) == 0 {
+		return true
+	}
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
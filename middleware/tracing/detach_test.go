@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+)
+
+func TestDetach(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	ctx, cancel := context.WithCancel(trace.ContextWithSpanContext(context.Background(), sc))
+	ctx = metadata.NewServerContext(ctx, metadata.New(map[string][]string{
+		"x-md-global-tenant": {"acme"},
+		"x-md-local-user":    {"42"},
+	}))
+
+	// the carrier survives a round-trip through a queue
+	data, err := json.Marshal(ExtractCarrier(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	var c Carrier
+	if err = json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	detached := NewDetachedContext(c)
+	if err = detached.Err(); err != nil {
+		t.Errorf("expected the detached context not canceled, got %v", err)
+	}
+	got := trace.SpanContextFromContext(detached)
+	if got.TraceID() != traceID || got.SpanID() != spanID || !got.IsSampled() || !got.IsRemote() {
+		t.Errorf("expected the remote span context %v, got %v", sc, got)
+	}
+	md, ok := metadata.FromServerContext(detached)
+	if !ok {
+		t.Fatal("expected the metadata")
+	}
+	if md.Get("x-md-global-tenant") != "acme" || md.Get("x-md-local-user") != "42" {
+		t.Errorf("unexpected metadata %v", md)
+	}
+}
+
+func TestDetachMetadataKeys(t *testing.T) {
+	ctx := metadata.NewServerContext(context.Background(), metadata.New(map[string][]string{
+		"x-md-global-tenant": {"acme"},
+		"x-md-local-user":    {"42"},
+	}))
+	md, _ := metadata.FromServerContext(Detach(ctx, WithMetadataKeys("X-Md-Global-Tenant")))
+	want := metadata.Metadata{"x-md-global-tenant": {"acme"}}
+	if !reflect.DeepEqual(md, want) {
+		t.Errorf("expected %v, got %v", want, md)
+	}
+	// no span context without a span
+	if sc := trace.SpanContextFromContext(Detach(ctx)); sc.IsValid() {
+		t.Errorf("expected no span context, got %v", sc)
+	}
+}
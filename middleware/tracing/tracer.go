@@ -24,7 +24,7 @@ type Tracer struct {
 // NewTracer create tracer instance
 func NewTracer(kind trace.SpanKind, opts ...Option) *Tracer {
 	op := options{
-		propagator: propagation.NewCompositeTextMapPropagator(Metadata{}, propagation.Baggage{}, propagation.TraceContext{}),
+		propagator: defaultPropagator(),
 		tracerName: "kratos",
 	}
 	for _, o := range opts {
@@ -52,6 +52,7 @@ func (t *Tracer) Start(ctx context.Context, operation string, carrier propagatio
 	ctx, span := t.tracer.Start(ctx,
 		operation,
 		trace.WithSpanKind(t.kind),
+		trace.WithAttributes(t.opt.baggageAttributes()...),
 	)
 	if t.kind == trace.SpanKindClient {
 		t.opt.propagator.Inject(ctx, carrier)
@@ -71,6 +72,11 @@ func (t *Tracer) End(_ context.Context, span trace.Span, m any, err error) {
 		span.SetStatus(codes.Ok, "OK")
 	}
 
+	if t.kind == trace.SpanKindServer {
+		t.setBody(span, "send_msg.body", m)
+	} else {
+		t.setBody(span, "recv_msg.body", m)
+	}
 	if p, ok := m.(proto.Message); ok {
 		if t.kind == trace.SpanKindServer {
 			span.SetAttributes(attribute.Key("send_msg.size").Int(proto.Size(p)))
@@ -80,3 +86,11 @@ func (t *Tracer) End(_ context.Context, span trace.Span, m any, err error) {
 	}
 	span.End()
 }
+
+// setBody records the redacted message body when WithRedactFields is set.
+func (t *Tracer) setBody(span trace.Span, key string, m any) {
+	if t.opt.redactor == nil || m == nil {
+		return
+	}
+	span.SetAttributes(attribute.Key(key).String(t.opt.redactor.Redact(m)))
+}
@@ -3,6 +3,7 @@ package tracing
 import (
 	"context"
 
+	"github.com/go-kratos/kratos/v2/internal/redact"
 	"github.com/go-kratos/kratos/v2/log"
 
 	"go.opentelemetry.io/otel/propagation"
@@ -19,6 +20,9 @@ type options struct {
 	tracerName     string
 	tracerProvider trace.TracerProvider
 	propagator     propagation.TextMapPropagator
+	redactor       *redact.Redactor
+	baggage        Baggage
+	metadataKeys   []string
 }
 
 // WithPropagator with tracer propagator.
@@ -43,6 +47,15 @@ func WithTracerName(tracerName string) Option {
 	}
 }
 
+// WithRedactFields records the request and reply bodies on spans with the
+// given field paths masked, e.g. "password" or "user.ssn".
+// Bodies are not recorded unless this option is set.
+func WithRedactFields(paths ...string) Option {
+	return func(opts *options) {
+		opts.redactor = redact.New(paths, redact.DefaultMaxSize)
+	}
+}
+
 // Server returns a new server middleware for OpenTelemetry.
 func Server(opts ...Option) middleware.Middleware {
 	tracer := NewTracer(trace.SpanKindServer, opts...)
@@ -52,6 +65,7 @@ func Server(opts ...Option) middleware.Middleware {
 				var span trace.Span
 				ctx, span = tracer.Start(ctx, tr.Operation(), tr.RequestHeader())
 				setServerSpan(ctx, span, req)
+				tracer.setBody(span, "recv_msg.body", req)
 				defer func() { tracer.End(ctx, span, reply, err) }()
 			}
 			return handler(ctx, req)
@@ -68,6 +82,7 @@ func Client(opts ...Option) middleware.Middleware {
 				var span trace.Span
 				ctx, span = tracer.Start(ctx, tr.Operation(), tr.RequestHeader())
 				setClientSpan(ctx, span, req)
+				tracer.setBody(span, "send_msg.body", req)
 				defer func() { tracer.End(ctx, span, reply, err) }()
 			}
 			return handler(ctx, req)
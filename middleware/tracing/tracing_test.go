@@ -7,8 +7,10 @@ import (
 	"reflect"
 	"testing"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -233,3 +235,41 @@ func TestClient(t *testing.T) {
 		t.Errorf("expected %v, got %v", childTraceID, span.SpanContext().TraceID().String())
 	}
 }
+
+func TestServerRedactFields(t *testing.T) {
+	type request struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	tr := &mockTransport{
+		kind:      transport.KindHTTP,
+		endpoint:  "server:2233",
+		operation: "/test.server/hello",
+		header:    headerCarrier{},
+	}
+	recorder := tracetest.NewSpanRecorder()
+	next := func(context.Context, any) (any, error) {
+		return &request{Username: "reply", Password: "reply-secret"}, nil
+	}
+	_, err := Server(
+		WithTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))),
+		WithRedactFields("password"),
+	)(next)(transport.NewServerContext(context.Background(), tr), &request{Username: "kratos", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	if want := `{"password":"****","username":"kratos"}`; attrs["recv_msg.body"] != want {
+		t.Errorf("expected %s, got %s", want, attrs["recv_msg.body"])
+	}
+	if want := `{"password":"****","username":"reply"}`; attrs["send_msg.body"] != want {
+		t.Errorf("expected %s, got %s", want, attrs["send_msg.body"])
+	}
+}
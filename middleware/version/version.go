@@ -0,0 +1,133 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// DefaultHeader is the default header declaring the schema version.
+	DefaultHeader = "X-Schema-Version"
+
+	// ReasonMissing is the reason of the error of a request without version.
+	ReasonMissing = "SCHEMA_VERSION_MISSING"
+	// ReasonUnsupported is the reason of the error of an unsupported version.
+	ReasonUnsupported = "SCHEMA_VERSION_UNSUPPORTED"
+)
+
+// Option is schema version option.
+type Option func(*options)
+
+type options struct {
+	header    string
+	supported []string
+	min, max  int
+	ranged    bool
+	fallback  string
+}
+
+// WithHeader sets the header declaring the version, default is X-Schema-Version.
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithSupported adds the supported versions, e.g. "2024-01" or "v2".
+func WithSupported(versions ...string) Option {
+	return func(o *options) {
+		o.supported = append(o.supported, versions...)
+	}
+}
+
+// WithRange supports the integer versions from min to max inclusive,
+// optionally prefixed by "v".
+func WithRange(min, max int) Option {
+	return func(o *options) {
+		o.min, o.max, o.ranged = min, max, true
+	}
+}
+
+// WithDefault assumes the version of the requests without header, e.g.
+// the latest one. By default such requests are rejected.
+func WithDefault(version string) Option {
+	return func(o *options) {
+		o.fallback = version
+	}
+}
+
+type versionKey struct{}
+
+// NewContext returns a new context carrying the schema version.
+func NewContext(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, versionKey{}, version)
+}
+
+// FromContext returns the schema version of the request, if any.
+func FromContext(ctx context.Context) (version string, ok bool) {
+	version, ok = ctx.Value(versionKey{}).(string)
+	return
+}
+
+// Server is a server middleware rejecting the requests whose schema
+// version header is not supported, before they reach the handler. The
+// rejections are precondition failed errors, FailedPrecondition on gRPC,
+// whose metadata "supported" lists the supported versions.
+// The accepted version is available to the handler with FromContext.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{header: DefaultHeader}
+	for _, opt := range opts {
+		opt(o)
+	}
+	supported := o.describe()
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			var version string
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				version = strings.TrimSpace(tr.RequestHeader().Get(o.header))
+			}
+			if version == "" {
+				if o.fallback == "" {
+					return nil, errors.PreconditionFailed(ReasonMissing,
+						fmt.Sprintf("missing header %s, supported versions: %s", o.header, supported)).
+						WithMetadata(map[string]string{"supported": supported})
+				}
+				version = o.fallback
+			}
+			if !o.supports(version) {
+				return nil, errors.PreconditionFailed(ReasonUnsupported,
+					fmt.Sprintf("unsupported schema version %q, supported versions: %s", version, supported)).
+					WithMetadata(map[string]string{"supported": supported})
+			}
+			return handler(NewContext(ctx, version), req)
+		}
+	}
+}
+
+func (o *options) supports(version string) bool {
+	for _, v := range o.supported {
+		if v == version {
+			return true
+		}
+	}
+	if !o.ranged {
+		return false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	return err == nil && n >= o.min && n <= o.max
+}
+
+// describe returns the supported versions, e.g. "1-3, 2024-01".
+func (o *options) describe() string {
+	var vs []string
+	if o.ranged {
+		vs = append(vs, fmt.Sprintf("%d-%d", o.min, o.max))
+	}
+	return strings.Join(append(vs, o.supported...), ", ")
+}
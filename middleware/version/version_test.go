@@ -0,0 +1,137 @@
+package version
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type Transport struct {
+	transport.Transporter
+	header headerCarrier
+}
+
+func (tr *Transport) RequestHeader() transport.Header { return tr.header }
+
+func newContext(key, value string) context.Context {
+	header := headerCarrier{}
+	if value != "" {
+		header.Set(key, value)
+	}
+	return transport.NewServerContext(context.Background(), &Transport{header: header})
+}
+
+func handler(ctx context.Context, _ any) (any, error) {
+	v, _ := FromContext(ctx)
+	return v, nil
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		ctx     context.Context
+		version string
+		reason  string
+	}{
+		{"supported", []Option{WithSupported("2024-01", "2024-06")}, newContext(DefaultHeader, "2024-06"), "2024-06", ""},
+		{"range", []Option{WithRange(1, 3)}, newContext(DefaultHeader, "2"), "2", ""},
+		{"range prefixed", []Option{WithRange(1, 3)}, newContext(DefaultHeader, "v3"), "v3", ""},
+		{"header", []Option{WithHeader("X-Api-Version"), WithSupported("v1")}, newContext("X-Api-Version", "v1"), "v1", ""},
+		{"unsupported", []Option{WithSupported("2024-01")}, newContext(DefaultHeader, "2023-01"), "", ReasonUnsupported},
+		{"out of range", []Option{WithRange(1, 3)}, newContext(DefaultHeader, "4"), "", ReasonUnsupported},
+		{"missing", []Option{WithSupported("v1")}, newContext(DefaultHeader, ""), "", ReasonMissing},
+		{"no transport", []Option{WithSupported("v1")}, context.Background(), "", ReasonMissing},
+		{"default", []Option{WithSupported("v1", "v2"), WithDefault("v2")}, newContext(DefaultHeader, ""), "v2", ""},
+		{"unsupported default", []Option{WithSupported("v1"), WithDefault("v2")}, newContext(DefaultHeader, ""), "", ReasonUnsupported},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reply, err := Server(test.opts...)(handler)(test.ctx, nil)
+			if test.reason == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if reply != test.version {
+					t.Errorf("expected version %q, got %v", test.version, reply)
+				}
+				return
+			}
+			if !errors.IsPreconditionFailed(err) || errors.Reason(err) != test.reason {
+				t.Fatalf("expected %s, got %v", test.reason, err)
+			}
+			if code := status.Code(err); code != codes.FailedPrecondition {
+				t.Errorf("expected gRPC code %v, got %v", codes.FailedPrecondition, code)
+			}
+		})
+	}
+}
+
+func TestServerSupportedMetadata(t *testing.T) {
+	_, err := Server(WithRange(1, 3), WithSupported("2024-01"))(handler)(newContext(DefaultHeader, "9"), nil)
+	se := errors.FromError(err)
+	if se == nil {
+		t.Fatal("expected an error")
+	}
+	if got := se.Metadata["supported"]; got != "1-3, 2024-01" {
+		t.Errorf("expected supported 1-3, 2024-01, got %q", got)
+	}
+}
+
+type greeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (greeter) SayHello(_ context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	return &pb.HelloReply{Message: "hello " + in.Name}, nil
+}
+
+func TestServerGRPC(t *testing.T) {
+	srv := grpc.NewServer(grpc.Middleware(Server(WithSupported("v1"))))
+	pb.RegisterGreeterServer(srv, greeter{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	conn, err := grpc.DialInsecure(context.Background(), grpc.WithEndpoint(u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	_, err = pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	if !errors.IsPreconditionFailed(err) || errors.Reason(err) != ReasonMissing {
+		t.Fatalf("expected %s, got %v", ReasonMissing, err)
+	}
+	if got := errors.FromError(err).Metadata["supported"]; got != "v1" {
+		t.Errorf("expected supported v1, got %q", got)
+	}
+}
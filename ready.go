@@ -0,0 +1,129 @@
+package kratos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var (
+	errNotStarted    = errors.New("not started")
+	errStopped       = errors.New("stopped")
+	errNotRegistered = errors.New("not registered")
+)
+
+// ReadinessError reports the components of an App which are not ready,
+// keyed by component name, e.g. "server[0] *grpc.Server" or "registrar".
+type ReadinessError struct {
+	Components map[string]error
+}
+
+func (e *ReadinessError) Error() string {
+	names := make([]string, 0, len(e.Components))
+	for name := range e.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	s := make([]string, 0, len(names))
+	for _, name := range names {
+		s = append(s, fmt.Sprintf("%s: %v", name, e.Components[name]))
+	}
+	return "kratos: not ready: " + strings.Join(s, "; ")
+}
+
+// readiness tracks the lifecycle state of the app components.
+type readiness struct {
+	mu      sync.RWMutex
+	servers []error
+	reg     error
+}
+
+func newReadiness(o options) *readiness {
+	r := &readiness{servers: make([]error, len(o.servers))}
+	for i := range r.servers {
+		r.servers[i] = errNotStarted
+	}
+	if o.registrar != nil {
+		r.reg = errNotRegistered
+	}
+	return r
+}
+
+func (r *readiness) server(i int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.servers[i] = err
+}
+
+func (r *readiness) registrar(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reg = err
+}
+
+func serverName(i int, srv transport.Server) string {
+	return fmt.Sprintf("server[%d] %T", i, srv)
+}
+
+// Ready reports whether every server is running and, when a registrar is
+// set, the service instance is registered. Servers implementing
+// transport.Readier are also asked whether they accept requests.
+// The returned *ReadinessError attributes each failure to its component.
+func (a *App) Ready() error {
+	a.ready.mu.RLock()
+	states := append([]error(nil), a.ready.servers...)
+	reg := a.ready.reg
+	a.ready.mu.RUnlock()
+
+	components := make(map[string]error)
+	for i, srv := range a.opts.servers {
+		err := states[i]
+		if err == nil {
+			if r, ok := srv.(transport.Readier); ok {
+				err = r.Ready()
+			}
+		}
+		if err != nil {
+			components[serverName(i, srv)] = err
+		}
+	}
+	if reg != nil {
+		components["registrar"] = reg
+	}
+	if len(components) > 0 {
+		return &ReadinessError{Components: components}
+	}
+	return nil
+}
+
+// ReadyHandler returns an HTTP readiness probe handler, it replies 200 when
+// the app is ready, otherwise 503 with the failing components as JSON.
+func (a *App) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		err := a.Ready()
+		if err == nil {
+			_, _ = w.Write([]byte(`{"status":"ready"}`))
+			return
+		}
+		reply := struct {
+			Status     string            `json:"status"`
+			Components map[string]string `json:"components,omitempty"`
+		}{Status: "not ready"}
+		var re *ReadinessError
+		if errors.As(err, &re) {
+			reply.Components = make(map[string]string, len(re.Components))
+			for name, cerr := range re.Components {
+				reply.Components[name] = cerr.Error()
+			}
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(reply)
+	})
+}
@@ -0,0 +1,146 @@
+package kratos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+type readyServer struct {
+	mu   sync.Mutex
+	err  error
+	stop chan struct{}
+}
+
+func newReadyServer() *readyServer {
+	return &readyServer{stop: make(chan struct{})}
+}
+
+func (s *readyServer) Start(context.Context) error {
+	<-s.stop
+	return nil
+}
+
+func (s *readyServer) Stop(context.Context) error {
+	close(s.stop)
+	return nil
+}
+
+func (s *readyServer) Ready() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *readyServer) set(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+type failRegistrar struct{ err error }
+
+func (r *failRegistrar) Register(context.Context, *registry.ServiceInstance) error { return r.err }
+
+func (r *failRegistrar) Deregister(context.Context, *registry.ServiceInstance) error { return nil }
+
+func waitReady(t *testing.T, app *App) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for app.Ready() != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected app ready, got %v", app.Ready())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func readinessComponents(t *testing.T, err error) map[string]error {
+	t.Helper()
+	var re *ReadinessError
+	if !errors.As(err, &re) {
+		t.Fatalf("expected *ReadinessError, got %v", err)
+	}
+	return re.Components
+}
+
+func TestAppReady(t *testing.T) {
+	s0, s1 := newReadyServer(), newReadyServer()
+	app := New(
+		Name("kratos"),
+		Server(s0, s1),
+		Registrar(&mockRegistry{service: make(map[string]*registry.ServiceInstance)}),
+	)
+	components := readinessComponents(t, app.Ready())
+	if len(components) != 3 {
+		t.Errorf("expected servers and registrar not ready before run, got %v", components)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
+	waitReady(t, app)
+
+	probe := func() (int, map[string]string) {
+		w := httptest.NewRecorder()
+		app.ReadyHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var reply struct {
+			Components map[string]string `json:"components"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&reply); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, reply.Components
+	}
+	if code, _ := probe(); code != http.StatusOK {
+		t.Errorf("expected 200, got %d", code)
+	}
+
+	s1.set(errors.New("database unreachable"))
+	components = readinessComponents(t, app.Ready())
+	if len(components) != 1 || components["server[1] *kratos.readyServer"] == nil {
+		t.Errorf("expected server[1] not ready, got %v", components)
+	}
+	code, reply := probe()
+	if code != http.StatusServiceUnavailable || reply["server[1] *kratos.readyServer"] != "database unreachable" {
+		t.Errorf("expected 503 attributed to server[1], got %d %v", code, reply)
+	}
+	s1.set(nil)
+	waitReady(t, app)
+
+	if err := app.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	components = readinessComponents(t, app.Ready())
+	for _, name := range []string{"server[0] *kratos.readyServer", "server[1] *kratos.readyServer", "registrar"} {
+		if components[name] == nil {
+			t.Errorf("expected %s not ready after stop, got %v", name, components)
+		}
+	}
+}
+
+func TestAppReadyRegistrarFailure(t *testing.T) {
+	errRegister := errors.New("registry unavailable")
+	s := newReadyServer()
+	app := New(Name("kratos"), Server(s), Registrar(&failRegistrar{err: errRegister}))
+	if err := app.Run(); !errors.Is(err, errRegister) {
+		t.Fatalf("expected register error, got %v", err)
+	}
+	components := readinessComponents(t, app.Ready())
+	if !errors.Is(components["registrar"], errRegister) {
+		t.Errorf("expected registrar failure, got %v", components)
+	}
+	if components["server[0] *kratos.readyServer"] != nil {
+		t.Errorf("expected server ready, got %v", components)
+	}
+	_ = s.Stop(context.Background())
+}
@@ -0,0 +1,53 @@
+package registry
+
+import "sort"
+
+// Diff compares two instance lists of a service keyed by ID, as returned by
+// consecutive Watcher.Next calls. An instance is changed when its ID exists in
+// both lists with different endpoints, metadata or version. Added and changed
+// instances are taken from cur and removed instances from prev.
+func Diff(prev, cur []*ServiceInstance) (added, removed, changed []*ServiceInstance) {
+	old := make(map[string]*ServiceInstance, len(prev))
+	for _, in := range prev {
+		old[in.ID] = in
+	}
+	seen := make(map[string]struct{}, len(cur))
+	for _, in := range cur {
+		seen[in.ID] = struct{}{}
+		o, ok := old[in.ID]
+		switch {
+		case !ok:
+			added = append(added, in)
+		case !sameInstance(o, in):
+			changed = append(changed, in)
+		}
+	}
+	for _, in := range prev {
+		if _, ok := seen[in.ID]; !ok {
+			removed = append(removed, in)
+		}
+	}
+	return added, removed, changed
+}
+
+// sameInstance is like Equal without reordering the endpoints of a and b.
+func sameInstance(a, b *ServiceInstance) bool {
+	if a.Version != b.Version || len(a.Endpoints) != len(b.Endpoints) || len(a.Metadata) != len(b.Metadata) {
+		return false
+	}
+	for k, v := range a.Metadata {
+		if w, ok := b.Metadata[k]; !ok || v != w {
+			return false
+		}
+	}
+	ae := append([]string(nil), a.Endpoints...)
+	be := append([]string(nil), b.Endpoints...)
+	sort.Strings(ae)
+	sort.Strings(be)
+	for i := range ae {
+		if ae[i] != be[i] {
+			return false
+		}
+	}
+	return true
+}
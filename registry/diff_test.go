@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func ids(ins []*ServiceInstance) []string {
+	var s []string
+	for _, in := range ins {
+		s = append(s, in.ID)
+	}
+	return s
+}
+
+func TestDiff(t *testing.T) {
+	a := &ServiceInstance{ID: "a", Version: "v1", Endpoints: []string{"grpc://127.0.0.1:9000", "http://127.0.0.1:8000"}}
+	b := &ServiceInstance{ID: "b", Version: "v1", Metadata: map[string]string{"zone": "a"}}
+	tests := []struct {
+		name    string
+		prev    []*ServiceInstance
+		cur     []*ServiceInstance
+		added   []string
+		removed []string
+		changed []string
+	}{
+		{
+			name:  "add",
+			prev:  []*ServiceInstance{a},
+			cur:   []*ServiceInstance{a, b},
+			added: []string{"b"},
+		},
+		{
+			name:    "remove",
+			prev:    []*ServiceInstance{a, b},
+			cur:     []*ServiceInstance{b},
+			removed: []string{"a"},
+		},
+		{
+			name: "change",
+			prev: []*ServiceInstance{a, b},
+			cur: []*ServiceInstance{
+				{ID: "a", Version: "v2", Endpoints: a.Endpoints},
+				{ID: "b", Version: "v1", Metadata: map[string]string{"zone": "b"}},
+			},
+			changed: []string{"a", "b"},
+		},
+		{
+			name:    "change endpoints",
+			prev:    []*ServiceInstance{a},
+			cur:     []*ServiceInstance{{ID: "a", Version: "v1", Endpoints: []string{"grpc://127.0.0.1:9001", "http://127.0.0.1:8000"}}},
+			changed: []string{"a"},
+		},
+		{
+			name: "no-op",
+			prev: []*ServiceInstance{a, b},
+			cur: []*ServiceInstance{
+				b,
+				{ID: "a", Version: "v1", Endpoints: []string{"http://127.0.0.1:8000", "grpc://127.0.0.1:9000"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed, changed := Diff(tt.prev, tt.cur)
+			if got := ids(added); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("added: expected %v, got %v", tt.added, got)
+			}
+			if got := ids(removed); !reflect.DeepEqual(got, tt.removed) {
+				t.Errorf("removed: expected %v, got %v", tt.removed, got)
+			}
+			if got := ids(changed); !reflect.DeepEqual(got, tt.changed) {
+				t.Errorf("changed: expected %v, got %v", tt.changed, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	_ Discovery = (*fallbackDiscovery)(nil)
+	_ Watcher   = (*fallbackWatcher)(nil)
+)
+
+type fallbackDiscovery struct {
+	primary   Discovery
+	secondary Discovery
+}
+
+// NewFallbackDiscovery returns a Discovery which serves from primary and
+// falls back to secondary when primary fails.
+//
+// GetService queries primary first and uses secondary when primary returns
+// an error or no instances.
+//
+// Watch prefers primary. When the active watcher's stream returns an error,
+// the watcher switches to the other backend and keeps serving from it; it
+// switches back to primary only when the secondary stream errors in turn.
+// Errors caused by the watch context being done are returned as is.
+func NewFallbackDiscovery(primary, secondary Discovery) Discovery {
+	return &fallbackDiscovery{primary: primary, secondary: secondary}
+}
+
+func (d *fallbackDiscovery) GetService(ctx context.Context, serviceName string) ([]*ServiceInstance, error) {
+	ins, err := d.primary.GetService(ctx, serviceName)
+	if err == nil && len(ins) > 0 {
+		return ins, nil
+	}
+	if fins, ferr := d.secondary.GetService(ctx, serviceName); ferr == nil && len(fins) > 0 {
+		return fins, nil
+	}
+	return ins, err
+}
+
+func (d *fallbackDiscovery) Watch(ctx context.Context, serviceName string) (Watcher, error) {
+	fw := &fallbackWatcher{
+		name:     serviceName,
+		backends: [2]Discovery{d.primary, d.secondary},
+	}
+	fw.ctx, fw.cancel = context.WithCancel(ctx)
+	w, err := d.primary.Watch(fw.ctx, serviceName)
+	if err != nil {
+		if w, err = d.secondary.Watch(fw.ctx, serviceName); err != nil {
+			fw.cancel()
+			return nil, err
+		}
+		fw.current = 1
+	}
+	fw.w = w
+	return fw, nil
+}
+
+type fallbackWatcher struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	name     string
+	backends [2]Discovery
+
+	mu      sync.Mutex
+	current int
+	w       Watcher
+	stopped bool
+}
+
+func (w *fallbackWatcher) Next() ([]*ServiceInstance, error) {
+	w.mu.Lock()
+	cur, idx := w.w, w.current
+	w.mu.Unlock()
+
+	ins, err := cur.Next()
+	if err == nil || w.ctx.Err() != nil {
+		return ins, err
+	}
+	next := 1 - idx
+	nw, werr := w.backends[next].Watch(w.ctx, w.name)
+	if werr != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		_ = nw.Stop()
+		return nil, w.ctx.Err()
+	}
+	_ = w.w.Stop()
+	w.w, w.current = nw, next
+	w.mu.Unlock()
+	return nw.Next()
+}
+
+func (w *fallbackWatcher) Stop() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+	w.cancel()
+	return w.w.Stop()
+}
@@ -0,0 +1,138 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errBackend = errors.New("backend unavailable")
+
+type testDiscovery struct {
+	ins      []*ServiceInstance
+	err      error
+	watchErr error
+	next     chan error
+}
+
+func (d *testDiscovery) GetService(context.Context, string) ([]*ServiceInstance, error) {
+	return d.ins, d.err
+}
+
+func (d *testDiscovery) Watch(ctx context.Context, _ string) (Watcher, error) {
+	if d.watchErr != nil {
+		return nil, d.watchErr
+	}
+	return &testWatcher{ctx: ctx, d: d, first: true}, nil
+}
+
+type testWatcher struct {
+	ctx   context.Context
+	d     *testDiscovery
+	first bool
+}
+
+func (w *testWatcher) Next() ([]*ServiceInstance, error) {
+	if w.first {
+		w.first = false
+		return w.d.ins, nil
+	}
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case err := <-w.d.next:
+		if err != nil {
+			return nil, err
+		}
+		return w.d.ins, nil
+	}
+}
+
+func (w *testWatcher) Stop() error {
+	return nil
+}
+
+func TestFallbackGetService(t *testing.T) {
+	primary := &testDiscovery{ins: []*ServiceInstance{{ID: "primary"}}}
+	secondary := &testDiscovery{ins: []*ServiceInstance{{ID: "secondary"}}}
+	d := NewFallbackDiscovery(primary, secondary)
+
+	tests := []struct {
+		name string
+		ins  []*ServiceInstance
+		err  error
+		want string
+	}{
+		{"primary", primary.ins, nil, "primary"},
+		{"primary error", nil, errBackend, "secondary"},
+		{"primary empty", nil, nil, "secondary"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			primary.ins, primary.err = test.ins, test.err
+			ins, err := d.GetService(context.Background(), "helloworld")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ins) != 1 || ins[0].ID != test.want {
+				t.Errorf("expected %s, got %v", test.want, ins)
+			}
+		})
+	}
+
+	secondary.err = errBackend
+	primary.err = errBackend
+	if _, err := d.GetService(context.Background(), "helloworld"); !errors.Is(err, errBackend) {
+		t.Errorf("expected %v, got %v", errBackend, err)
+	}
+}
+
+func TestFallbackWatch(t *testing.T) {
+	primary := &testDiscovery{ins: []*ServiceInstance{{ID: "primary"}}, next: make(chan error, 1)}
+	secondary := &testDiscovery{ins: []*ServiceInstance{{ID: "secondary"}}, next: make(chan error, 1)}
+	w, err := NewFallbackDiscovery(primary, secondary).Watch(context.Background(), "helloworld")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	expect := func(want string) {
+		t.Helper()
+		ins, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ins) != 1 || ins[0].ID != want {
+			t.Fatalf("expected %s, got %v", want, ins)
+		}
+	}
+	expect("primary")
+	primary.next <- errBackend
+	expect("secondary")
+	secondary.next <- nil
+	expect("secondary")
+	secondary.next <- errBackend
+	expect("primary")
+}
+
+func TestFallbackWatchPrimaryUnavailable(t *testing.T) {
+	primary := &testDiscovery{watchErr: errBackend}
+	secondary := &testDiscovery{ins: []*ServiceInstance{{ID: "secondary"}}}
+	w, err := NewFallbackDiscovery(primary, secondary).Watch(context.Background(), "helloworld")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ins) != 1 || ins[0].ID != "secondary" {
+		t.Errorf("expected secondary, got %v", ins)
+	}
+	if err = w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Next(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
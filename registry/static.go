@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+var (
+	_ Discovery = (*staticDiscovery)(nil)
+	_ Watcher   = (*staticWatcher)(nil)
+)
+
+type staticDiscovery struct {
+	instances []*ServiceInstance
+}
+
+// NewStatic returns a Discovery serving a fixed list of instances, for
+// deployments without a registry. GetService returns the instances of the
+// given service name. The first Next of Watch returns them, even when there
+// is none, and the later ones block until the watch context is done or the
+// watcher is stopped. See the static package for a constructor from config.
+func NewStatic(instances []*ServiceInstance) Discovery {
+	return &staticDiscovery{instances: instances}
+}
+
+func (d *staticDiscovery) GetService(_ context.Context, serviceName string) ([]*ServiceInstance, error) {
+	var ins []*ServiceInstance
+	for _, in := range d.instances {
+		if in.Name == serviceName {
+			ins = append(ins, in)
+		}
+	}
+	return ins, nil
+}
+
+func (d *staticDiscovery) Watch(ctx context.Context, serviceName string) (Watcher, error) {
+	ins, _ := d.GetService(ctx, serviceName)
+	w := &staticWatcher{ins: ins}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	return w, nil
+}
+
+type staticWatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	emitted atomic.Bool
+	ins     []*ServiceInstance
+}
+
+func (w *staticWatcher) Next() ([]*ServiceInstance, error) {
+	if !w.emitted.Swap(true) {
+		if err := w.ctx.Err(); err != nil {
+			return nil, err
+		}
+		return w.ins, nil
+	}
+	<-w.ctx.Done()
+	return nil, w.ctx.Err()
+}
+
+func (w *staticWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
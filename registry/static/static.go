@@ -0,0 +1,24 @@
+// Package static builds the static registry.Discovery from config, so that
+// the registry package does not depend on the config one.
+package static
+
+import (
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// FromConfig returns a registry.NewStatic Discovery of the instances listed
+// under key of c, e.g.:
+//
+//	discovery:
+//	  - id: helloworld-1
+//	    name: helloworld
+//	    endpoints:
+//	      - grpc://127.0.0.1:9000
+func FromConfig(c config.Config, key string) (registry.Discovery, error) {
+	var instances []*registry.ServiceInstance
+	if err := c.Value(key).Scan(&instances); err != nil {
+		return nil, err
+	}
+	return registry.NewStatic(instances), nil
+}
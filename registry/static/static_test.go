@@ -0,0 +1,35 @@
+package static
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/memory"
+)
+
+func TestFromConfig(t *testing.T) {
+	c := config.New(config.WithSource(memory.NewSource(map[string]any{
+		"discovery": []any{
+			map[string]any{"id": "1", "name": "helloworld", "endpoints": []any{"grpc://127.0.0.1:9000"}},
+		},
+	})))
+	if err := c.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+	d, err := FromConfig(c, "discovery")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ins, err := d.GetService(context.Background(), "helloworld")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ins) != 1 || ins[0].ID != "1" || ins[0].Endpoints[0] != "grpc://127.0.0.1:9000" {
+		t.Errorf("unexpected instances: %v", ins)
+	}
+	if _, err = FromConfig(c, "missing"); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var staticInstances = []*ServiceInstance{
+	{ID: "1", Name: "helloworld", Endpoints: []string{"grpc://127.0.0.1:9000"}},
+	{ID: "2", Name: "helloworld", Endpoints: []string{"grpc://127.0.0.1:9001"}},
+	{ID: "3", Name: "greeter", Endpoints: []string{"grpc://127.0.0.1:9002"}},
+}
+
+func TestStaticGetService(t *testing.T) {
+	d := NewStatic(staticInstances)
+	ins, err := d.GetService(context.Background(), "helloworld")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ins, staticInstances[:2]) {
+		t.Errorf("expected %v, got %v", staticInstances[:2], ins)
+	}
+	if ins, _ = d.GetService(context.Background(), "unknown"); len(ins) != 0 {
+		t.Errorf("expected no instances, got %v", ins)
+	}
+}
+
+func TestStaticWatch(t *testing.T) {
+	d := NewStatic(staticInstances)
+	w, err := d.Watch(context.Background(), "greeter")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ins, err := w.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ins, staticInstances[2:]) {
+		t.Errorf("expected %v, got %v", staticInstances[2:], ins)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Next()
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		t.Fatalf("expected Next to block, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err = w.Stop(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+}
+
+func TestStaticWatchEmpty(t *testing.T) {
+	w, err := NewStatic(staticInstances).Watch(context.Background(), "unknown")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Stop()
+	done := make(chan error, 1)
+	go func() {
+		ins, err := w.Next()
+		if err == nil && len(ins) != 0 {
+			err = errors.New("unexpected instances")
+		}
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Errorf("expected the empty set, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the first Next to return the empty set")
+	}
+}
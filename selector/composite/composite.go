@@ -0,0 +1,187 @@
+package composite
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/node/direct"
+	"github.com/go-kratos/kratos/v2/selector/random"
+)
+
+const (
+	// Name is composite balancer name
+	Name = "composite"
+)
+
+var _ selector.Balancer = (*Balancer)(nil)
+
+// Layer narrows the candidates of a pick to the preferred ones, e.g. the
+// nodes in the zone of the client or the least loaded ones.
+type Layer func(ctx context.Context, nodes []selector.WeightedNode) []selector.WeightedNode
+
+// Filter adapts a node filter into a layer, e.g. filter.Labels for a
+// zone affinity. The nodes returned by f which are not weighted nodes, as
+// the ones it is given, are dropped.
+func Filter(f selector.NodeFilter) Layer {
+	return func(ctx context.Context, nodes []selector.WeightedNode) []selector.WeightedNode {
+		raw := make([]selector.Node, len(nodes))
+		for i, n := range nodes {
+			raw[i] = n
+		}
+		raw = f(ctx, raw)
+		narrowed := make([]selector.WeightedNode, 0, len(raw))
+		for _, n := range raw {
+			if wn, ok := n.(selector.WeightedNode); ok {
+				narrowed = append(narrowed, wn)
+			}
+		}
+		return narrowed
+	}
+}
+
+// LeastInflight keeps the nodes with the fewest in-flight requests picked
+// by the balancer. It ranks the candidates of a composite Balancer pick
+// only, which carry their in-flight requests: used in any other layer
+// chain it keeps every node.
+func LeastInflight() Layer {
+	return func(_ context.Context, nodes []selector.WeightedNode) []selector.WeightedNode {
+		var (
+			least    []selector.WeightedNode
+			fewest   int64
+			inflight = func(n selector.WeightedNode) int64 {
+				if c, ok := n.(*counted); ok {
+					return c.inflight
+				}
+				return 0
+			}
+		)
+		for _, n := range nodes {
+			switch i := inflight(n); {
+			case least == nil || i < fewest:
+				least, fewest = []selector.WeightedNode{n}, i
+			case i == fewest:
+				least = append(least, n)
+			}
+		}
+		return least
+	}
+}
+
+// Option is composite builder option.
+type Option func(o *options)
+
+// options is composite builder options
+type options struct {
+	layers   []Layer
+	tiebreak selector.BalancerBuilder
+}
+
+// WithLayers appends the layers narrowing the candidates, in order.
+func WithLayers(layers ...Layer) Option {
+	return func(o *options) {
+		o.layers = append(o.layers, layers...)
+	}
+}
+
+// WithTieBreak picks among the candidates left by the layers with the
+// balancer of b, default is random.
+func WithTieBreak(b selector.BalancerBuilder) Option {
+	return func(o *options) {
+		o.tiebreak = b
+	}
+}
+
+// Balancer is a composite balancer. A pick runs the layers in order, each
+// narrowing the candidates left by the previous one, and then breaks the
+// tie among the remaining candidates with the tie-break balancer. A layer
+// leaving no candidate is skipped, so that a preference such as a zone
+// affinity falls back to the other nodes instead of failing the pick.
+//
+// For example the layers Filter(filter.Labels(map[string]string{"zone":
+// "a"})) and LeastInflight() with the random tie-break pick a random node
+// among the least loaded nodes of zone a, or of all zones without any node
+// in zone a.
+type Balancer struct {
+	layers   []Layer
+	tiebreak selector.Balancer
+
+	mu       sync.Mutex
+	inflight map[string]int64
+}
+
+// New creates a composite selector.
+func New(opts ...Option) selector.Selector {
+	return NewBuilder(opts...).Build()
+}
+
+// counted is a candidate with the in-flight requests of its address at the
+// start of the pick.
+type counted struct {
+	selector.WeightedNode
+	inflight int64
+}
+
+// Pick is pick a weighted node.
+func (b *Balancer) Pick(ctx context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, selector.ErrNoAvailable
+	}
+	candidates := make([]selector.WeightedNode, len(nodes))
+	b.mu.Lock()
+	for i, n := range nodes {
+		candidates[i] = &counted{WeightedNode: n, inflight: b.inflight[n.Address()]}
+	}
+	b.mu.Unlock()
+	for _, layer := range b.layers {
+		if narrowed := layer(ctx, candidates); len(narrowed) > 0 {
+			candidates = narrowed
+		}
+	}
+	selected, done, err := b.tiebreak.Pick(ctx, candidates)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c, ok := selected.(*counted); ok {
+		selected = c.WeightedNode
+	}
+	addr := selected.Address()
+	b.mu.Lock()
+	b.inflight[addr]++
+	b.mu.Unlock()
+	return selected, func(ctx context.Context, di selector.DoneInfo) {
+		b.mu.Lock()
+		if b.inflight[addr]--; b.inflight[addr] <= 0 {
+			delete(b.inflight, addr)
+		}
+		b.mu.Unlock()
+		done(ctx, di)
+	}, nil
+}
+
+// NewBuilder returns a selector builder with composite balancer
+func NewBuilder(opts ...Option) selector.Builder {
+	option := options{tiebreak: &random.Builder{}}
+	for _, opt := range opts {
+		opt(&option)
+	}
+	return &selector.DefaultBuilder{
+		Balancer: &Builder{layers: option.layers, tiebreak: option.tiebreak},
+		Node:     &direct.Builder{},
+	}
+}
+
+// Builder is composite builder
+type Builder struct {
+	layers   []Layer
+	tiebreak selector.BalancerBuilder
+}
+
+// Build creates Balancer
+func (b *Builder) Build() selector.Balancer {
+	return &Balancer{
+		layers:   b.layers,
+		tiebreak: b.tiebreak.Build(),
+		inflight: make(map[string]int64),
+	}
+}
@@ -0,0 +1,147 @@
+package composite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/filter"
+	"github.com/go-kratos/kratos/v2/selector/node/direct"
+)
+
+func zoneNodes() []selector.Node {
+	var nodes []selector.Node
+	for _, n := range []struct{ addr, zone string }{
+		{"127.0.0.1:8080", "a"},
+		{"127.0.0.2:8080", "a"},
+		{"127.0.0.3:8080", "b"},
+	} {
+		nodes = append(nodes, selector.NewNode("http", n.addr, &registry.ServiceInstance{
+			ID:       n.addr,
+			Name:     "helloworld",
+			Metadata: map[string]string{"zone": n.zone},
+		}))
+	}
+	return nodes
+}
+
+func zoneLayers(zone string) Option {
+	return WithLayers(Filter(filter.Labels(map[string]string{"zone": zone})), LeastInflight())
+}
+
+func TestLayers(t *testing.T) {
+	s := New(zoneLayers("a"))
+	s.Apply(zoneNodes())
+	ctx := context.Background()
+
+	// the zone filter, then the least in-flight node of the zone
+	n1, done1, err := s.Select(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n2, done2, err := s.Select(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n1.Metadata()["zone"] != "a" || n2.Metadata()["zone"] != "a" || n1.Address() == n2.Address() {
+		t.Fatalf("expect both nodes of zone a, got %s and %s", n1.Address(), n2.Address())
+	}
+	// n2 is done, so it is the least loaded node of the zone
+	done2(ctx, selector.DoneInfo{})
+	for i := 0; i < 10; i++ {
+		n, done, err := s.Select(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.Address() != n2.Address() {
+			t.Fatalf("expect the least loaded %s, got %s", n2.Address(), n.Address())
+		}
+		done(ctx, selector.DoneInfo{})
+	}
+	done1(ctx, selector.DoneInfo{})
+
+	// the random tie-break among the equally loaded nodes of the zone
+	picked := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		n, done, err := s.Select(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		picked[n.Address()]++
+		done(ctx, selector.DoneInfo{})
+	}
+	if len(picked) != 2 || picked["127.0.0.3:8080"] != 0 {
+		t.Errorf("expect random picks among the nodes of zone a, got %v", picked)
+	}
+}
+
+func TestLayerFallback(t *testing.T) {
+	// no node in zone c, the least loaded nodes of every zone are candidates
+	s := New(zoneLayers("c"))
+	s.Apply(zoneNodes())
+	ctx := context.Background()
+	seen := make(map[string]bool)
+	var dones []selector.DoneFunc
+	for i := 0; i < 3; i++ {
+		n, done, err := s.Select(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[n.Address()] = true
+		dones = append(dones, done)
+	}
+	if len(seen) != 3 {
+		t.Errorf("expect every node picked once by least in-flight, got %v", seen)
+	}
+	for _, done := range dones {
+		done(ctx, selector.DoneInfo{})
+	}
+	b := s.(*selector.Default).Balancer.(*Balancer)
+	if len(b.inflight) != 0 {
+		t.Errorf("expect no in-flight requests left, got %v", b.inflight)
+	}
+}
+
+// firstBalancer picks the first candidate.
+type firstBalancer struct{}
+
+func (firstBalancer) Build() selector.Balancer { return firstBalancer{} }
+
+func (firstBalancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	return nodes[0], nodes[0].Pick(), nil
+}
+
+func TestTieBreak(t *testing.T) {
+	s := New(WithLayers(Filter(filter.Labels(map[string]string{"zone": "a"}))), WithTieBreak(firstBalancer{}))
+	s.Apply(zoneNodes())
+	for i := 0; i < 3; i++ {
+		n, done, err := s.Select(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.Address() != "127.0.0.1:8080" {
+			t.Errorf("expect the tie-break pick 127.0.0.1:8080, got %s", n.Address())
+		}
+		done(context.Background(), selector.DoneInfo{})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	b := (&Builder{tiebreak: firstBalancer{}}).Build()
+	if _, _, err := b.Pick(context.Background(), nil); err != selector.ErrNoAvailable {
+		t.Errorf("expect %v, got %v", selector.ErrNoAvailable, err)
+	}
+}
+
+func TestFilterUnweighted(t *testing.T) {
+	// a filter returning plain nodes instead of the weighted nodes it is given
+	unwrap := func(_ context.Context, nodes []selector.Node) []selector.Node {
+		return append(zoneNodes()[:1], nodes[1])
+	}
+	wn := (&direct.Builder{}).Build(zoneNodes()[1])
+	narrowed := Filter(unwrap)(context.Background(), []selector.WeightedNode{wn, wn})
+	if len(narrowed) != 1 || narrowed[0] != wn {
+		t.Errorf("expect the plain node dropped, got %v", narrowed)
+	}
+}
@@ -10,6 +10,7 @@ var _ Node = (*DefaultNode)(nil)
 
 // DefaultNode is selector node
 type DefaultNode struct {
+	id       string
 	scheme   string
 	addr     string
 	weight   *int64
@@ -18,6 +19,11 @@ type DefaultNode struct {
 	metadata map[string]string
 }
 
+// ID is node instance id
+func (n *DefaultNode) ID() string {
+	return n.id
+}
+
 // Scheme is node scheme
 func (n *DefaultNode) Scheme() string {
 	return n.scheme
@@ -55,6 +61,7 @@ func NewNode(scheme, addr string, ins *registry.ServiceInstance) Node {
 		addr:   addr,
 	}
 	if ins != nil {
+		n.id = ins.ID
 		n.name = ins.Name
 		n.version = ins.Version
 		n.metadata = ins.Metadata
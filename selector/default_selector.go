@@ -2,85 +2,183 @@ package selector
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"sync/atomic"
+	"time"
 )
 
 var (
-	_ Rebalancer = (*Default)(nil)
-	_ Builder    = (*DefaultBuilder)(nil)
+	_ Rebalancer  = (*Default)(nil)
+	_ Snapshotter = (*Default)(nil)
+	_ Builder     = (*DefaultBuilder)(nil)
 )
 
 // Default is composite selector.
 type Default struct {
 	NodeBuilder WeightedNodeBuilder
 	Balancer    Balancer
+	// SortNodes sorts the applied nodes by instance ID, then by address, so
+	// balancers see the candidates in a deterministic order whatever the
+	// discovery order is. The ID is the one of the nodes implementing
+	// ID() string, such as the ones of NewNode.
+	SortNodes bool
+	// DrainTimeout bounds how long a removed node is tracked while its
+	// in-flight requests complete, default is DefaultDrainTimeout.
+	DrainTimeout time.Duration
+	// SlowStart is the window over which the weight of a node added after
+	// the first Apply, added back after it was removed, or passing the
+	// health filters again after they rejected it, ramps up linearly to
+	// its full weight. Zero disables slow start.
+	SlowStart time.Duration
+	// PanicThreshold is the fraction of healthy candidates below which the
+	// health filters are ignored and every candidate is balanced, so that a
+	// flapping health check never rejects all the calls. Zero disables the
+	// panic mode.
+	PanicThreshold float64
 
-	nodes atomic.Value
+	nodes       atomic.Value
+	drainer     drainer
+	slowStarter slowStarter
 }
 
 // Select is select one node.
 func (d *Default) Select(ctx context.Context, opts ...SelectOption) (selected Node, done DoneFunc, err error) {
-	var (
-		options    SelectOptions
-		candidates []WeightedNode
-	)
-	nodes, ok := d.nodes.Load().([]WeightedNode)
-	if !ok {
-		return nil, nil, ErrNoAvailable
+	var options SelectOptions
+	p, hasPeer := FromPeerContext(ctx)
+	if hasPeer {
+		// Reset the peer so a failed attempt never reports the node of a previous one.
+		p.Node = nil
 	}
 	for _, o := range opts {
 		o(&options)
 	}
-	if len(options.NodeFilters) > 0 {
-		newNodes := make([]Node, len(nodes))
-		for i, wc := range nodes {
-			newNodes[i] = wc
+	options.NodeFilters = append(options.NodeFilters, FromFilterContext(ctx)...)
+	for i := 0; i < maxStalePicks; i++ {
+		candidates, err := d.candidates(ctx, options.NodeFilters, FromHealthFilterContext(ctx))
+		if err != nil {
+			return nil, nil, err
+		}
+		wn, done, err := d.Balancer.Pick(ctx, candidates)
+		if err != nil {
+			if _, ok := NoAvailableCauseOf(err); !ok && errors.Is(err, ErrNoAvailable) {
+				err = errNoAvailableUnhealthy
+			}
+			return nil, nil, err
 		}
-		for _, filter := range options.NodeFilters {
-			newNodes = filter(ctx, newNodes)
+		addr := wn.Address()
+		t, ok := d.drainer.acquire(addr)
+		if !ok {
+			// the node was removed while picking, pick again among the new nodes
+			done(ctx, DoneInfo{Err: ErrNoAvailable})
+			continue
 		}
-		candidates = make([]WeightedNode, len(newNodes))
-		for i, n := range newNodes {
-			candidates[i] = n.(WeightedNode)
+		if hasPeer {
+			p.Node = wn.Raw()
 		}
-	} else {
-		candidates = nodes
+		return wn.Raw(), func(ctx context.Context, di DoneInfo) {
+			done(ctx, di)
+			d.drainer.release(addr, t)
+		}, nil
 	}
+	return nil, nil, errNoAvailableRemoved
+}
 
+func (d *Default) candidates(ctx context.Context, filters, health []NodeFilter) ([]WeightedNode, error) {
+	nodes, ok := d.nodes.Load().([]WeightedNode)
+	if !ok || len(nodes) == 0 {
+		return nil, errNoAvailableEmpty
+	}
+	candidates := filterNodes(ctx, nodes, filters)
 	if len(candidates) == 0 {
-		return nil, nil, ErrNoAvailable
+		return nil, errNoAvailableFiltered
 	}
-	wn, done, err := d.Balancer.Pick(ctx, candidates)
-	if err != nil {
-		return nil, nil, err
+	healthy := filterNodes(ctx, candidates, health)
+	if len(health) > 0 {
+		healthy = d.slowStarter.readmit(candidates, healthy, d.SlowStart)
 	}
-	p, ok := FromPeerContext(ctx)
-	if ok {
-		p.Node = wn.Raw()
+	if d.PanicThreshold > 0 && float64(len(healthy)) < d.PanicThreshold*float64(len(candidates)) {
+		// panic mode: better any node than none
+		return candidates, nil
 	}
-	return wn.Raw(), done, nil
+	if len(healthy) == 0 {
+		return nil, errNoAvailableHealth
+	}
+	return healthy, nil
+}
+
+func filterNodes(ctx context.Context, nodes []WeightedNode, filters []NodeFilter) []WeightedNode {
+	if len(filters) == 0 {
+		return nodes
+	}
+	newNodes := make([]Node, len(nodes))
+	for i, wc := range nodes {
+		newNodes[i] = wc
+	}
+	for _, filter := range filters {
+		newNodes = filter(ctx, newNodes)
+	}
+	filtered := make([]WeightedNode, len(newNodes))
+	for i, n := range newNodes {
+		filtered[i] = n.(WeightedNode)
+	}
+	return filtered
 }
 
 // Apply update nodes info.
 func (d *Default) Apply(nodes []Node) {
+	if d.SortNodes {
+		nodes = sortNodes(nodes)
+	}
 	weightedNodes := make([]WeightedNode, 0, len(nodes))
 	for _, n := range nodes {
 		weightedNodes = append(weightedNodes, d.NodeBuilder.Build(n))
 	}
+	weightedNodes = d.slowStarter.apply(weightedNodes, d.SlowStart)
 	// TODO: Do not delete unchanged nodes
-	d.nodes.Store(weightedNodes)
+	d.drainer.apply(weightedNodes, d.DrainTimeout, func() {
+		d.nodes.Store(weightedNodes)
+	})
+}
+
+// sortNodes returns a copy of nodes sorted by instance ID, then by address.
+func sortNodes(nodes []Node) []Node {
+	sorted := append([]Node(nil), nodes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if a, b := nodeID(sorted[i]), nodeID(sorted[j]); a != b {
+			return a < b
+		}
+		return sorted[i].Address() < sorted[j].Address()
+	})
+	return sorted
+}
+
+// nodeID returns the instance ID of the nodes implementing ID() string.
+func nodeID(n Node) string {
+	if in, ok := n.(interface{ ID() string }); ok {
+		return in.ID()
+	}
+	return ""
 }
 
 // DefaultBuilder is de
 type DefaultBuilder struct {
-	Node     WeightedNodeBuilder
-	Balancer BalancerBuilder
+	Node           WeightedNodeBuilder
+	Balancer       BalancerBuilder
+	SortNodes      bool
+	DrainTimeout   time.Duration
+	SlowStart      time.Duration
+	PanicThreshold float64
 }
 
 // Build create builder
 func (db *DefaultBuilder) Build() Selector {
 	return &Default{
-		NodeBuilder: db.Node,
-		Balancer:    db.Balancer.Build(),
+		NodeBuilder:    db.Node,
+		Balancer:       db.Balancer.Build(),
+		SortNodes:      db.SortNodes,
+		DrainTimeout:   db.DrainTimeout,
+		SlowStart:      db.SlowStart,
+		PanicThreshold: db.PanicThreshold,
 	}
 }
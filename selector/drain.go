@@ -0,0 +1,125 @@
+package selector
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultDrainTimeout is the default maximum time a removed node is tracked
+// while its in-flight requests complete.
+const DefaultDrainTimeout = 30 * time.Second
+
+// maxStalePicks bounds the picks retried when the balancer returns a node
+// removed by a concurrent Apply.
+const maxStalePicks = 3
+
+// tracked is the in-flight accounting of a node address.
+type tracked struct {
+	node     Node
+	inflight int64
+	// drain is set while the node is removed but still has in-flight requests.
+	drain *time.Timer
+}
+
+// drainer excludes removed nodes from new picks and tracks them until their
+// in-flight requests are done or the drain timeout expires.
+type drainer struct {
+	mu      sync.Mutex
+	active  map[string]struct{}
+	tracked map[string]*tracked
+}
+
+// apply switches the active nodes, the removed nodes with in-flight requests
+// start draining. store publishes the nodes under the lock, so a pick racing
+// with apply is never accounted to a removed node.
+func (d *drainer) apply(nodes []WeightedNode, timeout time.Duration, store func()) {
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tracked == nil {
+		d.tracked = make(map[string]*tracked)
+	}
+	active := make(map[string]struct{}, len(nodes))
+	for _, n := range nodes {
+		addr := n.Address()
+		active[addr] = struct{}{}
+		t, ok := d.tracked[addr]
+		if !ok {
+			d.tracked[addr] = &tracked{node: n.Raw()}
+			continue
+		}
+		t.node = n.Raw()
+		if t.drain != nil {
+			// added back while draining
+			t.drain.Stop()
+			t.drain = nil
+		}
+	}
+	for addr, t := range d.tracked {
+		if _, ok := active[addr]; ok || t.drain != nil {
+			continue
+		}
+		if t.inflight == 0 {
+			delete(d.tracked, addr)
+			continue
+		}
+		addr, t := addr, t
+		t.drain = time.AfterFunc(timeout, func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.drop(addr, t)
+		})
+	}
+	d.active = active
+	store()
+}
+
+// acquire accounts a new request to addr, it fails if addr is not active.
+func (d *drainer) acquire(addr string) (*tracked, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.active[addr]; !ok {
+		return nil, false
+	}
+	t := d.tracked[addr]
+	t.inflight++
+	return t, true
+}
+
+// release accounts the end of a request, a draining node is dropped once
+// its last in-flight request is done.
+func (d *drainer) release(addr string, t *tracked) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t.inflight--
+	if t.inflight <= 0 && t.drain != nil {
+		t.drain.Stop()
+		d.drop(addr, t)
+	}
+}
+
+func (d *drainer) drop(addr string, t *tracked) {
+	if d.tracked[addr] == t && t.drain != nil {
+		delete(d.tracked, addr)
+	}
+}
+
+// Draining returns the removed nodes which still have in-flight requests,
+// sorted by address. They are never returned by Select.
+func (d *Default) Draining() []Node {
+	d.drainer.mu.Lock()
+	defer d.drainer.mu.Unlock()
+	var nodes []Node
+	for _, t := range d.drainer.tracked {
+		if t.drain != nil {
+			nodes = append(nodes, t.node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Address() < nodes[j].Address()
+	})
+	return nodes
+}
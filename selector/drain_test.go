@@ -0,0 +1,141 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// staleBalancer always picks the node of address, even once it is removed.
+type staleBalancer struct {
+	address string
+	node    WeightedNode
+}
+
+func (b *staleBalancer) Pick(_ context.Context, nodes []WeightedNode) (WeightedNode, DoneFunc, error) {
+	for _, n := range nodes {
+		if n.Address() == b.address {
+			b.node = n
+		}
+	}
+	if b.node == nil {
+		return nil, nil, ErrNoAvailable
+	}
+	return b.node, b.node.Pick(), nil
+}
+
+func drainNode(addr string) Node {
+	return NewNode("http", addr, &registry.ServiceInstance{ID: addr, Name: "helloworld"})
+}
+
+func drainingAddrs(d *Default) []string {
+	var addrs []string
+	for _, n := range d.Draining() {
+		addrs = append(addrs, n.Address())
+	}
+	return addrs
+}
+
+func TestDrain(t *testing.T) {
+	b := &staleBalancer{address: "127.0.0.1:8080"}
+	d := &Default{NodeBuilder: &mockWeightedNodeBuilder{}, Balancer: b}
+	d.Apply([]Node{drainNode("127.0.0.1:8080"), drainNode("127.0.0.1:9090")})
+
+	n, done, err := d.Select(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Address() != "127.0.0.1:8080" {
+		t.Fatalf("expect 127.0.0.1:8080, got %s", n.Address())
+	}
+
+	// removed with an in-flight request
+	d.Apply([]Node{drainNode("127.0.0.1:9090")})
+	if got := drainingAddrs(d); len(got) != 1 || got[0] != "127.0.0.1:8080" {
+		t.Fatalf("expect 127.0.0.1:8080 draining, got %v", got)
+	}
+	// a pick of the draining node is never returned
+	if _, _, err = d.Select(context.Background()); !errors.Is(err, ErrNoAvailable) {
+		t.Fatalf("expect %v, got %v", ErrNoAvailable, err)
+	}
+
+	// the in-flight request completes
+	done(context.Background(), DoneInfo{})
+	if got := drainingAddrs(d); len(got) != 0 {
+		t.Fatalf("expect no draining node, got %v", got)
+	}
+}
+
+func TestDrainNoInflight(t *testing.T) {
+	d := &Default{NodeBuilder: &mockWeightedNodeBuilder{}, Balancer: &mockBalancer{}}
+	d.Apply([]Node{drainNode("127.0.0.1:8080"), drainNode("127.0.0.1:9090")})
+	_, done, err := d.Select(context.Background(), WithNodeFilter(func(_ context.Context, nodes []Node) []Node {
+		return nodes[1:]
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done(context.Background(), DoneInfo{})
+
+	d.Apply([]Node{drainNode("127.0.0.1:9090")})
+	if got := drainingAddrs(d); len(got) != 0 {
+		t.Fatalf("expect no draining node, got %v", got)
+	}
+	for i := 0; i < 10; i++ {
+		n, done, err := d.Select(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.Address() != "127.0.0.1:9090" {
+			t.Fatalf("expect 127.0.0.1:9090, got %s", n.Address())
+		}
+		done(context.Background(), DoneInfo{})
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	d := &Default{NodeBuilder: &mockWeightedNodeBuilder{}, Balancer: &mockBalancer{}, DrainTimeout: 20 * time.Millisecond}
+	d.Apply([]Node{drainNode("127.0.0.1:8080")})
+	_, done, err := d.Select(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.Apply(nil)
+	if got := drainingAddrs(d); len(got) != 1 {
+		t.Fatalf("expect 1 draining node, got %v", got)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := drainingAddrs(d); len(got) != 0 {
+		t.Fatalf("expect the drain to time out, got %v", got)
+	}
+	// a late done is harmless
+	done(context.Background(), DoneInfo{})
+}
+
+func TestDrainReadd(t *testing.T) {
+	d := &Default{NodeBuilder: &mockWeightedNodeBuilder{}, Balancer: &mockBalancer{}}
+	d.Apply([]Node{drainNode("127.0.0.1:8080")})
+	_, done, err := d.Select(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.Apply(nil)
+	d.Apply([]Node{drainNode("127.0.0.1:8080")})
+	if got := drainingAddrs(d); len(got) != 0 {
+		t.Fatalf("expect no draining node, got %v", got)
+	}
+	done(context.Background(), DoneInfo{})
+	n, done, err := d.Select(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Address() != "127.0.0.1:8080" {
+		t.Fatalf("expect 127.0.0.1:8080, got %s", n.Address())
+	}
+	done(context.Background(), DoneInfo{})
+}
@@ -4,3 +4,36 @@ import "context"
 
 // NodeFilter is select filter.
 type NodeFilter func(context.Context, []Node) []Node
+
+type filterKey struct{}
+
+// NewFilterContext returns a new context carrying node filters, which are applied
+// by the selector in addition to the filters passed with WithNodeFilter.
+// It lets middleware narrow the candidate nodes of a single call.
+func NewFilterContext(ctx context.Context, filters ...NodeFilter) context.Context {
+	return context.WithValue(ctx, filterKey{}, append(FromFilterContext(ctx), filters...))
+}
+
+// FromFilterContext returns the node filters in ctx if they exist.
+func FromFilterContext(ctx context.Context) []NodeFilter {
+	filters, _ := ctx.Value(filterKey{}).([]NodeFilter)
+	// copy to keep the filters of parent contexts untouched
+	return append([]NodeFilter(nil), filters...)
+}
+
+type healthKey struct{}
+
+// NewHealthFilterContext returns a new context carrying health filters,
+// which remove the unhealthy nodes from the candidates of a single call,
+// e.g. the nodes whose circuit breaker is open. Unlike the node filters,
+// they are ignored in panic mode, see Default.PanicThreshold.
+func NewHealthFilterContext(ctx context.Context, filters ...NodeFilter) context.Context {
+	return context.WithValue(ctx, healthKey{}, append(FromHealthFilterContext(ctx), filters...))
+}
+
+// FromHealthFilterContext returns the health filters in ctx if they exist.
+func FromHealthFilterContext(ctx context.Context) []NodeFilter {
+	filters, _ := ctx.Value(healthKey{}).([]NodeFilter)
+	// copy to keep the filters of parent contexts untouched
+	return append([]NodeFilter(nil), filters...)
+}
@@ -0,0 +1,20 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// Labels is metadata labels filter, it keeps the nodes matching every label.
+func Labels(labels map[string]string) selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		newNodes := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if selector.MatchLabels(n, labels) {
+				newNodes = append(newNodes, n)
+			}
+		}
+		return newNodes
+	}
+}
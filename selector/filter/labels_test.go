@@ -0,0 +1,22 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestLabels(t *testing.T) {
+	f := Labels(map[string]string{"zone": "a"})
+	nodes := []selector.Node{
+		selector.NewNode("http", "127.0.0.1:9090", &registry.ServiceInstance{Metadata: map[string]string{"zone": "a"}}),
+		selector.NewNode("http", "127.0.0.2:9090", &registry.ServiceInstance{Metadata: map[string]string{"zone": "b"}}),
+		selector.NewNode("http", "127.0.0.3:9090", nil),
+	}
+	nodes = f(context.Background(), nodes)
+	if len(nodes) != 1 || nodes[0].Address() != "127.0.0.1:9090" {
+		t.Errorf("expect node 127.0.0.1:9090, got %v", nodes)
+	}
+}
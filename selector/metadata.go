@@ -0,0 +1,37 @@
+package selector
+
+import "strconv"
+
+// MetadataInt returns the metadata value of key parsed as an integer,
+// ok is false when the key is missing or not an integer.
+func MetadataInt(n Node, key string) (v int64, ok bool) {
+	s, ok := n.Metadata()[key]
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	return v, err == nil
+}
+
+// MetadataBool returns the metadata value of key parsed as a bool, such as
+// "true" or "0", ok is false when the key is missing or not a bool.
+func MetadataBool(n Node, key string) (v bool, ok bool) {
+	s, ok := n.Metadata()[key]
+	if !ok {
+		return false, false
+	}
+	v, err := strconv.ParseBool(s)
+	return v, err == nil
+}
+
+// MatchLabels reports whether the metadata of n contains every key value
+// pair of labels, e.g. {"zone": "us-east-1a"}. Empty labels match every node.
+func MatchLabels(n Node, labels map[string]string) bool {
+	md := n.Metadata()
+	for k, v := range labels {
+		if mv, ok := md[k]; !ok || mv != v {
+			return false
+		}
+	}
+	return true
+}
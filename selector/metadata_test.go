@@ -0,0 +1,59 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func newMetadataNode(md map[string]string) Node {
+	return NewNode("grpc", "127.0.0.1:9000", &registry.ServiceInstance{Metadata: md})
+}
+
+func TestMetadataInt(t *testing.T) {
+	n := newMetadataNode(map[string]string{"shard": "3", "zone": "a"})
+	if v, ok := MetadataInt(n, "shard"); !ok || v != 3 {
+		t.Errorf("expected 3, got %d %v", v, ok)
+	}
+	if _, ok := MetadataInt(n, "zone"); ok {
+		t.Error("expected an invalid integer not ok")
+	}
+	if _, ok := MetadataInt(n, "missing"); ok {
+		t.Error("expected a missing key not ok")
+	}
+}
+
+func TestMetadataBool(t *testing.T) {
+	n := newMetadataNode(map[string]string{"canary": "true", "stable": "0", "zone": "a"})
+	if v, ok := MetadataBool(n, "canary"); !ok || !v {
+		t.Errorf("expected true, got %v %v", v, ok)
+	}
+	if v, ok := MetadataBool(n, "stable"); !ok || v {
+		t.Errorf("expected false, got %v %v", v, ok)
+	}
+	if _, ok := MetadataBool(n, "zone"); ok {
+		t.Error("expected an invalid bool not ok")
+	}
+	if _, ok := MetadataBool(NewNode("grpc", "127.0.0.1:9000", nil), "canary"); ok {
+		t.Error("expected a node without metadata not ok")
+	}
+}
+
+func TestMatchLabels(t *testing.T) {
+	n := newMetadataNode(map[string]string{"zone": "a", "version": "v2"})
+	tests := []struct {
+		labels map[string]string
+		want   bool
+	}{
+		{nil, true},
+		{map[string]string{"zone": "a"}, true},
+		{map[string]string{"zone": "a", "version": "v2"}, true},
+		{map[string]string{"zone": "b"}, false},
+		{map[string]string{"zone": "a", "canary": "true"}, false},
+	}
+	for _, test := range tests {
+		if got := MatchLabels(n, test.labels); got != test.want {
+			t.Errorf("MatchLabels(%v) = %v, want %v", test.labels, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package selector
+
+import (
+	stderrors "errors"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// NoAvailableCause is why no node is available, it is carried by the
+// errors returned by the Default selector in the "cause" metadata.
+type NoAvailableCause string
+
+const (
+	// NoAvailableEmpty is no node discovered.
+	NoAvailableEmpty NoAvailableCause = "empty"
+	// NoAvailableFiltered is every node removed by the node filters.
+	NoAvailableFiltered NoAvailableCause = "filtered"
+	// NoAvailableUnhealthy is every candidate rejected by the health filters
+	// or by the balancer.
+	NoAvailableUnhealthy NoAvailableCause = "unhealthy"
+	// NoAvailableRemoved is every picked node removed by a concurrent update.
+	NoAvailableRemoved NoAvailableCause = "removed"
+)
+
+const metadataCause = "cause"
+
+var (
+	errNoAvailableEmpty     = noAvailable(NoAvailableEmpty, "no node discovered")
+	errNoAvailableFiltered  = noAvailable(NoAvailableFiltered, "all nodes filtered")
+	errNoAvailableUnhealthy = noAvailable(NoAvailableUnhealthy, "all nodes rejected by the balancer")
+	errNoAvailableHealth    = noAvailable(NoAvailableUnhealthy, "all nodes rejected by the health filters")
+	errNoAvailableRemoved   = noAvailable(NoAvailableRemoved, "all picked nodes removed")
+)
+
+// noAvailable returns ErrNoAvailable annotated with cause, it still
+// matches ErrNoAvailable with errors.Is.
+func noAvailable(cause NoAvailableCause, message string) *errors.Error {
+	err := errors.Clone(ErrNoAvailable).WithMetadata(map[string]string{metadataCause: string(cause)})
+	err.Message = message
+	return err
+}
+
+// NoAvailableCauseOf returns the cause of an ErrNoAvailable error returned
+// by the Default selector, also when wrapped by another error such as the
+// one of a client failing to select a node, ok is false for other errors.
+func NoAvailableCauseOf(err error) (cause NoAvailableCause, ok bool) {
+	for se := new(errors.Error); stderrors.As(err, &se); err = se.Unwrap() {
+		if se.Is(ErrNoAvailable) {
+			c, ok := se.Metadata[metadataCause]
+			return NoAvailableCause(c), ok
+		}
+	}
+	return "", false
+}
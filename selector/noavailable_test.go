@@ -0,0 +1,82 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+type funcBalancerBuilder func(ctx context.Context, nodes []WeightedNode) (WeightedNode, DoneFunc, error)
+
+func (b funcBalancerBuilder) Build() Balancer { return b }
+
+func (b funcBalancerBuilder) Pick(ctx context.Context, nodes []WeightedNode) (WeightedNode, DoneFunc, error) {
+	return b(ctx, nodes)
+}
+
+func noAvailableNode(addr, version string) Node {
+	return NewNode("http", addr, &registry.ServiceInstance{Version: version})
+}
+
+func TestNoAvailableCause(t *testing.T) {
+	unhealthy := funcBalancerBuilder(func(context.Context, []WeightedNode) (WeightedNode, DoneFunc, error) {
+		return nil, nil, ErrNoAvailable
+	})
+	var (
+		sel  Selector
+		port int
+	)
+	removing := funcBalancerBuilder(func(_ context.Context, nodes []WeightedNode) (WeightedNode, DoneFunc, error) {
+		// the picked node is replaced concurrently on every pick
+		port++
+		sel.Apply([]Node{noAvailableNode(fmt.Sprintf("127.0.0.1:%d", 9000+port), "v1")})
+		return nodes[0], func(context.Context, DoneInfo) {}, nil
+	})
+	tests := []struct {
+		name     string
+		balancer BalancerBuilder
+		nodes    []Node
+		filters  []NodeFilter
+		health   []NodeFilter
+		cause    NoAvailableCause
+	}{
+		{"not applied", &mockBalancerBuilder{}, nil, nil, nil, NoAvailableEmpty},
+		{"empty", &mockBalancerBuilder{}, []Node{}, nil, nil, NoAvailableEmpty},
+		{"filtered", &mockBalancerBuilder{}, []Node{noAvailableNode("127.0.0.1:8000", "v1")}, []NodeFilter{mockFilter("v2")}, nil, NoAvailableFiltered},
+		{"unhealthy", unhealthy, []Node{noAvailableNode("127.0.0.1:8000", "v1")}, nil, nil, NoAvailableUnhealthy},
+		{"health filtered", &mockBalancerBuilder{}, []Node{noAvailableNode("127.0.0.1:8000", "v1")}, nil, []NodeFilter{mockFilter("v2")}, NoAvailableUnhealthy},
+		{"removed", removing, []Node{noAvailableNode("127.0.0.1:8000", "v1")}, nil, nil, NoAvailableRemoved},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sel = (&DefaultBuilder{Node: &mockWeightedNodeBuilder{}, Balancer: test.balancer}).Build()
+			if test.nodes != nil {
+				sel.Apply(test.nodes)
+			}
+			ctx := NewHealthFilterContext(context.Background(), test.health...)
+			_, _, err := sel.Select(ctx, WithNodeFilter(test.filters...))
+			if !errors.Is(err, ErrNoAvailable) {
+				t.Fatalf("expected %v, got %v", ErrNoAvailable, err)
+			}
+			cause, ok := NoAvailableCauseOf(err)
+			if !ok || cause != test.cause {
+				t.Errorf("expected cause %s, got %s %v", test.cause, cause, ok)
+			}
+		})
+	}
+}
+
+func TestNoAvailableCauseOf(t *testing.T) {
+	if _, ok := NoAvailableCauseOf(ErrNoAvailable); ok {
+		t.Error("expected no cause of the bare error")
+	}
+	if _, ok := NoAvailableCauseOf(errNodeNotMatch); ok {
+		t.Error("expected no cause of another error")
+	}
+	if _, ok := NoAvailableCauseOf(nil); ok {
+		t.Error("expected no cause of nil")
+	}
+}
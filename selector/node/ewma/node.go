@@ -21,6 +21,7 @@ const (
 var (
 	_ selector.WeightedNode        = (*Node)(nil)
 	_ selector.WeightedNodeBuilder = (*Builder)(nil)
+	_ selector.Stater              = (*Node)(nil)
 )
 
 // Node is endpoint instance
@@ -39,8 +40,9 @@ type Node struct {
 	// last lastPick timestamp
 	lastPick int64
 
-	errHandler   func(err error) (isErr bool)
-	cachedWeight *atomic.Value
+	errHandler    func(err error) (isErr bool)
+	successWeight float64
+	cachedWeight  *atomic.Value
 }
 
 type nodeWeight struct {
@@ -51,17 +53,23 @@ type nodeWeight struct {
 // Builder is ewma node builder.
 type Builder struct {
 	ErrHandler func(err error) (isErr bool)
+	// SuccessWeight is the exponent of the success rate in the node weight,
+	// which balances it against the latency. Larger values penalize failing
+	// nodes more, e.g. with 4 a node failing half of its requests gets 1/16
+	// of the weight of a healthy node of the same latency. Default is 1.
+	SuccessWeight float64
 }
 
 // Build create a weighted node.
 func (b *Builder) Build(n selector.Node) selector.WeightedNode {
 	s := &Node{
-		Node:         n,
-		lag:          0,
-		success:      1000,
-		inflight:     1,
-		errHandler:   b.ErrHandler,
-		cachedWeight: &atomic.Value{},
+		Node:          n,
+		lag:           0,
+		success:       1000,
+		inflight:      1,
+		errHandler:    b.ErrHandler,
+		successWeight: b.SuccessWeight,
+		cachedWeight:  &atomic.Value{},
 	}
 	return s
 }
@@ -169,9 +177,13 @@ func (n *Node) Weight() (weight float64) {
 	w, ok := n.cachedWeight.Load().(*nodeWeight)
 	now := time.Now().UnixNano()
 	if !ok || time.Duration(now-w.updateAt) > (time.Millisecond*5) {
-		health := n.health()
+		health := float64(n.health())
+		if n.successWeight > 0 && n.successWeight != 1 {
+			// a node without data has a success rate of 1, the neutral score
+			health = math.Pow(health/1000, n.successWeight) * 1000
+		}
 		load := n.load()
-		weight = float64(health*uint64(time.Microsecond)*10) / float64(load)
+		weight = health * float64(uint64(time.Microsecond)*10) / float64(load)
 		n.cachedWeight.Store(&nodeWeight{
 			value:    weight,
 			updateAt: now,
@@ -182,6 +194,15 @@ func (n *Node) Weight() (weight float64) {
 	return
 }
 
+// Stats returns the node runtime statistics.
+func (n *Node) Stats() map[string]float64 {
+	return map[string]float64{
+		"lag":      float64(atomic.LoadInt64(&n.lag)),
+		"success":  float64(n.health()),
+		"inflight": float64(atomic.LoadInt64(&n.inflight)),
+	}
+}
+
 func (n *Node) PickElapsed() time.Duration {
 	return time.Duration(time.Now().UnixNano() - atomic.LoadInt64(&n.lastPick))
 }
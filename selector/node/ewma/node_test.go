@@ -147,3 +147,34 @@ func BenchmarkPickAndWeight(b *testing.B) {
 		}
 	})
 }
+
+func TestSuccessWeight(t *testing.T) {
+	build := func(b *Builder, success uint64, lag time.Duration) *Node {
+		n := b.Build(selector.NewNode("http", "127.0.0.1:9090", &registry.ServiceInstance{})).(*Node)
+		n.success = success
+		n.lag = int64(lag)
+		return n
+	}
+	// fast but failing half of its requests, against slow but healthy
+	for _, tt := range []struct {
+		successWeight float64
+		failingWins   bool
+	}{
+		{0, true},
+		{1, true},
+		{4, false},
+	} {
+		b := &Builder{SuccessWeight: tt.successWeight}
+		failing := build(b, 500, time.Millisecond)
+		healthy := build(b, 1000, 20*time.Millisecond)
+		if got := failing.Weight() > healthy.Weight(); got != tt.failingWins {
+			t.Errorf("success weight %v: expect the failing node to win %v, got weights %v and %v",
+				tt.successWeight, tt.failingWins, failing.Weight(), healthy.Weight())
+		}
+	}
+	// a node without data has the neutral success rate
+	fresh := build(&Builder{SuccessWeight: 4}, 1000, 0).Weight()
+	if want := build(&Builder{}, 1000, 0).Weight(); fresh != want {
+		t.Errorf("expect the weight %v of a node without data, got %v", want, fresh)
+	}
+}
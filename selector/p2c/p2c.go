@@ -23,7 +23,39 @@ var _ selector.Balancer = (*Balancer)(nil)
 type Option func(o *options)
 
 // options is p2c builder options
-type options struct{}
+type options struct {
+	successWeight  float64
+	slowStart      time.Duration
+	panicThreshold float64
+}
+
+// WithSuccessWeight sets the exponent of the success rate in the node
+// weight, which balances it against the latency, default is 1. Raise it so
+// that fast nodes returning errors lose their traffic to healthy ones.
+func WithSuccessWeight(w float64) Option {
+	return func(o *options) {
+		o.successWeight = w
+	}
+}
+
+// WithSlowStart ramps the weight of the nodes added, or added back, after
+// the first update linearly up to their full weight over d, so a recovered
+// node is not flooded at once. Default is disabled.
+func WithSlowStart(d time.Duration) Option {
+	return func(o *options) {
+		o.slowStart = d
+	}
+}
+
+// WithPanicMode balances every node, ignoring the health filters such as
+// the open circuit breakers, when the fraction of healthy nodes drops below
+// threshold, e.g. 0.5. It gambles on the unhealthy nodes rather than
+// rejecting every call. Default is disabled.
+func WithPanicMode(threshold float64) Option {
+	return func(o *options) {
+		o.panicThreshold = threshold
+	}
+}
 
 // New creates a p2c selector.
 func New(opts ...Option) selector.Selector {
@@ -86,8 +118,10 @@ func NewBuilder(opts ...Option) selector.Builder {
 		opt(&option)
 	}
 	return &selector.DefaultBuilder{
-		Balancer: &Builder{},
-		Node:     &ewma.Builder{},
+		Balancer:       &Builder{},
+		Node:           &ewma.Builder{SuccessWeight: option.successWeight},
+		SlowStart:      option.slowStart,
+		PanicThreshold: option.panicThreshold,
 	}
 }
 
@@ -13,6 +13,7 @@ import (
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/filter"
+	"github.com/go-kratos/kratos/v2/selector/node/ewma"
 )
 
 func TestWrr3(t *testing.T) {
@@ -120,3 +121,43 @@ func TestOne(t *testing.T) {
 		t.Errorf("expect %v, got %v", "127.0.0.0:8080", n.Address())
 	}
 }
+
+func TestSnapshotDone(t *testing.T) {
+	p2c := New()
+	p2c.Apply([]selector.Node{selector.NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{ID: "1"})})
+	snapshotter, ok := p2c.(selector.Snapshotter)
+	if !ok {
+		t.Fatal("p2c selector must implement selector.Snapshotter")
+	}
+	_, done, err := p2c.Select(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := snapshotter.Snapshot().Nodes[0].ID; id != "1" {
+		t.Errorf("expect id 1, got %q", id)
+	}
+	stats := snapshotter.Snapshot().Nodes[0].Stats
+	if stats["inflight"] != 2 {
+		t.Errorf("expect inflight 2, got %v", stats["inflight"])
+	}
+	done(context.Background(), selector.DoneInfo{Err: context.DeadlineExceeded})
+	stats = snapshotter.Snapshot().Nodes[0].Stats
+	if stats["inflight"] != 1 {
+		t.Errorf("expect inflight 1, got %v", stats["inflight"])
+	}
+	if stats["success"] >= 1000 {
+		t.Errorf("expect success below 1000 after failure, got %v", stats["success"])
+	}
+	if stats["lag"] <= 0 {
+		t.Errorf("expect lag recorded, got %v", stats["lag"])
+	}
+}
+
+func TestSuccessWeight(t *testing.T) {
+	// the scoring of the success rate against the latency is tested with
+	// injected success rates and latencies in the ewma package
+	b := NewBuilder(WithSuccessWeight(4)).(*selector.DefaultBuilder)
+	if nb, ok := b.Node.(*ewma.Builder); !ok || nb.SuccessWeight != 4 {
+		t.Errorf("expect the ewma nodes built with the success weight 4, got %+v", b.Node)
+	}
+}
@@ -0,0 +1,98 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// unhealthy is a health filter removing the nodes at addrs.
+func unhealthy(addrs ...string) NodeFilter {
+	return func(_ context.Context, nodes []Node) []Node {
+		healthy := make([]Node, 0, len(nodes))
+	next:
+		for _, n := range nodes {
+			for _, addr := range addrs {
+				if n.Address() == addr {
+					continue next
+				}
+			}
+			healthy = append(healthy, n)
+		}
+		return healthy
+	}
+}
+
+func TestPanicMode(t *testing.T) {
+	s := (&DefaultBuilder{
+		Node:           &mockWeightedNodeBuilder{},
+		Balancer:       &mockBalancerBuilder{},
+		PanicThreshold: 0.5,
+	}).Build()
+	s.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", nil),
+		NewNode("http", "127.0.0.2:8080", nil),
+		NewNode("http", "127.0.0.3:8080", nil),
+		NewNode("http", "127.0.0.4:8080", nil),
+	})
+	picked := func(ctx context.Context) map[string]int {
+		t.Helper()
+		addrs := make(map[string]int)
+		for i := 0; i < 200; i++ {
+			n, done, err := s.Select(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			done(ctx, DoneInfo{})
+			addrs[n.Address()]++
+		}
+		return addrs
+	}
+
+	// half of the nodes healthy: the unhealthy ones are left out
+	ctx := NewHealthFilterContext(context.Background(), unhealthy("127.0.0.1:8080", "127.0.0.2:8080"))
+	if addrs := picked(ctx); len(addrs) != 2 || addrs["127.0.0.1:8080"] > 0 || addrs["127.0.0.2:8080"] > 0 {
+		t.Errorf("expected only the healthy nodes picked, got %v", addrs)
+	}
+
+	// below the threshold every node is picked
+	ctx = NewHealthFilterContext(context.Background(), unhealthy("127.0.0.1:8080", "127.0.0.2:8080", "127.0.0.3:8080"))
+	if addrs := picked(ctx); len(addrs) != 4 {
+		t.Errorf("expected every node picked in panic mode, got %v", addrs)
+	}
+	ctx = NewHealthFilterContext(context.Background(), unhealthy("127.0.0.1:8080", "127.0.0.2:8080", "127.0.0.3:8080", "127.0.0.4:8080"))
+	if addrs := picked(ctx); len(addrs) != 4 {
+		t.Errorf("expected every node picked in panic mode, got %v", addrs)
+	}
+
+	// the node filters still apply in panic mode
+	ctx = NewFilterContext(ctx, unhealthy("127.0.0.4:8080"))
+	if addrs := picked(ctx); len(addrs) != 3 || addrs["127.0.0.4:8080"] > 0 {
+		t.Errorf("expected the filtered node left out, got %v", addrs)
+	}
+}
+
+func TestPanicModeDisabled(t *testing.T) {
+	s := (&DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}).Build()
+	s.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", nil),
+		NewNode("http", "127.0.0.2:8080", nil),
+	})
+	ctx := NewHealthFilterContext(context.Background(), unhealthy("127.0.0.1:8080"))
+	for i := 0; i < 10; i++ {
+		n, _, err := s.Select(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.Address() != "127.0.0.2:8080" {
+			t.Errorf("expected the healthy node, got %s", n.Address())
+		}
+	}
+	ctx = NewHealthFilterContext(ctx, unhealthy("127.0.0.2:8080"))
+	if _, _, err := s.Select(ctx); !errors.Is(err, ErrNoAvailable) {
+		t.Errorf("expected %v, got %v", ErrNoAvailable, err)
+	}
+}
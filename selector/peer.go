@@ -23,3 +23,14 @@ func FromPeerContext(ctx context.Context) (p *Peer, ok bool) {
 	p, ok = ctx.Value(peerKey{}).(*Peer)
 	return
 }
+
+// PeerFromContext returns the node picked for the latest attempt of the call
+// carried by ctx. The node is replaced on every pick, so after a retry it
+// reports the node that actually served the request.
+func PeerFromContext(ctx context.Context) (Node, bool) {
+	p, ok := FromPeerContext(ctx)
+	if !ok || p.Node == nil {
+		return nil, false
+	}
+	return p.Node, true
+}
@@ -22,3 +22,20 @@ func TestNotPeer(t *testing.T) {
 		t.Fatalf("test no peer found peer!")
 	}
 }
+
+func TestPeerFromContext(t *testing.T) {
+	if _, ok := PeerFromContext(context.Background()); ok {
+		t.Fatal("expect no peer in background context")
+	}
+	ctx := NewPeerContext(context.Background(), &Peer{})
+	if _, ok := PeerFromContext(ctx); ok {
+		t.Fatal("expect no peer before a node is picked")
+	}
+	node := NewNode("http", "127.0.0.1:9000", nil)
+	p, _ := FromPeerContext(ctx)
+	p.Node = node
+	n, ok := PeerFromContext(ctx)
+	if !ok || n != node {
+		t.Fatalf("expect %v, got %v", node, n)
+	}
+}
@@ -3,6 +3,7 @@ package random
 import (
 	"context"
 	"math/rand"
+	"sync"
 
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/node/direct"
@@ -19,10 +20,71 @@ var _ selector.Balancer = (*Balancer)(nil) // Name is balancer name
 type Option func(o *options)
 
 // options is random builder options
-type options struct{}
+type options struct {
+	sortNodes      bool
+	seed           *int64
+	src            rand.Source
+	panicThreshold float64
+}
+
+// WithSortNodes sorts the nodes by instance ID before balancing, default is off.
+func WithSortNodes() Option {
+	return func(o *options) {
+		o.sortNodes = true
+	}
+}
+
+// WithSeed picks nodes with a random source seeded by seed, making the
+// selection sequence reproducible together with WithSortNodes.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = &seed
+	}
+}
+
+// WithSource picks nodes with the random source src, e.g. a seeded source
+// in tests. The source is shared by all the balancers built and is guarded
+// by a lock. By default the picks use the top-level functions of math/rand,
+// which do not contend on a global lock.
+func WithSource(src rand.Source) Option {
+	return func(o *options) {
+		o.src = src
+	}
+}
+
+// WithPanicMode balances every node, ignoring the health filters such as
+// the open circuit breakers, when the fraction of healthy nodes drops below
+// threshold, e.g. 0.5. It gambles on the unhealthy nodes rather than
+// rejecting every call. Default is disabled.
+func WithPanicMode(threshold float64) Option {
+	return func(o *options) {
+		o.panicThreshold = threshold
+	}
+}
+
+// lockedSource is a rand.Source safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
 
 // Balancer is a random balancer.
-type Balancer struct{}
+type Balancer struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
 
 // New a random selector.
 func New(opts ...Option) selector.Selector {
@@ -34,7 +96,14 @@ func (p *Balancer) Pick(_ context.Context, nodes []selector.WeightedNode) (selec
 	if len(nodes) == 0 {
 		return nil, nil, selector.ErrNoAvailable
 	}
-	cur := rand.Intn(len(nodes))
+	var cur int
+	if p.r != nil {
+		p.mu.Lock()
+		cur = p.r.Intn(len(nodes))
+		p.mu.Unlock()
+	} else {
+		cur = rand.Intn(len(nodes))
+	}
 	selected := nodes[cur]
 	d := selected.Pick()
 	return selected, d, nil
@@ -46,16 +115,31 @@ func NewBuilder(opts ...Option) selector.Builder {
 	for _, opt := range opts {
 		opt(&option)
 	}
+	builder := &Builder{seed: option.seed}
+	if option.src != nil {
+		builder.src = &lockedSource{src: option.src}
+	}
 	return &selector.DefaultBuilder{
-		Balancer: &Builder{},
-		Node:     &direct.Builder{},
+		Balancer:       builder,
+		Node:           &direct.Builder{},
+		SortNodes:      option.sortNodes,
+		PanicThreshold: option.panicThreshold,
 	}
 }
 
 // Builder is random builder
-type Builder struct{}
+type Builder struct {
+	seed *int64
+	src  rand.Source
+}
 
 // Build creates Balancer
 func (b *Builder) Build() selector.Balancer {
+	if b.src != nil {
+		return &Balancer{r: rand.New(b.src)}
+	}
+	if b.seed != nil {
+		return &Balancer{r: rand.New(rand.NewSource(*b.seed))}
+	}
 	return &Balancer{}
 }
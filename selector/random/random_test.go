@@ -2,6 +2,9 @@ package random
 
 import (
 	"context"
+	"math/rand"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/registry"
@@ -69,3 +72,81 @@ func TestEmpty(t *testing.T) {
 		t.Errorf("expect nil, got %v", err)
 	}
 }
+
+func TestDeterministic(t *testing.T) {
+	newNodes := func(addrs ...string) []selector.Node {
+		nodes := make([]selector.Node, 0, len(addrs))
+		for _, addr := range addrs {
+			nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{ID: addr}))
+		}
+		return nodes
+	}
+	sequence := func(nodes []selector.Node) []string {
+		s := New(WithSortNodes(), WithSeed(42))
+		s.Apply(nodes)
+		seq := make([]string, 0, 20)
+		for i := 0; i < 20; i++ {
+			n, done, err := s.Select(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			done(context.Background(), selector.DoneInfo{})
+			seq = append(seq, n.Address())
+		}
+		return seq
+	}
+	a := sequence(newNodes("127.0.0.1:8080", "127.0.0.2:8080", "127.0.0.3:8080"))
+	b := sequence(newNodes("127.0.0.3:8080", "127.0.0.1:8080", "127.0.0.2:8080"))
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expect the same sequence, got %v and %v", a, b)
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	var nodes []selector.Node
+	for _, addr := range []string{"127.0.0.1:8080", "127.0.0.2:8080", "127.0.0.3:8080"} {
+		nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{ID: addr}))
+	}
+	s := New(WithSortNodes(), WithSource(rand.NewSource(7)))
+	s.Apply(nodes)
+	expect := rand.New(rand.NewSource(7))
+	for i := 0; i < 20; i++ {
+		n, done, err := s.Select(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		done(context.Background(), selector.DoneInfo{})
+		if want := nodes[expect.Intn(len(nodes))].Address(); n.Address() != want {
+			t.Fatalf("pick %d: expect %s, got %s", i, want, n.Address())
+		}
+	}
+}
+
+func TestWithSourceConcurrent(t *testing.T) {
+	var nodes []selector.Node
+	for _, addr := range []string{"127.0.0.1:8080", "127.0.0.2:8080"} {
+		nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{ID: addr}))
+	}
+	// selectors built by the same builder share the source
+	builder := NewBuilder(WithSource(rand.NewSource(1)))
+	selectors := []selector.Selector{builder.Build(), builder.Build()}
+	var wg sync.WaitGroup
+	for _, s := range selectors {
+		s.Apply(nodes)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(s selector.Selector) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_, done, err := s.Select(context.Background())
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					done(context.Background(), selector.DoneInfo{})
+				}
+			}(s)
+		}
+	}
+	wg.Wait()
+}
@@ -289,3 +289,104 @@ func TestGlobalSelector(t *testing.T) {
 		t.Errorf("expect %v, got %v", nil, gBuilder)
 	}
 }
+
+func TestFilterContext(t *testing.T) {
+	s := (&DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}).Build()
+	s.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{Version: "v1.0.0"}),
+		NewNode("http", "127.0.0.2:8080", &registry.ServiceInstance{Version: "v2.0.0"}),
+	})
+	ctx := NewFilterContext(context.Background(), mockFilter("v2.0.0"))
+	for i := 0; i < 10; i++ {
+		n, _, err := s.Select(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.Address() != "127.0.0.2:8080" {
+			t.Errorf("expect 127.0.0.2:8080, got %s", n.Address())
+		}
+	}
+	if _, _, err := s.Select(NewFilterContext(ctx, mockFilter("v1.0.0"))); !errors.Is(err, ErrNoAvailable) {
+		t.Errorf("expect %v, got %v", ErrNoAvailable, err)
+	}
+	if got := len(FromFilterContext(ctx)); got != 1 {
+		t.Errorf("expect parent context filters untouched, got %d", got)
+	}
+}
+
+func TestPeerFromContextRetry(t *testing.T) {
+	s := (&DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}).Build()
+	s.Apply([]Node{
+		NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{ID: "1", Version: "v1.0.0"}),
+		NewNode("http", "127.0.0.2:8080", &registry.ServiceInstance{ID: "2", Version: "v2.0.0"}),
+	})
+	ctx := NewPeerContext(context.Background(), &Peer{})
+
+	// first attempt
+	first, _, err := s.Select(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, ok := PeerFromContext(ctx)
+	if !ok || n.Address() != first.Address() {
+		t.Fatalf("expect peer %s, got %v", first.Address(), n)
+	}
+
+	// retry excluding the node of the failed attempt
+	exclude := func(_ context.Context, nodes []Node) []Node {
+		filtered := make([]Node, 0, len(nodes))
+		for _, n := range nodes {
+			if n.Address() != first.Address() {
+				filtered = append(filtered, n)
+			}
+		}
+		return filtered
+	}
+	second, _, err := s.Select(NewFilterContext(ctx, exclude))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Address() == first.Address() {
+		t.Fatalf("expect retry on another node, got %s", second.Address())
+	}
+	n, ok = PeerFromContext(ctx)
+	if !ok || n.Address() != second.Address() {
+		t.Fatalf("expect peer %s, got %v", second.Address(), n)
+	}
+
+	// a failed pick must not report a stale node
+	if _, _, err = s.Select(NewFilterContext(ctx, mockFilter("v3.0.0"))); !errors.Is(err, ErrNoAvailable) {
+		t.Fatalf("expect %v, got %v", ErrNoAvailable, err)
+	}
+	if n, ok = PeerFromContext(ctx); ok {
+		t.Fatalf("expect no peer, got %s", n.Address())
+	}
+}
+
+func TestSortNodes(t *testing.T) {
+	nodes := []Node{
+		NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{ID: "c"}),
+		NewNode("http", "127.0.0.3:8080", &registry.ServiceInstance{ID: "a"}),
+		NewNode("http", "127.0.0.2:8080", &registry.ServiceInstance{ID: "b"}),
+		// without an ID, the nodes sort first by address
+		NewNode("http", "127.0.0.5:8080", nil),
+		NewNode("http", "127.0.0.4:8080", nil),
+	}
+	var got []string
+	for _, n := range sortNodes(nodes) {
+		got = append(got, n.Address())
+	}
+	want := []string{"127.0.0.4:8080", "127.0.0.5:8080", "127.0.0.3:8080", "127.0.0.2:8080", "127.0.0.1:8080"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+	if nodes[0].Address() != "127.0.0.1:8080" {
+		t.Error("expect the applied nodes unchanged")
+	}
+}
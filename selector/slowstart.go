@@ -0,0 +1,153 @@
+package selector
+
+import (
+	"sync"
+	"time"
+)
+
+// slowStartFloor is the fraction of its weight a node starts the slow
+// start ramp with, so that it still sees some traffic from the start.
+const slowStartFloor = 0.1
+
+// slowStarter tracks when the applied nodes were added, and when the nodes
+// rejected by the health filters passed them again.
+type slowStarter struct {
+	mu sync.Mutex
+	// added is nil until the first apply.
+	added map[string]time.Time
+	// rejected are the nodes removed by the health filters of the last
+	// select, readmitted the time they passed them again.
+	rejected   map[string]struct{}
+	readmitted map[string]time.Time
+}
+
+// apply wraps the nodes added since the previous apply, or added back after
+// being removed, to ramp their weight up over window. The nodes of the
+// first apply start at full weight.
+func (s *slowStarter) apply(nodes []WeightedNode, window time.Duration) []WeightedNode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	first := s.added == nil
+	added := make(map[string]time.Time, len(nodes))
+	for i, n := range nodes {
+		addr := n.Address()
+		start, ok := s.added[addr]
+		if !ok {
+			if first {
+				start = now.Add(-window)
+			} else {
+				start = now
+			}
+		}
+		added[addr] = start
+		if window > 0 && now.Sub(start) < window {
+			nodes[i] = &slowStartNode{WeightedNode: n, start: start, window: window}
+		}
+	}
+	s.added = added
+	for addr := range s.rejected {
+		if _, ok := added[addr]; !ok {
+			delete(s.rejected, addr)
+		}
+	}
+	for addr := range s.readmitted {
+		if _, ok := added[addr]; !ok {
+			delete(s.readmitted, addr)
+		}
+	}
+	return nodes
+}
+
+// readmit wraps the healthy nodes which passed the health filters again
+// after they rejected them, e.g. the ones a circuit breaker closed on, to
+// ramp their weight up over window as if they were added back. The
+// candidates are the nodes before the health filters.
+func (s *slowStarter) readmit(candidates, healthy []WeightedNode, window time.Duration) []WeightedNode {
+	if window <= 0 {
+		return healthy
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	passed := make(map[string]struct{}, len(healthy))
+	for _, n := range healthy {
+		passed[n.Address()] = struct{}{}
+	}
+	for _, n := range candidates {
+		if _, ok := passed[n.Address()]; !ok {
+			if s.rejected == nil {
+				s.rejected = make(map[string]struct{})
+			}
+			s.rejected[n.Address()] = struct{}{}
+			delete(s.readmitted, n.Address())
+		}
+	}
+	var ramped []WeightedNode
+	for i, n := range healthy {
+		addr := n.Address()
+		if _, ok := s.rejected[addr]; ok {
+			delete(s.rejected, addr)
+			if s.readmitted == nil {
+				s.readmitted = make(map[string]time.Time)
+			}
+			s.readmitted[addr] = now
+		}
+		start, ok := s.readmitted[addr]
+		if !ok {
+			continue
+		}
+		if now.Sub(start) >= window {
+			delete(s.readmitted, addr)
+			continue
+		}
+		if sn, ok := n.(*slowStartNode); ok {
+			if sn.start.After(start) {
+				// already ramping since it was added back
+				continue
+			}
+			n = sn.WeightedNode
+		}
+		if ramped == nil {
+			// healthy may be the applied nodes themselves
+			ramped = append([]WeightedNode(nil), healthy...)
+		}
+		ramped[i] = &slowStartNode{WeightedNode: n, start: start, window: window}
+	}
+	if ramped == nil {
+		return healthy
+	}
+	return ramped
+}
+
+// slowStartNode increases the weight of a node linearly from slowStartFloor
+// to its full weight over the window since start.
+type slowStartNode struct {
+	WeightedNode
+
+	start  time.Time
+	window time.Duration
+}
+
+func (n *slowStartNode) Weight() float64 {
+	return n.WeightedNode.Weight() * n.factor(time.Since(n.start))
+}
+
+func (n *slowStartNode) factor(elapsed time.Duration) float64 {
+	if elapsed >= n.window {
+		return 1
+	}
+	f := float64(elapsed) / float64(n.window)
+	if f < slowStartFloor {
+		return slowStartFloor
+	}
+	return f
+}
+
+// Stats passes the statistics of the wrapped node through.
+func (n *slowStartNode) Stats() map[string]float64 {
+	if st, ok := n.WeightedNode.(Stater); ok {
+		return st.Stats()
+	}
+	return nil
+}
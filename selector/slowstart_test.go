@@ -0,0 +1,146 @@
+package selector
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func snapshotWeights(d *Default) map[string]float64 {
+	weights := make(map[string]float64)
+	for _, n := range d.Snapshot().Nodes {
+		weights[n.Address] = n.Weight
+	}
+	return weights
+}
+
+func TestSlowStartFactor(t *testing.T) {
+	n := &slowStartNode{window: 10 * time.Second}
+	tests := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, slowStartFloor},
+		{500 * time.Millisecond, slowStartFloor},
+		{2500 * time.Millisecond, 0.25},
+		{5 * time.Second, 0.5},
+		{7500 * time.Millisecond, 0.75},
+		{10 * time.Second, 1},
+		{time.Minute, 1},
+	}
+	for _, tt := range tests {
+		if got := n.factor(tt.elapsed); got != tt.want {
+			t.Errorf("%v: expected factor %v, got %v", tt.elapsed, tt.want, got)
+		}
+	}
+}
+
+func TestSlowStart(t *testing.T) {
+	window := 400 * time.Millisecond
+	d := &Default{NodeBuilder: &mockWeightedNodeBuilder{}, Balancer: &mockBalancer{}, SlowStart: window}
+	d.Apply([]Node{drainNode("127.0.0.1:8080")})
+	// the nodes of the first apply start at full weight
+	if w := snapshotWeights(d)["127.0.0.1:8080"]; w != 100 {
+		t.Fatalf("expected full weight 100, got %v", w)
+	}
+
+	d.Apply([]Node{drainNode("127.0.0.1:8080"), drainNode("127.0.0.1:9090")})
+	weights := snapshotWeights(d)
+	if weights["127.0.0.1:8080"] != 100 {
+		t.Errorf("expected the existing node at full weight, got %v", weights["127.0.0.1:8080"])
+	}
+	if w := weights["127.0.0.1:9090"]; w < 100*slowStartFloor || w > 30 {
+		t.Errorf("expected the added node near %v, got %v", 100*slowStartFloor, w)
+	}
+	time.Sleep(window / 2)
+	// the ramp is kept across an apply of the same node
+	d.Apply([]Node{drainNode("127.0.0.1:8080"), drainNode("127.0.0.1:9090")})
+	if w := snapshotWeights(d)["127.0.0.1:9090"]; w < 50 || w >= 90 {
+		t.Errorf("expected the added node ramping at half weight, got %v", w)
+	}
+	time.Sleep(window / 2)
+	if w := snapshotWeights(d)["127.0.0.1:9090"]; w != 100 {
+		t.Errorf("expected full weight after the window, got %v", w)
+	}
+
+	// a removed node added back ramps again
+	d.Apply([]Node{drainNode("127.0.0.1:8080")})
+	d.Apply([]Node{drainNode("127.0.0.1:8080"), drainNode("127.0.0.1:9090")})
+	if w := snapshotWeights(d)["127.0.0.1:9090"]; w > 30 {
+		t.Errorf("expected the node added back ramping, got %v", w)
+	}
+	n, _, err := d.Select(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := n.(*slowStartNode); ok {
+		t.Error("expected the raw node selected")
+	}
+}
+
+func TestSlowStartDisabled(t *testing.T) {
+	d := &Default{NodeBuilder: &mockWeightedNodeBuilder{}, Balancer: &mockBalancer{}}
+	d.Apply([]Node{drainNode("127.0.0.1:8080")})
+	d.Apply([]Node{drainNode("127.0.0.1:8080"), drainNode("127.0.0.1:9090")})
+	if w := snapshotWeights(d)["127.0.0.1:9090"]; w != 100 {
+		t.Errorf("expected full weight without slow start, got %v", w)
+	}
+}
+
+func TestSlowStartReadmitted(t *testing.T) {
+	window := 400 * time.Millisecond
+	var weights map[string]float64
+	record := funcBalancerBuilder(func(_ context.Context, nodes []WeightedNode) (WeightedNode, DoneFunc, error) {
+		weights = make(map[string]float64, len(nodes))
+		for _, n := range nodes {
+			weights[n.Address()] = n.Weight()
+		}
+		return nodes[0], func(context.Context, DoneInfo) {}, nil
+	})
+	d := &Default{NodeBuilder: &mockWeightedNodeBuilder{}, Balancer: record, SlowStart: window}
+	d.Apply([]Node{drainNode("127.0.0.1:8080"), drainNode("127.0.0.1:9090")})
+
+	open := true
+	breaker := func(_ context.Context, nodes []Node) []Node {
+		if !open {
+			return nodes
+		}
+		return nodes[:1]
+	}
+	ctx := NewHealthFilterContext(context.Background(), breaker)
+	if _, _, err := d.Select(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := weights["127.0.0.1:9090"]; ok {
+		t.Fatalf("expected the node rejected by the health filter, got %v", weights)
+	}
+	// the node passing the health filter again ramps up
+	open = false
+	if _, _, err := d.Select(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if w := weights["127.0.0.1:9090"]; w < 100*slowStartFloor || w > 30 {
+		t.Errorf("expected the readmitted node near %v, got %v", 100*slowStartFloor, w)
+	}
+	if w := weights["127.0.0.1:8080"]; w != 100 {
+		t.Errorf("expected the healthy node at full weight, got %v", w)
+	}
+	time.Sleep(window / 2)
+	if _, _, err := d.Select(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if w := weights["127.0.0.1:9090"]; w < 50 || w >= 90 {
+		t.Errorf("expected the readmitted node ramping at half weight, got %v", w)
+	}
+	time.Sleep(window / 2)
+	if _, _, err := d.Select(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if w := weights["127.0.0.1:9090"]; w != 100 {
+		t.Errorf("expected full weight after the window, got %v", w)
+	}
+	// the applied nodes are not wrapped
+	if w := snapshotWeights(d)["127.0.0.1:9090"]; w != 100 {
+		t.Errorf("expected the applied node at full weight, got %v", w)
+	}
+}
@@ -0,0 +1,74 @@
+package selector
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Snapshotter is implemented by selectors which can dump their current view of nodes.
+type Snapshotter interface {
+	Snapshot() Snapshot
+}
+
+// Stater is implemented by weighted nodes which expose runtime statistics,
+// such as ewma latency or inflight requests.
+type Stater interface {
+	Stats() map[string]float64
+}
+
+// Snapshot is a point-in-time view of the selector nodes.
+type Snapshot struct {
+	Nodes []NodeState `json:"nodes"`
+}
+
+// NodeState is the state of one node in a Snapshot.
+type NodeState struct {
+	// ID is the instance ID of the nodes implementing ID() string, such as
+	// the ones of NewNode.
+	ID            string             `json:"id,omitempty"`
+	Scheme        string             `json:"scheme"`
+	Address       string             `json:"address"`
+	ServiceName   string             `json:"service_name"`
+	Version       string             `json:"version"`
+	Metadata      map[string]string  `json:"metadata,omitempty"`
+	InitialWeight *int64             `json:"initial_weight,omitempty"`
+	Weight        float64            `json:"weight"`
+	PickElapsed   time.Duration      `json:"pick_elapsed"`
+	Stats         map[string]float64 `json:"stats,omitempty"`
+}
+
+// Snapshot returns the current state of all applied nodes.
+// It is safe to call concurrently with Select and Apply.
+func (d *Default) Snapshot() Snapshot {
+	nodes, _ := d.nodes.Load().([]WeightedNode)
+	s := Snapshot{Nodes: make([]NodeState, 0, len(nodes))}
+	for _, n := range nodes {
+		state := NodeState{
+			ID:            nodeID(n.Raw()),
+			Scheme:        n.Scheme(),
+			Address:       n.Address(),
+			ServiceName:   n.ServiceName(),
+			Version:       n.Version(),
+			Metadata:      n.Metadata(),
+			InitialWeight: n.InitialWeight(),
+			Weight:        n.Weight(),
+			PickElapsed:   n.PickElapsed(),
+		}
+		if st, ok := n.(Stater); ok {
+			state.Stats = st.Stats()
+		}
+		s.Nodes = append(s.Nodes, state)
+	}
+	return s
+}
+
+// DebugHandler returns a http.Handler which writes the selector snapshot as JSON.
+func DebugHandler(s Snapshotter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.Snapshot()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package selector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestDefaultSnapshot(t *testing.T) {
+	s := (&DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}).Build().(*Default)
+	if got := len(s.Snapshot().Nodes); got != 0 {
+		t.Fatalf("expect 0 nodes, got %d", got)
+	}
+
+	nodes := []Node{
+		NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{ID: "1", Name: "helloworld", Version: "v1.0.0", Metadata: map[string]string{"weight": "10"}}),
+		NewNode("http", "127.0.0.2:8080", &registry.ServiceInstance{ID: "2", Name: "helloworld", Version: "v1.0.0"}),
+	}
+	s.Apply(nodes)
+	snap := s.Snapshot()
+	if len(snap.Nodes) != 2 {
+		t.Fatalf("expect 2 nodes, got %d", len(snap.Nodes))
+	}
+	if snap.Nodes[0].ID != "1" || snap.Nodes[0].Address != "127.0.0.1:8080" || snap.Nodes[0].Weight != 10 || snap.Nodes[0].ServiceName != "helloworld" {
+		t.Errorf("unexpected node state %+v", snap.Nodes[0])
+	}
+	if snap.Nodes[1].Weight != 100 || snap.Nodes[1].InitialWeight != nil {
+		t.Errorf("unexpected node state %+v", snap.Nodes[1])
+	}
+
+	s.Apply(nodes[1:])
+	snap = s.Snapshot()
+	if len(snap.Nodes) != 1 || snap.Nodes[0].ID != "2" || snap.Nodes[0].Address != "127.0.0.2:8080" {
+		t.Errorf("expect only 127.0.0.2:8080, got %+v", snap.Nodes)
+	}
+}
+
+func TestDefaultSnapshotConcurrent(t *testing.T) {
+	s := (&DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}).Build().(*Default)
+	s.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil)})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			_, done, err := s.Select(context.Background())
+			if err == nil {
+				done(context.Background(), DoneInfo{})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			s.Apply([]Node{NewNode("http", "127.0.0.1:8080", nil)})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = s.Snapshot()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDebugHandler(t *testing.T) {
+	s := (&DefaultBuilder{
+		Node:     &mockWeightedNodeBuilder{},
+		Balancer: &mockBalancerBuilder{},
+	}).Build().(*Default)
+	s.Apply([]Node{NewNode("grpc", "127.0.0.1:9000", nil)})
+
+	rec := httptest.NewRecorder()
+	DebugHandler(s).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/selector", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expect status 200, got %d", rec.Code)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Nodes) != 1 || snap.Nodes[0].Scheme != "grpc" || snap.Nodes[0].Address != "127.0.0.1:9000" {
+		t.Errorf("unexpected snapshot %+v", snap)
+	}
+}
@@ -3,6 +3,7 @@ package wrr
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/node/direct"
@@ -19,7 +20,38 @@ var _ selector.Balancer = (*Balancer)(nil) // Name is balancer name
 type Option func(o *options)
 
 // options is wrr builder options
-type options struct{}
+type options struct {
+	sortNodes      bool
+	slowStart      time.Duration
+	panicThreshold float64
+}
+
+// WithSortNodes sorts the nodes by instance ID before balancing, default is off.
+// Ties between equal weights are then broken in a deterministic order.
+func WithSortNodes() Option {
+	return func(o *options) {
+		o.sortNodes = true
+	}
+}
+
+// WithSlowStart ramps the weight of the nodes added, or added back, after
+// the first update linearly up to their full weight over d, so a recovered
+// node is not flooded at once. Default is disabled.
+func WithSlowStart(d time.Duration) Option {
+	return func(o *options) {
+		o.slowStart = d
+	}
+}
+
+// WithPanicMode balances every node, ignoring the health filters such as
+// the open circuit breakers, when the fraction of healthy nodes drops below
+// threshold, e.g. 0.5. It gambles on the unhealthy nodes rather than
+// rejecting every call. Default is disabled.
+func WithPanicMode(threshold float64) Option {
+	return func(o *options) {
+		o.panicThreshold = threshold
+	}
+}
 
 // Balancer is a wrr balancer.
 type Balancer struct {
@@ -68,8 +100,11 @@ func NewBuilder(opts ...Option) selector.Builder {
 		opt(&option)
 	}
 	return &selector.DefaultBuilder{
-		Balancer: &Builder{},
-		Node:     &direct.Builder{},
+		Balancer:       &Builder{},
+		Node:           &direct.Builder{},
+		SortNodes:      option.sortNodes,
+		SlowStart:      option.slowStart,
+		PanicThreshold: option.panicThreshold,
 	}
 }
 
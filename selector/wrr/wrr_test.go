@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
@@ -64,3 +65,58 @@ func TestEmpty(t *testing.T) {
 		t.Errorf("expect no error, got %v", err)
 	}
 }
+
+func TestDeterministic(t *testing.T) {
+	newNodes := func(addrs ...string) []selector.Node {
+		nodes := make([]selector.Node, 0, len(addrs))
+		for _, addr := range addrs {
+			nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{ID: addr}))
+		}
+		return nodes
+	}
+	sequence := func(nodes []selector.Node) []string {
+		s := New(WithSortNodes())
+		s.Apply(nodes)
+		seq := make([]string, 0, 6)
+		for i := 0; i < 6; i++ {
+			n, done, err := s.Select(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			done(context.Background(), selector.DoneInfo{})
+			seq = append(seq, n.Address())
+		}
+		return seq
+	}
+	want := []string{
+		"127.0.0.1:8080", "127.0.0.2:8080", "127.0.0.3:8080",
+		"127.0.0.1:8080", "127.0.0.2:8080", "127.0.0.3:8080",
+	}
+	if got := sequence(newNodes("127.0.0.3:8080", "127.0.0.1:8080", "127.0.0.2:8080")); !reflect.DeepEqual(got, want) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+	if got := sequence(newNodes("127.0.0.2:8080", "127.0.0.3:8080", "127.0.0.1:8080")); !reflect.DeepEqual(got, want) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+}
+
+func TestSlowStart(t *testing.T) {
+	s := New(WithSlowStart(time.Minute))
+	a := selector.NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{ID: "a"})
+	b := selector.NewNode("http", "127.0.0.2:8080", &registry.ServiceInstance{ID: "b"})
+	s.Apply([]selector.Node{a})
+	s.Apply([]selector.Node{a, b})
+	picks := make(map[string]int)
+	for i := 0; i < 22; i++ {
+		n, done, err := s.Select(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		done(context.Background(), selector.DoneInfo{})
+		picks[n.Address()]++
+	}
+	// the added node starts at a tenth of its weight
+	if got := picks["127.0.0.2:8080"]; got < 1 || got > 4 {
+		t.Errorf("expect the added node picked about twice, got %d of 22", got)
+	}
+}
@@ -0,0 +1,162 @@
+package certwatch
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Option is certificate watcher option.
+type Option func(*options)
+
+type options struct {
+	onReload func(err error)
+}
+
+// WithOnReload sets a function called after every reload attempt, with
+// the error of an invalid pair or nil once the new pair is served.
+func WithOnReload(fn func(err error)) Option {
+	return func(o *options) {
+		o.onReload = fn
+	}
+}
+
+// Watcher serves a certificate key pair loaded from files and reloads it
+// when the files change, so certificates rotate without a restart. The
+// connections already established keep their certificate, the new pair
+// is used for the following handshakes.
+type Watcher struct {
+	certFile string
+	keyFile  string
+	opts     options
+
+	cert atomic.Pointer[tls.Certificate]
+	fw   *fsnotify.Watcher
+	done chan struct{}
+	once sync.Once
+}
+
+// New loads the pair of certFile and keyFile and watches them. It fails
+// when the initial pair is invalid.
+func New(certFile, keyFile string, opts ...Option) (*Watcher, error) {
+	w := &Watcher{
+		certFile: filepath.Clean(certFile),
+		keyFile:  filepath.Clean(keyFile),
+		done:     make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(&w.opts)
+	}
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// the directories are watched, so files replaced by a rename or a
+	// Kubernetes "..data" symlink swap keep being observed.
+	for _, dir := range []string{filepath.Dir(w.certFile), filepath.Dir(w.keyFile)} {
+		if err = fw.Add(dir); err != nil {
+			_ = fw.Close()
+			return nil, err
+		}
+	}
+	w.fw = fw
+	go w.watch()
+	return w, nil
+}
+
+// load validates the pair of the files and swaps it in, the current pair
+// is kept on error.
+func (w *Watcher) load() error {
+	cert, err := tls.LoadX509KeyPair(w.certFile, w.keyFile)
+	if err != nil {
+		return err
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return err
+	}
+	w.cert.Store(&cert)
+	return nil
+}
+
+func (w *Watcher) watch() {
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.fw.Events:
+			if !ok {
+				return
+			}
+			if !w.relevant(event) {
+				continue
+			}
+			err := w.load()
+			if err != nil {
+				log.Errorf("certwatch: failed to reload %s: %v", w.certFile, err)
+			}
+			if w.opts.onReload != nil {
+				w.opts.onReload(err)
+			}
+		case err, ok := <-w.fw.Errors:
+			if !ok {
+				return
+			}
+			log.Errorf("certwatch: failed to watch %s: %v", w.certFile, err)
+		}
+	}
+}
+
+func (w *Watcher) relevant(event fsnotify.Event) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+		return false
+	}
+	name := filepath.Clean(event.Name)
+	return name == w.certFile || name == w.keyFile || filepath.Base(name) == "..data"
+}
+
+// Certificate returns the current certificate.
+func (w *Watcher) Certificate() *tls.Certificate {
+	return w.cert.Load()
+}
+
+// GetCertificate returns the current certificate, for tls.Config.GetCertificate.
+func (w *Watcher) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return w.cert.Load(), nil
+}
+
+// GetClientCertificate returns the current certificate, for
+// tls.Config.GetClientCertificate.
+func (w *Watcher) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return w.cert.Load(), nil
+}
+
+// TLSConfig returns a clone of base, which may be nil, serving the current
+// certificate, e.g. for the TLSConfig option of the http and grpc servers.
+func (w *Watcher) TLSConfig(base *tls.Config) *tls.Config {
+	c := &tls.Config{MinVersion: tls.VersionTLS12}
+	if base != nil {
+		c = base.Clone()
+	}
+	c.Certificates = nil
+	c.GetCertificate = w.GetCertificate
+	return c
+}
+
+// Close stops watching the files.
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.fw.Close()
+	})
+	return err
+}
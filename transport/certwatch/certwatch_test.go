@@ -0,0 +1,144 @@
+package certwatch
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePair(t *testing.T, certFile, keyFile, cn string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{cn},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeAtomic(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	writeAtomic(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// writeAtomic replaces the file by a rename, as certificate managers do.
+func writeAtomic(t *testing.T, name string, data []byte) {
+	t.Helper()
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// servedName returns the common name of the certificate served on addr.
+func servedName(t *testing.T, addr string) string {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true}) //nolint:gosec
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writePair(t, certFile, keyFile, "v1.example.com")
+
+	reloads := make(chan error, 16)
+	w, err := New(certFile, keyFile, WithOnReload(func(err error) { reloads <- err }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", w.TLSConfig(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+	if name := servedName(t, lis.Addr().String()); name != "v1.example.com" {
+		t.Fatalf("expected v1.example.com, got %s", name)
+	}
+
+	writePair(t, certFile, keyFile, "v2.example.com")
+	deadline := time.After(5 * time.Second)
+	for w.Certificate().Leaf.Subject.CommonName != "v2.example.com" {
+		select {
+		case <-reloads:
+		case <-deadline:
+			t.Fatal("timeout waiting the reload")
+		}
+	}
+	if name := servedName(t, lis.Addr().String()); name != "v2.example.com" {
+		t.Errorf("expected v2.example.com, got %s", name)
+	}
+}
+
+func TestWatcherInvalidPair(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writePair(t, certFile, keyFile, "v1.example.com")
+
+	reloads := make(chan error, 16)
+	w, err := New(certFile, keyFile, WithOnReload(func(err error) { reloads <- err }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	writeAtomic(t, certFile, []byte("invalid"))
+	select {
+	case err = <-reloads:
+		if err == nil {
+			t.Fatal("expected an error of the invalid pair")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting the reload")
+	}
+	if name := w.Certificate().Leaf.Subject.CommonName; name != "v1.example.com" {
+		t.Errorf("expected the previous certificate kept, got %s", name)
+	}
+}
+
+func TestNewInvalidPair(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := New(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")); err == nil {
+		t.Error("expected an error of missing files")
+	}
+}
@@ -0,0 +1,171 @@
+// Package dump logs the raw serialized messages exchanged by the transports,
+// to debug wire format mismatches without capturing the traffic.
+package dump
+
+import (
+	"encoding/hex"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/internal/redact"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// DefaultMaxSize is the default number of dumped bytes of a message.
+const DefaultMaxSize = 1024
+
+const (
+	// Request is the direction of the messages sent by the client.
+	Request = "request"
+	// Response is the direction of the messages sent by the server.
+	Response = "response"
+)
+
+// Option is dump option.
+type Option func(*Dumper)
+
+// WithLogger with the logger the dumps are written to at debug level,
+// default is the global logger.
+func WithLogger(logger log.Logger) Option {
+	return func(d *Dumper) {
+		d.logger = logger
+	}
+}
+
+// WithMaxSize caps the dumped bytes of each message, default is 1024.
+func WithMaxSize(n int) Option {
+	return func(d *Dumper) {
+		d.maxSize = n
+	}
+}
+
+// WithRedactFields masks the given field paths before serialization,
+// e.g. "password" or "user.ssn". Proto messages are matched by proto field
+// names and json bodies by json names. Bodies which cannot be redacted are
+// dumped without data.
+func WithRedactFields(paths ...string) Option {
+	return func(d *Dumper) {
+		d.redact = paths
+	}
+}
+
+// Dumper logs serialized messages keyed by operation.
+type Dumper struct {
+	logger   log.Logger
+	maxSize  int
+	redact   []string
+	redactor *redact.Redactor
+}
+
+// New returns a Dumper, it is passed to the transports to enable the dump mode.
+func New(opts ...Option) *Dumper {
+	d := &Dumper{
+		logger:  log.GetLogger(),
+		maxSize: DefaultMaxSize,
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	if len(d.redact) > 0 {
+		d.redactor = redact.New(d.redact, 0)
+	}
+	return d
+}
+
+// Message dumps the proto wire format of the message m.
+func (d *Dumper) Message(kind, component, operation, direction string, m any) {
+	pm, ok := m.(proto.Message)
+	if !ok {
+		// not a proto message, only the operation is dumped
+		d.log(kind, component, operation, direction, 0, nil)
+		return
+	}
+	if d.redactor != nil {
+		pm = d.redactor.Message(pm)
+	}
+	data, err := proto.Marshal(pm)
+	if err != nil {
+		d.log(kind, component, operation, direction, 0, nil)
+		return
+	}
+	d.log(kind, component, operation, direction, len(data), data)
+}
+
+// Body dumps the raw body data of the given content type.
+func (d *Dumper) Body(kind, component, operation, direction, contentType string, data []byte) {
+	d.body(kind, component, operation, direction, contentType, len(data), data)
+}
+
+// Capture is an io.Writer recording the first bytes of a body written to it,
+// up to the max size of its Dumper, and counting all of them, so that a
+// streamed body is dumped without buffering it whole.
+type Capture struct {
+	limit int
+	data  []byte
+	size  int
+}
+
+// Capture returns a Capture of a body to dump with CapturedBody.
+func (d *Dumper) Capture() *Capture {
+	// one more byte to report the truncation
+	return &Capture{limit: d.maxSize + 1}
+}
+
+// Write records p, never failing.
+func (c *Capture) Write(p []byte) (int, error) {
+	c.size += len(p)
+	if room := c.limit - len(c.data); room > 0 {
+		c.data = append(c.data, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
+
+// CapturedBody dumps the body of the given content type recorded by c. The
+// truncated JSON bodies cannot be redacted and are dumped without data.
+func (d *Dumper) CapturedBody(kind, component, operation, direction, contentType string, c *Capture) {
+	d.body(kind, component, operation, direction, contentType, c.size, c.data)
+}
+
+func (d *Dumper) body(kind, component, operation, direction, contentType string, size int, data []byte) {
+	if d.redactor != nil && size > 0 {
+		var err error
+		if mediaType, _, _ := mime.ParseMediaType(contentType); !strings.HasSuffix(mediaType, "json") || len(data) < size {
+			data = nil
+		} else if data, err = d.redactor.JSON(data); err != nil {
+			data = nil
+		}
+	}
+	d.log(kind, component, operation, direction, size, data)
+}
+
+func (d *Dumper) log(kind, component, operation, direction string, size int, data []byte) {
+	keyvals := []any{
+		"msg", "dump",
+		"kind", kind,
+		"component", component,
+		"operation", operation,
+		"direction", direction,
+		"size", size,
+	}
+	if data != nil {
+		keyvals = append(keyvals, "data", d.format(data), "truncated", len(data) > d.maxSize)
+	}
+	_ = d.logger.Log(log.LevelDebug, keyvals...)
+}
+
+// format returns text data as is and binary data hex encoded, capped to maxSize bytes.
+func (d *Dumper) format(data []byte) string {
+	text := utf8.Valid(data) && !strings.ContainsFunc(string(data), func(r rune) bool {
+		return r < ' ' && r != '\n' && r != '\r' && r != '\t'
+	})
+	if len(data) > d.maxSize {
+		data = data[:d.maxSize]
+	}
+	if text {
+		return string(data)
+	}
+	return "hex:" + hex.EncodeToString(data)
+}
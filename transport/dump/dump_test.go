@@ -0,0 +1,138 @@
+package dump
+
+import (
+	"encoding/hex"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/internal/testdata/complex"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type testLogger struct {
+	mu      sync.Mutex
+	entries []map[string]any
+}
+
+func (l *testLogger) Log(level log.Level, keyvals ...any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := map[string]any{"level": level}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		entry[keyvals[i].(string)] = keyvals[i+1]
+	}
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func TestMessage(t *testing.T) {
+	logger := &testLogger{}
+	req := &complex.Simple{Component: "kratos"}
+	New(WithLogger(logger)).Message("server", "grpc", "/helloworld.Greeter/SayHello", Request, req)
+
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.entries) != 1 {
+		t.Fatalf("expected one dump, got %v", logger.entries)
+	}
+	for k, v := range map[string]any{
+		"level":     log.LevelDebug,
+		"kind":      "server",
+		"component": "grpc",
+		"operation": "/helloworld.Greeter/SayHello",
+		"direction": Request,
+		"size":      len(data),
+		"data":      "hex:" + hex.EncodeToString(data),
+		"truncated": false,
+	} {
+		if got := logger.entries[0][k]; got != v {
+			t.Errorf("%s: expected %v, got %v", k, v, got)
+		}
+	}
+}
+
+func TestMessageRedact(t *testing.T) {
+	logger := &testLogger{}
+	New(WithLogger(logger), WithRedactFields("component")).Message("client", "grpc", "/helloworld.Greeter/SayHello", Request, &complex.Simple{Component: "kratos"})
+
+	data, err := proto.Marshal(&complex.Simple{Component: "****"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := logger.entries[0]["data"], "hex:"+hex.EncodeToString(data); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestBody(t *testing.T) {
+	logger := &testLogger{}
+	d := New(WithLogger(logger), WithRedactFields("password"))
+	d.Body("server", "http", "/login", Request, "application/json; charset=utf-8", []byte(`{"user":"kratos","password":"secret"}`))
+	d.Body("server", "http", "/login", Request, "application/x-www-form-urlencoded", []byte("user=kratos&password=secret"))
+
+	if got, want := logger.entries[0]["data"], `{"password":"****","user":"kratos"}`; got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	// bodies which cannot be redacted are dumped without data
+	if data, ok := logger.entries[1]["data"]; ok {
+		t.Errorf("expected no data, got %v", data)
+	}
+	if got := logger.entries[1]["size"]; got != len("user=kratos&password=secret") {
+		t.Errorf("expected the body size, got %v", got)
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	logger := &testLogger{}
+	New(WithLogger(logger), WithMaxSize(8)).Body("client", "http", "/hello", Response, "text/plain", []byte(strings.Repeat("x", 64)))
+
+	if got := logger.entries[0]["data"]; got != strings.Repeat("x", 8) {
+		t.Errorf("expected truncated data, got %v", got)
+	}
+	if got := logger.entries[0]["truncated"]; got != true {
+		t.Errorf("expected truncated, got %v", got)
+	}
+	if got := logger.entries[0]["size"]; got != 64 {
+		t.Errorf("expected size 64, got %v", got)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	logger := &testLogger{}
+	d := New(WithLogger(logger), WithMaxSize(8))
+	c := d.Capture()
+	for i := 0; i < 1024; i++ {
+		if n, err := c.Write([]byte(strings.Repeat("x", 64))); n != 64 || err != nil {
+			t.Fatalf("expected the write accepted, got %d %v", n, err)
+		}
+	}
+	// one more byte than the max size is kept to report the truncation
+	if len(c.data) != 9 {
+		t.Errorf("expected the capture capped, kept %d bytes", len(c.data))
+	}
+	d.CapturedBody("server", "http", "/upload", Request, "text/plain", c)
+	if got := logger.entries[0]["data"]; got != strings.Repeat("x", 8) {
+		t.Errorf("expected truncated data, got %v", got)
+	}
+	if got := logger.entries[0]["truncated"]; got != true {
+		t.Errorf("expected truncated, got %v", got)
+	}
+	if got := logger.entries[0]["size"]; got != 64*1024 {
+		t.Errorf("expected size %d, got %v", 64*1024, got)
+	}
+
+	// a truncated body cannot be redacted
+	logger = &testLogger{}
+	d = New(WithLogger(logger), WithMaxSize(8), WithRedactFields("password"))
+	c = d.Capture()
+	_, _ = c.Write([]byte(`{"user":"kratos","password":"secret"}`))
+	d.CapturedBody("server", "http", "/login", Request, "application/json", c)
+	if _, ok := logger.entries[0]["data"]; ok {
+		t.Errorf("expected no data, got %v", logger.entries[0]["data"])
+	}
+}
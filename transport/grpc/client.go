@@ -18,6 +18,7 @@ import (
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/wrr"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/dump"
 	"github.com/go-kratos/kratos/v2/transport/grpc/resolver/discovery"
 
 	// init resolver
@@ -62,6 +63,14 @@ func WithMiddleware(m ...middleware.Middleware) ClientOption {
 	}
 }
 
+// WithStreamMiddleware with client stream middleware, run around every
+// message sent or received on a stream.
+func WithStreamMiddleware(m ...middleware.Middleware) ClientOption {
+	return func(o *clientOptions) {
+		o.streamMiddleware = m
+	}
+}
+
 // WithDiscovery with client discovery.
 func WithDiscovery(d registry.Discovery) ClientOption {
 	return func(o *clientOptions) {
@@ -141,6 +150,9 @@ type clientOptions struct {
 	filters                []selector.NodeFilter
 	healthCheckConfig      string
 	printDiscoveryDebugLog bool
+	compressors            *compressorMatcher
+	dumper                 *dump.Dumper
+	maxResponseSize        *matcher.Sizes
 }
 
 // Dial returns a GRPC connection.
@@ -168,9 +180,21 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 		unaryClientInterceptor(options.middleware, options.timeout, options.filters),
 	}
 	sints := []grpc.StreamClientInterceptor{
-		streamClientInterceptor(options.streamMiddleware, options.filters),
+		streamClientInterceptor(options.middleware, options.streamMiddleware, options.filters),
 	}
 
+	if options.compressors != nil {
+		ints = append(ints, unaryCompressorInterceptor(options.compressors))
+		sints = append(sints, streamCompressorInterceptor(options.compressors))
+	}
+	if options.maxResponseSize != nil {
+		ints = append(ints, unaryMaxResponseSizeInterceptor(options.maxResponseSize))
+		sints = append(sints, streamMaxResponseSizeInterceptor(options.maxResponseSize))
+	}
+	if options.dumper != nil {
+		ints = append(ints, unaryClientDumpInterceptor(options.dumper))
+		sints = append(sints, streamClientDumpInterceptor(options.dumper))
+	}
 	if len(options.ints) > 0 {
 		ints = append(ints, options.ints...)
 	}
@@ -235,8 +259,9 @@ func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, f
 		if len(ms) > 0 {
 			h = middleware.Chain(ms...)(h)
 		}
-		var p selector.Peer
-		ctx = selector.NewPeerContext(ctx, &p)
+		if _, ok := selector.FromPeerContext(ctx); !ok {
+			ctx = selector.NewPeerContext(ctx, &selector.Peer{})
+		}
 		_, err := h(ctx, req)
 		return err
 	}
@@ -267,7 +292,7 @@ func (w *wrappedClientStream) SendMsg(m any) error {
 		h = middleware.Chain(next...)(h)
 	}
 
-	_, err := h(w.ctx, m)
+	_, err := h(newMessageContext(w.ctx, MessageSend), m)
 	return err
 }
 
@@ -285,11 +310,11 @@ func (w *wrappedClientStream) RecvMsg(m any) error {
 		h = middleware.Chain(next...)(h)
 	}
 
-	_, err := h(w.ctx, m)
+	_, err := h(newMessageContext(w.ctx, MessageRecv), m)
 	return err
 }
 
-func streamClientInterceptor(ms []middleware.Middleware, filters []selector.NodeFilter) grpc.StreamClientInterceptor {
+func streamClientInterceptor(ms, streamMs []middleware.Middleware, filters []selector.NodeFilter) grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) { // nolint
 		ctx = transport.NewClientContext(ctx, &Transport{
 			endpoint:    cc.Target(),
@@ -297,30 +322,43 @@ func streamClientInterceptor(ms []middleware.Middleware, filters []selector.Node
 			reqHeader:   headerCarrier{},
 			nodeFilters: filters,
 		})
-		var p selector.Peer
-		ctx = selector.NewPeerContext(ctx, &p)
-
-		clientStream, err := streamer(ctx, desc, cc, method, opts...)
-		if err != nil {
-			return nil, err
+		if _, ok := selector.FromPeerContext(ctx); !ok {
+			ctx = selector.NewPeerContext(ctx, &selector.Peer{})
 		}
 
-		h := func(_ context.Context, _ any) (any, error) {
-			return streamer, nil
+		// the middleware runs once when the stream is opened, the stream
+		// middleware around each of its messages.
+		h := func(ctx context.Context, _ any) (any, error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				header := tr.RequestHeader()
+				keys := header.Keys()
+				keyvals := make([]string, 0, len(keys))
+				for _, k := range keys {
+					keyvals = append(keyvals, k, header.Get(k))
+				}
+				ctx = grpcmd.AppendToOutgoingContext(ctx, keyvals...)
+			}
+			clientStream, err := streamer(ctx, desc, cc, method, opts...)
+			if err != nil {
+				return nil, err
+			}
+			m := matcher.New()
+			if len(streamMs) > 0 {
+				m.Use(streamMs...)
+			}
+			return &wrappedClientStream{
+				ClientStream: clientStream,
+				ctx:          ctx,
+				middleware:   m,
+			}, nil
 		}
-
-		m := matcher.New()
 		if len(ms) > 0 {
-			m.Use(ms...)
-			middleware.Chain(ms...)(h)
+			h = middleware.Chain(ms...)(h)
 		}
-
-		wrappedStream := &wrappedClientStream{
-			ClientStream: clientStream,
-			ctx:          ctx,
-			middleware:   m,
+		clientStream, err := h(ctx, nil)
+		if err != nil {
+			return nil, err
 		}
-
-		return wrappedStream, nil
+		return clientStream.(grpc.ClientStream), nil
 	}
 }
@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	// init gzip compressor
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+// WithCompressor enables the registered compressor name, e.g. "gzip", for the
+// calls whose operation matches one of the selectors. Calls are not compressed
+// by default.
+// selector:
+//   - '/*'
+//   - '/helloworld.v1.Greeter/*'
+//   - '/helloworld.v1.Greeter/SayHello'
+func WithCompressor(name string, selectors ...string) ClientOption {
+	return func(o *clientOptions) {
+		if o.compressors == nil {
+			o.compressors = newCompressorMatcher()
+		}
+		for _, selector := range selectors {
+			o.compressors.add(selector, name)
+		}
+	}
+}
+
+// RegisterCompressor registers c, e.g. zstd, for every server and client of
+// the process in addition to gzip which is always available. Like
+// encoding.RegisterCompressor it is not thread-safe and must be called from
+// an init function.
+func RegisterCompressor(c encoding.Compressor) {
+	encoding.RegisterCompressor(c)
+}
+
+// Compressors selects the registered compressors the server replies with,
+// the first one of names advertised by the client; the replies to the other
+// clients are not compressed. By default the server replies using the
+// compressor of the request.
+func Compressors(names ...string) ServerOption {
+	return func(s *Server) {
+		s.compressors = names
+	}
+}
+
+// setSendCompressor sets the compressor of the reply to the first of names
+// supported by the client, identity when there is none.
+func setSendCompressor(ctx context.Context, names []string) error {
+	supported, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil {
+		return err
+	}
+	name := encoding.Identity
+	for _, n := range names {
+		if encoding.GetCompressor(n) != nil && slices.Contains(supported, n) {
+			name = n
+			break
+		}
+	}
+	return grpc.SetSendCompressor(ctx, name)
+}
+
+func unaryCompressorServerInterceptor(names []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := setSendCompressor(ctx, names); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamCompressorServerInterceptor(names []string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := setSendCompressor(ss.Context(), names); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// compressorMatcher maps operation selectors to compressor names.
+type compressorMatcher struct {
+	prefix  []string
+	matches map[string]string
+}
+
+func newCompressorMatcher() *compressorMatcher {
+	return &compressorMatcher{matches: make(map[string]string)}
+}
+
+func (m *compressorMatcher) add(selector, name string) {
+	if strings.HasSuffix(selector, "*") {
+		selector = strings.TrimSuffix(selector, "*")
+		m.prefix = append(m.prefix, selector)
+		sort.Slice(m.prefix, func(i, j int) bool {
+			return m.prefix[i] > m.prefix[j]
+		})
+	}
+	m.matches[selector] = name
+}
+
+func (m *compressorMatcher) match(operation string) string {
+	if name, ok := m.matches[operation]; ok {
+		return name
+	}
+	for _, prefix := range m.prefix {
+		if strings.HasPrefix(operation, prefix) {
+			return m.matches[prefix]
+		}
+	}
+	return ""
+}
+
+func unaryCompressorInterceptor(m *compressorMatcher) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if name := m.match(method); name != "" {
+			opts = append(opts, grpc.UseCompressor(name))
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func streamCompressorInterceptor(m *compressorMatcher) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if name := m.match(method); name != "" {
+			opts = append(opts, grpc.UseCompressor(name))
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
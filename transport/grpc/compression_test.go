@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+const testCompressorName = "kratos-test-gzip"
+
+// countingCompressor wraps gzip and counts the decompressed messages
+// of both client and server, which share the compressor registry.
+type countingCompressor struct {
+	encoding.Compressor
+	decompressed int64
+}
+
+func (c *countingCompressor) Name() string {
+	return testCompressorName
+}
+
+func (c *countingCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	atomic.AddInt64(&c.decompressed, 1)
+	return c.Compressor.Decompress(r)
+}
+
+var testCompressor = &countingCompressor{Compressor: encoding.GetCompressor(gzip.Name)}
+
+func init() {
+	RegisterCompressor(testCompressor)
+}
+
+// startCompressionServer starts a greeter server and returns a client
+// compressing the calls of SayHello with testCompressorName.
+func startCompressionServer(t *testing.T, opts ...ServerOption) pb.GreeterClient {
+	srv := NewServer(opts...)
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	t.Cleanup(func() { _ = srv.Stop(context.Background()) })
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(u.Host),
+		WithOptions(grpc.WithBlock()),
+		WithCompressor(testCompressorName, "/helloworld.Greeter/SayHello"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewGreeterClient(conn)
+}
+
+func TestCompressorMatcher(t *testing.T) {
+	m := newCompressorMatcher()
+	m.add("/helloworld.Greeter/SayHello", "gzip")
+	m.add("/admin.*", "snappy")
+	tests := []struct {
+		operation string
+		want      string
+	}{
+		{"/helloworld.Greeter/SayHello", "gzip"},
+		{"/helloworld.Greeter/SayHelloStream", ""},
+		{"/admin.Admin/Get", "snappy"},
+	}
+	for _, test := range tests {
+		if got := m.match(test.operation); got != test.want {
+			t.Errorf("%s: expect %q, got %q", test.operation, test.want, got)
+		}
+	}
+}
+
+func TestWithCompressor(t *testing.T) {
+	c := testCompressor
+	start := atomic.LoadInt64(&c.decompressed)
+	client := startCompressionServer(t)
+
+	if _, err := client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	// both the request and the reply are compressed, the reply by server
+	// using the compressor of the request.
+	if got := atomic.LoadInt64(&c.decompressed) - start; got != 2 {
+		t.Errorf("expect compressed call for marked operation, decompressed %d", got)
+	}
+
+	stream, err := client.SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.Send(&pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	_ = stream.CloseSend()
+	if got := atomic.LoadInt64(&c.decompressed) - start; got != 2 {
+		t.Errorf("expect uncompressed call for unmarked operation, decompressed %d", got)
+	}
+}
+
+func TestCompressors(t *testing.T) {
+	c := testCompressor
+	start := atomic.LoadInt64(&c.decompressed)
+	// the server replies with gzip to the requests of the test compressor
+	client := startCompressionServer(t, Compressors("unregistered", gzip.Name))
+	if _, err := client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt64(&c.decompressed) - start; got != 1 {
+		t.Errorf("expect only the request decompressed by the test compressor, decompressed %d", got)
+	}
+}
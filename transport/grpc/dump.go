@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/go-kratos/kratos/v2/transport/dump"
+)
+
+// Dump enables the dump mode of the server, the serialized requests and
+// replies are logged by d at debug level. It is disabled by default.
+func Dump(d *dump.Dumper) ServerOption {
+	return func(s *Server) {
+		s.dumper = d
+	}
+}
+
+// WithDump enables the dump mode of the client, the serialized requests and
+// replies are logged by d at debug level. It is disabled by default.
+func WithDump(d *dump.Dumper) ClientOption {
+	return func(o *clientOptions) {
+		o.dumper = d
+	}
+}
+
+func unaryServerDumpInterceptor(d *dump.Dumper) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		d.Message("server", "grpc", info.FullMethod, dump.Request, req)
+		reply, err := handler(ctx, req)
+		if err == nil {
+			d.Message("server", "grpc", info.FullMethod, dump.Response, reply)
+		}
+		return reply, err
+	}
+}
+
+func streamServerDumpInterceptor(d *dump.Dumper) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &dumpServerStream{ServerStream: ss, dumper: d, operation: info.FullMethod})
+	}
+}
+
+type dumpServerStream struct {
+	grpc.ServerStream
+	dumper    *dump.Dumper
+	operation string
+}
+
+func (s *dumpServerStream) SendMsg(m any) error {
+	s.dumper.Message("server", "grpc", s.operation, dump.Response, m)
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *dumpServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.dumper.Message("server", "grpc", s.operation, dump.Request, m)
+	return nil
+}
+
+func unaryClientDumpInterceptor(d *dump.Dumper) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		d.Message("client", "grpc", method, dump.Request, req)
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+		d.Message("client", "grpc", method, dump.Response, reply)
+		return nil
+	}
+}
+
+func streamClientDumpInterceptor(d *dump.Dumper) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &dumpClientStream{ClientStream: cs, dumper: d, operation: method}, nil
+	}
+}
+
+type dumpClientStream struct {
+	grpc.ClientStream
+	dumper    *dump.Dumper
+	operation string
+}
+
+func (s *dumpClientStream) SendMsg(m any) error {
+	s.dumper.Message("client", "grpc", s.operation, dump.Request, m)
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *dumpClientStream) RecvMsg(m any) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.dumper.Message("client", "grpc", s.operation, dump.Response, m)
+	return nil
+}
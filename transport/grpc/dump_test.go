@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/dump"
+)
+
+type dumpLogger struct {
+	mu    sync.Mutex
+	dumps []string
+}
+
+func (l *dumpLogger) Log(_ log.Level, keyvals ...any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var kind, direction, operation string
+	dumped := false
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case "msg":
+			dumped = keyvals[i+1] == "dump"
+		case "kind":
+			kind, _ = keyvals[i+1].(string)
+		case "direction":
+			direction, _ = keyvals[i+1].(string)
+		case "operation":
+			operation, _ = keyvals[i+1].(string)
+		}
+	}
+	if dumped {
+		l.dumps = append(l.dumps, kind+" "+direction+" "+operation)
+	}
+	return nil
+}
+
+func (l *dumpLogger) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.dumps...)
+}
+
+func sayHello(t *testing.T, srvOpts []ServerOption, clientOpts ...ClientOption) {
+	srv := NewServer(srvOpts...)
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	time.Sleep(time.Second)
+	conn, err := DialInsecure(context.Background(), append(clientOpts, WithEndpoint(u.Host), WithOptions(grpc.WithBlock()))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err = pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDump(t *testing.T) {
+	logger := &dumpLogger{}
+	d := dump.New(dump.WithLogger(logger))
+	sayHello(t, []ServerOption{Dump(d)}, WithDump(d))
+
+	want := map[string]bool{
+		"client request /helloworld.Greeter/SayHello":  true,
+		"server request /helloworld.Greeter/SayHello":  true,
+		"server response /helloworld.Greeter/SayHello": true,
+		"client response /helloworld.Greeter/SayHello": true,
+	}
+	var got []string
+	for _, g := range logger.get() {
+		// the health checks of the client are dumped too
+		if !strings.Contains(g, "/grpc.health.v1.Health/") {
+			got = append(got, g)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d dumps, got %v", len(want), got)
+	}
+	for _, g := range got {
+		if !want[g] {
+			t.Errorf("unexpected dump %q", g)
+		}
+	}
+}
+
+func TestDumpDisabled(t *testing.T) {
+	logger := &dumpLogger{}
+	global := log.GetLogger()
+	log.SetLogger(logger)
+	defer log.SetLogger(global)
+	sayHello(t, nil)
+
+	if got := logger.get(); len(got) != 0 {
+		t.Errorf("expected no dump, got %v", got)
+	}
+}
@@ -3,6 +3,7 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"google.golang.org/grpc"
 	grpcmd "google.golang.org/grpc/metadata"
@@ -29,6 +30,7 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 			tr.endpoint = s.endpoint.String()
 		}
 		ctx = transport.NewServerContext(ctx, tr)
+		ctx = ic.WithRequestDeadline(ctx)
 		if s.timeout > 0 {
 			ctx, cancel = context.WithTimeout(ctx, s.timeout)
 			defer cancel()
@@ -73,28 +75,29 @@ func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 		defer cancel()
 		md, _ := grpcmd.FromIncomingContext(ctx)
 		replyHeader := grpcmd.MD{}
-		ctx = transport.NewServerContext(ctx, &Transport{
-			endpoint:    s.endpoint.String(),
+		tr := &Transport{
 			operation:   info.FullMethod,
 			reqHeader:   headerCarrier(md),
 			replyHeader: headerCarrier(replyHeader),
-		})
-
-		h := func(_ context.Context, _ any) (any, error) {
-			return handler(srv, ss), nil
 		}
-
-		if next := s.streamMiddleware.Match(info.FullMethod); len(next) > 0 {
-			middleware.Chain(next...)(h)
+		if s.endpoint != nil {
+			tr.endpoint = s.endpoint.String()
 		}
+		ctx = transport.NewServerContext(ctx, tr)
 
-		ctx = context.WithValue(ctx, stream{
-			ServerStream:     ss,
-			streamMiddleware: s.streamMiddleware,
-		}, ss)
-		ws := NewWrappedStream(ctx, ss, s.streamMiddleware)
-
-		err := handler(srv, ws)
+		// the middleware runs once when the stream is opened, the stream
+		// middleware around each of its messages.
+		h := func(ctx context.Context, _ any) (any, error) {
+			ctx = context.WithValue(ctx, stream{
+				ServerStream:     ss,
+				streamMiddleware: s.streamMiddleware,
+			}, ss)
+			return nil, handler(srv, NewWrappedStream(ctx, ss, s.streamMiddleware))
+		}
+		if next := s.middleware.Match(info.FullMethod); len(next) > 0 && !internalStream(info.FullMethod) {
+			h = middleware.Chain(next...)(h)
+		}
+		_, err := h(ctx, nil)
 		if len(replyHeader) > 0 {
 			_ = grpc.SetHeader(ctx, replyHeader)
 		}
@@ -102,6 +105,13 @@ func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 	}
 }
 
+// internalStream reports whether method is a stream of the health or
+// reflection services, which are not subject to the middleware so that
+// clients keep health checking the server.
+func internalStream(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.Health/") || strings.HasPrefix(method, "/grpc.reflection.")
+}
+
 type stream struct {
 	grpc.ServerStream
 	streamMiddleware matcher.Matcher
@@ -125,7 +135,7 @@ func (w *wrappedStream) SendMsg(m any) error {
 		h = middleware.Chain(next...)(h)
 	}
 
-	_, err := h(w.ctx, m)
+	_, err := h(newMessageContext(w.ctx, MessageSend), m)
 	return err
 }
 
@@ -143,6 +153,6 @@ func (w *wrappedStream) RecvMsg(m any) error {
 		h = middleware.Chain(next...)(h)
 	}
 
-	_, err := h(w.ctx, m)
+	_, err := h(newMessageContext(w.ctx, MessageRecv), m)
 	return err
 }
@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"math"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+)
+
+// ErrResponseTooLarge is returned when a response message exceeds the
+// maximum size, the error of grpc is its cause.
+var ErrResponseTooLarge = errors.New(http.StatusBadGateway, "RESPONSE_TOO_LARGE", "response exceeds the maximum size")
+
+// WithMaxResponseSize limits the response messages of the calls whose
+// operation matches one of the selectors to size bytes, or of every call
+// without a more specific limit when there is none. It overrides the
+// limit of grpc, 4MB by default, for the matching calls.
+// selector:
+//   - '/*'
+//   - '/helloworld.v1.Greeter/*'
+//   - '/helloworld.v1.Greeter/SayHello'
+func WithMaxResponseSize(size int64, selectors ...string) ClientOption {
+	return func(o *clientOptions) {
+		if o.maxResponseSize == nil {
+			o.maxResponseSize = &matcher.Sizes{}
+		}
+		o.maxResponseSize.Add(size, selectors...)
+	}
+}
+
+// responseTooLarge converts the error of grpc for a message exceeding
+// the maximum size into ErrResponseTooLarge. It is the ResourceExhausted
+// status without details, unlike the errors of a kratos server such as
+// the ones of its rate limiter, which carry their reason.
+func responseTooLarge(err error) error {
+	if s, ok := status.FromError(err); ok && s.Code() == codes.ResourceExhausted && len(s.Details()) == 0 {
+		return ErrResponseTooLarge.WithCause(err)
+	}
+	return err
+}
+
+// callRecvMsgSize is size as the receive limit of a call, capped at the
+// largest int.
+func callRecvMsgSize(size int64) grpc.CallOption {
+	return grpc.MaxCallRecvMsgSize(int(min(size, math.MaxInt)))
+}
+
+func unaryMaxResponseSizeInterceptor(m *matcher.Sizes) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		size := m.Match(method)
+		if size <= 0 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		opts = append(opts, callRecvMsgSize(size))
+		return responseTooLarge(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+func streamMaxResponseSizeInterceptor(m *matcher.Sizes) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		size := m.Match(method)
+		if size <= 0 {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		opts = append(opts, callRecvMsgSize(size))
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &limitedClientStream{ClientStream: cs}, nil
+	}
+}
+
+// limitedClientStream converts the errors of the received messages
+// exceeding the maximum size.
+type limitedClientStream struct {
+	grpc.ClientStream
+}
+
+func (s *limitedClientStream) RecvMsg(m any) error {
+	return responseTooLarge(s.ClientStream.RecvMsg(m))
+}
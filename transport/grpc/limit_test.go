@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+func TestWithMaxResponseSize(t *testing.T) {
+	srv := NewServer()
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(u.Host),
+		WithOptions(grpc.WithBlock()),
+		WithMaxResponseSize(64, "/helloworld.Greeter/SayHello"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := pb.NewGreeterClient(conn)
+
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Errorf("expected the small reply, got %v", err)
+	}
+	long := strings.Repeat("x", 100)
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: long}); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected %v, got %v", ErrResponseTooLarge, err)
+	}
+
+	// the other operations keep the default limit
+	stream, err := client.SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.Send(&pb.HelloRequest{Name: long}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		t.Errorf("expected the unlimited reply, got %v", err)
+	}
+	_ = stream.CloseSend()
+}
+
+func TestWithMaxResponseSizeStream(t *testing.T) {
+	srv := NewServer()
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(u.Host),
+		WithOptions(grpc.WithBlock()),
+		WithMaxResponseSize(64),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	stream, err := pb.NewGreeterClient(conn).SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.Send(&pb.HelloRequest{Name: strings.Repeat("x", 100)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stream.Recv(); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected %v, got %v", ErrResponseTooLarge, err)
+	}
+}
+
+func TestResponseTooLarge(t *testing.T) {
+	local := status.Error(codes.ResourceExhausted, "grpc: received message larger than max (100 vs. 64)")
+	if err := responseTooLarge(local); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected %v, got %v", ErrResponseTooLarge, err)
+	}
+	// a rate limited call of a kratos server carries its reason
+	limited := kratoserrors.New(http.StatusTooManyRequests, "RATELIMIT", "service unavailable due to rate limit exceeded")
+	if err := responseTooLarge(limited.GRPCStatus().Err()); errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected the rate limit error kept, got %v", err)
+	}
+	if err := responseTooLarge(status.Error(codes.Unavailable, "")); errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected the other errors kept, got %v", err)
+	}
+}
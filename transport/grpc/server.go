@@ -3,8 +3,10 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"net"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
@@ -21,13 +23,17 @@ import (
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/dump"
 )
 
 var (
 	_ transport.Server     = (*Server)(nil)
 	_ transport.Endpointer = (*Server)(nil)
+	_ transport.Readier    = (*Server)(nil)
 )
 
+var errNotServing = errors.New("grpc: server is not serving")
+
 // ServerOption is gRPC server option.
 type ServerOption func(o *Server)
 
@@ -142,12 +148,15 @@ type Server struct {
 	streamMiddleware  matcher.Matcher
 	unaryInts         []grpc.UnaryServerInterceptor
 	streamInts        []grpc.StreamServerInterceptor
+	compressors       []string
 	grpcOpts          []grpc.ServerOption
 	health            *health.Server
 	customHealth      bool
 	metadata          *apimd.Server
 	adminClean        func()
 	disableReflection bool
+	dumper            *dump.Dumper
+	serving           atomic.Bool
 }
 
 // NewServer creates a gRPC server by options.
@@ -170,6 +179,14 @@ func NewServer(opts ...ServerOption) *Server {
 	streamInts := []grpc.StreamServerInterceptor{
 		srv.streamServerInterceptor(),
 	}
+	if len(srv.compressors) > 0 {
+		unaryInts = append(unaryInts, unaryCompressorServerInterceptor(srv.compressors))
+		streamInts = append(streamInts, streamCompressorServerInterceptor(srv.compressors))
+	}
+	if srv.dumper != nil {
+		unaryInts = append(unaryInts, unaryServerDumpInterceptor(srv.dumper))
+		streamInts = append(streamInts, streamServerDumpInterceptor(srv.dumper))
+	}
 	if len(srv.unaryInts) > 0 {
 		unaryInts = append(unaryInts, srv.unaryInts...)
 	}
@@ -230,6 +247,8 @@ func (s *Server) Start(ctx context.Context) error {
 	s.baseCtx = ctx
 	log.Infof("[gRPC] server listening on: %s", s.lis.Addr().String())
 	s.health.Resume()
+	s.serving.Store(true)
+	defer s.serving.Store(false)
 	return s.Serve(s.lis)
 }
 
@@ -239,6 +258,7 @@ func (s *Server) Stop(ctx context.Context) error {
 		s.adminClean()
 	}
 	s.health.Shutdown()
+	s.serving.Store(false)
 
 	done := make(chan struct{})
 	go func() {
@@ -256,6 +276,14 @@ func (s *Server) Stop(ctx context.Context) error {
 	return nil
 }
 
+// Ready reports whether the gRPC server is serving requests.
+func (s *Server) Ready() error {
+	if !s.serving.Load() {
+		return errNotServing
+	}
+	return nil
+}
+
 func (s *Server) listenAndEndpoint() error {
 	if s.lis == nil {
 		lis, err := net.Listen(s.network, s.address)
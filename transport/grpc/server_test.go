@@ -498,3 +498,62 @@ func (b *safeBytesBuffer) String() string {
 	defer b.mu.Unlock()
 	return b.buf.String()
 }
+
+func TestReady(t *testing.T) {
+	s := NewServer()
+	if err := s.Ready(); err == nil {
+		t.Fatal("expected not ready before start")
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Start(context.Background()) }()
+	deadline := time.Now().Add(time.Second)
+	for s.Ready() != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected ready after start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if err := s.Ready(); err == nil {
+		t.Error("expected not ready after stop")
+	}
+}
+
+func TestServerErrorDetails(t *testing.T) {
+	violation := errors.FieldViolation{Field: "name", Description: "must not be reserved"}
+	srv := NewServer(Middleware(func(middleware.Handler) middleware.Handler {
+		return func(context.Context, any) (any, error) {
+			return nil, errors.BadRequest("INVALID_NAME", "invalid name").
+				WithMetadata(map[string]string{"name": "kratos"}).
+				WithFieldViolations(violation)
+		}
+	}))
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	conn, err := DialInsecure(context.Background(), WithEndpoint(u.Host), WithOptions(grpc.WithBlock()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	_, err = pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	e := errors.FromError(err)
+	if e.Code != 400 || e.Reason != "INVALID_NAME" || e.Metadata["name"] != "kratos" {
+		t.Errorf("expected the error reconstructed, got %v", err)
+	}
+	if got := e.FieldViolations(); len(got) != 1 || got[0] != violation {
+		t.Errorf("expected the field violation %v, got %v", violation, got)
+	}
+}
@@ -0,0 +1,38 @@
+package grpc
+
+import "context"
+
+// MessageDirection is the direction of a stream message.
+//
+// Server Middleware and client WithMiddleware run once per call, and once
+// when a stream is opened. StreamMiddleware and WithStreamMiddleware run
+// around every message sent or received on a stream, with the message as the
+// request and its direction in the context, e.g. for per-message metrics.
+type MessageDirection int
+
+const (
+	// MessageRecv is a message received from the peer.
+	MessageRecv MessageDirection = iota
+	// MessageSend is a message sent to the peer.
+	MessageSend
+)
+
+func (d MessageDirection) String() string {
+	if d == MessageSend {
+		return "send"
+	}
+	return "recv"
+}
+
+type messageDirectionKey struct{}
+
+// MessageDirectionFromContext returns the direction of the stream message
+// handled by a stream middleware.
+func MessageDirectionFromContext(ctx context.Context) (MessageDirection, bool) {
+	d, ok := ctx.Value(messageDirectionKey{}).(MessageDirection)
+	return d, ok
+}
+
+func newMessageContext(ctx context.Context, d MessageDirection) context.Context {
+	return context.WithValue(ctx, messageDirectionKey{}, d)
+}
@@ -0,0 +1,138 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type messageCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *messageCounter) middleware(prefix string) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			reply, err := handler(ctx, req)
+			// count the messages of the greeter only, not of the health checks
+			d, ok := MessageDirectionFromContext(ctx)
+			if tr, _ := transport.FromServerContext(ctx); tr != nil && tr.Operation() != "/helloworld.Greeter/SayHelloStream" {
+				ok = false
+			}
+			if ok && err == nil {
+				c.mu.Lock()
+				if c.counts == nil {
+					c.counts = make(map[string]int)
+				}
+				c.counts[prefix+" "+d.String()]++
+				c.mu.Unlock()
+			}
+			return reply, err
+		}
+	}
+}
+
+func (c *messageCounter) get(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[key]
+}
+
+func TestStreamMiddleware(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		opens int
+	)
+	auth := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, _ := transport.FromServerContext(ctx)
+			mu.Lock()
+			opens++
+			mu.Unlock()
+			if tr.RequestHeader().Get("authorization") != "token" {
+				return nil, errors.Unauthorized("UNAUTHORIZED", "missing token")
+			}
+			return handler(ctx, req)
+		}
+	}
+	token := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				tr.RequestHeader().Set("authorization", "token")
+			}
+			return handler(ctx, req)
+		}
+	}
+	counter := &messageCounter{}
+
+	srv := NewServer(Middleware(auth), StreamMiddleware(counter.middleware("server")))
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	time.Sleep(time.Second)
+
+	dial := func(opts ...ClientOption) pb.GreeterClient {
+		conn, err := DialInsecure(context.Background(), append(opts, WithEndpoint(u.Host), WithOptions(grpc.WithBlock()))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return pb.NewGreeterClient(conn)
+	}
+
+	// the stream is rejected at open without token
+	stream, err := dial().SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stream.Recv(); !errors.IsUnauthorized(err) {
+		t.Fatalf("expect unauthorized, got %v", err)
+	}
+
+	stream, err = dial(WithMiddleware(token), WithStreamMiddleware(counter.middleware("client"))).SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"kratos", "go"} {
+		if err = stream.Send(&pb.HelloRequest{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = stream.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = stream.CloseSend()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if opens != 2 {
+		t.Errorf("expect the middleware to run once per stream, got %d", opens)
+	}
+	for key, want := range map[string]int{
+		"client send": 2,
+		"client recv": 2,
+		"server recv": 2,
+		"server send": 2,
+	} {
+		if got := counter.get(key); got != want {
+			t.Errorf("%s: expect %d messages, got %d", key, want, got)
+		}
+	}
+}
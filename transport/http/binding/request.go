@@ -0,0 +1,150 @@
+package binding
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// Struct tags selecting the request source of a field. A request is bound
+// from the body first, then the query and finally the path, so on conflict
+// path params take precedence over query params, which take precedence
+// over the body.
+const (
+	// PathTag binds a field from a path param, e.g. `path:"id"`.
+	PathTag = "path"
+	// QueryTag binds a field from a query param, e.g. `query:"notify"`.
+	QueryTag = "query"
+	// BodyTag decodes the request body into the tagged field, e.g. `body:""`.
+	// Without a body field, the body is decoded into the whole target.
+	BodyTag = "body"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// BodyTarget returns the value the request body of target should be decoded
+// into: the address of the field tagged with BodyTag, or target itself.
+// Proto messages always receive the whole body.
+func BodyTarget(target any) any {
+	if _, ok := target.(proto.Message); ok {
+		return target
+	}
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return target
+	}
+	rv = rv.Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		if _, ok := rv.Type().Field(i).Tag.Lookup(BodyTag); ok && rv.Field(i).CanAddr() {
+			return rv.Field(i).Addr().Interface()
+		}
+	}
+	return target
+}
+
+// BindValues binds vars to the fields of target tagged with tag.
+// Proto messages are bound through their field mappings instead,
+// the same way as BindQuery.
+func BindValues(vars url.Values, tag string, target any) error {
+	if len(vars) == 0 {
+		return nil
+	}
+	if _, ok := target.(proto.Message); ok {
+		return BindQuery(vars, target)
+	}
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.BadRequest("CODEC", fmt.Sprintf("binding: unsupported target type %T", target))
+	}
+	if err := bindStruct(rv.Elem(), vars, tag); err != nil {
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	return nil
+}
+
+func bindStruct(rv reflect.Value, vars url.Values, tag string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := field.Tag.Lookup(tag)
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := bindStruct(rv.Field(i), vars, tag); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		values, ok := vars[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if err := setValue(rv.Field(i), values); err != nil {
+			return fmt.Errorf("binding: %s %q: %w", tag, name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), values)
+	}
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+	if v.Kind() == reflect.Slice {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(s.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	value := values[0]
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package binding
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	kratoserror "github.com/go-kratos/kratos/v2/errors"
+)
+
+type (
+	TestPatchBody struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	TestPatch struct {
+		ID      int64         `path:"id"`
+		Notify  bool          `query:"notify"`
+		Tags    []string      `query:"tag"`
+		Timeout time.Duration `query:"timeout"`
+		Version *uint32       `query:"version"`
+		Body    TestPatchBody `body:""`
+	}
+	TestEmbedded struct {
+		TestPatch
+		Trace string `query:"trace"`
+	}
+)
+
+func TestBodyTarget(t *testing.T) {
+	p := &TestPatch{}
+	if got := BodyTarget(p); got != &p.Body {
+		t.Errorf("BodyTarget() = %v, want %v", got, &p.Body)
+	}
+	b := &TestBind{}
+	if got := BodyTarget(b); got != b {
+		t.Errorf("BodyTarget() = %v, want %v", got, b)
+	}
+}
+
+func TestBindValues(t *testing.T) {
+	version := uint32(3)
+	tests := []struct {
+		name   string
+		vars   url.Values
+		tag    string
+		target any
+		want   any
+		err    error
+	}{
+		{
+			name:   "query",
+			vars:   url.Values{"notify": {"true"}, "tag": {"a", "b"}, "timeout": {"1000"}, "version": {"3"}, "id": {"1"}},
+			tag:    QueryTag,
+			target: &TestPatch{},
+			want:   &TestPatch{Notify: true, Tags: []string{"a", "b"}, Timeout: 1000, Version: &version},
+		},
+		{
+			name:   "path",
+			vars:   url.Values{"id": {"42"}, "notify": {"true"}},
+			tag:    PathTag,
+			target: &TestPatch{},
+			want:   &TestPatch{ID: 42},
+		},
+		{
+			name:   "embedded",
+			vars:   url.Values{"notify": {"true"}, "trace": {"abc"}},
+			tag:    QueryTag,
+			target: &TestEmbedded{},
+			want:   &TestEmbedded{TestPatch: TestPatch{Notify: true}, Trace: "abc"},
+		},
+		{
+			name:   "invalid",
+			vars:   url.Values{"id": {"kratos"}},
+			tag:    PathTag,
+			target: &TestPatch{},
+			err:    kratoserror.BadRequest("CODEC", ""),
+		},
+		{
+			name:   "unsupported",
+			vars:   url.Values{"id": {"1"}},
+			tag:    PathTag,
+			target: TestPatch{},
+			err:    kratoserror.BadRequest("CODEC", ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BindValues(tt.vars, tt.tag, tt.target)
+			if tt.err != nil {
+				if !kratoserror.Is(err, tt.err) {
+					t.Fatalf("BindValues() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.target, tt.want) {
+				t.Errorf("BindValues() target = %+v, want %+v", tt.target, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindValuesOverride(t *testing.T) {
+	type Conflict struct {
+		Name string `json:"name" query:"name" path:"name"`
+	}
+	c := &Conflict{Name: "body"}
+	if err := BindValues(url.Values{"name": {"query"}}, QueryTag, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := BindValues(url.Values{"name": {"path"}}, PathTag, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "path" {
+		t.Errorf("expect path to take precedence, got %s", c.Name)
+	}
+}
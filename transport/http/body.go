@@ -0,0 +1,59 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+// ErrBodyTooLarge is returned when the request body exceeds the BufferBody limit.
+var ErrBodyTooLarge = kratoserrors.New(http.StatusRequestEntityTooLarge, "BODY_TOO_LARGE", "request body is too large")
+
+// BufferBody with buffering the request bodies of up to size bytes, so that
+// the middleware can inspect them with RequestBody while the handler still
+// reads the whole body. Larger bodies are rejected with 413 before routing.
+// The bodies are not buffered by default.
+func BufferBody(size int64) ServerOption {
+	return func(s *Server) {
+		s.maxBufferBody = size
+	}
+}
+
+type bodyKey struct{}
+
+// RequestBody returns the request body buffered with BufferBody, ok is false
+// without. The body is shared by the middleware and must not be modified.
+func RequestBody(ctx context.Context) (body []byte, ok bool) {
+	body, ok = ctx.Value(bodyKey{}).([]byte)
+	return
+}
+
+// bufferBody reads the request body into memory up to maxBufferBody bytes.
+func (s *Server) bufferBody(next http.Handler) http.Handler {
+	if s.maxBufferBody <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.ContentLength > s.maxBufferBody {
+			s.ene(w, req, ErrBodyTooLarge)
+			return
+		}
+		// read one byte more than the limit to tell an exact fit from an excess
+		body, err := io.ReadAll(io.LimitReader(req.Body, s.maxBufferBody+1))
+		_ = req.Body.Close()
+		if err != nil {
+			s.ene(w, req, kratoserrors.BadRequest("BODY", err.Error()))
+			return
+		}
+		if int64(len(body)) > s.maxBufferBody {
+			s.ene(w, req, ErrBodyTooLarge)
+			return
+		}
+		req = req.WithContext(context.WithValue(req.Context(), bodyKey{}, body))
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+func bodyServer(seen *string, opts ...ServerOption) *Server {
+	inspect := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			body, ok := RequestBody(ctx)
+			if ok {
+				*seen = string(body)
+			} else {
+				*seen = "<none>"
+			}
+			return handler(ctx, req)
+		}
+	}
+	srv := NewServer(append(opts, Middleware(inspect))...)
+	srv.Route("/").POST("/users", func(ctx Context) error {
+		h := ctx.Middleware(func(context.Context, any) (any, error) {
+			var in User
+			if err := ctx.Bind(&in); err != nil {
+				return nil, err
+			}
+			return &in, nil
+		})
+		return ctx.Returns(h(ctx, nil))
+	})
+	return srv
+}
+
+func TestBufferBody(t *testing.T) {
+	var seen string
+	srv := bodyServer(&seen, BufferBody(32))
+	body := `{"name":"kratos"}`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body)
+	}
+	if seen != body {
+		t.Errorf("expected the middleware to see %s, got %s", body, seen)
+	}
+	if w.Body.String() != body {
+		t.Errorf("expected the handler to decode %s, got %s", body, w.Body)
+	}
+}
+
+func TestBufferBodyTooLarge(t *testing.T) {
+	large := `{"name":"` + strings.Repeat("x", 32) + `"}`
+	tests := []struct {
+		name string
+		body io.Reader
+	}{
+		{"content length", strings.NewReader(large)},
+		// chunked, without a Content-Length
+		{"chunked", io.MultiReader(strings.NewReader(large))},
+	}
+	for _, tt := range tests {
+		seen := ""
+		srv := bodyServer(&seen, BufferBody(32))
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/users", tt.body)
+		req.Header.Set("Content-Type", "application/json")
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: expected code 413, got %d", tt.name, w.Code)
+		}
+		if seen != "" {
+			t.Errorf("%s: expected the request rejected before the middleware", tt.name)
+		}
+	}
+}
+
+func TestBufferBodyDisabled(t *testing.T) {
+	var seen string
+	srv := bodyServer(&seen)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"kratos"}`))
+	req.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body)
+	}
+	if seen != "<none>" {
+		t.Errorf("expected no buffered body, got %s", seen)
+	}
+}
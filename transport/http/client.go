@@ -13,11 +13,13 @@ import (
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/go-kratos/kratos/v2/internal/httputil"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/wrr"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/dump"
 )
 
 func init() {
@@ -54,6 +56,9 @@ type clientOptions struct {
 	middleware   []middleware.Middleware
 	block        bool
 	subsetSize   int
+	dumper       *dump.Dumper
+
+	maxResponseSize matcher.Sizes
 }
 
 // WithSubset with client discovery subset size.
@@ -253,7 +258,16 @@ func (client *Client) Invoke(ctx context.Context, method, path string, args any,
 
 func (client *Client) invoke(ctx context.Context, req *http.Request, args any, reply any, c callInfo, opts ...CallOption) error {
 	h := func(ctx context.Context, _ any) (any, error) {
-		res, err := client.do(req.WithContext(ctx))
+		req := req.WithContext(ctx)
+		if req.GetBody != nil {
+			// a fresh body for every attempt, e.g. of the retry middleware
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		res, err := client.do(req)
 		if res != nil {
 			cs := csAttempt{res: res}
 			for _, o := range opts {
@@ -269,8 +283,9 @@ func (client *Client) invoke(ctx context.Context, req *http.Request, args any, r
 		}
 		return reply, nil
 	}
-	var p selector.Peer
-	ctx = selector.NewPeerContext(ctx, &p)
+	if _, ok := selector.FromPeerContext(ctx); !ok {
+		ctx = selector.NewPeerContext(ctx, &selector.Peer{})
+	}
 	if len(client.opts.middleware) > 0 {
 		h = middleware.Chain(client.opts.middleware...)(h)
 	}
@@ -299,7 +314,9 @@ func (client *Client) do(req *http.Request) (*http.Response, error) {
 			node selector.Node
 		)
 		if node, done, err = client.selector.Select(req.Context(), selector.WithNodeFilter(client.opts.nodeFilters...)); err != nil {
-			return nil, errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
+			// keep the selector error, e.g. its no available cause
+			return nil, errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error()).
+				WithMetadata(errors.FromError(err).Metadata).WithCause(err)
 		}
 		if client.insecure {
 			req.URL.Scheme = "http"
@@ -309,7 +326,16 @@ func (client *Client) do(req *http.Request) (*http.Response, error) {
 		req.URL.Host = node.Address()
 		req.Host = node.Address()
 	}
+	if client.opts.dumper != nil {
+		client.dumpRequest(req)
+	}
 	resp, err := client.cc.Do(req)
+	if err == nil {
+		err = client.limitResponse(req, resp)
+	}
+	if err == nil && client.opts.dumper != nil {
+		client.dumpResponse(req, resp)
+	}
 	if err == nil {
 		t, ok := transport.FromClientContext(req.Context())
 		if ok {
@@ -367,3 +367,27 @@ func TestNewClient(t *testing.T) {
 		t.Error("err should be equal to encoder error")
 	}
 }
+
+func TestInvokeNoAvailableCause(t *testing.T) {
+	client, err := NewClient(
+		context.Background(),
+		WithDiscovery(&mockDiscovery{}),
+		WithEndpoint("discovery:///go-kratos"),
+		WithBlock(),
+		WithNodeFilter(func(context.Context, []selector.Node) []selector.Node { return nil }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+	err = client.Invoke(context.Background(), http.MethodPost, "/go", map[string]string{"name": "kratos"}, nil)
+	if !kratoserrors.IsServiceUnavailable(err) || kratoserrors.Reason(err) != "NODE_NOT_FOUND" {
+		t.Fatalf("expected NODE_NOT_FOUND, got %v", err)
+	}
+	if cause, ok := selector.NoAvailableCauseOf(err); !ok || cause != selector.NoAvailableFiltered {
+		t.Errorf("expected cause %s, got %s %v", selector.NoAvailableFiltered, cause, ok)
+	}
+	if got := kratoserrors.FromError(err).Metadata["cause"]; got != string(selector.NoAvailableFiltered) {
+		t.Errorf("expected the cause metadata kept, got %q", got)
+	}
+}
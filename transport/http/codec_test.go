@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/gob"
 	"github.com/go-kratos/kratos/v2/errors"
 )
 
@@ -151,3 +153,39 @@ func TestCodecForRequest(t *testing.T) {
 		t.Errorf("expected %v, got %v", "json", c.Name())
 	}
 }
+
+func TestGobCodec(t *testing.T) {
+	type message struct {
+		A string
+		B int64
+	}
+	data, err := encoding.GetCodec("gob").Marshal(&message{A: "1", B: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := http.NewRequest(http.MethodPost, "", io.NopCloser(bytes.NewReader(data)))
+	r.Header.Set("Content-Type", "application/x-gob")
+	r.Header.Set("Accept", "application/x-gob")
+	var in message
+	if err = DefaultRequestDecoder(r, &in); err != nil {
+		t.Fatal(err)
+	}
+	if in.A != "1" || in.B != 2 {
+		t.Errorf("unexpected request %+v", in)
+	}
+
+	w := &mockResponseWriter{StatusCode: 200, header: make(http.Header)}
+	if err = DefaultResponseEncoder(w, r, &in); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/x-gob" {
+		t.Errorf("expected %v, got %v", "application/x-gob", got)
+	}
+	var out message
+	if err = encoding.GetCodec("gob").Unmarshal(w.Data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("expected %+v, got %+v", in, out)
+	}
+}
@@ -0,0 +1,290 @@
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMinSize is the default minimum response size to compress.
+const DefaultMinSize = 1024
+
+// DefaultContentTypes is the default allowlist of compressed content types.
+var DefaultContentTypes = []string{
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/x-protobuf",
+	"image/svg+xml",
+	"text/*",
+}
+
+// Encoder creates an encoding writer compressing into w.
+type Encoder func(w io.Writer) io.WriteCloser
+
+// Option is compress option.
+type Option func(*options)
+
+type options struct {
+	minSize      int
+	level        int
+	contentTypes []string
+	encodings    []encoding
+}
+
+type encoding struct {
+	name string
+	new  Encoder
+}
+
+// WithMinSize compresses responses of at least n bytes, default is 1024.
+func WithMinSize(n int) Option {
+	return func(o *options) {
+		o.minSize = n
+	}
+}
+
+// WithLevel with the gzip compression level, default is gzip.DefaultCompression.
+func WithLevel(level int) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// WithContentTypes with the allowlist of compressed content types.
+// A "type/*" entry matches every subtype.
+func WithContentTypes(types ...string) Option {
+	return func(o *options) {
+		o.contentTypes = types
+	}
+}
+
+// WithEncoder registers an additional encoding, e.g. "br", preferred over
+// gzip when the client accepts both.
+func WithEncoder(name string, enc Encoder) Option {
+	return func(o *options) {
+		o.encodings = append(o.encodings, encoding{name: name, new: enc})
+	}
+}
+
+// Filter returns an HTTP filter compressing the responses of allowlisted
+// content types above the minimum size, honoring the Accept-Encoding of the
+// client. Responses already carrying a Content-Encoding are left untouched.
+func Filter(opts ...Option) func(http.Handler) http.Handler {
+	o := options{
+		minSize:      DefaultMinSize,
+		level:        gzip.DefaultCompression,
+		contentTypes: DefaultContentTypes,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.encodings = append(o.encodings, encoding{name: "gzip", new: gzipEncoder(o.level)})
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			cw := &responseWriter{
+				ResponseWriter: w,
+				opts:           &o,
+				enc:            o.negotiate(req.Header.Get("Accept-Encoding")),
+				status:         http.StatusOK,
+			}
+			defer cw.Close()
+			next.ServeHTTP(cw, req)
+		})
+	}
+}
+
+// gzipEncoder returns an Encoder reusing pooled gzip writers.
+func gzipEncoder(level int) Encoder {
+	pool := &sync.Pool{New: func() any {
+		gw, err := gzip.NewWriterLevel(io.Discard, level)
+		if err != nil {
+			gw = gzip.NewWriter(io.Discard)
+		}
+		return gw
+	}}
+	return func(w io.Writer) io.WriteCloser {
+		gw := pool.Get().(*gzip.Writer)
+		gw.Reset(w)
+		return &gzipWriter{Writer: gw, pool: pool}
+	}
+}
+
+type gzipWriter struct {
+	*gzip.Writer
+	pool *sync.Pool
+}
+
+func (w *gzipWriter) Close() error {
+	err := w.Writer.Close()
+	w.pool.Put(w.Writer)
+	return err
+}
+
+// negotiate returns the preferred encoding accepted by the client.
+func (o *options) negotiate(accept string) *encoding {
+	if accept == "" {
+		return nil
+	}
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q > 0
+	}
+	for i, enc := range o.encodings {
+		ok, exists := accepted[enc.name]
+		if !exists {
+			ok = accepted["*"]
+		}
+		if ok {
+			return &o.encodings[i]
+		}
+	}
+	return nil
+}
+
+func (o *options) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range o.contentTypes {
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	opts *options
+	enc  *encoding
+
+	status      int
+	wroteHeader bool
+	decided     bool
+	buf         []byte
+	w           io.WriteCloser
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		// responses without body are written through
+		w.decided = true
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.w != nil {
+			return w.w.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.opts.minSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// decide writes the header, compressing the response if it is eligible.
+func (w *responseWriter) decide() error {
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	eligible := h.Get("Content-Encoding") == "" && w.status != http.StatusPartialContent && w.opts.allowed(h.Get("Content-Type"))
+	if eligible {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	if eligible && w.enc != nil && len(w.buf) >= w.opts.minSize {
+		h.Set("Content-Encoding", w.enc.name)
+		h.Del("Content-Length")
+		w.w = w.enc.new(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.w != nil {
+		_, err = w.w.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// Close flushes the buffered response and the encoder.
+func (w *responseWriter) Close() error {
+	if !w.decided {
+		if !w.wroteHeader {
+			// empty response, leave the defaults of the server untouched
+			return nil
+		}
+		if err := w.decide(); err != nil {
+			return err
+		}
+	}
+	if w.w == nil {
+		return nil
+	}
+	err := w.w.Close()
+	w.w = nil
+	return err
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	if !w.decided && w.wroteHeader {
+		_ = w.decide()
+	}
+	if f, ok := w.w.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("compress: response writer does not implement http.Hijacker")
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,125 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serve(h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func jsonHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+func largeJSON() string {
+	return `{"items":[` + strings.Repeat(`{"name":"kratos","url":"https://go-kratos.dev"},`, 100) + `{}]}`
+}
+
+func TestCompressLarge(t *testing.T) {
+	body := largeJSON()
+	w := serve(Filter()(jsonHandler(body)), "br;q=1.0, gzip;q=0.8")
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("expected Vary Accept-Encoding, got %q", got)
+	}
+	if w.Body.Len() >= len(body) {
+		t.Errorf("expected compressed body smaller than %d, got %d", len(body), w.Body.Len())
+	}
+	r, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != body {
+		t.Errorf("unexpected decompressed body %s", data)
+	}
+}
+
+func TestCompressSmall(t *testing.T) {
+	w := serve(Filter()(jsonHandler(`{"name":"kratos"}`)), "gzip")
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("expected no encoding, got %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("expected Vary Accept-Encoding, got %q", got)
+	}
+	if got := w.Body.String(); got != `{"name":"kratos"}` {
+		t.Errorf("unexpected body %s", got)
+	}
+}
+
+func TestCompressNotAccepted(t *testing.T) {
+	body := largeJSON()
+	for _, accept := range []string{"", "identity", "gzip;q=0"} {
+		w := serve(Filter()(jsonHandler(body)), accept)
+		if got := w.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("%q: expected no encoding, got %q", accept, got)
+		}
+		if w.Body.String() != body {
+			t.Errorf("%q: unexpected body", accept)
+		}
+	}
+}
+
+func TestCompressSkipped(t *testing.T) {
+	data := bytes.Repeat([]byte{0x1f, 0x8b, 0x08}, 1024)
+	tests := []struct {
+		name string
+		h    http.HandlerFunc
+	}{
+		{"compressed type", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/zip")
+			_, _ = w.Write(data)
+		}},
+		{"already encoded", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(data)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(Filter()(tt.h), "gzip")
+			if got := w.Header().Get("Vary"); got != "" {
+				t.Errorf("expected no Vary, got %q", got)
+			}
+			if !bytes.Equal(w.Body.Bytes(), data) {
+				t.Error("expected body untouched")
+			}
+		})
+	}
+}
+
+func TestCompressEncoder(t *testing.T) {
+	var used bool
+	enc := func(w io.Writer) io.WriteCloser {
+		used = true
+		gw, _ := gzip.NewWriterLevel(w, gzip.BestSpeed)
+		return gw
+	}
+	w := serve(Filter(WithEncoder("x-test", enc), WithMinSize(1))(jsonHandler(`{}`)), "gzip, x-test")
+	if got := w.Header().Get("Content-Encoding"); got != "x-test" || !used {
+		t.Errorf("expected x-test encoding, got %q", got)
+	}
+}
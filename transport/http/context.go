@@ -42,6 +42,20 @@ type Context interface {
 	Reset(http.ResponseWriter, *http.Request)
 }
 
+// BindRequest binds the body, query and path params of the request of ctx
+// into v. Struct fields select their source with the path, query and body
+// tags of the binding package; proto messages use their field mappings. On
+// conflict path params win over query params, which win over the body.
+func BindRequest(ctx Context, v any) error {
+	if err := ctx.Bind(binding.BodyTarget(v)); err != nil {
+		return err
+	}
+	if err := binding.BindValues(ctx.Query(), binding.QueryTag, v); err != nil {
+		return err
+	}
+	return binding.BindValues(ctx.Vars(), binding.PathTag, v)
+}
+
 type responseWriter struct {
 	code int
 	w    http.ResponseWriter
@@ -92,6 +106,7 @@ func (c *wrapper) Query() url.Values {
 func (c *wrapper) Request() *http.Request        { return c.req }
 func (c *wrapper) Response() http.ResponseWriter { return c.res }
 func (c *wrapper) Middleware(h middleware.Handler) middleware.Handler {
+	h = middleware.Chain(c.router.middleware...)(h)
 	if tr, ok := transport.FromServerContext(c.req.Context()); ok {
 		return middleware.Chain(c.router.srv.middleware.Match(tr.Operation())...)(h)
 	}
@@ -101,6 +116,7 @@ func (c *wrapper) Bind(v any) error      { return c.router.srv.decBody(c.req, v)
 func (c *wrapper) BindVars(v any) error  { return c.router.srv.decVars(c.req, v) }
 func (c *wrapper) BindQuery(v any) error { return c.router.srv.decQuery(c.req, v) }
 func (c *wrapper) BindForm(v any) error  { return binding.BindForm(c.req, v) }
+
 func (c *wrapper) Returns(v any, err error) error {
 	if err != nil {
 		return err
@@ -10,6 +10,10 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/go-kratos/kratos/v2/internal/testdata/binding"
 )
 
 var testRouter = &Router{srv: NewServer()}
@@ -249,3 +253,55 @@ func TestContextCtx(t *testing.T) {
 		t.Errorf("expected %v, got %v", nil, v)
 	}
 }
+
+func TestContextBindRequest(t *testing.T) {
+	type Patch struct {
+		ID     string `path:"id"`
+		Notify bool   `query:"notify"`
+		Name   string `json:"name" query:"name" path:"name"`
+		Age    int    `json:"age"`
+	}
+	for _, tt := range []struct {
+		name string
+		vars map[string]string
+		want Patch
+	}{
+		{
+			name: "mixed",
+			vars: map[string]string{"id": "1"},
+			want: Patch{ID: "1", Notify: true, Name: "query", Age: 18},
+		},
+		{
+			name: "path wins",
+			vars: map[string]string{"id": "1", "name": "path"},
+			want: Patch{ID: "1", Notify: true, Name: "path", Age: 18},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/users/1?notify=true&name=query", bytes.NewBufferString(`{"name":"body","age":18}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := &wrapper{router: testRouter, req: mux.SetURLVars(req, tt.vars)}
+			var p Patch
+			if err := BindRequest(w, &p); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, p)
+			}
+		})
+	}
+}
+
+func TestContextBindRequestProto(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPatch, "/hello/path?sub.naming=query&name=query",
+		bytes.NewBufferString(`{"name":"body","sub":{"naming":"body"},"optString":"body"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := &wrapper{router: testRouter, req: mux.SetURLVars(req, map[string]string{"name": "path"})}
+	var in binding.HelloRequest
+	if err := BindRequest(w, &in); err != nil {
+		t.Fatal(err)
+	}
+	if in.Name != "path" || in.GetSub().GetName() != "query" || in.GetOptString() != "body" {
+		t.Errorf("unexpected message %v", &in)
+	}
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CORSOption is a CORS option.
+type CORSOption func(*cors)
+
+// CORSOrigins with the origins allowed to make cross-origin requests,
+// "*" allows any origin. Default is none. The origins allowed only by "*"
+// are answered with a literal "*" and never with credentials, which would
+// let any website make credentialed reads.
+func CORSOrigins(origins ...string) CORSOption {
+	return func(c *cors) {
+		c.origins = origins
+	}
+}
+
+// CORSMethods with the methods allowed for cross-origin requests. The methods
+// answered to a preflight are further limited to the ones registered for the
+// requested route. Default is GET, HEAD, POST, PUT, PATCH and DELETE.
+func CORSMethods(methods ...string) CORSOption {
+	return func(c *cors) {
+		c.methods = methods
+	}
+}
+
+// CORSHeaders with the request headers allowed for cross-origin requests,
+// "*" allows any header. Default is Content-Type.
+func CORSHeaders(headers ...string) CORSOption {
+	return func(c *cors) {
+		c.headers = headers
+	}
+}
+
+// CORSExposedHeaders with the response headers exposed to the client.
+func CORSExposedHeaders(headers ...string) CORSOption {
+	return func(c *cors) {
+		c.exposed = headers
+	}
+}
+
+// CORSCredentials allows cross-origin requests to carry credentials.
+func CORSCredentials() CORSOption {
+	return func(c *cors) {
+		c.credentials = true
+	}
+}
+
+// CORSMaxAge with how long the result of a preflight can be cached.
+func CORSMaxAge(d time.Duration) CORSOption {
+	return func(c *cors) {
+		c.maxAge = d
+	}
+}
+
+// CORS with cross-origin resource sharing handling. Preflight OPTIONS
+// requests of registered routes are answered automatically, and the CORS
+// response headers are added to the cross-origin requests of allowed origins.
+func CORS(opts ...CORSOption) ServerOption {
+	return func(s *Server) {
+		c := &cors{
+			methods: []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+			headers: []string{"Content-Type"},
+		}
+		for _, o := range opts {
+			o(c)
+		}
+		s.cors = c
+	}
+}
+
+type cors struct {
+	origins     []string
+	methods     []string
+	headers     []string
+	exposed     []string
+	credentials bool
+	maxAge      time.Duration
+}
+
+// allowOrigin returns the value of Access-Control-Allow-Origin for origin,
+// empty when it is not allowed.
+func (c *cors) allowOrigin(origin string) string {
+	wildcard := false
+	for _, o := range c.origins {
+		if o == "*" {
+			wildcard = true
+		} else if strings.EqualFold(o, origin) {
+			return origin
+		}
+	}
+	if wildcard {
+		return "*"
+	}
+	return ""
+}
+
+func (c *cors) allowHeaders(headers []string) bool {
+	for _, h := range headers {
+		if !containsFold(c.headers, h) && !containsFold(c.headers, "*") {
+			return false
+		}
+	}
+	return true
+}
+
+// routeMethods returns the allowed methods registered for the path of req.
+func (c *cors) routeMethods(router *mux.Router, req *http.Request) []string {
+	var methods []string
+	for _, method := range c.methods {
+		r := req.Clone(req.Context())
+		r.Method = method
+		var match mux.RouteMatch
+		if router.Match(r, &match) && match.MatchErr == nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+func (c *cors) setHeaders(h http.Header, allowed string) {
+	h.Add("Vary", "Origin")
+	h.Set("Access-Control-Allow-Origin", allowed)
+	if c.credentials && allowed != "*" {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (s *Server) handleCORS(next http.Handler) http.Handler {
+	if s.cors == nil {
+		return next
+	}
+	c := s.cors
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+		reqMethod := req.Header.Get("Access-Control-Request-Method")
+		if req.Method != http.MethodOptions || reqMethod == "" {
+			// actual request
+			if allowed := c.allowOrigin(origin); allowed != "" {
+				c.setHeaders(w.Header(), allowed)
+				if len(c.exposed) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.exposed, ", "))
+				}
+			}
+			next.ServeHTTP(w, req)
+			return
+		}
+		// preflight request
+		methods := c.routeMethods(s.router, req)
+		if len(methods) == 0 {
+			next.ServeHTTP(w, req)
+			return
+		}
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		reqHeaders := splitHeader(req.Header.Get("Access-Control-Request-Headers"))
+		allowed := c.allowOrigin(origin)
+		if allowed == "" || !containsFold(methods, reqMethod) || !c.allowHeaders(reqHeaders) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		c.setHeaders(w.Header(), allowed)
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		if len(reqHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(reqHeaders, ", "))
+		}
+		if c.maxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.maxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func splitHeader(v string) []string {
+	var headers []string
+	for _, h := range strings.Split(v, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+func containsFold(ss []string, s string) bool {
+	for _, v := range ss {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
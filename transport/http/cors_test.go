@@ -0,0 +1,144 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCORSServer() *Server {
+	srv := NewServer(CORS(
+		CORSOrigins("https://app.example.com"),
+		CORSHeaders("Content-Type", "Authorization"),
+		CORSExposedHeaders("X-Request-Id"),
+		CORSCredentials(),
+		CORSMaxAge(time.Minute),
+	))
+	r := srv.Route("/")
+	r.GET("/users/{id}", func(ctx Context) error { return ctx.String(http.StatusOK, "get") })
+	r.PATCH("/users/{id}", func(ctx Context) error { return ctx.String(http.StatusOK, "patch") })
+	return srv
+}
+
+func preflight(origin, method, headers string) *http.Request {
+	req := httptest.NewRequest(http.MethodOptions, "/users/1", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	return req
+}
+
+func TestCORSPreflight(t *testing.T) {
+	srv := newCORSServer()
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, preflight("https://app.example.com", http.MethodPatch, "content-type, authorization"))
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected %d got %d", http.StatusNoContent, w.Code)
+	}
+	for k, v := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Methods":     "GET, PATCH",
+		"Access-Control-Allow-Headers":     "content-type, authorization",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "60",
+	} {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("%s: expected %q got %q", k, v, got)
+		}
+	}
+}
+
+func TestCORSPreflightDenied(t *testing.T) {
+	srv := newCORSServer()
+	tests := []struct {
+		name string
+		req  *http.Request
+		code int
+	}{
+		{"origin", preflight("https://evil.example.com", http.MethodGet, ""), http.StatusForbidden},
+		{"method", preflight("https://app.example.com", http.MethodDelete, ""), http.StatusForbidden},
+		{"header", preflight("https://app.example.com", http.MethodGet, "X-Custom"), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(w, tt.req)
+			if w.Code != tt.code {
+				t.Errorf("expected %d got %d", tt.code, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Errorf("expected no allow origin got %q", got)
+			}
+		})
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	srv := newCORSServer()
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "get" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("expected allow origin got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Request-Id" {
+		t.Errorf("expected expose headers got %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("expected vary Origin got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d", http.StatusOK, w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no allow origin got %q", got)
+	}
+}
+
+func TestCORSWildcardCredentials(t *testing.T) {
+	srv := NewServer(CORS(
+		CORSOrigins("https://app.example.com", "*"),
+		CORSCredentials(),
+	))
+	srv.Route("/").GET("/users/{id}", func(ctx Context) error { return ctx.String(http.StatusOK, "get") })
+
+	tests := []struct {
+		origin      string
+		allowed     string
+		credentials string
+	}{
+		{"https://app.example.com", "https://app.example.com", "true"},
+		// the origins allowed only by the wildcard get no credentials
+		{"https://evil.example.com", "*", ""},
+	}
+	for _, tt := range tests {
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/users/1", nil),
+			preflight(tt.origin, http.MethodGet, ""),
+		} {
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(w, req)
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowed {
+				t.Errorf("%s %s: expected allow origin %q got %q", req.Method, tt.origin, tt.allowed, got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.credentials {
+				t.Errorf("%s %s: expected allow credentials %q got %q", req.Method, tt.origin, tt.credentials, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/dump"
+)
+
+// Dump enables the dump mode of the server, the raw request and response
+// bodies are logged by d at debug level. It is disabled by default.
+func Dump(d *dump.Dumper) ServerOption {
+	return func(s *Server) {
+		s.dumper = d
+	}
+}
+
+// WithDump enables the dump mode of the client, the raw request and response
+// bodies are logged by d at debug level. It is disabled by default.
+func WithDump(d *dump.Dumper) ClientOption {
+	return func(o *clientOptions) {
+		o.dumper = d
+	}
+}
+
+// dumpWriter records the response body written through it.
+type dumpWriter struct {
+	http.ResponseWriter
+	body *dump.Capture
+}
+
+func (w *dumpWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	_, _ = w.body.Write(p[:n])
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *dumpWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *dumpWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// recordBody replaces the body of req by a reader recording the bytes read.
+func recordBody(d *dump.Dumper, req *http.Request) *dump.Capture {
+	c := d.Capture()
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(req.Body, c), req.Body}
+	}
+	return c
+}
+
+// serveDump serves the request of tr, dumping the bodies once the handler
+// returned so that the operation set by the handler is reported.
+func serveDump(d *dump.Dumper, next http.Handler, w http.ResponseWriter, tr *Transport) {
+	reqBody := recordBody(d, tr.request)
+	dw := &dumpWriter{ResponseWriter: w, body: d.Capture()}
+	tr.response = dw
+	next.ServeHTTP(dw, tr.request)
+	d.CapturedBody("server", "http", tr.operation, dump.Request, tr.request.Header.Get("Content-Type"), reqBody)
+	d.CapturedBody("server", "http", tr.operation, dump.Response, w.Header().Get("Content-Type"), dw.body)
+}
+
+func (client *Client) dumpRequest(req *http.Request) {
+	c := client.opts.dumper.Capture()
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			_, _ = io.Copy(c, body)
+			_ = body.Close()
+		}
+	}
+	client.opts.dumper.CapturedBody("client", "http", clientOperation(req), dump.Request, req.Header.Get("Content-Type"), c)
+}
+
+// dumpResponse replaces the body of res by a reader recording the bytes read
+// by the caller, which are dumped once it is consumed or closed.
+func (client *Client) dumpResponse(req *http.Request, res *http.Response) {
+	d := client.opts.dumper
+	c := d.Capture()
+	body := &dumpBody{ReadCloser: res.Body, capture: c}
+	body.done = func() {
+		d.CapturedBody("client", "http", clientOperation(req), dump.Response, res.Header.Get("Content-Type"), c)
+	}
+	res.Body = body
+}
+
+// dumpBody records the body read through it and dumps it once, at the end of
+// the body or when it is closed.
+type dumpBody struct {
+	io.ReadCloser
+	capture *dump.Capture
+	once    sync.Once
+	done    func()
+}
+
+func (b *dumpBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	_, _ = b.capture.Write(p[:n])
+	if err != nil {
+		b.once.Do(b.done)
+	}
+	return n, err
+}
+
+func (b *dumpBody) Close() error {
+	b.once.Do(b.done)
+	return b.ReadCloser.Close()
+}
+
+func clientOperation(req *http.Request) string {
+	if tr, ok := transport.FromClientContext(req.Context()); ok && tr.Operation() != "" {
+		return tr.Operation()
+	}
+	return req.URL.Path
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/dump"
+)
+
+type dumpLogger struct {
+	mu    sync.Mutex
+	dumps map[string]any
+}
+
+func (l *dumpLogger) Log(_ log.Level, keyvals ...any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	kv := make(map[any]any)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		kv[keyvals[i]] = keyvals[i+1]
+	}
+	if kv["msg"] == "dump" {
+		if l.dumps == nil {
+			l.dumps = make(map[string]any)
+		}
+		l.dumps[kv["kind"].(string)+" "+kv["direction"].(string)+" "+kv["operation"].(string)] = kv["data"]
+	}
+	return nil
+}
+
+func sayHelloHTTP(t *testing.T, srvOpts []ServerOption, clientOpts ...ClientOption) {
+	srv := NewServer(srvOpts...)
+	srv.Route("/").POST("/hello", func(ctx Context) error {
+		var in map[string]string
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, map[string]string{"message": "hello " + in["name"]})
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client, err := NewClient(context.Background(), append(clientOpts, WithEndpoint(ts.Listener.Addr().String()))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply map[string]string
+	if err = client.Invoke(context.Background(), http.MethodPost, "/hello", map[string]string{"name": "kratos"}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply["message"] != "hello kratos" {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+}
+
+func TestDump(t *testing.T) {
+	logger := &dumpLogger{}
+	d := dump.New(dump.WithLogger(logger))
+	sayHelloHTTP(t, []ServerOption{Dump(d)}, WithDump(d))
+
+	want := map[string]any{
+		"client request /hello":  `{"name":"kratos"}`,
+		"server request /hello":  `{"name":"kratos"}`,
+		"server response /hello": `{"message":"hello kratos"}`,
+		"client response /hello": `{"message":"hello kratos"}`,
+	}
+	if len(logger.dumps) != len(want) {
+		t.Fatalf("expected %d dumps, got %v", len(want), logger.dumps)
+	}
+	for k, v := range want {
+		if got := logger.dumps[k]; got != v {
+			t.Errorf("%s: expected %v, got %v", k, v, got)
+		}
+	}
+}
+
+func TestDumpDisabled(t *testing.T) {
+	logger := &dumpLogger{}
+	global := log.GetLogger()
+	log.SetLogger(logger)
+	defer log.SetLogger(global)
+	sayHelloHTTP(t, nil)
+
+	if len(logger.dumps) != 0 {
+		t.Errorf("expected no dump, got %v", logger.dumps)
+	}
+}
+
+// countingReader counts the bytes read from it.
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += n
+	return n, err
+}
+
+func (r *countingReader) Close() error { return nil }
+
+func TestDumpResponseStreamed(t *testing.T) {
+	logger := &dumpLogger{}
+	client := &Client{opts: clientOptions{dumper: dump.New(dump.WithLogger(logger))}}
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	body := &countingReader{r: strings.NewReader("data: 1\n\ndata: 2\n\n")}
+	res := &http.Response{Header: http.Header{"Content-Type": {"text/event-stream"}}, Body: body}
+
+	client.dumpResponse(req, res)
+	if body.read != 0 || len(logger.dumps) != 0 {
+		t.Fatalf("expected the body not read ahead of the caller, read %d", body.read)
+	}
+	buf := make([]byte, 9)
+	if _, err := io.ReadFull(res.Body, buf); err != nil || string(buf) != "data: 1\n\n" {
+		t.Fatalf("unexpected read %q %v", buf, err)
+	}
+	if body.read != 9 {
+		t.Errorf("expected only the bytes read by the caller, read %d", body.read)
+	}
+	_ = res.Body.Close()
+	if got := logger.dumps["client response /events"]; got != "data: 1\n\n" {
+		t.Errorf("expected the bytes read dumped on close, got %v", got)
+	}
+}
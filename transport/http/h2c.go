@@ -0,0 +1,31 @@
+package http
+
+import (
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// H2C with HTTP/2 over cleartext TCP, e.g. for the proxies and gRPC-style
+// clients speaking h2c to their upstreams. Both the prior knowledge and the
+// "Upgrade: h2c" connections are served over HTTP/2, the HTTP/1.1 clients are
+// served on the same listener as before. It has no effect with TLSConfig,
+// where HTTP/2 is negotiated with ALPN.
+func H2C(enabled bool) ServerOption {
+	return func(o *Server) {
+		o.enableH2C = enabled
+	}
+}
+
+// serveH2C wraps the handler of the server with h2c. The HTTP/2 server is
+// configured on the http.Server, so that Shutdown sends a GOAWAY to the h2c
+// connections and lets their in-flight streams complete.
+func (s *Server) serveH2C() {
+	h2s := &http2.Server{IdleTimeout: s.Server.IdleTimeout}
+	if err := http2.ConfigureServer(s.Server, h2s); err != nil {
+		log.Errorf("http: failed to configure h2c: %v", err)
+		return
+	}
+	s.Server.Handler = h2c.NewHandler(s.Server.Handler, h2s)
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+func testH2CServer(t *testing.T, opts ...ServerOption) string {
+	srv := NewServer(opts...)
+	srv.HandleFunc("/proto", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	t.Cleanup(func() { _ = srv.Stop(context.Background()) })
+	time.Sleep(100 * time.Millisecond)
+	return "http://" + e.Host + "/proto"
+}
+
+func h2cClient() *http.Client {
+	return &http.Client{
+		Timeout: time.Second,
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+}
+
+func getProto(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestH2C(t *testing.T) {
+	url := testH2CServer(t, H2C(true))
+	proto, err := getProto(h2cClient(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proto != "HTTP/2.0" {
+		t.Errorf("expected HTTP/2.0 for the h2c client, got %s", proto)
+	}
+	// the HTTP/1.1 clients are still served on the same server
+	proto, err = getProto(&http.Client{Timeout: time.Second}, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proto != "HTTP/1.1" {
+		t.Errorf("expected HTTP/1.1 for the plain client, got %s", proto)
+	}
+}
+
+func TestH2CDisabled(t *testing.T) {
+	url := testH2CServer(t)
+	if _, err := getProto(h2cClient(), url); err == nil {
+		t.Error("expected the h2c client rejected without H2C")
+	}
+	proto, err := getProto(&http.Client{Timeout: time.Second}, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proto != "HTTP/1.1" {
+		t.Errorf("expected HTTP/1.1, got %s", proto)
+	}
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+// ErrResponseTooLarge is returned when a response exceeds the maximum size.
+var ErrResponseTooLarge = kratoserrors.New(http.StatusBadGateway, "RESPONSE_TOO_LARGE", "response exceeds the maximum size")
+
+// WithMaxResponseSize limits the body of the responses to the calls whose
+// operation matches one of the selectors to size bytes, or of every call
+// without a more specific limit when there is none. A response announcing
+// a larger Content-Length is rejected before its body is read, otherwise
+// reading the body fails once it exceeds size. Responses are not limited
+// by default.
+// selector:
+//   - '/*'
+//   - '/helloworld.v1.Greeter/*'
+//   - '/helloworld.v1.Greeter/SayHello'
+func WithMaxResponseSize(size int64, selectors ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.maxResponseSize.Add(size, selectors...)
+	}
+}
+
+// limitResponse limits the body of res to the maximum size of the call.
+func (client *Client) limitResponse(req *http.Request, res *http.Response) error {
+	size := client.opts.maxResponseSize.Match(clientOperation(req))
+	if size <= 0 {
+		return nil
+	}
+	if res.ContentLength > size {
+		_ = res.Body.Close()
+		return ErrResponseTooLarge
+	}
+	res.Body = &limitedBody{ReadCloser: res.Body, remaining: size}
+	return nil
+}
+
+// limitedBody fails the reads beyond remaining bytes with ErrResponseTooLarge.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	// read one byte more than the limit to tell an exact fit from an excess
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if b.remaining -= int64(n); b.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	large := `{"name":"` + strings.Repeat("x", 100) + `"}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/small":
+			_, _ = w.Write([]byte(`{"name":"kratos"}`))
+		case "/large", "/big/large":
+			_, _ = w.Write([]byte(large))
+		case "/stream":
+			// chunked, without a Content-Length
+			for _, c := range []string{`{"name":"`, strings.Repeat("x", 100), `"}`} {
+				_, _ = w.Write([]byte(c))
+				w.(http.Flusher).Flush()
+			}
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(large))
+		}
+	}))
+	defer ts.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(ts.URL, "http://")),
+		WithMaxResponseSize(64),
+		WithMaxResponseSize(1024, "/big/*"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		err  error
+	}{
+		{"/small", nil},
+		{"/large", ErrResponseTooLarge},
+		{"/stream", ErrResponseTooLarge},
+		{"/error", ErrResponseTooLarge},
+		{"/big/large", nil},
+	}
+	for _, tt := range tests {
+		var reply User
+		err := client.Invoke(context.Background(), http.MethodGet, tt.path, nil, &reply)
+		if tt.err == nil && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.path, err)
+		}
+		if tt.err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.err, err)
+		}
+	}
+}
+
+func TestLimitedBody(t *testing.T) {
+	for _, tt := range []struct {
+		body string
+		err  error
+	}{
+		{"", nil},
+		{"hello", nil},
+		{"hello!", ErrResponseTooLarge},
+	} {
+		body := &limitedBody{ReadCloser: io.NopCloser(strings.NewReader(tt.body)), remaining: 5}
+		data, err := io.ReadAll(body)
+		if err != tt.err {
+			t.Errorf("%q: expected %v, got %v", tt.body, tt.err, err)
+		}
+		if err == nil && string(data) != tt.body {
+			t.Errorf("expected %q, got %q", tt.body, data)
+		}
+	}
+}
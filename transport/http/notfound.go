@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var (
+	// ErrRouteNotFound is written for the requests to an unregistered path.
+	ErrRouteNotFound = kratoserrors.NotFound("ROUTE_NOT_FOUND", "route not found")
+	// ErrMethodNotAllowed is written for the requests to a registered path
+	// with a method it does not serve.
+	ErrMethodNotAllowed = kratoserrors.New(http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+)
+
+const (
+	// OperationNotFound is the operation of the requests to an unregistered path.
+	OperationNotFound = "NotFound"
+	// OperationMethodNotAllowed is the operation of the requests to a
+	// registered path with a method it does not serve.
+	OperationMethodNotAllowed = "MethodNotAllowed"
+)
+
+// RouteErrorHandler answers the requests to unregistered paths and methods
+// with ErrRouteNotFound and ErrMethodNotAllowed, written by the error
+// encoder in the content type negotiated from the Accept header. Only the
+// middleware m runs for them, e.g. logging and metrics, with the operation
+// OperationNotFound or OperationMethodNotAllowed rather than the request
+// path, which would be unbounded. It replaces the NotFoundHandler and
+// MethodNotAllowedHandler, which default to http.DefaultServeMux.
+func RouteErrorHandler(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.router.NotFoundHandler = s.routeError(OperationNotFound, ErrRouteNotFound, m)
+		s.router.MethodNotAllowedHandler = s.routeError(OperationMethodNotAllowed, ErrMethodNotAllowed, m)
+	}
+}
+
+func (s *Server) routeError(operation string, routeErr error, m []middleware.Middleware) http.Handler {
+	h := func(context.Context, any) (any, error) {
+		return nil, routeErr
+	}
+	if len(m) > 0 {
+		h = middleware.Chain(m...)(h)
+	}
+	// the router runs its middleware only for the matched routes
+	return s.filter()(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if tr, ok := transport.FromServerContext(req.Context()); ok {
+			if tr, ok := tr.(*Transport); ok {
+				tr.operation, tr.pathTemplate = operation, operation
+			}
+		}
+		err := routeErr
+		if _, merr := h(req.Context(), req); merr != nil {
+			err = merr
+		}
+		s.ene(w, req, err)
+	}))
+}
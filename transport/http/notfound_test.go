@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestRouteErrorHandler(t *testing.T) {
+	operations := make(chan string, 1)
+	logging := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operations <- tr.Operation()
+			}
+			return handler(ctx, req)
+		}
+	}
+	srv := NewServer(RouteErrorHandler(logging))
+	srv.Route("/").GET("/users", func(ctx Context) error {
+		return ctx.Result(http.StatusOK, &User{Name: "kratos"})
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	tests := []struct {
+		method      string
+		path        string
+		accept      string
+		contentType string
+		want        *kratoserrors.Error
+		operation   string
+	}{
+		{http.MethodGet, "/unknown", "", appJSONStr, ErrRouteNotFound, OperationNotFound},
+		{http.MethodGet, "/unknown/1", "application/proto", "application/proto", ErrRouteNotFound, OperationNotFound},
+		{http.MethodPost, "/users", "", appJSONStr, ErrMethodNotAllowed, OperationMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, ts.URL+tt.path, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != int(tt.want.Code) {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.want.Code, res.StatusCode)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s %s: expected content type %s, got %s", tt.method, tt.path, tt.contentType, ct)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		e := new(kratoserrors.Error)
+		if err = CodecForResponse(res).Unmarshal(body, e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Reason != tt.want.Reason || e.Code != tt.want.Code {
+			t.Errorf("%s %s: expected %v, got %v", tt.method, tt.path, tt.want, e)
+		}
+		if op := <-operations; op != tt.operation {
+			t.Errorf("%s %s: expected the middleware to run with operation %s, got %s", tt.method, tt.path, tt.operation, op)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package http
 import (
 	"net/http"
 	"path"
+
+	"github.com/go-kratos/kratos/v2/middleware"
 )
 
 // WalkRouteFunc is the type of the function called for each route visited by Walk.
@@ -19,9 +21,10 @@ type HandlerFunc func(Context) error
 
 // Router is an HTTP router.
 type Router struct {
-	prefix  string
-	srv     *Server
-	filters []FilterFunc
+	prefix     string
+	srv        *Server
+	filters    []FilterFunc
+	middleware []middleware.Middleware
 }
 
 func newRouter(prefix string, srv *Server, filters ...FilterFunc) *Router {
@@ -38,7 +41,19 @@ func (r *Router) Group(prefix string, filters ...FilterFunc) *Router {
 	var newFilters []FilterFunc
 	newFilters = append(newFilters, r.filters...)
 	newFilters = append(newFilters, filters...)
-	return newRouter(path.Join(r.prefix, prefix), r.srv, newFilters...)
+	nr := newRouter(path.Join(r.prefix, prefix), r.srv, newFilters...)
+	nr.middleware = r.middleware
+	return nr
+}
+
+// With returns a copy of the router whose routes also run the middleware m,
+// e.g. r.With(auth).GET("/admin", h). The route-scoped middleware runs after
+// the server middleware matched by operation, right before the handler, when
+// the handler chains them with Context.Middleware.
+func (r *Router) With(m ...middleware.Middleware) *Router {
+	nr := newRouter(r.prefix, r.srv, r.filters...)
+	nr.middleware = append(append([]middleware.Middleware(nil), r.middleware...), m...)
+	return nr
 }
 
 // Handle registers a new route with a matcher for the URL path and method.
@@ -52,7 +67,11 @@ func (r *Router) Handle(method, relativePath string, h HandlerFunc, filters ...F
 	}))
 	next = FilterChain(filters...)(next)
 	next = FilterChain(r.filters...)(next)
-	r.srv.router.Handle(path.Join(r.prefix, relativePath), next).Methods(method)
+	methods := []string{method}
+	if method == http.MethodGet && r.srv.headForGet {
+		methods = append(methods, http.MethodHead)
+	}
+	r.srv.router.Handle(path.Join(r.prefix, relativePath), next).Methods(methods...)
 }
 
 // GET registers a new GET route for a path with matching handler in the router.
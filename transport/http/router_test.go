@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"runtime"
 	"strings"
@@ -15,6 +17,7 @@ import (
 	"time"
 
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/middleware"
 )
 
 const appJSONStr = "application/json"
@@ -248,3 +251,91 @@ func TestRouter_ContextDataRace(t *testing.T) {
 	_ = srv.Stop(ctx)
 	t.Log("test end")
 }
+
+func TestRouter_With(t *testing.T) {
+	var calls []string
+	record := func(name string) middleware.Middleware {
+		return func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req any) (any, error) {
+				calls = append(calls, name)
+				return handler(ctx, req)
+			}
+		}
+	}
+	srv := NewServer(Middleware(record("global")))
+	handler := func(ctx Context) error {
+		h := ctx.Middleware(func(context.Context, any) (any, error) {
+			calls = append(calls, "handler")
+			return &User{Name: "foo"}, nil
+		})
+		return ctx.Returns(h(ctx, nil))
+	}
+	route := srv.Route("/v1")
+	route.With(record("route")).GET("/admin", handler)
+	route.GET("/users", handler)
+	route.With(record("group")).Group("/inner").With(record("inner")).GET("/users", handler)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/v1/admin", []string{"global", "route", "handler"}},
+		{"/v1/users", []string{"global", "handler"}},
+		{"/v1/inner/users", []string{"global", "group", "inner", "handler"}},
+	}
+	for _, tt := range tests {
+		calls = nil
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: code %d", tt.path, w.Code)
+		}
+		if !reflect.DeepEqual(calls, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, calls)
+		}
+	}
+}
+
+func TestRouter_HeadForGet(t *testing.T) {
+	handler := func(ctx Context) error {
+		ctx.Response().Header().Set("X-Version", "v1")
+		return ctx.Result(http.StatusOK, &User{Name: "foo"})
+	}
+	for _, tt := range []struct {
+		opts []ServerOption
+		ok   bool
+	}{
+		{nil, false},
+		{[]ServerOption{HeadForGet()}, true},
+	} {
+		srv := NewServer(tt.opts...)
+		srv.Route("/v1").GET("/users", handler)
+		ts := httptest.NewServer(srv)
+
+		res, err := http.Head(ts.URL + "/v1/users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		ts.Close()
+		if (res.StatusCode == http.StatusOK) != tt.ok {
+			t.Fatalf("expected served %v, got code %d", tt.ok, res.StatusCode)
+		}
+		if !tt.ok {
+			continue
+		}
+		if len(body) != 0 {
+			t.Errorf("expected no body, got %q", body)
+		}
+		if v := res.Header.Get("X-Version"); v != "v1" {
+			t.Errorf("expected header v1, got %q", v)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != appJSONStr {
+			t.Errorf("expected content type %s, got %q", appJSONStr, ct)
+		}
+		if res.ContentLength != int64(len(`{"name":"foo"}`)) {
+			t.Errorf("expected content length %d, got %d", len(`{"name":"foo"}`), res.ContentLength)
+		}
+	}
+}
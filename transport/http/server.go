@@ -7,24 +7,34 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
 
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	ic "github.com/go-kratos/kratos/v2/internal/context"
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/go-kratos/kratos/v2/internal/matcher"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/dump"
 )
 
 var (
 	_ transport.Server     = (*Server)(nil)
 	_ transport.Endpointer = (*Server)(nil)
+	_ transport.Readier    = (*Server)(nil)
 	_ http.Handler         = (*Server)(nil)
 )
 
+var errNotServing = errors.New("http: server is not serving")
+
+// ErrURLTooLong is returned when the request URI exceeds the MaxURLLength limit.
+var ErrURLTooLong = kratoserrors.New(http.StatusRequestURITooLong, "URL_TOO_LONG", "request URL is too long")
+
 // ServerOption is an HTTP server option.
 type ServerOption func(*Server)
 
@@ -141,6 +151,33 @@ func PathPrefix(prefix string) ServerOption {
 	}
 }
 
+// MaxHeaderBytes with the maximum number of bytes the server will read parsing
+// the request header, including the request line. Oversized requests are
+// rejected with 431. Default is http.DefaultMaxHeaderBytes.
+func MaxHeaderBytes(n int) ServerOption {
+	return func(s *Server) {
+		s.maxHeaderBytes = n
+	}
+}
+
+// MaxURLLength with the maximum length of the request URI.
+// Longer requests are rejected with 414 before routing. Default is unlimited.
+func MaxURLLength(n int) ServerOption {
+	return func(s *Server) {
+		s.maxURLLength = n
+	}
+}
+
+// HeadForGet with serving HEAD requests by the GET routes registered with
+// Router. The handler runs as for GET, the headers including Content-Length
+// are preserved and the body is discarded. A HEAD route registered
+// explicitly for the same path is shadowed by the GET route.
+func HeadForGet() ServerOption {
+	return func(s *Server) {
+		s.headForGet = true
+	}
+}
+
 func NotFoundHandler(handler http.Handler) ServerOption {
 	return func(s *Server) {
 		s.router.NotFoundHandler = handler
@@ -171,7 +208,17 @@ type Server struct {
 	enc         EncodeResponseFunc
 	ene         EncodeErrorFunc
 	strictSlash bool
+	headForGet  bool
 	router      *mux.Router
+
+	maxHeaderBytes int
+	maxURLLength   int
+	maxBufferBody  int64
+	enableH2C      bool
+	handlerTimeout time.Duration
+	cors           *cors
+	dumper         *dump.Dumper
+	serving        atomic.Bool
 }
 
 // NewServer creates an HTTP server by options.
@@ -195,10 +242,14 @@ func NewServer(opts ...ServerOption) *Server {
 		o(srv)
 	}
 	srv.router.StrictSlash(srv.strictSlash)
-	srv.router.Use(srv.filter())
+	srv.router.Use(srv.timeoutFilter, srv.filter())
 	srv.Server = &http.Server{
-		Handler:   FilterChain(srv.filters...)(srv.router),
-		TLSConfig: srv.tlsConf,
+		Handler:        srv.limitURL(srv.bufferBody(srv.handleCORS(FilterChain(srv.filters...)(srv.router)))),
+		TLSConfig:      srv.tlsConf,
+		MaxHeaderBytes: srv.maxHeaderBytes,
+	}
+	if srv.enableH2C && srv.tlsConf == nil {
+		srv.serveH2C()
 	}
 	return srv
 }
@@ -270,6 +321,20 @@ func (s *Server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	s.Handler.ServeHTTP(res, req)
 }
 
+// limitURL rejects requests whose URI exceeds maxURLLength.
+func (s *Server) limitURL(next http.Handler) http.Handler {
+	if s.maxURLLength <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if len(req.RequestURI) > s.maxURLLength {
+			s.ene(w, req, ErrURLTooLong)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
 func (s *Server) filter() mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -277,10 +342,11 @@ func (s *Server) filter() mux.MiddlewareFunc {
 				ctx    context.Context
 				cancel context.CancelFunc
 			)
+			ctx = ic.WithRequestDeadline(req.Context())
 			if s.timeout > 0 {
-				ctx, cancel = context.WithTimeout(req.Context(), s.timeout)
+				ctx, cancel = context.WithTimeout(ctx, s.timeout)
 			} else {
-				ctx, cancel = context.WithCancel(req.Context())
+				ctx, cancel = context.WithCancel(ctx)
 			}
 			defer cancel()
 
@@ -302,6 +368,10 @@ func (s *Server) filter() mux.MiddlewareFunc {
 				tr.endpoint = s.endpoint.String()
 			}
 			tr.request = req.WithContext(transport.NewServerContext(ctx, tr))
+			if s.dumper != nil {
+				serveDump(s.dumper, next, w, tr)
+				return
+			}
 			next.ServeHTTP(w, tr.request)
 		})
 	}
@@ -328,6 +398,8 @@ func (s *Server) Start(ctx context.Context) error {
 		return ctx
 	}
 	log.Infof("[HTTP] server listening on: %s", s.lis.Addr().String())
+	s.serving.Store(true)
+	defer s.serving.Store(false)
 	var err error
 	if s.tlsConf != nil {
 		err = s.ServeTLS(s.lis, "", "")
@@ -343,6 +415,7 @@ func (s *Server) Start(ctx context.Context) error {
 // Stop stop the HTTP server.
 func (s *Server) Stop(ctx context.Context) error {
 	log.Info("[HTTP] server stopping")
+	s.serving.Store(false)
 	err := s.Shutdown(ctx)
 	if err != nil {
 		if ctx.Err() != nil {
@@ -353,6 +426,14 @@ func (s *Server) Stop(ctx context.Context) error {
 	return err
 }
 
+// Ready reports whether the HTTP server is serving requests.
+func (s *Server) Ready() error {
+	if !s.serving.Load() {
+		return errNotServing
+	}
+	return nil
+}
+
 func (s *Server) listenAndEndpoint() error {
 	if s.lis == nil {
 		lis, err := net.Listen(s.network, s.address)
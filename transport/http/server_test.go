@@ -485,3 +485,92 @@ func (b *safeBytesBuffer) String() string {
 	defer b.mu.Unlock()
 	return b.buf.String()
 }
+
+func TestMaxHeaderBytes(t *testing.T) {
+	o := &Server{}
+	v := 1024
+	MaxHeaderBytes(v)(o)
+	if !reflect.DeepEqual(v, o.maxHeaderBytes) {
+		t.Errorf("expected %v got %v", v, o.maxHeaderBytes)
+	}
+}
+
+func TestMaxURLLength(t *testing.T) {
+	o := &Server{}
+	v := 1024
+	MaxURLLength(v)(o)
+	if !reflect.DeepEqual(v, o.maxURLLength) {
+		t.Errorf("expected %v got %v", v, o.maxURLLength)
+	}
+}
+
+func TestServerLimits(t *testing.T) {
+	srv := NewServer(MaxHeaderBytes(1024), MaxURLLength(64))
+	srv.HandleFunc("/index", h)
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	time.Sleep(time.Second)
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		code   int
+	}{
+		{"normal", "/index", "", http.StatusOK},
+		{"oversized header", "/index", strings.Repeat("x", 64*1024), http.StatusRequestHeaderFieldsTooLarge},
+		{"over-long url", "/index?q=" + strings.Repeat("x", 64), "", http.StatusRequestURITooLong},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, e.String()+test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.header != "" {
+				req.Header.Set("X-Large", test.header)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			if res.StatusCode != test.code {
+				t.Errorf("expected status %d got %d", test.code, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestReady(t *testing.T) {
+	s := NewServer()
+	if err := s.Ready(); err == nil {
+		t.Fatal("expected not ready before start")
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Start(context.Background()) }()
+	deadline := time.Now().Add(time.Second)
+	for s.Ready() != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected ready after start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Ready(); err == nil {
+		t.Error("expected not ready after stop")
+	}
+}
@@ -43,6 +43,8 @@ func (c statusConverter) ToGRPCCode(code int) codes.Code {
 		return codes.NotFound
 	case http.StatusConflict:
 		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
 	case http.StatusTooManyRequests:
 		return codes.ResourceExhausted
 	case http.StatusInternalServerError:
@@ -84,7 +86,7 @@ func (c statusConverter) FromGRPCCode(code codes.Code) int {
 	case codes.ResourceExhausted:
 		return http.StatusTooManyRequests
 	case codes.FailedPrecondition:
-		return http.StatusBadRequest
+		return http.StatusPreconditionFailed
 	case codes.Aborted:
 		return http.StatusConflict
 	case codes.OutOfRange:
@@ -19,6 +19,7 @@ func TestToGRPCCode(t *testing.T) {
 		{"http.StatusForbidden", http.StatusForbidden, codes.PermissionDenied},
 		{"http.StatusNotFound", http.StatusNotFound, codes.NotFound},
 		{"http.StatusConflict", http.StatusConflict, codes.Aborted},
+		{"http.StatusPreconditionFailed", http.StatusPreconditionFailed, codes.FailedPrecondition},
 		{"http.StatusTooManyRequests", http.StatusTooManyRequests, codes.ResourceExhausted},
 		{"http.StatusInternalServerError", http.StatusInternalServerError, codes.Internal},
 		{"http.StatusNotImplemented", http.StatusNotImplemented, codes.Unimplemented},
@@ -52,7 +53,7 @@ func TestFromGRPCCode(t *testing.T) {
 		{"codes.PermissionDenied", codes.PermissionDenied, http.StatusForbidden},
 		{"codes.Unauthenticated", codes.Unauthenticated, http.StatusUnauthorized},
 		{"codes.ResourceExhausted", codes.ResourceExhausted, http.StatusTooManyRequests},
-		{"codes.FailedPrecondition", codes.FailedPrecondition, http.StatusBadRequest},
+		{"codes.FailedPrecondition", codes.FailedPrecondition, http.StatusPreconditionFailed},
 		{"codes.Aborted", codes.Aborted, http.StatusConflict},
 		{"codes.OutOfRange", codes.OutOfRange, http.StatusBadRequest},
 		{"codes.Unimplemented", codes.Unimplemented, http.StatusNotImplemented},
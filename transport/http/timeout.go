@@ -0,0 +1,140 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+// ErrHandlerTimeout is written when a handler exceeds the HandlerTimeout.
+var ErrHandlerTimeout = kratoserrors.GatewayTimeout("HANDLER_TIMEOUT", "handler timeout")
+
+// HandlerTimeout with the maximum duration of a route handler. On expiry
+// the handler context is canceled and ErrHandlerTimeout is written with the
+// error encoder, unless the handler already sent the response header; the
+// later writes of the handler then fail with http.ErrHandlerTimeout.
+// Unlike Timeout, which only sets the context deadline, the client always
+// gets a response. Default is disabled.
+func HandlerTimeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.handlerTimeout = d
+	}
+}
+
+func (s *Server) timeoutFilter(next http.Handler) http.Handler {
+	if s.handlerTimeout <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the handler context is canceled only once the timeout response is
+		// claimed, so a handler reacting to it never races with it.
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+		timer := time.NewTimer(s.handlerTimeout)
+		defer timer.Stop()
+		tw := &timeoutWriter{w: w, h: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+				close(done)
+			}()
+			next.ServeHTTP(tw, req.WithContext(ctx))
+		}()
+		select {
+		case <-done:
+			select {
+			case p := <-panicked:
+				panic(p)
+			default:
+			}
+		case <-req.Context().Done():
+			if !tw.timeout() {
+				cancel()
+				<-done
+			}
+		case <-timer.C:
+			if !tw.timeout() {
+				// too late to respond, the handler finishes its response
+				cancel()
+				<-done
+				return
+			}
+			s.ene(w, req, ErrHandlerTimeout)
+		}
+	})
+}
+
+// timeoutWriter passes the response of the handler through until the
+// handler times out, its header is copied to the response on WriteHeader.
+type timeoutWriter struct {
+	w http.ResponseWriter
+	h http.Header
+
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+// timeout discards the later writes of the handler, it reports false when
+// the handler already sent the response header.
+func (tw *timeoutWriter) timeout() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.wroteHeader {
+		return false
+	}
+	tw.timedOut = true
+	return true
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.writeHeader(code)
+}
+
+func (tw *timeoutWriter) writeHeader(code int) {
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	dst := tw.w.Header()
+	for k, v := range tw.h {
+		dst[k] = v
+	}
+	tw.w.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeader(http.StatusOK)
+	return tw.w.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	tw.writeHeader(http.StatusOK)
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestHandlerTimeout(t *testing.T) {
+	canceled := make(chan error, 1)
+	srv := NewServer(HandlerTimeout(50 * time.Millisecond))
+	route := srv.Route("/")
+	route.GET("/fast", func(ctx Context) error {
+		return ctx.Result(http.StatusOK, &User{Name: "fast"})
+	})
+	route.GET("/slow", func(ctx Context) error {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		// the late response is discarded
+		return ctx.Result(http.StatusOK, &User{Name: "slow"})
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || string(body) != `{"name":"fast"}` {
+		t.Errorf("unexpected response: %d %s", res.StatusCode, body)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != appJSONStr {
+		t.Errorf("expected content type %s, got %q", appJSONStr, ct)
+	}
+
+	res, err = http.Get(ts.URL + "/slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusGatewayTimeout {
+		t.Fatalf("expected code 504, got %d", res.StatusCode)
+	}
+	se := new(kratoserrors.Error)
+	if err = json.Unmarshal(body, se); err != nil {
+		t.Fatalf("unexpected body %s: %v", body, err)
+	}
+	if se.Reason != ErrHandlerTimeout.Reason {
+		t.Errorf("expected reason %s, got %s", ErrHandlerTimeout.Reason, se.Reason)
+	}
+	select {
+	case err = <-canceled:
+		if err == nil {
+			t.Error("expected the handler context canceled")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the handler")
+	}
+}
+
+func TestHandlerTimeoutWritten(t *testing.T) {
+	srv := NewServer(HandlerTimeout(20 * time.Millisecond))
+	srv.Route("/").GET("/stream", func(ctx Context) error {
+		ctx.Response().WriteHeader(http.StatusAccepted)
+		<-ctx.Done()
+		if _, err := ctx.Response().Write([]byte("late")); err != nil {
+			return err
+		}
+		return nil
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	// the response already started, it is not written twice
+	if res.StatusCode != http.StatusAccepted || string(body) != "late" {
+		t.Errorf("unexpected response: %d %s", res.StatusCode, body)
+	}
+}
+
+func TestTimeoutWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	tw := &timeoutWriter{w: rec, h: make(http.Header)}
+	tw.Header().Set("X-Test", "1")
+	tw.WriteHeader(http.StatusCreated)
+	tw.WriteHeader(http.StatusOK)
+	if rec.Code != http.StatusCreated || rec.Header().Get("X-Test") != "1" {
+		t.Errorf("unexpected response: %d %v", rec.Code, rec.Header())
+	}
+	tw.timedOut = true
+	if _, err := tw.Write([]byte("data")); !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("expected %v, got %v", http.ErrHandlerTimeout, err)
+	}
+}
@@ -23,6 +23,11 @@ type Endpointer interface {
 	Endpoint() (*url.URL, error)
 }
 
+// Readier is implemented by servers reporting whether they accept requests.
+type Readier interface {
+	Ready() error
+}
+
 // Header is the storage medium used by a Header.
 type Header interface {
 	Get(key string) string
@@ -70,6 +75,7 @@ const (
 type (
 	serverTransportKey struct{}
 	clientTransportKey struct{}
+	attemptKey         struct{}
 )
 
 // NewServerContext returns a new Context that carries value.
@@ -93,3 +99,16 @@ func FromClientContext(ctx context.Context) (tr Transporter, ok bool) {
 	tr, ok = ctx.Value(clientTransportKey{}).(Transporter)
 	return
 }
+
+// NewAttemptContext returns a new Context that carries the attempt number
+// of a request, 0 for the first attempt and incremented on every retry.
+func NewAttemptContext(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// AttemptFromContext returns the attempt number stored in ctx, 0 when the
+// request is not retried, e.g. to label logs, metrics and spans.
+func AttemptFromContext(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
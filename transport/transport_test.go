@@ -85,3 +85,13 @@ func TestClientTransport(t *testing.T) {
 		t.Errorf("expected:%v got:%v", "test_endpoint", mtr.endpoint)
 	}
 }
+
+func TestAttemptContext(t *testing.T) {
+	ctx := context.Background()
+	if got := AttemptFromContext(ctx); got != 0 {
+		t.Errorf("expected attempt 0 without retries, got %d", got)
+	}
+	if got := AttemptFromContext(NewAttemptContext(ctx, 2)); got != 2 {
+		t.Errorf("expected attempt 2, got %d", got)
+	}
+}